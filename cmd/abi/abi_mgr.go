@@ -21,9 +21,18 @@ package abi
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/log"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+)
+
+const (
+	NEOVM_ABI_DIR      = "neovm"     //Sub dir of abi path keeping the registered neovm contract abi
+	MAX_NEOVM_ABI_SIZE = 1024 * 1024 //Max size of neovm contract abi file
 )
 
 var DefAbiMgr = NewAbiMgr()
@@ -31,15 +40,20 @@ var DefAbiMgr = NewAbiMgr()
 type AbiMgr struct {
 	Path       string
 	nativeAbis map[string]*NativeContractAbi
+	neovmAbis  map[string]*NeovmContractAbi
+	lock       sync.RWMutex
 }
 
 func NewAbiMgr() *AbiMgr {
 	return &AbiMgr{
 		nativeAbis: make(map[string]*NativeContractAbi),
+		neovmAbis:  make(map[string]*NeovmContractAbi),
 	}
 }
 
 func (this *AbiMgr) GetNativeAbi(address string) *NativeContractAbi {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
 	abi, ok := this.nativeAbis[address]
 	if ok {
 		return abi
@@ -47,9 +61,56 @@ func (this *AbiMgr) GetNativeAbi(address string) *NativeContractAbi {
 	return nil
 }
 
+//GetNeovmAbi return the registered abi of neovm contract, address is in hex string
+func (this *AbiMgr) GetNeovmAbi(address string) *NeovmContractAbi {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	abi, ok := this.neovmAbis[address]
+	if ok {
+		return abi
+	}
+	return nil
+}
+
+//RegisterNeovmAbi register the abi of neovm contract, which replaces the abi registered before. The abi is saved
+//to NEOVM_ABI_DIR of abi path, and loaded by Init
+func (this *AbiMgr) RegisterNeovmAbi(abi *NeovmContractAbi) error {
+	address, err := common.AddressFromHexString(strings.TrimPrefix(abi.Address, "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid contract hash:%s", abi.Address)
+	}
+	abi.Address = address.ToHexString()
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if _, ok := this.nativeAbis[abi.Address]; ok {
+		return fmt.Errorf("contract:%s is native contract", abi.Address)
+	}
+	if this.Path != "" {
+		data, err := json.Marshal(abi)
+		if err != nil {
+			return err
+		}
+		if len(data) > MAX_NEOVM_ABI_SIZE {
+			return fmt.Errorf("abi size:%d exceed max size:%d", len(data), MAX_NEOVM_ABI_SIZE)
+		}
+		dir := filepath.Join(this.Path, NEOVM_ABI_DIR)
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(filepath.Join(dir, abi.Address+".json"), data, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	this.neovmAbis[abi.Address] = abi
+	return nil
+}
+
 func (this *AbiMgr) Init(path string) {
 	this.Path = path
 	this.loadNativeAbi()
+	this.loadNeovmAbi()
 }
 
 func (this *AbiMgr) loadNativeAbi() {
@@ -77,7 +138,43 @@ func (this *AbiMgr) loadNativeAbi() {
 			log.Errorf("AbiMgr loadNativeAbi name:%s error:%s", fileName, err)
 			continue
 		}
+		this.lock.Lock()
 		this.nativeAbis[nativeAbi.Address] = nativeAbi
+		this.lock.Unlock()
 		log.Infof("Native contract name:%s address:%s abi load success", fileName, nativeAbi.Address)
 	}
 }
+
+func (this *AbiMgr) loadNeovmAbi() {
+	dir := filepath.Join(this.Path, NEOVM_ABI_DIR)
+	abiFiles, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, abiFile := range abiFiles {
+		fileName := abiFile.Name()
+		if abiFile.IsDir() || !strings.HasSuffix(fileName, ".json") || abiFile.Size() > MAX_NEOVM_ABI_SIZE {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			log.Errorf("AbiMgr loadNeovmAbi name:%s error:%s", fileName, err)
+			continue
+		}
+		neovmAbi := &NeovmContractAbi{}
+		err = json.Unmarshal(data, neovmAbi)
+		if err != nil {
+			log.Errorf("AbiMgr loadNeovmAbi name:%s error:%s", fileName, err)
+			continue
+		}
+		address, err := common.AddressFromHexString(strings.TrimPrefix(neovmAbi.Address, "0x"))
+		if err != nil {
+			log.Errorf("AbiMgr loadNeovmAbi name:%s invalid hash:%s", fileName, neovmAbi.Address)
+			continue
+		}
+		neovmAbi.Address = address.ToHexString()
+		this.lock.Lock()
+		this.neovmAbis[neovmAbi.Address] = neovmAbi
+		this.lock.Unlock()
+	}
+}
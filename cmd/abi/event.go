@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dnaproject2/DNA/common"
+)
+
+//EventField is a named and typed field of contract event decoded by abi
+type EventField struct {
+	Name  string
+	Type  string
+	Value interface{}
+}
+
+//Event is a contract event decoded by abi
+type Event struct {
+	Name   string
+	Fields []*EventField
+}
+
+//DecodeEvent decode the states of event notified by contract at address in hex string, return nil if the abi of
+//contract or event is not found, or the states do not match the event abi.
+//The first state is the event name, and the parameters of event abi are the following states, or all the states
+//if the count of parameters equals to the count of states, e.g. the event abi of ontid contract
+func (this *AbiMgr) DecodeEvent(address string, states interface{}) *Event {
+	values := toSlice(states)
+	if len(values) == 0 {
+		return nil
+	}
+	if nativeAbi := this.GetNativeAbi(address); nativeAbi != nil {
+		name, ok := values[0].(string)
+		if !ok {
+			return nil
+		}
+		evtAbi := nativeAbi.GetEvent(name)
+		if evtAbi == nil {
+			return nil
+		}
+		params := make([]*NeovmContractParamsAbi, 0, len(evtAbi.Parameters))
+		for _, param := range evtAbi.Parameters {
+			params = append(params, &NeovmContractParamsAbi{Name: param.Name, Type: param.Type})
+		}
+		return decodeEvent(evtAbi.Name, params, values, decodeNativeValue)
+	}
+	if neovmAbi := this.GetNeovmAbi(address); neovmAbi != nil {
+		return DecodeNeovmEvent(neovmAbi, values)
+	}
+	return nil
+}
+
+//DecodeNeovmEvent decode the states of event notified by neovm contract with the given abi, return nil if the
+//event is not found in abi or the states do not match the event abi
+func DecodeNeovmEvent(neovmAbi *NeovmContractAbi, states interface{}) *Event {
+	values := toSlice(states)
+	if len(values) == 0 {
+		return nil
+	}
+	name, ok := decodeNeovmValue(NEOVM_PARAM_TYPE_STRING, values[0]).(string)
+	if !ok {
+		return nil
+	}
+	evtAbi := neovmAbi.GetEvent(name)
+	if evtAbi == nil {
+		return nil
+	}
+	return decodeEvent(evtAbi.Name, evtAbi.Parameters, values, decodeNeovmValue)
+}
+
+func decodeEvent(name string, params []*NeovmContractParamsAbi, values []interface{},
+	decode func(typ string, value interface{}) interface{}) *Event {
+	if len(params) == len(values)-1 {
+		values = values[1:]
+	} else if len(params) != len(values) {
+		return nil
+	}
+	evt := &Event{Name: name, Fields: make([]*EventField, 0, len(params))}
+	for i, param := range params {
+		evt.Fields = append(evt.Fields, &EventField{
+			Name:  param.Name,
+			Type:  param.Type,
+			Value: decode(param.Type, values[i]),
+		})
+	}
+	return evt
+}
+
+func toSlice(states interface{}) []interface{} {
+	switch v := states.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		values := make([]interface{}, 0, len(v))
+		for _, s := range v {
+			values = append(values, s)
+		}
+		return values
+	}
+	return nil
+}
+
+//decodeNativeValue return the value of native contract event, which is already typed
+func decodeNativeValue(typ string, value interface{}) interface{} {
+	if data, ok := value.([]byte); ok {
+		return common.ToHexString(data)
+	}
+	return value
+}
+
+//decodeNeovmValue decode the value of neovm contract event in hex string by abi type, return the value unchanged
+//if failed
+func decodeNeovmValue(typ string, value interface{}) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+	data, err := common.HexToBytes(str)
+	if err != nil {
+		return value
+	}
+	switch strings.ToLower(typ) {
+	case NEOVM_PARAM_TYPE_STRING:
+		if utf8.Valid(data) {
+			return string(data)
+		}
+	case NEOVM_PARAM_TYPE_INTEGER:
+		return common.BigIntFromNeoBytes(data)
+	case NEOVM_PARAM_TYPE_BOOL:
+		for _, b := range data {
+			if b != 0 {
+				return true
+			}
+		}
+		return false
+	case NEOVM_PARAM_TYPE_ADDRESS, NEOVM_PARAM_TYPE_HASH160:
+		if len(data) == common.ADDR_LEN {
+			address, _ := common.AddressParseFromBytes(data)
+			return address.ToBase58()
+		}
+	}
+	return value
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeNativeEvent(t *testing.T) {
+	mgr := NewAbiMgr()
+	mgr.Init("./native_abi_script")
+
+	ont := "0100000000000000000000000000000000000000"
+	evt := mgr.DecodeEvent(ont, []interface{}{"transfer", "from", "to", uint64(10)})
+	assert.NotNil(t, evt)
+	assert.Equal(t, "transfer", evt.Name)
+	assert.Equal(t, 3, len(evt.Fields))
+	assert.Equal(t, "value", evt.Fields[2].Name)
+	assert.Equal(t, uint64(10), evt.Fields[2].Value)
+
+	//the parameters of ontid event include the event name
+	ontid := "0300000000000000000000000000000000000000"
+	evt = mgr.DecodeEvent(ontid, []string{"Register", "did:dna:foo"})
+	assert.NotNil(t, evt)
+	assert.Equal(t, "operation", evt.Fields[0].Name)
+	assert.Equal(t, "Register", evt.Fields[0].Value)
+	assert.Equal(t, "did:dna:foo", evt.Fields[1].Value)
+
+	assert.Nil(t, mgr.DecodeEvent(ont, []interface{}{"unknown"}))
+	assert.Nil(t, mgr.DecodeEvent(ont, []interface{}{"transfer", "from"}))
+	assert.Nil(t, mgr.DecodeEvent(ont, "transfer"))
+}
+
+func TestDecodeNeovmEvent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "abi")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	mgr := NewAbiMgr()
+	mgr.Init(dir)
+	contract := common.AddressFromVmCode([]byte{1})
+	err = mgr.RegisterNeovmAbi(&NeovmContractAbi{
+		Address: "0x" + contract.ToHexString(),
+		Events: []*NeovmContractEventAbi{{
+			Name: "Transfer",
+			Parameters: []*NeovmContractParamsAbi{
+				{Name: "from", Type: "Hash160"},
+				{Name: "memo", Type: "String"},
+				{Name: "amount", Type: "Integer"},
+				{Name: "ok", Type: "Boolean"},
+				{Name: "data", Type: "ByteArray"},
+			},
+		}},
+	})
+	assert.Nil(t, err)
+	err = mgr.RegisterNeovmAbi(&NeovmContractAbi{Address: "0x01"})
+	assert.NotNil(t, err)
+
+	//the registered abi is loaded again
+	mgr = NewAbiMgr()
+	mgr.Init(dir)
+	assert.NotNil(t, mgr.GetNeovmAbi(contract.ToHexString()))
+
+	states := []interface{}{
+		common.ToHexString([]byte("transfer")),
+		common.ToHexString(contract[:]),
+		common.ToHexString([]byte("hi")),
+		common.ToHexString(common.BigIntToNeoBytes(big.NewInt(300))),
+		"01",
+		"abcd",
+	}
+	evt := mgr.DecodeEvent(contract.ToHexString(), states)
+	assert.NotNil(t, evt)
+	assert.Equal(t, "Transfer", evt.Name)
+	assert.Equal(t, contract.ToBase58(), evt.Fields[0].Value)
+	assert.Equal(t, "hi", evt.Fields[1].Value)
+	assert.Equal(t, big.NewInt(300), evt.Fields[2].Value)
+	assert.Equal(t, true, evt.Fields[3].Value)
+	assert.Equal(t, "abcd", evt.Fields[4].Value)
+
+	assert.Nil(t, mgr.DecodeEvent(common.ADDRESS_EMPTY.ToHexString(), states))
+}
@@ -27,6 +27,8 @@ const (
 	NEOVM_PARAM_TYPE_BYTE_ARRAY = "bytearray"
 	NEOVM_PARAM_TYPE_VOID       = "void"
 	NEOVM_PARAM_TYPE_ANY        = "any"
+	NEOVM_PARAM_TYPE_ADDRESS    = "address"
+	NEOVM_PARAM_TYPE_HASH160    = "hash160"
 )
 
 type NeovmContractAbi struct {
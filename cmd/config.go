@@ -49,6 +49,29 @@ func SetDNAConfig(ctx *cli.Context) (*config.DNAConfig, error) {
 		cfg.P2PNode.NetworkMagic = config.GetNetworkMagic(cfg.P2PNode.NetworkId)
 		cfg.Common.GasPrice = 0
 	}
+	if cfg.Common.PruneKeepBlocks != 0 && cfg.Common.PruneKeepBlocks < config.MIN_PRUNE_KEEP_BLOCKS {
+		return nil, fmt.Errorf("prune keep blocks should not be less than %d", config.MIN_PRUNE_KEEP_BLOCKS)
+	}
+	if cfg.Common.EnableLightMode {
+		if cfg.Genesis.ConsensusType == config.CONSENSUS_TYPE_SOLO {
+			return nil, fmt.Errorf("light mode is not supported by solo consensus")
+		}
+		cfg.Consensus.EnableConsensus = false
+		cfg.P2PNode.EnableFastSync = false
+		cfg.Restful.EnableHttpRestful = false
+		cfg.Ws.EnableHttpWs = false
+	}
+	if cfg.P2PNode.EnableFastSync {
+		//the digest is the only trust anchor of snapshot, which is not committed on chain
+		digest := cfg.P2PNode.FastSyncStateDigest
+		if digest == "" {
+			return nil, fmt.Errorf("fast sync requires a trusted state digest, set it by --%s",
+				utils.GetFlagName(utils.FastSyncStateDigestFlag))
+		}
+		if hash, err := common.Uint256FromHexString(digest); err != nil || hash == common.UINT256_EMPTY {
+			return nil, fmt.Errorf("invalid fast sync state digest %s", digest)
+		}
+	}
 	if cfg.P2PNode.NetworkId == config.NETWORK_ID_MAIN_NET ||
 		cfg.P2PNode.NetworkId == config.NETWORK_ID_POLARIS_NET {
 		defNetworkId, err := cfg.GetDefaultNetworkId()
@@ -128,6 +151,12 @@ func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.GasLimit = ctx.Uint64(utils.GetFlagName(utils.GasLimitFlag))
 	cfg.GasPrice = ctx.Uint64(utils.GetFlagName(utils.GasPriceFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.SnapshotInterval = uint32(ctx.Uint(utils.GetFlagName(utils.SnapshotIntervalFlag)))
+	cfg.EnableLightMode = ctx.Bool(utils.GetFlagName(utils.EnableLightModeFlag))
+	cfg.PruneKeepBlocks = uint32(ctx.Uint(utils.GetFlagName(utils.PruneKeepBlocksFlag)))
+	cfg.StorageBackend = ctx.String(utils.GetFlagName(utils.StorageBackendFlag))
+	cfg.ExecWorkers = ctx.Uint(utils.GetFlagName(utils.ExecWorkersFlag))
+	cfg.TraceHistoryBlocks = uint32(ctx.Uint(utils.GetFlagName(utils.TraceHistoryFlag)))
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
@@ -146,6 +175,9 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.MaxConnOutBound = ctx.Uint(utils.GetFlagName(utils.MaxConnOutBoundFlag))
 	cfg.MaxConnInBoundForSingleIP = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundForSingleIPFlag))
 	cfg.CertPath = ctx.String(utils.GetFlagName(utils.CertPathFlag))
+	cfg.DisableCompression = ctx.Bool(utils.GetFlagName(utils.DisableCompressionFlag))
+	cfg.EnableFastSync = ctx.Bool(utils.GetFlagName(utils.EnableFastSyncFlag))
+	cfg.FastSyncStateDigest = ctx.String(utils.GetFlagName(utils.FastSyncStateDigestFlag))
 
 	rsvfile := ctx.String(utils.GetFlagName(utils.ReservedPeersFileFlag))
 	if cfg.ReservedPeersOnly {
@@ -26,6 +26,10 @@ import (
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/config"
 	httpcom "github.com/dnaproject2/DNA/http/base/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/auth"
+	"github.com/dnaproject2/DNA/smartcontract/service/neovm"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/dnaproject2/DNA/vm/neovm/disasm"
 	"github.com/urfave/cli"
 	"io/ioutil"
 	"strings"
@@ -37,7 +41,7 @@ var (
 		Action:      cli.ShowSubcommandHelp,
 		Usage:       "Deploy or invoke smart contract",
 		ArgsUsage:   " ",
-		Description: `Smart contract operations support the deployment of NeoVM smart contract, and the pre-execution and execution of NeoVM smart contract.`,
+		Description: `Smart contract operations support the deployment of NeoVM smart contract, the pre-execution and execution of NeoVM smart contract, the disassembly and assembly of NeoVM code, and the debugging of NeoVM smart contract.`,
 		Subcommands: []cli.Command{
 			{
 				Action:    deployContract,
@@ -77,6 +81,7 @@ var (
 
   Return type
      When invoke contract with --prepare flag, you need specifies return type by --return flag, to decode the return value.
+     If --return flag is not set, the return type is taken from the abi published on chain, if any.
      Return type support bytearray(encoded to hex string), string, integer, boolean. 
      If return type is object array, enclose array with '[]'. 
      For example: [string,int,bool,string]
@@ -90,6 +95,61 @@ var (
 					utils.ContractVersionFlag,
 					utils.ContractPrepareInvokeFlag,
 					utils.ContractReturnTypeFlag,
+					utils.ContractProfileFlag,
+					utils.ExecutorFileFlag,
+					utils.AccountAddressFlag,
+				},
+			},
+			{
+				Action:    upgradeContract,
+				Name:      "upgrade",
+				Usage:     "Upgrade the code of smart contract in place",
+				ArgsUsage: " ",
+				Description: `Replace the code of deployed smart contract while keeping its address and storage. The upgrade must be signed by the contract admin set in auth contract, contracts without admin can not be upgraded.
+
+  If --migrate flag is set, the method of new code is called after upgrade, with the previous version as argument.`,
+				Flags: []cli.Flag{
+					utils.RPCPortFlag,
+					utils.TransactionGasPriceFlag,
+					utils.TransactionGasLimitFlag,
+					utils.ContractAddrFlag,
+					utils.ContractStorageFlag,
+					utils.ContractCodeFileFlag,
+					utils.ContractNameFlag,
+					utils.ContractVersionFlag,
+					utils.ContractAuthorFlag,
+					utils.ContractEmailFlag,
+					utils.ContractDescFlag,
+					utils.ContractMigrateMethodFlag,
+					utils.ContractAdminKeyNoFlag,
+					utils.ExecutorFileFlag,
+					utils.AccountAddressFlag,
+				},
+			},
+			{
+				Action:      contractHistory,
+				Name:        "history",
+				Usage:       "Show the code version history of smart contract",
+				ArgsUsage:   " ",
+				Description: "Show the code versions of smart contract, from the first deployment to the latest upgrade.",
+				Flags: []cli.Flag{
+					utils.RPCPortFlag,
+					utils.ContractAddrFlag,
+				},
+			},
+			{
+				Action:      publishContract,
+				Name:        "publish",
+				Usage:       "Publish the abi and source hash of smart contract",
+				ArgsUsage:   " ",
+				Description: "Publish the abi and source hash of smart contract to the on-chain registry, so that explorers and tools can fetch the abi of contract. Only the account which deployed the contract can publish.",
+				Flags: []cli.Flag{
+					utils.RPCPortFlag,
+					utils.TransactionGasPriceFlag,
+					utils.TransactionGasLimitFlag,
+					utils.ContractAddrFlag,
+					utils.ContractAbiFileFlag,
+					utils.ContractSourceHashFlag,
 					utils.ExecutorFileFlag,
 					utils.AccountAddressFlag,
 				},
@@ -109,6 +169,44 @@ var (
 					utils.AccountAddressFlag,
 				},
 			},
+			{
+				Action:      disasmContract,
+				Name:        "disasm",
+				Usage:       "Disassemble NeoVM contract code",
+				ArgsUsage:   " ",
+				Description: "Disassemble NeoVM code in hex from code file, or of the contract deployed at address, into assembly.",
+				Flags: []cli.Flag{
+					utils.RPCPortFlag,
+					utils.ContractCodeFileFlag,
+					utils.ContractAddrFlag,
+				},
+			},
+			{
+				Action:    debugContract,
+				Name:      "debug",
+				Usage:     "Debug smart contract invocation step by step",
+				ArgsUsage: " ",
+				Description: `Pre-execute the invocation of smart contract against the local ledger, and debug it interactively with breakpoints, stepping and inspection of stacks and storage. The parameters are the same as invoke command.
+
+  Note that the ledger of data dir is opened by debug command, so the node using the same data dir must be stopped.`,
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.ConfigFlag,
+					utils.NetworkIdFlag,
+					utils.ContractAddrFlag,
+					utils.ContractParamsFlag,
+				},
+			},
+			{
+				Action:      asmContract,
+				Name:        "asm",
+				Usage:       "Assemble NeoVM contract code",
+				ArgsUsage:   " ",
+				Description: "Assemble the assembly in code file, in the format printed by disasm, into NeoVM code in hex.",
+				Flags: []cli.Flag{
+					utils.ContractCodeFileFlag,
+				},
+			},
 		},
 	}
 )
@@ -182,6 +280,94 @@ func deployContract(ctx *cli.Context) error {
 	return nil
 }
 
+func upgradeContract(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if !ctx.IsSet(utils.GetFlagName(utils.ContractAddrFlag)) ||
+		!ctx.IsSet(utils.GetFlagName(utils.ContractCodeFileFlag)) {
+		PrintErrorMsg("Missing %s or %s argument.", utils.ContractAddrFlag.Name, utils.ContractCodeFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	contractAddr, err := common.AddressFromHexString(ctx.String(utils.GetFlagName(utils.ContractAddrFlag)))
+	if err != nil {
+		return fmt.Errorf("invalid contract address error:%s", err)
+	}
+	codeFile := ctx.String(utils.GetFlagName(utils.ContractCodeFileFlag))
+	codeStr, err := ioutil.ReadFile(codeFile)
+	if err != nil {
+		return fmt.Errorf("read code:%s error:%s", codeFile, err)
+	}
+	code, err := common.HexToBytes(strings.TrimSpace(string(codeStr)))
+	if err != nil {
+		return fmt.Errorf("contrace code convert hex to bytes error:%s", err)
+	}
+	param := &auth.UpgradeContractParam{
+		ContractAddr:  contractAddr,
+		Code:          code,
+		NeedStorage:   ctx.Bool(utils.GetFlagName(utils.ContractStorageFlag)),
+		Name:          ctx.String(utils.GetFlagName(utils.ContractNameFlag)),
+		Version:       ctx.String(utils.GetFlagName(utils.ContractVersionFlag)),
+		Author:        ctx.String(utils.GetFlagName(utils.ContractAuthorFlag)),
+		Email:         ctx.String(utils.GetFlagName(utils.ContractEmailFlag)),
+		Description:   ctx.String(utils.GetFlagName(utils.ContractDescFlag)),
+		MigrateMethod: ctx.String(utils.GetFlagName(utils.ContractMigrateMethodFlag)),
+		KeyNo:         ctx.Uint64(utils.GetFlagName(utils.ContractAdminKeyNoFlag)),
+	}
+
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("get signer account error:%s", err)
+	}
+	gasPrice := ctx.Uint64(utils.GetFlagName(utils.TransactionGasPriceFlag))
+	gasLimit := ctx.Uint64(utils.GetFlagName(utils.TransactionGasLimitFlag))
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+
+	txHash, err := utils.UpgradeContract(gasPrice, gasLimit, signer, param)
+	if err != nil {
+		return fmt.Errorf("UpgradeContract error:%s", err)
+	}
+	codeHash := common.AddressFromVmCode(code)
+	PrintInfoMsg("Upgrade contract:")
+	PrintInfoMsg("  Contract Address:%s", contractAddr.ToHexString())
+	PrintInfoMsg("  Code Hash:%s", codeHash.ToHexString())
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './DNA info status %s' to query transaction status.", txHash)
+	return nil
+}
+
+func contractHistory(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if !ctx.IsSet(utils.GetFlagName(utils.ContractAddrFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.ContractAddrFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	address := ctx.String(utils.GetFlagName(utils.ContractAddrFlag))
+	versions, err := utils.GetContractHistory(address)
+	if err != nil {
+		return fmt.Errorf("get contract:%s history error:%s", address, err)
+	}
+	if len(versions) == 0 {
+		PrintInfoMsg("No version history of contract:%s", address)
+		return nil
+	}
+	for i, v := range versions {
+		PrintInfoMsg("Index:%d", i)
+		PrintInfoMsg("  Version:%s", v.Version)
+		PrintInfoMsg("  Code Hash:%s", v.CodeHash)
+		PrintInfoMsg("  Height:%d", v.Height)
+		PrintInfoMsg("  TxHash:%s", v.TxHash)
+	}
+	return nil
+}
+
 func invokeCodeContract(ctx *cli.Context) error {
 	SetRpcPort(ctx)
 	if !ctx.IsSet(utils.GetFlagName(utils.ContractCodeFileFlag)) {
@@ -297,6 +483,9 @@ func invokeContract(ctx *cli.Context) error {
 	PrintInfoMsg("Invoke:%x Params:%s", contractAddr[:], paramData)
 
 	if ctx.IsSet(utils.GetFlagName(utils.ContractPrepareInvokeFlag)) {
+		if ctx.IsSet(utils.GetFlagName(utils.ContractProfileFlag)) {
+			return profileContract(ctx, contractAddr, params)
+		}
 		preResult, err := utils.PrepareInvokeNeoVMContract(contractAddr, params)
 		if err != nil {
 			return fmt.Errorf("PrepareInvokeNeoVMSmartContact error:%s", err)
@@ -308,6 +497,9 @@ func invokeContract(ctx *cli.Context) error {
 		PrintInfoMsg("  Gas limit:%d", preResult.Gas)
 
 		rawReturnTypes := ctx.String(utils.GetFlagName(utils.ContractReturnTypeFlag))
+		if rawReturnTypes == "" {
+			rawReturnTypes = getAbiReturnType(contractAddrStr, params)
+		}
 		if rawReturnTypes == "" {
 			PrintInfoMsg("  Return:%s (raw value)", preResult.Result)
 			return nil
@@ -350,3 +542,193 @@ func invokeContract(ctx *cli.Context) error {
 	PrintInfoMsg("  Using './DNA info status %s' to query transaction status.", txHash)
 	return nil
 }
+
+//getAbiReturnType return the return type of invoked method by the abi published on chain, or empty string if unknown
+func getAbiReturnType(contractAddr string, params []interface{}) string {
+	if len(params) == 0 {
+		return ""
+	}
+	method, ok := params[0].(string)
+	if !ok {
+		return ""
+	}
+	contractAbi, err := utils.GetContractAbi(contractAddr)
+	if err != nil || contractAbi == nil {
+		return ""
+	}
+	funcAbi := contractAbi.GetFunc(method)
+	if funcAbi == nil {
+		return ""
+	}
+	return utils.GetNeovmReturnType(funcAbi)
+}
+
+func publishContract(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if !ctx.IsSet(utils.GetFlagName(utils.ContractAddrFlag)) ||
+		!ctx.IsSet(utils.GetFlagName(utils.ContractAbiFileFlag)) {
+		PrintErrorMsg("Missing %s or %s argument.", utils.ContractAddrFlag.Name, utils.ContractAbiFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	contractAddrStr := ctx.String(utils.GetFlagName(utils.ContractAddrFlag))
+	contractAddr, err := common.AddressFromHexString(contractAddrStr)
+	if err != nil {
+		return fmt.Errorf("invalid contract address error:%s", err)
+	}
+	abiFile := ctx.String(utils.GetFlagName(utils.ContractAbiFileFlag))
+	abiData, err := ioutil.ReadFile(abiFile)
+	if err != nil {
+		return fmt.Errorf("read abi:%s error:%s", abiFile, err)
+	}
+	contractAbi, err := utils.NewNeovmContractAbi(abiData)
+	if err != nil {
+		return err
+	}
+	if contractAbi.Address != "" {
+		abiAddr, err := common.AddressFromHexString(strings.TrimPrefix(contractAbi.Address, "0x"))
+		if err != nil || abiAddr != contractAddr {
+			return fmt.Errorf("hash:%s of abi mismatch contract address:%s", contractAbi.Address, contractAddrStr)
+		}
+	}
+	//publish the compact json of abi to save storage
+	abiData, err = json.Marshal(contractAbi)
+	if err != nil {
+		return fmt.Errorf("json.Marshal abi error:%s", err)
+	}
+	var sourceHash []byte
+	if ctx.IsSet(utils.GetFlagName(utils.ContractSourceHashFlag)) {
+		sourceHash, err = common.HexToBytes(strings.TrimPrefix(ctx.String(utils.GetFlagName(utils.ContractSourceHashFlag)), "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid source hash error:%s", err)
+		}
+	}
+
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("get signer account error:%s", err)
+	}
+	gasPrice := ctx.Uint64(utils.GetFlagName(utils.TransactionGasPriceFlag))
+	gasLimit := ctx.Uint64(utils.GetFlagName(utils.TransactionGasLimitFlag))
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+
+	txHash, err := utils.PublishContractAbi(gasPrice, gasLimit, signer, contractAddr, abiData, sourceHash)
+	if err != nil {
+		return fmt.Errorf("publish contract abi error:%s", err)
+	}
+	PrintInfoMsg("Publish abi of contract:%s", contractAddr.ToHexString())
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTips:")
+	PrintInfoMsg("  Using './DNA info status %s' to query transaction status.", txHash)
+	return nil
+}
+
+func disasmContract(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	var code []byte
+	switch {
+	case ctx.IsSet(utils.GetFlagName(utils.ContractCodeFileFlag)):
+		codeFile := ctx.String(utils.GetFlagName(utils.ContractCodeFileFlag))
+		codeStr, err := ioutil.ReadFile(codeFile)
+		if err != nil {
+			return fmt.Errorf("read code:%s error:%s", codeFile, err)
+		}
+		code, err = common.HexToBytes(strings.TrimSpace(string(codeStr)))
+		if err != nil {
+			return fmt.Errorf("contrace code convert hex to bytes error:%s", err)
+		}
+	case ctx.IsSet(utils.GetFlagName(utils.ContractAddrFlag)):
+		address := ctx.String(utils.GetFlagName(utils.ContractAddrFlag))
+		c, err := utils.GetContractCode(address)
+		if err != nil {
+			return fmt.Errorf("get contract:%s error:%s", address, err)
+		}
+		code = c
+	default:
+		PrintErrorMsg("Missing %s or %s argument.", utils.ContractCodeFileFlag.Name, utils.ContractAddrFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+
+	instrs, err := disasm.Disassemble(code)
+	if err != nil {
+		return fmt.Errorf("disassemble error:%s", err)
+	}
+	fmt.Print(disasm.Format(instrs))
+	for _, instr := range instrs {
+		if instr.Op != vm.SYSCALL {
+			continue
+		}
+		if _, ok := neovm.ServiceMap[instr.Syscall]; !ok {
+			//print as comment to keep the output assemblable
+			PrintInfoMsg("; unsupported syscall %q at offset 0x%04x", instr.Syscall, instr.Offset)
+		}
+	}
+	return nil
+}
+
+func asmContract(ctx *cli.Context) error {
+	if !ctx.IsSet(utils.GetFlagName(utils.ContractCodeFileFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.ContractCodeFileFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	codeFile := ctx.String(utils.GetFlagName(utils.ContractCodeFileFlag))
+	text, err := ioutil.ReadFile(codeFile)
+	if err != nil {
+		return fmt.Errorf("read code:%s error:%s", codeFile, err)
+	}
+	code, err := disasm.Assemble(string(text))
+	if err != nil {
+		return fmt.Errorf("assemble error:%s", err)
+	}
+	fmt.Println(common.ToHexString(code))
+	return nil
+}
+
+func profileContract(ctx *cli.Context, contractAddr common.Address, params []interface{}) error {
+	result, err := utils.ProfileInvokeNeoVMContract(contractAddr, params)
+	if err != nil {
+		return fmt.Errorf("ProfileInvokeNeoVMContract error:%s", err)
+	}
+	if result.State == 0 {
+		PrintErrorMsg("Contract invoke failed:%s", result.Error)
+	} else {
+		PrintInfoMsg("Contract invoke successfully")
+	}
+	PrintInfoMsg("  Gas consumed:%d", result.GasConsumed)
+	PrintInfoMsg("Contracts:")
+	for _, item := range result.Contracts {
+		PrintInfoMsg("  %s calls:%d self:%d total:%d", item.Contract, item.Calls, item.SelfGas, item.TotalGas)
+	}
+	PrintInfoMsg("Syscalls:")
+	for _, item := range result.Syscalls {
+		PrintInfoMsg("  %s count:%d gas:%d", item.Name, item.Count, item.Gas)
+	}
+	PrintInfoMsg("Storage writes:")
+	for _, item := range result.Storage {
+		PrintInfoMsg("  %s %s count:%d gas:%d", item.Contract, item.Key, item.Count, item.Gas)
+	}
+	PrintInfoMsg("Opcodes:")
+	for _, item := range result.Opcodes {
+		PrintInfoMsg("  %s count:%d gas:%d", item.Name, item.Count, item.Gas)
+	}
+
+	file := ctx.String(utils.GetFlagName(utils.ContractProfileFlag))
+	folded := ""
+	if len(result.Folded) > 0 {
+		folded = strings.Join(result.Folded, "\n") + "\n"
+	}
+	err = ioutil.WriteFile(file, []byte(folded), 0644)
+	if err != nil {
+		return fmt.Errorf("write profile file:%s error:%s", file, err)
+	}
+	PrintInfoMsg("Folded stacks written to %s", file)
+	return nil
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dnaproject2/DNA/cmd/utils"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/genesis"
+	"github.com/dnaproject2/DNA/core/ledger"
+	httpcom "github.com/dnaproject2/DNA/http/base/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/debug"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/vm/neovm/disasm"
+	"github.com/urfave/cli"
+)
+
+const debugHelp = `Commands:
+  break <offset> [contract]    set breakpoint at offset of contract, default current contract
+  delete <offset> [contract]   remove breakpoint
+  continue (c)                 run until breakpoint or the end of execution
+  step (s)                     step to next opcode, entering called contract
+  next (n)                     step to next opcode of current contract
+  out (o)                      run until current contract returns
+  stack                        print evaluation stack, top first
+  altstack                     print alt stack, top first
+  storage <key> [contract]     print storage value, key in hex
+  frames (bt)                  print call stack
+  list (l)                     print code around current offset
+  quit (q)                     abort execution and quit`
+
+func debugContract(ctx *cli.Context) error {
+	log.InitLog(log.ErrorLog)
+	if !ctx.IsSet(utils.GetFlagName(utils.ContractAddrFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.ContractAddrFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	contractAddr, err := common.AddressFromHexString(ctx.String(utils.GetFlagName(utils.ContractAddrFlag)))
+	if err != nil {
+		return fmt.Errorf("invalid contract address error:%s", err)
+	}
+	params, err := utils.ParseParams(ctx.String(utils.GetFlagName(utils.ContractParamsFlag)))
+	if err != nil {
+		return fmt.Errorf("parseParams error:%s", err)
+	}
+	mutable, err := httpcom.NewNeovmInvokeTransaction(0, 0, contractAddr, params)
+	if err != nil {
+		return err
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return err
+	}
+
+	err = initDebugLedger(ctx)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+
+	session := debug.NewSession(ledger.DefLedger.GetStore(), func(tracer context.Tracer) (*sstate.PreExecResult, error) {
+		return ledger.DefLedger.PreExecuteContractWithTracer(tx, tracer)
+	})
+	session.SetBreakpoint(contractAddr, 0)
+	PrintInfoMsg("Debug:%s, paused at entry. Type 'help' for commands.", contractAddr.ToHexString())
+
+	current := contractAddr
+	offset := 0
+	reader := bufio.NewReader(os.Stdin)
+	for !session.Finished() {
+		fmt.Printf("(debug) ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			session.Abort()
+			return nil
+		}
+		args := strings.Fields(line)
+		if len(args) == 0 {
+			continue
+		}
+		var stop *debug.Stop
+		switch args[0] {
+		case "help", "h":
+			PrintInfoMsg(debugHelp)
+		case "break", "b", "delete", "d":
+			bpOffset, contract, err := parseDebugLocation(args[1:], current)
+			if err != nil {
+				PrintErrorMsg("%s", err)
+				continue
+			}
+			if args[0] == "delete" || args[0] == "d" {
+				session.ClearBreakpoint(contract, bpOffset)
+			} else {
+				session.SetBreakpoint(contract, bpOffset)
+			}
+		case "continue", "c":
+			stop = session.Continue()
+		case "step", "s":
+			stop = session.StepInto()
+		case "next", "n":
+			stop = session.StepOver()
+		case "out", "o":
+			stop = session.StepOut()
+		case "quit", "q":
+			stop = session.Abort()
+		case "stack", "altstack":
+			var items []interface{}
+			if args[0] == "stack" {
+				items, err = session.EvaluationStack()
+			} else {
+				items, err = session.AltStack()
+			}
+			if err != nil {
+				PrintErrorMsg("%s", err)
+				continue
+			}
+			for i, item := range items {
+				data, _ := json.Marshal(item)
+				PrintInfoMsg("  %d: %s", i, data)
+			}
+		case "storage":
+			if len(args) < 2 {
+				PrintErrorMsg("Missing storage key")
+				continue
+			}
+			key, err := hex.DecodeString(args[1])
+			if err != nil {
+				PrintErrorMsg("invalid storage key:%s", err)
+				continue
+			}
+			contract := current
+			if len(args) > 2 {
+				contract, err = common.AddressFromHexString(args[2])
+				if err != nil {
+					PrintErrorMsg("invalid contract address:%s", err)
+					continue
+				}
+			}
+			value, err := session.Storage(contract, key)
+			if err != nil {
+				PrintErrorMsg("%s", err)
+				continue
+			}
+			PrintInfoMsg("  %x", value)
+		case "frames", "bt":
+			frames, err := session.Frames()
+			if err != nil {
+				PrintErrorMsg("%s", err)
+				continue
+			}
+			for i, frame := range frames {
+				if frame.Type == "neovm" {
+					PrintInfoMsg("  #%d %s %s 0x%04x %s", i, frame.Type, frame.Contract.ToHexString(), frame.Offset, frame.Op)
+				} else {
+					PrintInfoMsg("  #%d %s %s %s", i, frame.Type, frame.Contract.ToHexString(), frame.Method)
+				}
+			}
+		case "list", "l":
+			err := listDebugCode(session, offset)
+			if err != nil {
+				PrintErrorMsg("%s", err)
+			}
+		default:
+			PrintErrorMsg("Unknown command:%s, type 'help' for commands.", args[0])
+		}
+		if stop == nil {
+			continue
+		}
+		if stop.Finished {
+			printDebugResult(stop)
+			break
+		}
+		current, offset = stop.Contract, stop.Offset
+		reason := ""
+		if stop.Breakpoint {
+			reason = " (breakpoint)"
+		}
+		PrintInfoMsg("Paused at %s 0x%04x %s depth:%d%s", stop.Contract.ToHexString(), stop.Offset, stop.Op, stop.Depth, reason)
+	}
+	return nil
+}
+
+func initDebugLedger(ctx *cli.Context) error {
+	cfg, err := SetDNAConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("SetDNAConfig error:%s", err)
+	}
+	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
+	stateHashHeight := config.GetStateHashCheckHeight(cfg.P2PNode.NetworkId)
+	ledger.DefLedger, err = ledger.NewLedger(dbDir, stateHashHeight)
+	if err != nil {
+		return fmt.Errorf("NewLedger error:%s", err)
+	}
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		return fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+	return nil
+}
+
+func parseDebugLocation(args []string, current common.Address) (int, common.Address, error) {
+	if len(args) == 0 {
+		return 0, current, fmt.Errorf("missing offset")
+	}
+	offset, err := strconv.ParseInt(args[0], 0, 32)
+	if err != nil || offset < 0 {
+		return 0, current, fmt.Errorf("invalid offset:%s", args[0])
+	}
+	if len(args) > 1 {
+		current, err = common.AddressFromHexString(args[1])
+		if err != nil {
+			return 0, current, fmt.Errorf("invalid contract address:%s", err)
+		}
+	}
+	return int(offset), current, nil
+}
+
+func listDebugCode(session *debug.Session, offset int) error {
+	code, err := session.Code()
+	if err != nil {
+		return err
+	}
+	instrs, err := disasm.Disassemble(code)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(strings.TrimRight(disasm.Format(instrs), "\n"), "\n")
+	pos := fmt.Sprintf("0x%04x ", offset)
+	index := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, pos) {
+			index = i
+			break
+		}
+	}
+	start, end := index-5, index+6
+	if start < 0 {
+		start = 0
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for i := start; i < end; i++ {
+		if i == index {
+			fmt.Printf("=> %s\n", lines[i])
+		} else {
+			fmt.Printf("   %s\n", lines[i])
+		}
+	}
+	return nil
+}
+
+func printDebugResult(stop *debug.Stop) {
+	if stop.Err != nil {
+		PrintErrorMsg("Execution failed:%s", stop.Err)
+		return
+	}
+	if stop.Result == nil {
+		PrintInfoMsg("Execution finished")
+		return
+	}
+	PrintInfoMsg("Execution finished, state:%d gas:%d", stop.Result.State, stop.Result.Gas)
+	result, _ := json.Marshal(stop.Result.Result)
+	PrintInfoMsg("  Return:%s (raw value)", result)
+	for _, notify := range stop.Result.Notify {
+		data, _ := json.Marshal(notify.States)
+		PrintInfoMsg("  Notify:%s %s", notify.ContractAddress.ToHexString(), data)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	cmdcom "github.com/dnaproject2/DNA/cmd/common"
+	"github.com/dnaproject2/DNA/cmd/utils"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/did"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/claimrecord"
+	"github.com/urfave/cli"
+)
+
+var CredentialCommand = cli.Command{
+	Name:        "credential",
+	Usage:       "Issue, verify and revoke verifiable credentials",
+	Description: "Credential management commands issue the credential signed by the key of DNA ID, anchor its status in claim record contract, and verify it against chain state.",
+	Subcommands: []cli.Command{
+		{
+			Action:    issueCredential,
+			Name:      "issue",
+			Usage:     "Issue a credential and commit it to claim record contract",
+			ArgsUsage: " ",
+			Description: `Issue a JWT credential signed by the key of issuer's DNA ID, and commit the credential id to claim record contract. The signer account must own the key of --keyno in issuer's DNA ID.
+
+  The claims about the subject are given by --claims in JSON object, for example '{"name":"alice"}'.`,
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.TransactionGasPriceFlag,
+				utils.TransactionGasLimitFlag,
+				utils.CredentialIssuerFlag,
+				utils.CredentialSubjectFlag,
+				utils.CredentialKeyNoFlag,
+				utils.CredentialTypeFlag,
+				utils.CredentialClaimsFlag,
+				utils.CredentialExpireFlag,
+				utils.ExecutorFileFlag,
+				utils.AccountAddressFlag,
+			},
+		},
+		{
+			Action:      verifyCredential,
+			Name:        "verify",
+			Usage:       "Verify a credential against chain state",
+			ArgsUsage:   "<credential>",
+			Description: "Verify the signature of credential by the DID document of issuer, and check the credential is committed and not revoked in claim record contract.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
+		{
+			Action:      revokeCredential,
+			Name:        "revoke",
+			Usage:       "Revoke a committed credential",
+			ArgsUsage:   "<credential>",
+			Description: "Revoke the credential in claim record contract. The signer account must own the key of --keyno in issuer's DNA ID.",
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+				utils.TransactionGasPriceFlag,
+				utils.TransactionGasLimitFlag,
+				utils.CredentialKeyNoFlag,
+				utils.ExecutorFileFlag,
+				utils.AccountAddressFlag,
+			},
+		},
+	},
+}
+
+func issueCredential(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if !ctx.IsSet(utils.GetFlagName(utils.CredentialIssuerFlag)) ||
+		!ctx.IsSet(utils.GetFlagName(utils.CredentialSubjectFlag)) {
+		PrintErrorMsg("Missing %s or %s argument.", utils.CredentialIssuerFlag.Name, utils.CredentialSubjectFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	issuer := ctx.String(utils.GetFlagName(utils.CredentialIssuerFlag))
+	subject := ctx.String(utils.GetFlagName(utils.CredentialSubjectFlag))
+	keyNo := ctx.Uint(utils.GetFlagName(utils.CredentialKeyNoFlag))
+	types := make([]string, 0)
+	for _, t := range strings.Split(ctx.String(utils.GetFlagName(utils.CredentialTypeFlag)), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	subjectClaims := make(map[string]interface{})
+	if claims := ctx.String(utils.GetFlagName(utils.CredentialClaimsFlag)); claims != "" {
+		if err := json.Unmarshal([]byte(claims), &subjectClaims); err != nil {
+			return fmt.Errorf("invalid claims:%s", err)
+		}
+	}
+	now := time.Now().Unix()
+	var expiration int64
+	if expire := ctx.Uint64(utils.GetFlagName(utils.CredentialExpireFlag)); expire > 0 {
+		expiration = now + int64(expire)
+	}
+
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("get signer account error:%s", err)
+	}
+	claims, err := did.NewCredentialClaims(issuer, subject, types, subjectClaims, now, expiration)
+	if err != nil {
+		return err
+	}
+	credential, err := did.IssueCredential(signer, uint32(keyNo), claims)
+	if err != nil {
+		return err
+	}
+
+	gasPrice := ctx.Uint64(utils.GetFlagName(utils.TransactionGasPriceFlag))
+	gasLimit := ctx.Uint64(utils.GetFlagName(utils.TransactionGasLimitFlag))
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+	txHash, err := utils.CommitClaim(gasPrice, gasLimit, signer, &claimrecord.CommitParam{
+		ClaimId: []byte(claims.ID),
+		Issuer:  []byte(issuer),
+		KeyNo:   uint64(keyNo),
+		Subject: []byte(subject),
+	})
+	if err != nil {
+		return fmt.Errorf("CommitClaim error:%s", err)
+	}
+	PrintInfoMsg("Issue credential:")
+	PrintInfoMsg("  Credential Id:%s", claims.ID)
+	PrintInfoMsg("  Credential:%s", credential)
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './DNA info status %s' to query transaction status.", txHash)
+	return nil
+}
+
+func verifyCredential(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing credential argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	cred, err := utils.VerifyCredential(ctx.Args().First())
+	if cred != nil {
+		PrintInfoMsg("Credential:")
+		PrintInfoMsg("  Credential Id:%s", cred.Claims.ID)
+		PrintInfoMsg("  Issuer:%s", cred.Claims.Issuer)
+		PrintInfoMsg("  Subject:%s", cred.Claims.Subject)
+		PrintInfoMsg("  Issued At:%s", time.Unix(cred.Claims.IssuedAt, 0).Format(time.RFC3339))
+		if cred.Claims.Expiration != 0 {
+			PrintInfoMsg("  Expiration:%s", time.Unix(cred.Claims.Expiration, 0).Format(time.RFC3339))
+		}
+		if cred.Claims.Credential != nil {
+			PrintJsonObject(cred.Claims.Credential.CredentialSubject)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid credential:%s", err)
+	}
+	PrintInfoMsg("Credential is valid.")
+	return nil
+}
+
+func revokeCredential(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing credential argument.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	cred, err := did.ParseCredential(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	signer, err := cmdcom.GetAccount(ctx)
+	if err != nil {
+		return fmt.Errorf("get signer account error:%s", err)
+	}
+	gasPrice := ctx.Uint64(utils.GetFlagName(utils.TransactionGasPriceFlag))
+	gasLimit := ctx.Uint64(utils.GetFlagName(utils.TransactionGasLimitFlag))
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+	txHash, err := utils.RevokeClaim(gasPrice, gasLimit, signer, &claimrecord.RevokeParam{
+		ClaimId: []byte(cred.Claims.ID),
+		Issuer:  []byte(cred.Claims.Issuer),
+		KeyNo:   uint64(ctx.Uint(utils.GetFlagName(utils.CredentialKeyNoFlag))),
+	})
+	if err != nil {
+		return fmt.Errorf("RevokeClaim error:%s", err)
+	}
+	PrintInfoMsg("Revoke credential:")
+	PrintInfoMsg("  Credential Id:%s", cred.Claims.ID)
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './DNA info status %s' to query transaction status.", txHash)
+	return nil
+}
@@ -72,6 +72,36 @@ var InfoCommand = cli.Command{
 				utils.RPCPortFlag,
 			},
 		},
+		{
+			Action:      governancePeers,
+			Name:        "peers",
+			Usage:       "Display the consensus and candidate peers",
+			ArgsUsage:   "",
+			Description: `Display the peers of current view with stake and status. NextStatus is the status of peer at next view if the stakes are not changed.`,
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
+		{
+			Action:      governanceStake,
+			Name:        "stake",
+			Usage:       "Display the authorizations and withdrawable stake of address",
+			ArgsUsage:   "<address>",
+			Description: `Display the authorizations of address to the peers of current view, the stake can be withdrawn and the fee not withdrawn yet.`,
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
+		{
+			Action:      feeSplit,
+			Name:        "feesplit",
+			Usage:       "Display the fee split of peers at the end of view",
+			ArgsUsage:   "[view]",
+			Description: `Display the fee split of peers at the end of view, the last finished view is used if view is omitted. Fee split is only recorded for views committed after its activation height.`,
+			Flags: []cli.Flag{
+				utils.RPCPortFlag,
+			},
+		},
 	},
 	Description: `Query information command can query information such as blocks, transactions, and transaction executions. 
 You can use the ./DNA info block --help command to view help information.`,
@@ -165,6 +195,63 @@ func curBlockHeight(ctx *cli.Context) error {
 	return nil
 }
 
+func governancePeers(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	info, err := utils.GetGovernancePeers()
+	if err != nil {
+		return fmt.Errorf("GetGovernancePeers error:%s", err)
+	}
+	PrintJsonObject(info)
+	return nil
+}
+
+func governanceStake(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.NArg() < 1 {
+		PrintErrorMsg("Missing argument. Address expected.")
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	info, err := utils.GetGovernanceStake(ctx.Args().First())
+	if err != nil {
+		return fmt.Errorf("GetGovernanceStake error:%s", err)
+	}
+	PrintJsonObject(info)
+	return nil
+}
+
+func feeSplit(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	var view uint64
+	var err error
+	if ctx.NArg() > 0 {
+		view, err = strconv.ParseUint(ctx.Args().First(), 10, 32)
+		if err != nil {
+			return fmt.Errorf("arg:%s invalid view", ctx.Args().First())
+		}
+	} else {
+		peers, err := utils.GetGovernancePeers()
+		if err != nil {
+			return fmt.Errorf("GetGovernancePeers error:%s", err)
+		}
+		if peers.View <= 1 {
+			PrintInfoMsg("No view is finished yet.")
+			return nil
+		}
+		view = uint64(peers.View - 1)
+	}
+	info, err := utils.GetFeeSplit(uint32(view))
+	if err != nil {
+		return fmt.Errorf("GetFeeSplit error:%s", err)
+	}
+	if info == nil {
+		PrintInfoMsg("Fee split of view %d is not recorded.", view)
+		return nil
+	}
+	PrintJsonObject(info)
+	return nil
+}
+
 func showTx(ctx *cli.Context) error {
 	SetRpcPort(ctx)
 	if ctx.NArg() < 1 {
@@ -30,4 +30,7 @@ func init() {
 	DefCliRpcSvr.RegHandler("signeovminvoketx", handlers.SigNeoVMInvokeTx)
 	DefCliRpcSvr.RegHandler("signeovminvokeabitx", handlers.SigNeoVMInvokeAbiTx)
 	DefCliRpcSvr.RegHandler("signativeinvoketx", handlers.SigNativeInvokeTx)
+	DefCliRpcSvr.RegHandler("sigcredential", handlers.SigCredential)
+	DefCliRpcSvr.RegHandler("verifycredential", handlers.VerifyCredential)
+	DefCliRpcSvr.RegHandler("sigstaketx", handlers.SigStakeTx)
 }
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	clisvrcom "github.com/dnaproject2/DNA/cmd/sigsvr/common"
+	cliutil "github.com/dnaproject2/DNA/cmd/utils"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/did"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/claimrecord"
+)
+
+type SigCredentialReq struct {
+	Issuer     string                 `json:"issuer"`
+	Subject    string                 `json:"subject"`
+	KeyNo      uint32                 `json:"key_no"`
+	Types      []string               `json:"types"`
+	Claims     map[string]interface{} `json:"claims"`
+	Expiration int64                  `json:"expiration"`
+	GasPrice   uint64                 `json:"gas_price"`
+	GasLimit   uint64                 `json:"gas_limit"`
+	Payer      string                 `json:"payer"`
+}
+
+type SigCredentialRsp struct {
+	ClaimId    string `json:"claim_id"`
+	Credential string `json:"credential"`
+	SignedTx   string `json:"signed_tx"`
+}
+
+//SigCredential issue a JWT credential signed by the key of issuer, with the signed transaction to commit it to claim
+//record contract
+func SigCredential(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigCredentialReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigCredential json.Unmarshal SigCredentialReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	if rawReq.Issuer == "" || rawReq.Subject == "" {
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	if rawReq.KeyNo == 0 {
+		rawReq.KeyNo = 1
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigCredential GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	claims, err := did.NewCredentialClaims(rawReq.Issuer, rawReq.Subject, rawReq.Types, rawReq.Claims,
+		time.Now().Unix(), rawReq.Expiration)
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	credential, err := did.IssueCredential(signer, rawReq.KeyNo, claims)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigCredential IssueCredential error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+
+	tx, err := cliutil.CommitClaimTx(rawReq.GasPrice, rawReq.GasLimit, &claimrecord.CommitParam{
+		ClaimId: []byte(claims.ID),
+		Issuer:  []byte(rawReq.Issuer),
+		KeyNo:   uint64(rawReq.KeyNo),
+		Subject: []byte(rawReq.Subject),
+	})
+	if err != nil {
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	if rawReq.Payer != "" {
+		payerAddress, err := common.AddressFromBase58(rawReq.Payer)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigCredential AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+		tx.Payer = payerAddress
+	}
+	err = cliutil.SignTransaction(signer, tx)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigCredential SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	immutable, err := tx.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigCredential convert to immutable transaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	buf := bytes.NewBuffer(nil)
+	err = immutable.Serialize(buf)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigCredential tx Serialize error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	resp.Result = &SigCredentialRsp{
+		ClaimId:    claims.ID,
+		Credential: credential,
+		SignedTx:   hex.EncodeToString(buf.Bytes()),
+	}
+}
+
+type VerifyCredentialReq struct {
+	Credential string `json:"credential"`
+}
+
+type VerifyCredentialRsp struct {
+	Valid   bool   `json:"valid"`
+	ClaimId string `json:"claim_id"`
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+//VerifyCredential verify the JWT credential against chain state through the rpc of DNA node
+func VerifyCredential(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &VerifyCredentialReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s VerifyCredential json.Unmarshal VerifyCredentialReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	cred, err := cliutil.VerifyCredential(rawReq.Credential)
+	if cred == nil {
+		log.Infof("Cli Qid:%s VerifyCredential ParseCredential error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	rsp := &VerifyCredentialRsp{
+		Valid:   err == nil,
+		ClaimId: cred.Claims.ID,
+		Issuer:  cred.Claims.Issuer,
+		Subject: cred.Claims.Subject,
+	}
+	if err != nil {
+		rsp.Reason = err.Error()
+	}
+	resp.Result = rsp
+}
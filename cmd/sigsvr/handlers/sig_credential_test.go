@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	clisvrcom "github.com/dnaproject2/DNA/cmd/sigsvr/common"
+	"github.com/dnaproject2/DNA/did"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func TestSigCredential(t *testing.T) {
+	defAcc, err := testExecutor.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Errorf("GetDefaultAccount error:%s", err)
+		return
+	}
+	issuer, _ := account.GenerateID()
+	subject, _ := account.GenerateID()
+	credReq := &SigCredentialReq{
+		Issuer:   issuer,
+		Subject:  subject,
+		KeyNo:    1,
+		Types:    []string{"KYCCredential"},
+		Claims:   map[string]interface{}{"name": "alice"},
+		GasLimit: 20000,
+	}
+	data, err := json.Marshal(credReq)
+	if err != nil {
+		t.Errorf("json.Marshal SigCredentialReq error:%s", err)
+		return
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "sigcredential",
+		Params:  data,
+		Account: defAcc.Address.ToBase58(),
+		Pwd:     string(pwd),
+	}
+	rsp := &clisvrcom.CliRpcResponse{}
+	SigCredential(req, rsp)
+	if rsp.ErrorCode != 0 {
+		t.Errorf("SigCredential failed. ErrorCode:%d ErrorInfo:%s", rsp.ErrorCode, rsp.ErrorInfo)
+		return
+	}
+	result := rsp.Result.(*SigCredentialRsp)
+	cred, err := did.ParseCredential(result.Credential)
+	if err != nil {
+		t.Errorf("ParseCredential error:%s", err)
+		return
+	}
+	if cred.Claims.ID != result.ClaimId || cred.Claims.Subject != subject {
+		t.Errorf("unexpected credential claims:%+v", cred.Claims)
+		return
+	}
+	doc := &did.Document{
+		ID: issuer,
+		VerificationMethod: []*did.VerificationMethod{{
+			ID:           did.KeyID(issuer, 1),
+			Controller:   issuer,
+			PublicKeyHex: hex.EncodeToString(keypair.SerializePublicKey(defAcc.PublicKey)),
+		}},
+	}
+	if err := cred.VerifySignature(doc); err != nil {
+		t.Errorf("VerifySignature error:%s", err)
+		return
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"github.com/dnaproject2/DNA/cmd/abi"
 	clisvrcom "github.com/dnaproject2/DNA/cmd/sigsvr/common"
 	cliutil "github.com/dnaproject2/DNA/cmd/utils"
 	"github.com/dnaproject2/DNA/common"
@@ -50,11 +51,27 @@ func SigNeoVMInvokeAbiTx(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcRes
 		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
 		return
 	}
-	contractAbi, err := cliutil.NewNeovmContractAbi(rawReq.ContractAbi)
-	if err != nil {
-		resp.ErrorCode = clisvrcom.CLIERR_ABI_UNMATCH
-		resp.ErrorInfo = err.Error()
-		return
+	var contractAbi *abi.NeovmContractAbi
+	if len(rawReq.ContractAbi) == 0 {
+		//fetch the abi published on chain if not specified
+		contractAbi, err = cliutil.GetContractAbi(rawReq.Address)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigNeoVMInvokeAbiTx GetContractAbi:%s error:%s", req.Qid, rawReq.Address, err)
+			resp.ErrorCode = clisvrcom.CLIERR_ABI_NOT_FOUND
+			resp.ErrorInfo = err.Error()
+			return
+		}
+		if contractAbi == nil {
+			resp.ErrorCode = clisvrcom.CLIERR_ABI_NOT_FOUND
+			return
+		}
+	} else {
+		contractAbi, err = cliutil.NewNeovmContractAbi(rawReq.ContractAbi)
+		if err != nil {
+			resp.ErrorCode = clisvrcom.CLIERR_ABI_UNMATCH
+			resp.ErrorInfo = err.Error()
+			return
+		}
 	}
 	funcAbi := contractAbi.GetFunc(rawReq.Method)
 	if funcAbi == nil {
@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	clisvrcom "github.com/dnaproject2/DNA/cmd/sigsvr/common"
+	cliutil "github.com/dnaproject2/DNA/cmd/utils"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/log"
+)
+
+type SigStakeTxReq struct {
+	Method      string   `json:"method"`
+	PeerPubkeys []string `json:"peer_pubkeys"`
+	Amounts     []uint32 `json:"amounts"`
+	PeerCost    uint32   `json:"peer_cost"`
+	Caller      string   `json:"caller"`
+	KeyNo       uint32   `json:"key_no"`
+	GasPrice    uint64   `json:"gas_price"`
+	GasLimit    uint64   `json:"gas_limit"`
+	Payer       string   `json:"payer"`
+}
+
+type SigStakeTxRsp struct {
+	Preview  []string `json:"preview"`
+	SignedTx string   `json:"signed_tx"`
+}
+
+//SigStakeTx sign the transaction of staking operation in governance contract by the account of request, Method is
+//one of cliutil.StakeMethods
+func SigStakeTx(req *clisvrcom.CliRpcRequest, resp *clisvrcom.CliRpcResponse) {
+	rawReq := &SigStakeTxReq{}
+	err := json.Unmarshal(req.Params, rawReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigStakeTx json.Unmarshal SigStakeTxReq:%s error:%s", req.Qid, req.Params, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		return
+	}
+	if rawReq.KeyNo == 0 {
+		rawReq.KeyNo = 1
+	}
+	signer, err := req.GetAccount()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigStakeTx GetAccount:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_ACCOUNT_UNLOCK
+		return
+	}
+	stakeReq := &cliutil.StakeRequest{
+		Method:      rawReq.Method,
+		Address:     signer.Address,
+		PeerPubkeys: rawReq.PeerPubkeys,
+		Amounts:     rawReq.Amounts,
+		PeerCost:    rawReq.PeerCost,
+		Caller:      rawReq.Caller,
+		KeyNo:       rawReq.KeyNo,
+	}
+	mutable, err := cliutil.StakeTx(rawReq.GasPrice, rawReq.GasLimit, stakeReq)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigStakeTx StakeTx error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+		resp.ErrorInfo = err.Error()
+		return
+	}
+	if rawReq.Payer != "" {
+		payerAddress, err := common.AddressFromBase58(rawReq.Payer)
+		if err != nil {
+			log.Infof("Cli Qid:%s SigStakeTx AddressFromBase58 error:%s", req.Qid, err)
+			resp.ErrorCode = clisvrcom.CLIERR_INVALID_PARAMS
+			return
+		}
+		mutable.Payer = payerAddress
+	}
+	err = cliutil.SignTransaction(signer, mutable)
+	if err != nil {
+		log.Infof("Cli Qid:%s SigStakeTx SignTransaction error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		log.Infof("Cli Qid:%s SigStakeTx tx IntoImmutable error:%s", req.Qid, err)
+		resp.ErrorCode = clisvrcom.CLIERR_INTERNAL_ERR
+		return
+	}
+	sink := common.ZeroCopySink{}
+	tx.Serialization(&sink)
+	resp.Result = &SigStakeTxRsp{
+		Preview:  stakeReq.Preview(),
+		SignedTx: hex.EncodeToString(sink.Bytes()),
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	clisvrcom "github.com/dnaproject2/DNA/cmd/sigsvr/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+func sigStakeTx(t *testing.T, stakeReq *SigStakeTxReq) *clisvrcom.CliRpcResponse {
+	defAcc, err := testExecutor.GetDefaultAccount(pwd)
+	if err != nil {
+		t.Fatalf("GetDefaultAccount error:%s", err)
+	}
+	data, err := json.Marshal(stakeReq)
+	if err != nil {
+		t.Fatalf("json.Marshal SigStakeTxReq error:%s", err)
+	}
+	req := &clisvrcom.CliRpcRequest{
+		Qid:     "t",
+		Method:  "sigstaketx",
+		Params:  data,
+		Account: defAcc.Address.ToBase58(),
+		Pwd:     string(pwd),
+	}
+	rsp := &clisvrcom.CliRpcResponse{}
+	SigStakeTx(req, rsp)
+	return rsp
+}
+
+func TestSigStakeTx(t *testing.T) {
+	peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(account.NewAccount("").PublicKey))
+	rsp := sigStakeTx(t, &SigStakeTxReq{
+		Method:      governance.AUTHORIZE_FOR_PEER,
+		PeerPubkeys: []string{peerPubkey},
+		Amounts:     []uint32{500},
+		GasLimit:    20000,
+	})
+	if rsp.ErrorCode != 0 {
+		t.Errorf("SigStakeTx failed. ErrorCode:%d ErrorInfo:%s", rsp.ErrorCode, rsp.ErrorInfo)
+		return
+	}
+	result := rsp.Result.(*SigStakeTxRsp)
+	if len(result.Preview) != 2 {
+		t.Errorf("unexpected preview:%v", result.Preview)
+		return
+	}
+	data, err := hex.DecodeString(result.SignedTx)
+	if err != nil {
+		t.Errorf("hex.DecodeString error:%s", err)
+		return
+	}
+	if _, err := types.TransactionFromRawBytes(data); err != nil {
+		t.Errorf("TransactionFromRawBytes error:%s", err)
+		return
+	}
+
+	for _, stakeReq := range []*SigStakeTxReq{
+		{Method: governance.COMMIT_DPOS},
+		{Method: governance.AUTHORIZE_FOR_PEER, PeerPubkeys: []string{peerPubkey}},
+		{Method: governance.SET_PEER_COST, PeerPubkeys: []string{peerPubkey}, PeerCost: 101},
+		{Method: governance.QUIT_NODE, PeerPubkeys: []string{"invalid"}},
+	} {
+		rsp := sigStakeTx(t, stakeReq)
+		if rsp.ErrorCode != clisvrcom.CLIERR_INVALID_PARAMS {
+			t.Errorf("SigStakeTx %s should fail with invalid params, ErrorCode:%d", stakeReq.Method, rsp.ErrorCode)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmdcom "github.com/dnaproject2/DNA/cmd/common"
+	"github.com/dnaproject2/DNA/cmd/utils"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/urfave/cli"
+)
+
+var stakeTxFlags = []cli.Flag{
+	utils.RPCPortFlag,
+	utils.TransactionGasPriceFlag,
+	utils.TransactionGasLimitFlag,
+	utils.StakePreviewFlag,
+	utils.ExecutorFileFlag,
+	utils.AccountAddressFlag,
+}
+
+var StakeCommand = cli.Command{
+	Name:        "stake",
+	Usage:       "Register candidate peers and authorize stake to peers",
+	Description: "Stake commands build, preview and send the staking transactions of governance contract for peer owners and stakers. Using --preview to show what the transaction does without sending it.",
+	Subcommands: []cli.Command{
+		{
+			Action:      registerCandidate,
+			Name:        "register",
+			Usage:       "Register a candidate peer",
+			ArgsUsage:   " ",
+			Description: "Register a candidate peer with init pos of account. The DNA ID of --caller must be authorized to register candidate by the governance contract.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakeAmountFlag,
+				utils.StakeCallerFlag,
+				utils.StakeKeyNoFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      authorizeForPeer,
+			Name:        "authorize",
+			Usage:       "Authorize ONT to peers",
+			ArgsUsage:   " ",
+			Description: "Authorize ONT of account to peers, --amount gives the ONT of each peer in --peer.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakeAmountFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      unAuthorizeForPeer,
+			Name:        "unauthorize",
+			Usage:       "Unauthorize ONT from peers",
+			ArgsUsage:   " ",
+			Description: "Unauthorize ONT of account from peers, the ONT can be withdrawn after it is unfrozen.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakeAmountFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      withdrawStake,
+			Name:        "withdraw",
+			Usage:       "Withdraw unfrozen ONT from peers",
+			ArgsUsage:   " ",
+			Description: "Withdraw unfrozen ONT of account from peers. All withdrawable ONT of the peers is withdrawn if --amount is omitted, and all peers with withdrawable ONT are used if --peer is omitted too.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakeAmountFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      withdrawFee,
+			Name:        "withdrawfee",
+			Usage:       "Withdraw split fee of ONG",
+			ArgsUsage:   " ",
+			Description: "Withdraw all split fee of ONG of account.",
+			Flags:       stakeTxFlags,
+		},
+		{
+			Action:      setPeerCost,
+			Name:        "setpeercost",
+			Usage:       "Set the percentage of split fee kept by peer",
+			ArgsUsage:   " ",
+			Description: "Set the percentage of split fee kept by peer owner, the rest is split to stakers. It takes effect after 2 views.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakePeerCostFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      addInitPos,
+			Name:        "addinitpos",
+			Usage:       "Add init pos of peer",
+			ArgsUsage:   " ",
+			Description: "Add init pos of peer by the ONT of peer owner.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakeAmountFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      reduceInitPos,
+			Name:        "reduceinitpos",
+			Usage:       "Reduce init pos of peer",
+			ArgsUsage:   " ",
+			Description: "Reduce init pos of peer, the init pos can not be less than the promise pos and the authorized pos limit.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+				utils.StakeAmountFlag,
+			}, stakeTxFlags...),
+		},
+		{
+			Action:      quitNode,
+			Name:        "quit",
+			Usage:       "Quit peer",
+			ArgsUsage:   " ",
+			Description: "Quit peer owned by account, the init pos can be withdrawn after the peer quits.",
+			Flags: append([]cli.Flag{
+				utils.StakePeerFlag,
+			}, stakeTxFlags...),
+		},
+	},
+}
+
+func registerCandidate(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if !ctx.IsSet(utils.GetFlagName(utils.StakeCallerFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.StakeCallerFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	req, err := newStakeRequest(ctx, governance.REGISTER_CANDIDATE, true)
+	if err != nil || req == nil {
+		return err
+	}
+	req.Caller = ctx.String(utils.GetFlagName(utils.StakeCallerFlag))
+	req.KeyNo = uint32(ctx.Uint(utils.GetFlagName(utils.StakeKeyNoFlag)))
+	return sendStake(ctx, req)
+}
+
+func authorizeForPeer(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	req, err := newStakeRequest(ctx, governance.AUTHORIZE_FOR_PEER, true)
+	if err != nil || req == nil {
+		return err
+	}
+	return sendStake(ctx, req)
+}
+
+func unAuthorizeForPeer(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	req, err := newStakeRequest(ctx, governance.UNAUTHORIZE_FOR_PEER, true)
+	if err != nil || req == nil {
+		return err
+	}
+	return sendStake(ctx, req)
+}
+
+func withdrawStake(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if ctx.IsSet(utils.GetFlagName(utils.StakeAmountFlag)) {
+		req, err := newStakeRequest(ctx, governance.WITHDRAW, true)
+		if err != nil || req == nil {
+			return err
+		}
+		return sendStake(ctx, req)
+	}
+	req, err := newStakeRequest(ctx, governance.WITHDRAW, false)
+	if err != nil {
+		return err
+	}
+	stake, err := utils.GetGovernanceStake(req.Address.ToBase58())
+	if err != nil {
+		return fmt.Errorf("GetGovernanceStake error:%s", err)
+	}
+	withdrawable := make(map[string]uint64)
+	for _, authorization := range stake.Authorizations {
+		if authorization.Withdrawable > 0 {
+			withdrawable[authorization.PeerPubkey] = authorization.Withdrawable
+			if !ctx.IsSet(utils.GetFlagName(utils.StakePeerFlag)) {
+				req.PeerPubkeys = append(req.PeerPubkeys, authorization.PeerPubkey)
+			}
+		}
+	}
+	for _, peerPubkey := range req.PeerPubkeys {
+		amount, ok := withdrawable[peerPubkey]
+		if !ok {
+			return fmt.Errorf("no withdrawable ONT of peer:%s", peerPubkey)
+		}
+		req.Amounts = append(req.Amounts, uint32(amount))
+	}
+	if len(req.PeerPubkeys) == 0 {
+		PrintInfoMsg("No withdrawable ONT of account:%s", req.Address.ToBase58())
+		return nil
+	}
+	return sendStake(ctx, req)
+}
+
+func withdrawFee(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	req, err := newStakeRequest(ctx, governance.WITHDRAW_FEE, false)
+	if err != nil {
+		return err
+	}
+	stake, err := utils.GetGovernanceStake(req.Address.ToBase58())
+	if err != nil {
+		return fmt.Errorf("GetGovernanceStake error:%s", err)
+	}
+	if stake.WithdrawFee == 0 {
+		PrintInfoMsg("No split fee of account:%s", req.Address.ToBase58())
+		return nil
+	}
+	PrintInfoMsg("Split fee:%s ONG", utils.FormatOng(stake.WithdrawFee))
+	return sendStake(ctx, req)
+}
+
+func setPeerCost(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	if !ctx.IsSet(utils.GetFlagName(utils.StakePeerCostFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.StakePeerCostFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	req, err := newStakeRequest(ctx, governance.SET_PEER_COST, false)
+	if err != nil || req == nil {
+		return err
+	}
+	req.PeerCost = uint32(ctx.Uint(utils.GetFlagName(utils.StakePeerCostFlag)))
+	return sendStake(ctx, req)
+}
+
+func addInitPos(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	req, err := newStakeRequest(ctx, governance.ADD_INIT_POS, true)
+	if err != nil || req == nil {
+		return err
+	}
+	return sendStake(ctx, req)
+}
+
+func reduceInitPos(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	req, err := newStakeRequest(ctx, governance.REDUCE_INIT_POS, true)
+	if err != nil || req == nil {
+		return err
+	}
+	return sendStake(ctx, req)
+}
+
+func quitNode(ctx *cli.Context) error {
+	SetRpcPort(ctx)
+	req, err := newStakeRequest(ctx, governance.QUIT_NODE, false)
+	if err != nil || req == nil {
+		return err
+	}
+	return sendStake(ctx, req)
+}
+
+//newStakeRequest return the stake request of account with the peers and amounts of flags. It returns nil request
+//after showing help if the required flags are missing
+func newStakeRequest(ctx *cli.Context, method string, withAmount bool) (*utils.StakeRequest, error) {
+	peerSet := ctx.IsSet(utils.GetFlagName(utils.StakePeerFlag))
+	amountSet := ctx.IsSet(utils.GetFlagName(utils.StakeAmountFlag))
+	needPeer := method != governance.WITHDRAW_FEE && method != governance.WITHDRAW
+	if (needPeer && !peerSet) || (withAmount && !amountSet) {
+		if withAmount {
+			PrintErrorMsg("Missing %s or %s argument.", utils.StakePeerFlag.Name, utils.StakeAmountFlag.Name)
+		} else {
+			PrintErrorMsg("Missing %s argument.", utils.StakePeerFlag.Name)
+		}
+		cli.ShowSubcommandHelp(ctx)
+		return nil, nil
+	}
+	executor, err := cmdcom.OpenExecutor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	acc := cmdcom.GetAccountMetadataMulti(executor, ctx.String(utils.GetFlagName(utils.AccountAddressFlag)))
+	if acc == nil {
+		return nil, fmt.Errorf("cannot get account")
+	}
+	address, err := common.AddressFromBase58(acc.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account address:%s", err)
+	}
+	req := &utils.StakeRequest{
+		Method:  method,
+		Address: address,
+	}
+	if peerSet {
+		for _, peerPubkey := range strings.Split(ctx.String(utils.GetFlagName(utils.StakePeerFlag)), ",") {
+			req.PeerPubkeys = append(req.PeerPubkeys, strings.TrimSpace(peerPubkey))
+		}
+	}
+	if withAmount {
+		for _, amountStr := range strings.Split(ctx.String(utils.GetFlagName(utils.StakeAmountFlag)), ",") {
+			amount, err := strconv.ParseUint(strings.TrimSpace(amountStr), 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount:%s", amountStr)
+			}
+			req.Amounts = append(req.Amounts, uint32(amount))
+		}
+	}
+	return req, nil
+}
+
+//sendStake show the preview of staking operation, and send the transaction signed by account if not preview only
+func sendStake(ctx *cli.Context, req *utils.StakeRequest) error {
+	gasPrice := ctx.Uint64(utils.GetFlagName(utils.TransactionGasPriceFlag))
+	gasLimit := ctx.Uint64(utils.GetFlagName(utils.TransactionGasLimitFlag))
+	networkId, err := utils.GetNetworkId()
+	if err != nil {
+		return err
+	}
+	if networkId == config.NETWORK_ID_SOLO_NET {
+		gasPrice = 0
+	}
+	tx, err := utils.StakeTx(gasPrice, gasLimit, req)
+	if err != nil {
+		return err
+	}
+	peers, err := utils.GetGovernancePeers()
+	if err != nil {
+		return fmt.Errorf("GetGovernancePeers error:%s", err)
+	}
+
+	PrintInfoMsg("Stake %s:", req.Method)
+	for _, line := range req.Preview() {
+		PrintInfoMsg("  %s", line)
+	}
+	for _, peerPubkey := range req.PeerPubkeys {
+		found := false
+		for _, peer := range peers.Peers {
+			if peer.PeerPubkey != peerPubkey {
+				continue
+			}
+			found = true
+			PrintInfoMsg("  Peer %s:", peerPubkey)
+			PrintInfoMsg("    Owner:%s", peer.Address)
+			PrintInfoMsg("    Status:%s", peer.Status)
+			PrintInfoMsg("    InitPos:%d ONT", peer.InitPos)
+			PrintInfoMsg("    TotalPos:%d ONT", peer.TotalPos)
+			PrintInfoMsg("    MaxAuthorize:%d ONT", peer.MaxAuthorize)
+			PrintInfoMsg("    PeerCost:%d%%", peer.PeerCost)
+		}
+		if !found {
+			PrintInfoMsg("  Peer %s is not in peer pool of view %d", peerPubkey, peers.View)
+		}
+	}
+	if ctx.Bool(utils.GetFlagName(utils.StakePreviewFlag)) {
+		PrintInfoMsg("\nTip:")
+		PrintInfoMsg("  Remove --%s flag to send the transaction.", utils.StakePreviewFlag.Name)
+		return nil
+	}
+
+	signer, err := cmdcom.GetAccount(ctx, req.Address.ToBase58())
+	if err != nil {
+		return fmt.Errorf("get signer account error:%s", err)
+	}
+	txHash, err := utils.InvokeSmartContract(signer, tx)
+	if err != nil {
+		return fmt.Errorf("%s error:%s", req.Method, err)
+	}
+	PrintInfoMsg("  TxHash:%s", txHash)
+	PrintInfoMsg("\nTip:")
+	PrintInfoMsg("  Using './DNA info status %s' to query transaction status.", txHash)
+	return nil
+}
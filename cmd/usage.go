@@ -103,6 +103,12 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.DisableLogFileFlag,
 			utils.DisableEventLogFlag,
 			utils.DataDirFlag,
+			utils.StorageBackendFlag,
+			utils.SnapshotIntervalFlag,
+			utils.PruneKeepBlocksFlag,
+			utils.ExecWorkersFlag,
+			utils.TraceHistoryFlag,
+			utils.EnableLightModeFlag,
 		},
 	},
 	{
@@ -158,6 +164,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MaxConnOutBoundFlag,
 			utils.MaxConnInBoundForSingleIPFlag,
 			utils.CertPathFlag,
+			utils.DisableCompressionFlag,
+			utils.EnableFastSyncFlag,
+			utils.FastSyncStateDigestFlag,
 		},
 	},
 	{
@@ -184,6 +193,12 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WsPortFlag,
 		},
 	},
+	{
+		Name: "ABI",
+		Flags: []cli.Flag{
+			utils.CliABIPathFlag,
+		},
+	},
 	{
 		Name: "TEST MODE",
 		Flags: []cli.Flag{
@@ -206,6 +221,32 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.ContractPrepareInvokeFlag,
 			utils.ContractParamsFlag,
 			utils.ContractReturnTypeFlag,
+			utils.ContractAbiFileFlag,
+			utils.ContractSourceHashFlag,
+			utils.ContractMigrateMethodFlag,
+			utils.ContractAdminKeyNoFlag,
+		},
+	},
+	{
+		Name: "CREDENTIAL",
+		Flags: []cli.Flag{
+			utils.CredentialIssuerFlag,
+			utils.CredentialSubjectFlag,
+			utils.CredentialKeyNoFlag,
+			utils.CredentialTypeFlag,
+			utils.CredentialClaimsFlag,
+			utils.CredentialExpireFlag,
+		},
+	},
+	{
+		Name: "STAKE",
+		Flags: []cli.Flag{
+			utils.StakePeerFlag,
+			utils.StakeAmountFlag,
+			utils.StakeCallerFlag,
+			utils.StakeKeyNoFlag,
+			utils.StakePeerCostFlag,
+			utils.StakePreviewFlag,
 		},
 	},
 	{
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/did"
+	httpcom "github.com/dnaproject2/DNA/http/base/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/claimrecord"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//ResolveDID return the DID resolution result of ID
+func ResolveDID(id string) (*did.ResolutionResult, error) {
+	data, ontErr := sendRpcRequest("resolvedid", []interface{}{id})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	result := &did.ResolutionResult{}
+	err := json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return result, nil
+}
+
+//GetClaimRecord return the on-chain record of credential committed by issuer, or nil if not committed
+func GetClaimRecord(issuer, claimId string) (*claimrecord.ClaimRecord, error) {
+	preResult, err := PrepareInvokeNativeContract(utils.ClaimRecordContractAddress, 0, claimrecord.GET_STATUS,
+		[]interface{}{&claimrecord.GetStatusParam{Issuer: []byte(issuer), ClaimId: []byte(claimId)}})
+	if err != nil {
+		return nil, err
+	}
+	str, ok := preResult.Result.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid result of %s", claimrecord.GET_STATUS)
+	}
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return claimrecord.DecodeClaimRecord(data)
+}
+
+//CommitClaimTx return the transaction to commit the credential to claim record contract, it should be signed by
+//the key of issuer or its controller
+func CommitClaimTx(gasPrice, gasLimit uint64, param *claimrecord.CommitParam) (*types.MutableTransaction, error) {
+	return httpcom.NewNativeInvokeTransaction(gasPrice, gasLimit, utils.ClaimRecordContractAddress, 0,
+		claimrecord.COMMIT, []interface{}{param})
+}
+
+//CommitClaim commit the credential to claim record contract, signer must own the key of issuer
+func CommitClaim(gasPrice, gasLimit uint64, signer *account.Account, param *claimrecord.CommitParam) (string, error) {
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.ClaimRecordContractAddress, 0, claimrecord.COMMIT,
+		[]interface{}{param})
+}
+
+//RevokeClaim revoke the committed credential, signer must own the key of issuer
+func RevokeClaim(gasPrice, gasLimit uint64, signer *account.Account, param *claimrecord.RevokeParam) (string, error) {
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.ClaimRecordContractAddress, 0, claimrecord.REVOKE,
+		[]interface{}{param})
+}
+
+//VerifyCredential verify the JWT credential against chain state, the credential is valid only if it is not expired,
+//signed by a key in use of issuer, and committed without revoked
+func VerifyCredential(jwt string) (*did.Credential, error) {
+	cred, err := did.ParseCredential(jwt)
+	if err != nil {
+		return nil, err
+	}
+	if cred.Expired(time.Now().Unix()) {
+		return cred, fmt.Errorf("credential expired at %d", cred.Claims.Expiration)
+	}
+	result, err := ResolveDID(cred.Claims.Issuer)
+	if err != nil {
+		return cred, fmt.Errorf("resolve issuer error:%s", err)
+	}
+	if result.Document == nil {
+		return cred, fmt.Errorf("resolve issuer %s failed:%s", cred.Claims.Issuer, result.ResolutionMetadata.Error)
+	}
+	if result.DocumentMetadata.Deactivated {
+		return cred, fmt.Errorf("issuer %s is revoked", cred.Claims.Issuer)
+	}
+	if err := cred.VerifySignature(result.Document); err != nil {
+		return cred, fmt.Errorf("verify signature error:%s", err)
+	}
+	record, err := GetClaimRecord(cred.Claims.Issuer, cred.Claims.ID)
+	if err != nil {
+		return cred, fmt.Errorf("get claim record error:%s", err)
+	}
+	if record == nil {
+		return cred, fmt.Errorf("credential %s is not committed", cred.Claims.ID)
+	}
+	if string(record.Subject) != cred.Claims.Subject {
+		return cred, fmt.Errorf("credential %s is committed for another subject", cred.Claims.ID)
+	}
+	if record.Status != claimrecord.STATUS_COMMITTED {
+		return cred, fmt.Errorf("credential %s is revoked at height %d", cred.Claims.ID, record.RevokeHeight)
+	}
+	return cred, nil
+}
@@ -72,6 +72,31 @@ var (
 		Usage: "Block data storage `<path>`",
 		Value: config.DEFAULT_DATA_DIR,
 	}
+	SnapshotIntervalFlag = cli.UintFlag{
+		Name:  "snapshot-interval",
+		Usage: "Produce a state snapshot for fast sync peers every `<number>` blocks. 0 means disable",
+	}
+	StorageBackendFlag = cli.StringFlag{
+		Name:  "storage-backend",
+		Usage: "Storage backend `<name>` of ledger, leveldb or badger",
+		Value: config.DEFAULT_STORAGE_BACKEND,
+	}
+	PruneKeepBlocksFlag = cli.UintFlag{
+		Name:  "prune-keep-blocks",
+		Usage: "Keep full data of the latest `<number>` blocks, transactions and events of older blocks are pruned in background. 0 means archive node",
+	}
+	ExecWorkersFlag = cli.UintFlag{
+		Name:  "exec-workers",
+		Usage: "Execute transactions of block optimistically in parallel with `<number>` workers. 0 or 1 means sequential execution",
+	}
+	TraceHistoryFlag = cli.UintFlag{
+		Name:  "trace-history",
+		Usage: "Keep the previous states of the latest `<number>` blocks to trace their transactions. 0 means only pre-executed transactions can be traced",
+	}
+	EnableLightModeFlag = cli.BoolFlag{
+		Name:  "light",
+		Usage: "Run as light node, which only syncs and verifies block headers. Transactions are verified by the merkle proof from full nodes",
+	}
 
 	//Consensus setting
 	EnableConsensusFlag = cli.BoolFlag{
@@ -150,6 +175,18 @@ var (
 		Usage: "cert path for node",
 		Value: config.DEFAULT_CERT_PATH,
 	}
+	DisableCompressionFlag = cli.BoolFlag{
+		Name:  "disable-p2p-compression",
+		Usage: "Do not compress block, header and address messages sent to peers.",
+	}
+	EnableFastSyncFlag = cli.BoolFlag{
+		Name:  "fast-sync",
+		Usage: "Bootstrap an empty ledger from the state snapshot served by peers. Requires --fast-sync-state-digest",
+	}
+	FastSyncStateDigestFlag = cli.StringFlag{
+		Name:  "fast-sync-state-digest",
+		Usage: "Trusted state digest `<hex>` of the snapshot used by fast sync, as logged by the node making the snapshot. The digest is not committed on chain, so the snapshot is only as trustworthy as the source of the digest",
+	}
 	// RPC settings
 	RPCDisabledFlag = cli.BoolFlag{
 		Name:  "disable-rpc",
@@ -330,6 +367,81 @@ var (
 		Name:  "return",
 		Usage: "Return `<type>` of contract. bytearray(hexstring), string, integer, boolean",
 	}
+	ContractProfileFlag = cli.StringFlag{
+		Name:  "profile",
+		Usage: "Profile the gas of prepare invoke, and write the folded stacks for flame graph to `<file>`",
+	}
+	ContractAbiFileFlag = cli.StringFlag{
+		Name:  "abifile",
+		Usage: "File path of contract abi `<path>`",
+	}
+	ContractSourceHashFlag = cli.StringFlag{
+		Name:  "sourcehash",
+		Usage: "Hash of contract source code in hex `<hash>`",
+	}
+	ContractMigrateMethodFlag = cli.StringFlag{
+		Name:  "migrate",
+		Usage: "Contract `<method>` called after upgrade, with the previous version as argument",
+	}
+	ContractAdminKeyNoFlag = cli.Uint64Flag{
+		Name:  "keyno",
+		Usage: "Key `<number>` of contract admin's DNA ID to sign the upgrade",
+		Value: 1,
+	}
+
+	//credential setting
+	CredentialIssuerFlag = cli.StringFlag{
+		Name:  "issuer",
+		Usage: "DNA ID of credential `<issuer>`",
+	}
+	CredentialSubjectFlag = cli.StringFlag{
+		Name:  "subject",
+		Usage: "DNA ID of credential `<subject>`",
+	}
+	CredentialKeyNoFlag = cli.UintFlag{
+		Name:  "keyno",
+		Usage: "Key `<number>` of issuer's DNA ID to sign the credential",
+		Value: 1,
+	}
+	CredentialTypeFlag = cli.StringFlag{
+		Name:  "type",
+		Usage: "Credential `<types>` besides VerifiableCredential, separated by ','",
+	}
+	CredentialClaimsFlag = cli.StringFlag{
+		Name:  "claims",
+		Usage: "Claims about the subject in JSON object `<claims>`",
+	}
+	CredentialExpireFlag = cli.Uint64Flag{
+		Name:  "expire",
+		Usage: "Valid duration of credential in `<seconds>`, 0 means never expire",
+	}
+
+	//stake setting
+	StakePeerFlag = cli.StringFlag{
+		Name:  "peer",
+		Usage: "Public key of consensus `<peers>` in hex, separated by ','",
+	}
+	StakeAmountFlag = cli.StringFlag{
+		Name:  "amount",
+		Usage: "ONT `<amounts>` of each peer, separated by ','",
+	}
+	StakeCallerFlag = cli.StringFlag{
+		Name:  "caller",
+		Usage: "DNA ID `<id>` authorized to register candidate",
+	}
+	StakeKeyNoFlag = cli.UintFlag{
+		Name:  "keyno",
+		Usage: "Key `<number>` of caller's DNA ID to register candidate",
+		Value: 1,
+	}
+	StakePeerCostFlag = cli.UintFlag{
+		Name:  "cost",
+		Usage: "`<percentage>` of split fee kept by peer, from 0 to 100",
+	}
+	StakePreviewFlag = cli.BoolFlag{
+		Name:  "preview",
+		Usage: "Show what the staking operation does without sending transaction",
+	}
 
 	//information cmd settings
 	BlockHashInfoFlag = cli.StringFlag{
@@ -0,0 +1,277 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/types"
+	httpcom "github.com/dnaproject2/DNA/http/base/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+//GetGovernancePeers return the peers of current view with stake and status
+func GetGovernancePeers() (*httpcom.GovernancePeersInfo, error) {
+	data, ontErr := sendRpcRequest("getgovernancepeers", []interface{}{})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	info := &httpcom.GovernancePeersInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return info, nil
+}
+
+//GetGovernanceStake return the authorizations of address to peers and the stake and fee can be withdrawn
+func GetGovernanceStake(address string) (*httpcom.GovernanceStakeInfo, error) {
+	data, ontErr := sendRpcRequest("getgovernancestake", []interface{}{address})
+	if ontErr != nil {
+		switch ontErr.ErrorCode {
+		case ERROR_INVALID_PARAMS:
+			return nil, fmt.Errorf("invalid address:%s", address)
+		}
+		return nil, ontErr.Error
+	}
+	info := &httpcom.GovernanceStakeInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return info, nil
+}
+
+//GetFeeSplit return the fee split at the end of view, or nil if the fee split of view is not recorded
+func GetFeeSplit(view uint32) (*httpcom.FeeSplitInfo, error) {
+	data, ontErr := sendRpcRequest("getfeesplit", []interface{}{view})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	var info *httpcom.FeeSplitInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return info, nil
+}
+
+//StakeMethods is the methods of governance contract can be invoked by staking commands
+var StakeMethods = []string{
+	governance.REGISTER_CANDIDATE,
+	governance.AUTHORIZE_FOR_PEER,
+	governance.UNAUTHORIZE_FOR_PEER,
+	governance.WITHDRAW,
+	governance.WITHDRAW_FEE,
+	governance.SET_PEER_COST,
+	governance.ADD_INIT_POS,
+	governance.REDUCE_INIT_POS,
+	governance.QUIT_NODE,
+}
+
+//StakeRequest is the staking operation of account in governance contract. Amounts are in ONT and correspond to
+//PeerPubkeys one by one
+type StakeRequest struct {
+	Method      string
+	Address     common.Address
+	PeerPubkeys []string
+	Amounts     []uint32
+	PeerCost    uint32
+	Caller      string
+	KeyNo       uint32
+}
+
+//Param return the param of governance contract method, it validates the request before the transaction is built
+func (this *StakeRequest) Param() (interface{}, error) {
+	for _, peerPubkey := range this.PeerPubkeys {
+		data, err := hex.DecodeString(peerPubkey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid peer pubkey:%s", peerPubkey)
+		}
+		if _, err := keypair.DeserializePublicKey(data); err != nil {
+			return nil, fmt.Errorf("invalid peer pubkey:%s", peerPubkey)
+		}
+	}
+	switch this.Method {
+	case governance.REGISTER_CANDIDATE:
+		if err := this.checkPeer(true); err != nil {
+			return nil, err
+		}
+		if this.Caller == "" {
+			return nil, fmt.Errorf("missing DNA ID of caller")
+		}
+		return &governance.RegisterCandidateParam{
+			PeerPubkey: this.PeerPubkeys[0],
+			Address:    this.Address,
+			InitPos:    this.Amounts[0],
+			Caller:     []byte(this.Caller),
+			KeyNo:      this.KeyNo,
+		}, nil
+	case governance.AUTHORIZE_FOR_PEER, governance.UNAUTHORIZE_FOR_PEER:
+		if err := this.checkPeers(); err != nil {
+			return nil, err
+		}
+		return &governance.AuthorizeForPeerParam{
+			Address:        this.Address,
+			PeerPubkeyList: this.PeerPubkeys,
+			PosList:        this.Amounts,
+		}, nil
+	case governance.WITHDRAW:
+		if err := this.checkPeers(); err != nil {
+			return nil, err
+		}
+		return &governance.WithdrawParam{
+			Address:        this.Address,
+			PeerPubkeyList: this.PeerPubkeys,
+			WithdrawList:   this.Amounts,
+		}, nil
+	case governance.WITHDRAW_FEE:
+		return &governance.WithdrawFeeParam{
+			Address: this.Address,
+		}, nil
+	case governance.SET_PEER_COST:
+		if err := this.checkPeer(false); err != nil {
+			return nil, err
+		}
+		if this.PeerCost > 100 {
+			return nil, fmt.Errorf("peer cost %d is larger than 100", this.PeerCost)
+		}
+		return &governance.SetPeerCostParam{
+			PeerPubkey: this.PeerPubkeys[0],
+			Address:    this.Address,
+			PeerCost:   this.PeerCost,
+		}, nil
+	case governance.ADD_INIT_POS, governance.REDUCE_INIT_POS:
+		if err := this.checkPeer(true); err != nil {
+			return nil, err
+		}
+		return &governance.ChangeInitPosParam{
+			PeerPubkey: this.PeerPubkeys[0],
+			Address:    this.Address,
+			Pos:        this.Amounts[0],
+		}, nil
+	case governance.QUIT_NODE:
+		if err := this.checkPeer(false); err != nil {
+			return nil, err
+		}
+		return &governance.QuitNodeParam{
+			PeerPubkey: this.PeerPubkeys[0],
+			Address:    this.Address,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported stake method:%s", this.Method)
+}
+
+func (this *StakeRequest) checkPeer(withAmount bool) error {
+	if len(this.PeerPubkeys) != 1 {
+		return fmt.Errorf("%s needs exactly one peer", this.Method)
+	}
+	if !withAmount {
+		return nil
+	}
+	return this.checkPeers()
+}
+
+func (this *StakeRequest) checkPeers() error {
+	if len(this.PeerPubkeys) == 0 {
+		return fmt.Errorf("%s needs at least one peer", this.Method)
+	}
+	if len(this.PeerPubkeys) != len(this.Amounts) {
+		return fmt.Errorf("%d peers do not match %d amounts", len(this.PeerPubkeys), len(this.Amounts))
+	}
+	for _, amount := range this.Amounts {
+		if amount == 0 {
+			return fmt.Errorf("amount of %s should be larger than 0", this.Method)
+		}
+	}
+	return nil
+}
+
+//Preview return the human-readable lines describing what the staking operation does
+func (this *StakeRequest) Preview() []string {
+	lines := []string{fmt.Sprintf("Account:%s", this.Address.ToBase58())}
+	switch this.Method {
+	case governance.REGISTER_CANDIDATE:
+		lines = append(lines,
+			fmt.Sprintf("Register candidate peer %s with init pos %d ONT", this.PeerPubkeys[0], this.Amounts[0]),
+			fmt.Sprintf("Authorized by %s key %d", this.Caller, this.KeyNo),
+			"The init pos and candidate fee of ONG are transferred from account")
+	case governance.AUTHORIZE_FOR_PEER:
+		for i, peerPubkey := range this.PeerPubkeys {
+			lines = append(lines, fmt.Sprintf("Authorize %d ONT to peer %s", this.Amounts[i], peerPubkey))
+		}
+	case governance.UNAUTHORIZE_FOR_PEER:
+		for i, peerPubkey := range this.PeerPubkeys {
+			lines = append(lines, fmt.Sprintf("Unauthorize %d ONT from peer %s", this.Amounts[i], peerPubkey))
+		}
+		lines = append(lines, "The unauthorized ONT can be withdrawn after it is unfrozen")
+	case governance.WITHDRAW:
+		for i, peerPubkey := range this.PeerPubkeys {
+			lines = append(lines, fmt.Sprintf("Withdraw %d ONT from peer %s", this.Amounts[i], peerPubkey))
+		}
+	case governance.WITHDRAW_FEE:
+		lines = append(lines, "Withdraw all split fee of ONG")
+	case governance.SET_PEER_COST:
+		lines = append(lines, fmt.Sprintf("Set cost of peer %s to %d%%, it takes effect after 2 views",
+			this.PeerPubkeys[0], this.PeerCost))
+	case governance.ADD_INIT_POS:
+		lines = append(lines, fmt.Sprintf("Add init pos %d ONT to peer %s", this.Amounts[0], this.PeerPubkeys[0]))
+	case governance.REDUCE_INIT_POS:
+		lines = append(lines, fmt.Sprintf("Reduce init pos %d ONT of peer %s", this.Amounts[0], this.PeerPubkeys[0]))
+	case governance.QUIT_NODE:
+		lines = append(lines, fmt.Sprintf("Quit peer %s, the init pos can be withdrawn after the peer quits",
+			this.PeerPubkeys[0]))
+	}
+	return lines
+}
+
+//StakeTx return the unsigned transaction of staking operation, it should be signed by the account of request
+func StakeTx(gasPrice, gasLimit uint64, req *StakeRequest) (*types.MutableTransaction, error) {
+	if !IsStakeMethod(req.Method) {
+		return nil, fmt.Errorf("unsupported stake method:%s", req.Method)
+	}
+	param, err := req.Param()
+	if err != nil {
+		return nil, err
+	}
+	return httpcom.NewNativeInvokeTransaction(gasPrice, gasLimit, utils.GovernanceContractAddress, 0, req.Method,
+		[]interface{}{param})
+}
+
+//Stake sign and send the transaction of staking operation
+func Stake(gasPrice, gasLimit uint64, signer *account.Account, req *StakeRequest) (string, error) {
+	tx, err := StakeTx(gasPrice, gasLimit, req)
+	if err != nil {
+		return "", err
+	}
+	return InvokeSmartContract(signer, tx)
+}
+
+//IsStakeMethod return whether the governance method can be invoked by staking commands
+func IsStakeMethod(method string) bool {
+	for _, m := range StakeMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -35,6 +35,22 @@ func NewNeovmContractAbi(abiData []byte) (*abi.NeovmContractAbi, error) {
 	return abi, nil
 }
 
+//GetNeovmReturnType return the return type of function abi in the format of --return flag, or empty string
+//if the return value cannot be decoded by type, e.g. void, any and array
+func GetNeovmReturnType(funcAbi *abi.NeovmContractFunctionAbi) string {
+	switch strings.ToLower(funcAbi.ReturnType) {
+	case abi.NEOVM_PARAM_TYPE_BOOL:
+		return PARAM_TYPE_BOOLEAN
+	case abi.NEOVM_PARAM_TYPE_STRING:
+		return PARAM_TYPE_STRING
+	case abi.NEOVM_PARAM_TYPE_INTEGER:
+		return PARAM_TYPE_INTEGER
+	case abi.NEOVM_PARAM_TYPE_BYTE_ARRAY, abi.NEOVM_PARAM_TYPE_ADDRESS, abi.NEOVM_PARAM_TYPE_HASH160:
+		return PARAM_TYPE_BYTE_ARRAY
+	}
+	return ""
+}
+
 func ParseNeovmFunc(rawParams []string, funcAbi *abi.NeovmContractFunctionAbi) ([]interface{}, error) {
 	res := make([]interface{}, 0)
 	funcName := convertNeovmFuncName(funcAbi.Name)
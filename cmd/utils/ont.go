@@ -25,6 +25,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/cmd/abi"
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/constants"
 	"github.com/dnaproject2/DNA/common/serialization"
@@ -34,7 +35,9 @@ import (
 	cutils "github.com/dnaproject2/DNA/core/utils"
 	httpcom "github.com/dnaproject2/DNA/http/base/common"
 	rpccommon "github.com/dnaproject2/DNA/http/base/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/auth"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
 	"github.com/dnaproject2/DNA/smartcontract/service/wasmvm"
 	cstates "github.com/dnaproject2/DNA/smartcontract/states"
@@ -474,6 +477,33 @@ func PrepareSendRawTransaction(txData string) (*cstates.PreExecResult, error) {
 	return preResult, nil
 }
 
+//ProfileInvokeNeoVMContract return the gas profile of pre-executing the invocation of contract
+func ProfileInvokeNeoVMContract(contractAddress common.Address, params []interface{}) (*rpccommon.GasProfileInfo, error) {
+	mutable, err := httpcom.NewNeovmInvokeTransaction(0, 0, contractAddress, params)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = tx.Serialize(&buffer)
+	if err != nil {
+		return nil, fmt.Errorf("tx serialize error:%s", err)
+	}
+	data, ontErr := sendRpcRequest("profilegas", []interface{}{hex.EncodeToString(buffer.Bytes())})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	result := &rpccommon.GasProfileInfo{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal GasProfile:%s error:%s", data, err)
+	}
+	return result, nil
+}
+
 //GetSmartContractEvent return smart contract event execute by invoke transaction by hex string code
 func GetSmartContractEvent(txHash string) (*rpccommon.ExecuteNotify, error) {
 	data, ontErr := sendRpcRequest("getsmartcodeevent", []interface{}{txHash})
@@ -592,6 +622,81 @@ func GetTxHeight(txHash string) (uint32, error) {
 	return height, nil
 }
 
+func GetContractCode(address string) ([]byte, error) {
+	data, ontErr := sendRpcRequest("getcontractstate", []interface{}{address, 1})
+	if ontErr != nil {
+		switch ontErr.ErrorCode {
+		case ERROR_INVALID_PARAMS:
+			return nil, fmt.Errorf("invalid contract address:%s", address)
+		}
+		return nil, ontErr.Error
+	}
+	contract := &httpcom.DeployCodeInfo{}
+	err := json.Unmarshal(data, contract)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return common.HexToBytes(contract.Code)
+}
+
+//GetContractAbi return the abi published in registry contract, or nil if not published
+func GetContractAbi(address string) (*abi.NeovmContractAbi, error) {
+	data, ontErr := sendRpcRequest("getcontractabi", []interface{}{address})
+	if ontErr != nil {
+		switch ontErr.ErrorCode {
+		case ERROR_INVALID_PARAMS:
+			return nil, fmt.Errorf("invalid contract address:%s", address)
+		}
+		return nil, ontErr.Error
+	}
+	meta := &httpcom.ContractMetadataInfo{}
+	err := json.Unmarshal(data, &meta)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	if meta == nil {
+		return nil, nil
+	}
+	return NewNeovmContractAbi(meta.Abi)
+}
+
+//PublishContractAbi publish the abi and source hash of contract to registry contract, signer must be the deployer
+func PublishContractAbi(gasPrice, gasLimit uint64, signer *account.Account, contractAddress common.Address,
+	abiData, sourceHash []byte) (string, error) {
+	params := &registry.PublishParam{
+		Contract:   contractAddress,
+		Abi:        abiData,
+		SourceHash: sourceHash,
+	}
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.RegistryContractAddress, 0, registry.PUBLISH,
+		[]interface{}{params})
+}
+
+//GetContractHistory return the code version history of contract
+func GetContractHistory(address string) ([]*httpcom.ContractVersionInfo, error) {
+	data, ontErr := sendRpcRequest("getcontracthistory", []interface{}{address})
+	if ontErr != nil {
+		switch ontErr.ErrorCode {
+		case ERROR_INVALID_PARAMS:
+			return nil, fmt.Errorf("invalid contract address:%s", address)
+		}
+		return nil, ontErr.Error
+	}
+	versions := make([]*httpcom.ContractVersionInfo, 0)
+	err := json.Unmarshal(data, &versions)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	return versions, nil
+}
+
+//UpgradeContract replace the code of contract in place by auth contract, signer must be the contract admin or the
+//governance operator
+func UpgradeContract(gasPrice, gasLimit uint64, signer *account.Account, param *auth.UpgradeContractParam) (string, error) {
+	return InvokeNativeContract(gasPrice, gasLimit, signer, utils.AuthContractAddress, 0, "upgradeContract",
+		[]interface{}{param})
+}
+
 func DeployContract(
 	gasPrice,
 	gasLimit uint64,
@@ -41,9 +41,10 @@ const (
 	DEFAULT_WALLET_FILE_NAME = "./executor.dat"
 	MIN_GEN_BLOCK_TIME       = 2
 	DEFAULT_GEN_BLOCK_TIME   = 6
-	DBFT_MIN_NODE_NUM        = 4 //min node number of dbft consensus
-	SOLO_MIN_NODE_NUM        = 1 //min node number of solo consensus
-	VBFT_MIN_NODE_NUM        = 4 //min node number of vbft consensus
+	DBFT_MIN_NODE_NUM        = 4    //min node number of dbft consensus
+	SOLO_MIN_NODE_NUM        = 1    //min node number of solo consensus
+	VBFT_MIN_NODE_NUM        = 4    //min node number of vbft consensus
+	MIN_PRUNE_KEEP_BLOCKS    = 1000 //min number of latest blocks keeping full data when pruning
 
 	CONSENSUS_TYPE_DBFT = "dbft"
 	CONSENSUS_TYPE_SOLO = "solo"
@@ -72,8 +73,9 @@ const (
 	DEFAULT_GAS_PRICE                       = 500
 	DEFAULT_CERT_PATH                       = "./cert.pem"
 
-	DEFAULT_DATA_DIR      = "./Chain"
-	DEFAULT_RESERVED_FILE = "./peers.rsv"
+	DEFAULT_DATA_DIR        = "./Chain"
+	DEFAULT_RESERVED_FILE   = "./peers.rsv"
+	DEFAULT_STORAGE_BACKEND = "leveldb"
 )
 
 const (
@@ -125,6 +127,106 @@ func GetOpcodeUpdateCheckHeight(id uint32) uint32 {
 	return OPCODE_UPDATE_CHECK_HEIGHT[id]
 }
 
+var CONTRACT_REGISTRY_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.CONTRACT_REGISTRY_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.CONTRACT_REGISTRY_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                          //Network solo
+}
+
+//GetContractRegistryHeight return the height from which deployments are recorded in registry contract and contracts
+//can be upgraded in place
+func GetContractRegistryHeight(id uint32) uint32 {
+	return CONTRACT_REGISTRY_HEIGHT[id]
+}
+
+var VIEW_SPLIT_FEE_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.VIEW_SPLIT_FEE_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.VIEW_SPLIT_FEE_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                       //Network solo
+}
+
+//GetViewSplitFeeHeight return the height from which the fee split of each view is recorded by governance contract
+func GetViewSplitFeeHeight(id uint32) uint32 {
+	return VIEW_SPLIT_FEE_HEIGHT[id]
+}
+
+var NESTED_GROUP_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.NESTED_GROUP_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.NESTED_GROUP_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                     //Network solo
+}
+
+//GetNestedGroupHeight return the height from which a group of ONT ID controller or recovery can have group members
+func GetNestedGroupHeight(id uint32) uint32 {
+	return NESTED_GROUP_HEIGHT[id]
+}
+
+var CLAIM_RECORD_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.CLAIM_RECORD_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.CLAIM_RECORD_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                     //Network solo
+}
+
+//GetClaimRecordHeight return the height from which the claim record contract can be invoked
+func GetClaimRecordHeight(id uint32) uint32 {
+	return CLAIM_RECORD_HEIGHT[id]
+}
+
+var TOKEN_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.TOKEN_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.TOKEN_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                              //Network solo
+}
+
+//GetTokenHeight return the height from which the token contract can be invoked
+func GetTokenHeight(id uint32) uint32 {
+	return TOKEN_HEIGHT[id]
+}
+
+var NFT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.NFT_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.NFT_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                            //Network solo
+}
+
+//GetNftHeight return the height from which the non-fungible token contract can be invoked
+func GetNftHeight(id uint32) uint32 {
+	return NFT_HEIGHT[id]
+}
+
+var VESTING_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.VESTING_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.VESTING_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                //Network solo
+}
+
+//GetVestingHeight return the height from which the vesting contract can be invoked
+func GetVestingHeight(id uint32) uint32 {
+	return VESTING_HEIGHT[id]
+}
+
+var SLASH_EQUIVOCATION_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.SLASH_EQUIVOCATION_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.SLASH_EQUIVOCATION_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                           //Network solo
+}
+
+//GetSlashEquivocationHeight return the height from which consensus peers can be slashed by equivocation evidence
+func GetSlashEquivocationHeight(id uint32) uint32 {
+	return SLASH_EQUIVOCATION_HEIGHT[id]
+}
+
+var PROPOSAL_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET:    constants.PROPOSAL_HEIGHT_MAINNET, //Network main
+	NETWORK_ID_POLARIS_NET: constants.PROPOSAL_HEIGHT_POLARIS, //Network polaris
+	NETWORK_ID_SOLO_NET:    0,                                 //Network solo
+}
+
+//GetProposalHeight return the height from which governance proposals can be made and executed
+func GetProposalHeight(id uint32) uint32 {
+	return PROPOSAL_HEIGHT[id]
+}
+
 func GetNetworkName(id uint32) string {
 	name, ok := NETWORK_NAME[id]
 	if ok {
@@ -489,13 +591,19 @@ type SOLOConfig struct {
 }
 
 type CommonConfig struct {
-	LogLevel       uint
-	NodeType       string
-	EnableEventLog bool
-	SystemFee      map[string]int64
-	GasLimit       uint64
-	GasPrice       uint64
-	DataDir        string
+	LogLevel           uint
+	NodeType           string
+	EnableEventLog     bool
+	SystemFee          map[string]int64
+	GasLimit           uint64
+	GasPrice           uint64
+	DataDir            string
+	SnapshotInterval   uint32
+	EnableLightMode    bool
+	PruneKeepBlocks    uint32
+	StorageBackend     string
+	ExecWorkers        uint
+	TraceHistoryBlocks uint32
 }
 
 type ConsensusConfig struct {
@@ -524,6 +632,9 @@ type P2PNodeConfig struct {
 	MaxConnInBound            uint
 	MaxConnOutBound           uint
 	MaxConnInBoundForSingleIP uint
+	DisableCompression        bool
+	EnableFastSync            bool   //Bootstrap an empty ledger from the state snapshot served by peers
+	FastSyncStateDigest       string //Trusted state digest of snapshot, required by fast sync. It is not verified on chain
 }
 
 type RpcConfig struct {
@@ -566,6 +677,7 @@ func NewDNAConfig() *DNAConfig {
 			SystemFee:      make(map[string]int64),
 			GasLimit:       DEFAULT_GAS_LIMIT,
 			DataDir:        DEFAULT_DATA_DIR,
+			StorageBackend: DEFAULT_STORAGE_BACKEND,
 		},
 		Consensus: &ConsensusConfig{
 			EnableConsensus: true,
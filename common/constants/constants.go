@@ -19,6 +19,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -86,3 +87,39 @@ const STATE_HASH_HEIGHT_POLARIS = 850000
 // neovm opcode update check height
 const OPCODE_HEIGHT_UPDATE_FIRST_MAINNET = 6300000
 const OPCODE_HEIGHT_UPDATE_FIRST_POLARIS = 2100000
+
+// contract registry and in-place upgrade activation height, not scheduled on main net and polaris yet
+const CONTRACT_REGISTRY_HEIGHT_MAINNET = math.MaxUint32
+const CONTRACT_REGISTRY_HEIGHT_POLARIS = math.MaxUint32
+
+// governance fee split record of each view activation height, not scheduled on main net and polaris yet
+const VIEW_SPLIT_FEE_HEIGHT_MAINNET = math.MaxUint32
+const VIEW_SPLIT_FEE_HEIGHT_POLARIS = math.MaxUint32
+
+// nested group of ONT ID controller and recovery activation height, not scheduled on main net and polaris yet
+const NESTED_GROUP_HEIGHT_MAINNET = math.MaxUint32
+const NESTED_GROUP_HEIGHT_POLARIS = math.MaxUint32
+
+// claim record contract activation height, not scheduled on main net and polaris yet
+const CLAIM_RECORD_HEIGHT_MAINNET = math.MaxUint32
+const CLAIM_RECORD_HEIGHT_POLARIS = math.MaxUint32
+
+// token contract activation height, not scheduled on main net and polaris yet
+const TOKEN_HEIGHT_MAINNET = math.MaxUint32
+const TOKEN_HEIGHT_POLARIS = math.MaxUint32
+
+// non-fungible token contract activation height, not scheduled on main net and polaris yet
+const NFT_HEIGHT_MAINNET = math.MaxUint32
+const NFT_HEIGHT_POLARIS = math.MaxUint32
+
+// vesting contract activation height, not scheduled on main net and polaris yet
+const VESTING_HEIGHT_MAINNET = math.MaxUint32
+const VESTING_HEIGHT_POLARIS = math.MaxUint32
+
+// governance slashing by equivocation evidence activation height, not scheduled on main net and polaris yet
+const SLASH_EQUIVOCATION_HEIGHT_MAINNET = math.MaxUint32
+const SLASH_EQUIVOCATION_HEIGHT_POLARIS = math.MaxUint32
+
+// on-chain governance proposal activation height, not scheduled on main net and polaris yet
+const PROPOSAL_HEIGHT_MAINNET = math.MaxUint32
+const PROPOSAL_HEIGHT_POLARIS = math.MaxUint32
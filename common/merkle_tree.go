@@ -19,6 +19,7 @@ package common
  */
 import (
 	"crypto/sha256"
+	"fmt"
 )
 
 // param hashes will be used as workspace
@@ -57,3 +58,50 @@ func ComputeMerkleRoot(hashes []Uint256) Uint256 {
 
 	return hashes[0]
 }
+
+func hashPair(left, right Uint256) Uint256 {
+	temp := sha256.Sum256(append(left[:], right[:]...))
+	return Uint256(sha256.Sum256(temp[:]))
+}
+
+//ComputeMerkleProof return the sibling hashes from leaf to root, which prove hashes[index] is in the tree of ComputeMerkleRoot
+func ComputeMerkleProof(hashes []Uint256, index uint32) ([]Uint256, error) {
+	if int(index) >= len(hashes) {
+		return nil, fmt.Errorf("wrong index %d, leaf count %d", index, len(hashes))
+	}
+	level := make([]Uint256, len(hashes))
+	copy(level, hashes)
+	var proof []Uint256
+	for len(level) != 1 {
+		sibling := index ^ 1
+		if int(sibling) >= len(level) {
+			sibling = index
+		}
+		proof = append(proof, level[sibling])
+		next := make([]Uint256, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, hashPair(level[i], level[i+1]))
+			} else {
+				next = append(next, hashPair(level[i], level[i]))
+			}
+		}
+		level = next
+		index /= 2
+	}
+	return proof, nil
+}
+
+//VerifyMerkleProof check the proof of leaf at index against the root of ComputeMerkleRoot
+func VerifyMerkleProof(leaf Uint256, index uint32, proof []Uint256, root Uint256) bool {
+	hash := leaf
+	for _, sibling := range proof {
+		if index&1 == 0 {
+			hash = hashPair(hash, sibling)
+		} else {
+			hash = hashPair(sibling, hash)
+		}
+		index /= 2
+	}
+	return index == 0 && hash == root
+}
@@ -182,3 +182,27 @@ func computeMerkleRootOld(hashes []Uint256) Uint256 {
 	tree, _ := newMerkleTree(hashes)
 	return tree.Root.Hash
 }
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n < 40; n++ {
+		data := make([]Uint256, n)
+		for i := range data {
+			data[i] = Uint256(sha256.Sum256([]byte(fmt.Sprint(i))))
+		}
+		leaves := make([]Uint256, n)
+		copy(leaves, data)
+		root := ComputeMerkleRoot(data)
+		for i := range leaves {
+			proof, err := ComputeMerkleProof(leaves, uint32(i))
+			assert.Nil(t, err)
+			assert.True(t, VerifyMerkleProof(leaves[i], uint32(i), proof, root))
+			assert.False(t, VerifyMerkleProof(UINT256_EMPTY, uint32(i), proof, root))
+			if len(proof) > 0 {
+				proof[0][0] ^= 1
+				assert.False(t, VerifyMerkleProof(leaves[i], uint32(i), proof, root))
+			}
+		}
+		_, err := ComputeMerkleProof(leaves, uint32(n))
+		assert.NotNil(t, err)
+	}
+}
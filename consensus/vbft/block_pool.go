@@ -27,7 +27,10 @@ import (
 
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/consensus/vbft/config"
 	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/types"
+	gover "github.com/dnaproject2/DNA/smartcontract/service/native/governance"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -58,8 +61,12 @@ type CandidateInfo struct {
 	SealedBlock *Block
 
 	// candidate msgs for this round
-	Proposals  []*blockProposalMsg
-	CommitMsgs []*blockCommitMsg
+	Proposals   []*blockProposalMsg
+	EndorseMsgs []*blockEndorseMsg
+	CommitMsgs  []*blockCommitMsg
+
+	// first conflicting proposal of each proposer, kept as equivocation evidence
+	ConflictProposals []*blockProposalMsg
 
 	// indexed by endorserIndex
 	EndorseSigs map[uint32][]*CandidateEndorseSigInfo
@@ -116,6 +123,7 @@ func (pool *BlockPool) getCandidateInfoLocked(blkNum uint32) *CandidateInfo {
 		// new candiateInfo for blockNum
 		candidate = &CandidateInfo{
 			Proposals:   make([]*blockProposalMsg, 0),
+			EndorseMsgs: make([]*blockEndorseMsg, 0),
 			CommitMsgs:  make([]*blockCommitMsg, 0),
 			EndorseSigs: make(map[uint32][]*CandidateEndorseSigInfo),
 		}
@@ -141,6 +149,7 @@ func (pool *BlockPool) newBlockProposal(msg *blockProposalMsg) error {
 			if bytes.Compare(p.Block.Block.Header.SigData[0], msg.Block.Block.Header.SigData[0]) == 0 {
 				return nil
 			}
+			pool.addConflictProposalLocked(candidate, msg)
 			return errDupProposal
 		}
 	}
@@ -150,6 +159,18 @@ func (pool *BlockPool) newBlockProposal(msg *blockProposalMsg) error {
 	return nil
 }
 
+//
+// keep the conflicting proposal, so that endorse/commit msgs for it can be verified as equivocation
+//
+func (pool *BlockPool) addConflictProposalLocked(candidate *CandidateInfo, msg *blockProposalMsg) {
+	for _, p := range candidate.ConflictProposals {
+		if p.Block.getProposer() == msg.Block.getProposer() {
+			return
+		}
+	}
+	candidate.ConflictProposals = append(candidate.ConflictProposals, msg)
+}
+
 func (pool *BlockPool) getBlockProposals(blkNum uint32) []*blockProposalMsg {
 	pool.lock.RLock()
 	defer pool.lock.RUnlock()
@@ -292,6 +313,15 @@ func (pool *BlockPool) newBlockEndorsement(msg *blockEndorseMsg) {
 		ForEmpty:         msg.EndorseForEmpty,
 	}
 	pool.addBlockEndorsementLocked(msg.GetBlockNum(), msg.Endorser, eSig)
+
+	// keep endorse-msgs for equivocation checking
+	candidate := pool.getCandidateInfoLocked(msg.GetBlockNum())
+	for _, e := range candidate.EndorseMsgs {
+		if e.Endorser == msg.Endorser && e.EndorsedBlockHash == msg.EndorsedBlockHash {
+			return
+		}
+	}
+	candidate.EndorseMsgs = append(candidate.EndorseMsgs, msg)
 }
 
 //
@@ -774,3 +804,122 @@ func (pool *BlockPool) submitBlock(blkNum uint32) error {
 	defer pool.lock.Unlock()
 	return pool.chainStore.submitBlock(blkNum)
 }
+
+//
+// find the header of candidate block (or empty block) with blockhash
+//
+func (pool *BlockPool) findCandidateHeaderLocked(blkNum uint32, hash common.Uint256) *types.Header {
+	candidate := pool.candidateBlocks[blkNum]
+	if candidate == nil {
+		return nil
+	}
+	for _, proposals := range [][]*blockProposalMsg{candidate.Proposals, candidate.ConflictProposals} {
+		for _, p := range proposals {
+			if p.Block.Block.Hash() == hash {
+				return p.Block.Block.Header
+			}
+			if p.Block.EmptyBlock != nil && p.Block.EmptyBlock.Hash() == hash {
+				return p.Block.EmptyBlock.Header
+			}
+		}
+	}
+	return nil
+}
+
+//
+// build equivocation evidence from two conflicting headers signed by peer,
+// return nil if headers are not available or not conflicting
+//
+func (pool *BlockPool) buildEvidenceLocked(msgType uint8, peer uint32, header1 *types.Header, sig1 []byte,
+	header2 *types.Header, sig2 []byte) *gover.EquivocationEvidence {
+	if header1 == nil || header2 == nil {
+		return nil
+	}
+	pubkey := pool.server.peerPool.GetPeerPubKey(peer)
+	if pubkey == nil {
+		return nil
+	}
+	evidence := &gover.EquivocationEvidence{
+		MsgType:    msgType,
+		PeerPubkey: vconfig.PubkeyID(pubkey),
+		Header1:    header1.ToArray(),
+		Sig1:       sig1,
+		Header2:    header2.ToArray(),
+		Sig2:       sig2,
+	}
+	if _, _, err := gover.VerifyEquivocationEvidence(evidence); err != nil {
+		log.Debugf("ignore equivocation of peer %d: %s", peer, err)
+		return nil
+	}
+	return evidence
+}
+
+//
+// get equivocation evidence of proposer, if msg conflicts with proposal in CandidateInfo
+//
+func (pool *BlockPool) proposalEquivocation(msg *blockProposalMsg) *gover.EquivocationEvidence {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	candidate := pool.candidateBlocks[msg.GetBlockNum()]
+	if candidate == nil {
+		return nil
+	}
+	for _, p := range candidate.Proposals {
+		if p.Block.getProposer() == msg.Block.getProposer() {
+			return pool.buildEvidenceLocked(gover.EVIDENCE_PROPOSAL, msg.Block.getProposer(),
+				p.Block.Block.Header, p.Block.Block.Header.SigData[0],
+				msg.Block.Block.Header, msg.Block.Block.Header.SigData[0])
+		}
+	}
+	return nil
+}
+
+//
+// get equivocation evidence of endorser, if msg conflicts with endorse-msg in CandidateInfo
+//
+func (pool *BlockPool) endorseEquivocation(msg *blockEndorseMsg) *gover.EquivocationEvidence {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	blkNum := msg.GetBlockNum()
+	candidate := pool.candidateBlocks[blkNum]
+	if candidate == nil {
+		return nil
+	}
+	for _, e := range candidate.EndorseMsgs {
+		if e.Endorser == msg.Endorser && e.EndorsedProposer == msg.EndorsedProposer &&
+			e.EndorsedBlockHash != msg.EndorsedBlockHash {
+			evidence := pool.buildEvidenceLocked(gover.EVIDENCE_ENDORSE, msg.Endorser,
+				pool.findCandidateHeaderLocked(blkNum, e.EndorsedBlockHash), e.EndorserSig,
+				pool.findCandidateHeaderLocked(blkNum, msg.EndorsedBlockHash), msg.EndorserSig)
+			if evidence != nil {
+				return evidence
+			}
+		}
+	}
+	return nil
+}
+
+//
+// get equivocation evidence of committer, if msg conflicts with commit-msg in CandidateInfo
+//
+func (pool *BlockPool) commitEquivocation(msg *blockCommitMsg) *gover.EquivocationEvidence {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	blkNum := msg.GetBlockNum()
+	candidate := pool.candidateBlocks[blkNum]
+	if candidate == nil {
+		return nil
+	}
+	for _, c := range candidate.CommitMsgs {
+		if c.Committer == msg.Committer && c.BlockProposer == msg.BlockProposer &&
+			c.CommitBlockHash != msg.CommitBlockHash {
+			return pool.buildEvidenceLocked(gover.EVIDENCE_COMMIT, msg.Committer,
+				pool.findCandidateHeaderLocked(blkNum, c.CommitBlockHash), c.CommitterSig,
+				pool.findCandidateHeaderLocked(blkNum, msg.CommitBlockHash), msg.CommitterSig)
+		}
+	}
+	return nil
+}
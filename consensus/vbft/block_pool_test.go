@@ -19,12 +19,17 @@
 package vbft
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/dnaproject2/DNA/account"
 	"github.com/dnaproject2/DNA/common"
+	vconfig "github.com/dnaproject2/DNA/consensus/vbft/config"
 	"github.com/dnaproject2/DNA/core/ledger"
+	"github.com/dnaproject2/DNA/core/signature"
 	"github.com/dnaproject2/DNA/core/types"
+	gover "github.com/dnaproject2/DNA/smartcontract/service/native/governance"
 )
 
 func buildTestBlockPool(t *testing.T) (*BlockPool, error) {
@@ -88,3 +93,135 @@ func TestAddBlock(t *testing.T) {
 		t.Errorf("submitBlock err:%s", err)
 	}
 }
+
+func buildSignedTestBlock(t *testing.T, signer *account.Account, blkNum, proposer, timestamp uint32) *Block {
+	info := &vconfig.VbftBlockInfo{Proposer: proposer}
+	payload, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("marshal block info err:%s", err)
+	}
+	header := &types.Header{
+		Timestamp:        timestamp,
+		Height:           blkNum,
+		ConsensusData:    common.GetNonce(),
+		ConsensusPayload: payload,
+	}
+	hash := header.Hash()
+	sig, err := signature.Sign(signer, hash[:])
+	if err != nil {
+		t.Fatalf("sign block err:%s", err)
+	}
+	header.SigData = [][]byte{sig}
+	return &Block{
+		Block: &types.Block{Header: header},
+		Info:  info,
+	}
+}
+
+func signTestBlock(t *testing.T, signer *account.Account, blk *Block) []byte {
+	hash := blk.Block.Hash()
+	sig, err := signature.Sign(signer, hash[:])
+	if err != nil {
+		t.Fatalf("sign block err:%s", err)
+	}
+	return sig
+}
+
+func TestBlockPoolEquivocation(t *testing.T) {
+	blockpool, err := buildTestBlockPool(t)
+	if err != nil {
+		t.Fatalf("buildTestBlockPool err:%s", err)
+	}
+	server := &Server{}
+	server.peerPool = NewPeerPool(0, server)
+	blockpool.server = server
+	accs := make([]*account.Account, 4)
+	for i := 1; i < len(accs); i++ {
+		accs[i] = account.NewAccount("")
+		if err := server.peerPool.addPeer(&vconfig.PeerConfig{
+			Index: uint32(i),
+			ID:    vconfig.PubkeyID(accs[i].PublicKey),
+		}); err != nil {
+			t.Fatalf("addPeer err:%s", err)
+		}
+	}
+	blkNum := blockpool.chainStore.GetChainedBlockNum() + 1
+
+	//proposer 1 proposes block A and B, proposer 2 proposes block C
+	timestamp := uint32(time.Now().Unix())
+	blkA := buildSignedTestBlock(t, accs[1], blkNum, 1, timestamp)
+	blkB := buildSignedTestBlock(t, accs[1], blkNum, 1, timestamp+1)
+	blkC := buildSignedTestBlock(t, accs[2], blkNum, 2, timestamp)
+	if err := blockpool.newBlockProposal(&blockProposalMsg{Block: blkA}); err != nil {
+		t.Fatalf("newBlockProposal err:%s", err)
+	}
+	if err := blockpool.newBlockProposal(&blockProposalMsg{Block: blkC}); err != nil {
+		t.Fatalf("newBlockProposal err:%s", err)
+	}
+	proposalB := &blockProposalMsg{Block: blkB}
+	if err := blockpool.newBlockProposal(proposalB); err != errDupProposal {
+		t.Fatalf("expect dup proposal, got %v", err)
+	}
+	if evidence := blockpool.proposalEquivocation(proposalB); evidence == nil ||
+		evidence.MsgType != gover.EVIDENCE_PROPOSAL || evidence.PeerPubkey != vconfig.PubkeyID(accs[1].PublicKey) {
+		t.Errorf("expect proposal evidence of peer 1, got %v", evidence)
+	}
+
+	endorse := func(endorser uint32, blk *Block) *gover.EquivocationEvidence {
+		msg := &blockEndorseMsg{
+			Endorser:          endorser,
+			EndorsedProposer:  blk.getProposer(),
+			BlockNum:          blkNum,
+			EndorsedBlockHash: blk.Block.Hash(),
+			EndorserSig:       signTestBlock(t, accs[endorser], blk),
+		}
+		blockpool.newBlockEndorsement(msg)
+		return blockpool.endorseEquivocation(msg)
+	}
+	//endorser 2 endorses block A and B of proposer 1
+	if evidence := endorse(2, blkA); evidence != nil {
+		t.Errorf("unexpected endorse evidence %v", evidence)
+	}
+	if evidence := endorse(2, blkB); evidence == nil || evidence.MsgType != gover.EVIDENCE_ENDORSE ||
+		evidence.PeerPubkey != vconfig.PubkeyID(accs[2].PublicKey) {
+		t.Errorf("expect endorse evidence of peer 2, got %v", evidence)
+	}
+	//endorser 3 endorses blocks of different proposers
+	if evidence := endorse(3, blkA); evidence != nil {
+		t.Errorf("unexpected endorse evidence %v", evidence)
+	}
+	if evidence := endorse(3, blkC); evidence != nil {
+		t.Errorf("endorsing different proposers is not equivocation, got %v", evidence)
+	}
+
+	commit := func(committer uint32, blk *Block) (*blockCommitMsg, error) {
+		msg := &blockCommitMsg{
+			Committer:       committer,
+			BlockProposer:   blk.getProposer(),
+			BlockNum:        blkNum,
+			CommitBlockHash: blk.Block.Hash(),
+			EndorsersSig:    map[uint32][]byte{},
+			CommitterSig:    signTestBlock(t, accs[committer], blk),
+		}
+		return msg, blockpool.newBlockCommitment(msg)
+	}
+	//committer 3 commits block A, then C of another proposer and B of the same proposer
+	if _, err := commit(3, blkA); err != nil {
+		t.Fatalf("newBlockCommitment err:%s", err)
+	}
+	msg, err := commit(3, blkC)
+	if err != errDupCommit {
+		t.Fatalf("expect dup commit, got %v", err)
+	}
+	if evidence := blockpool.commitEquivocation(msg); evidence != nil {
+		t.Errorf("committing blocks of different proposers is not reported, got %v", evidence)
+	}
+	msg, err = commit(3, blkB)
+	if err != errDupCommit {
+		t.Fatalf("expect dup commit, got %v", err)
+	}
+	if evidence := blockpool.commitEquivocation(msg); evidence == nil || evidence.MsgType != gover.EVIDENCE_COMMIT ||
+		evidence.PeerPubkey != vconfig.PubkeyID(accs[3].PublicKey) {
+		t.Errorf("expect commit evidence of peer 3, got %v", evidence)
+	}
+}
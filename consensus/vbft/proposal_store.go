@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const PROPOSAL_FILE_NAME = "vbft_proposal"
+
+//proposalStore persist the last proposal made by the server. A second proposal at the same height is
+//slashable equivocation, so the proposal is saved before it is broadcast, and a server restarted within the
+//round rebroadcasts it instead of proposing again
+type proposalStore struct {
+	path string
+}
+
+func newProposalStore(dir string) *proposalStore {
+	return &proposalStore{path: filepath.Join(dir, PROPOSAL_FILE_NAME)}
+}
+
+//save write the proposal, replacing the one saved before, and sync it to disk
+func (self *proposalStore) save(proposal *blockProposalMsg) error {
+	data, err := proposal.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize proposal: %s", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(self.path), 0755); err != nil {
+		return err
+	}
+	tmp := self.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, self.path)
+}
+
+//load return the saved proposal of block blkNum, nil if the last proposal is at another height
+func (self *proposalStore) load(blkNum uint32) (*blockProposalMsg, error) {
+	data, err := ioutil.ReadFile(self.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	proposal := &blockProposalMsg{}
+	if err := proposal.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("deserialize proposal: %s", err)
+	}
+	if proposal.GetBlockNum() != blkNum {
+		return nil, nil
+	}
+	return proposal, nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposalStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proposal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store := newProposalStore(dir)
+	proposal, err := store.load(20)
+	assert.Nil(t, err)
+	assert.Nil(t, proposal)
+
+	msg := constructProposalMsgTest(account.NewAccount(""))
+	assert.Nil(t, store.save(msg))
+	//the proposal survives a restart, which opens the store again
+	proposal, err = newProposalStore(dir).load(20)
+	assert.Nil(t, err)
+	assert.Equal(t, msg.Block.Block.Hash(), proposal.Block.Block.Hash())
+	assert.Equal(t, msg.Block.Block.Header.SigData[0], proposal.Block.Block.Header.SigData[0])
+
+	proposal, err = store.load(21)
+	assert.Nil(t, err)
+	assert.Nil(t, proposal)
+}
@@ -22,21 +22,25 @@ import (
 	"bytes"
 	"fmt"
 	"math"
+	"path/filepath"
 	"reflect"
 	"sync"
 	"time"
 
 	"github.com/dnaproject2/DNA/account"
 	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
 	actorTypes "github.com/dnaproject2/DNA/consensus/actor"
 	"github.com/dnaproject2/DNA/consensus/vbft/config"
 	"github.com/dnaproject2/DNA/core/ledger"
 	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/signature"
 	"github.com/dnaproject2/DNA/core/types"
 	"github.com/dnaproject2/DNA/core/utils"
 	"github.com/dnaproject2/DNA/events"
 	"github.com/dnaproject2/DNA/events/message"
+	p2preq "github.com/dnaproject2/DNA/p2pserver/actor/req"
 	p2pmsg "github.com/dnaproject2/DNA/p2pserver/message/types"
 	gover "github.com/dnaproject2/DNA/smartcontract/service/native/governance"
 	ninit "github.com/dnaproject2/DNA/smartcontract/service/native/init"
@@ -119,6 +123,11 @@ type Server struct {
 	stateMgr   *StateMgr
 	timer      *EventTimer
 
+	// last proposal of self, kept across restarts
+	proposalStore *proposalStore
+	// peers which have been reported for equivocation
+	equivocators map[string]struct{}
+
 	msgRecvC   map[uint32]chan *p2pMsgPayload
 	msgC       chan ConsensusMsg
 	bftActionC chan *BftAction
@@ -137,6 +146,7 @@ func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, e
 		p2p:                &actorTypes.P2PActor{P2P: p2p},
 		ledger:             ledger.DefLedger,
 		incrValidator:      increment.NewIncrementValidator(20),
+		equivocators:       make(map[string]struct{}),
 	}
 	server.stateMgr = newStateMgr(server)
 
@@ -443,6 +453,8 @@ func (self *Server) initialize() error {
 		return fmt.Errorf("init blockpool: %s", err)
 	}
 	self.msgPool = newMsgPool(self, self.msgHistoryDuration)
+	self.proposalStore = newProposalStore(filepath.Join(config.DefConfig.Common.DataDir,
+		config.DefConfig.P2PNode.NetworkName))
 	self.peerPool = NewPeerPool(0, self) // FIXME: maxSize
 	self.timer = NewEventTimer(self)
 	self.syncer = newSyncer(self)
@@ -1205,7 +1217,7 @@ func (self *Server) processMsgEvent() error {
 				// add proposal to block-pool
 				if err := self.blockPool.newBlockProposal(pMsg); err != nil {
 					if err == errDupProposal {
-						// TODO: faulty proposer detected
+						self.reportEquivocation(self.blockPool.proposalEquivocation(pMsg))
 					}
 					log.Errorf("failed to add block proposal (%d): %s", msgBlkNum, err)
 					return nil
@@ -1261,6 +1273,7 @@ func (self *Server) processMsgEvent() error {
 			if msgBlkNum == self.GetCurrentBlockNo() {
 				// add endorse to block-pool
 				self.blockPool.newBlockEndorsement(pMsg)
+				self.reportEquivocation(self.blockPool.endorseEquivocation(pMsg))
 				log.Infof("server %d received endorse from %d, for proposer %d, block %d, empty: %t",
 					self.Index, pMsg.Endorser, pMsg.EndorsedProposer, msgBlkNum, pMsg.EndorseForEmpty)
 
@@ -1317,6 +1330,9 @@ func (self *Server) processMsgEvent() error {
 				//              else if WaitCommitsTimer has not started:
 				//                      start WaitCommitsTimer
 				if err := self.blockPool.newBlockCommitment(pMsg); err != nil {
+					if err == errDupCommit {
+						self.reportEquivocation(self.blockPool.commitEquivocation(pMsg))
+					}
 					log.Errorf("failed to add commit msg (%d): %s", msgBlkNum, err)
 					return nil
 				}
@@ -2127,6 +2143,49 @@ func (self *Server) creategovernaceTransaction(blkNum uint32) (*types.Transactio
 	return tx, err
 }
 
+//reportEquivocation invoke governance native contract slashEquivocation with evidence.
+//makeProposal never proposes twice at one height, even across restarts, since the proposal is saved in
+//proposalStore before it is broadcast.
+func (self *Server) reportEquivocation(evidence *gover.EquivocationEvidence) {
+	if evidence == nil {
+		return
+	}
+	if self.GetCurrentBlockNo() < config.GetSlashEquivocationHeight(config.DefConfig.P2PNode.NetworkId) {
+		return
+	}
+	if _, present := self.equivocators[evidence.PeerPubkey]; present {
+		return
+	}
+	code, err := utils.BuildNativeInvokeCode(nutils.GovernanceContractAddress, 0, gover.SLASH_EQUIVOCATION,
+		[]interface{}{evidence})
+	if err != nil {
+		log.Errorf("server %d build equivocation invoke code failed: %s", self.Index, err)
+		return
+	}
+	mutable := utils.NewInvokeTransaction(code)
+	mutable.GasPrice = config.DefConfig.Common.GasPrice
+	mutable.GasLimit = config.DefConfig.Common.GasLimit
+	mutable.Nonce = uint32(time.Now().Unix())
+	mutable.Payer = self.account.Address
+	hash := mutable.Hash()
+	sig, err := signature.Sign(self.account, hash[:])
+	if err != nil {
+		log.Errorf("server %d sign equivocation tx failed: %s", self.Index, err)
+		return
+	}
+	mutable.Sigs = []types.Sig{{PubKeys: []keypair.PublicKey{self.account.PublicKey}, M: 1, SigData: [][]byte{sig}}}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		log.Errorf("server %d construct equivocation tx failed: %s", self.Index, err)
+		return
+	}
+	p2preq.AddTransaction(tx)
+	self.equivocators[evidence.PeerPubkey] = struct{}{}
+	txHash := tx.Hash()
+	log.Warnf("server %d reported equivocation of peer %s, type %d, tx %s",
+		self.Index, evidence.PeerPubkey, evidence.MsgType, txHash.ToHexString())
+}
+
 //checkNeedUpdateChainConfig use blockcount
 func (self *Server) checkNeedUpdateChainConfig(blockNum uint32) bool {
 	prevBlk, _ := self.blockPool.getSealedBlock(blockNum - 1)
@@ -2184,6 +2243,29 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 			self.Index, blkNum, self.GetCurrentBlockNo())
 	}
 
+	// a second proposal at the same height is slashable equivocation, even with the same txs,
+	// since every block gets a new nonce, so rebroadcast the proposal made before.
+	for _, m := range self.msgPool.GetProposalMsgs(blkNum) {
+		if p, ok := m.(*blockProposalMsg); ok && p.Block.getProposer() == self.Index {
+			log.Infof("server %d rebroadcast proposal for block %d", self.Index, blkNum)
+			self.broadcast(p)
+			return nil
+		}
+	}
+	// the proposal made before restart is not in msg pool, but in proposal store
+	saved, err := self.proposalStore.load(blkNum)
+	if err != nil {
+		return fmt.Errorf("failed to load saved proposal: %s", err)
+	}
+	if saved != nil && saved.Block.getProposer() == self.Index {
+		log.Infof("server %d rebroadcast saved proposal for block %d", self.Index, blkNum)
+		h, _ := HashMsg(saved)
+		self.msgPool.AddMsg(saved, h)
+		self.processProposalMsg(saved)
+		self.broadcast(saved)
+		return nil
+	}
+
 	validHeight := self.validHeight(blkNum)
 	sysTxs := make([]*types.Transaction, 0)
 	userTxs := make([]*types.Transaction, 0)
@@ -2225,6 +2307,9 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	}
 
 	log.Infof("server %d make proposal for block %d", self.Index, blkNum)
+	if err := self.proposalStore.save(proposal); err != nil {
+		return fmt.Errorf("failed to save proposal: %s", err)
+	}
 
 	// add proposal to self
 	h, _ := HashMsg(proposal)
@@ -26,9 +26,13 @@ import (
 	"github.com/dnaproject2/DNA/core/states"
 	"github.com/dnaproject2/DNA/core/store"
 	"github.com/dnaproject2/DNA/core/store/ledgerstore"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
 	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/context"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
 	cstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -48,6 +52,17 @@ func NewLedger(dataDir string, stateHashHeight uint32) (*Ledger, error) {
 	}, nil
 }
 
+//NewLightLedger return the ledger of light node, which only keeps verified headers
+func NewLightLedger(dataDir string) (*Ledger, error) {
+	ldgStore, err := ledgerstore.NewLightStore(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("NewLightStore error %s", err)
+	}
+	return &Ledger{
+		ldgStore: ldgStore,
+	}, nil
+}
+
 func (self *Ledger) GetStore() store.LedgerStore {
 	return self.ldgStore
 }
@@ -179,6 +194,30 @@ func (self *Ledger) PreExecuteContract(tx *types.Transaction) (*cstate.PreExecRe
 	return self.ldgStore.PreExecuteContract(tx)
 }
 
+func (self *Ledger) PreExecuteContractWithOverride(tx *types.Transaction, override *cstate.StateOverride) (*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractWithOverride(tx, override)
+}
+
+func (self *Ledger) TraceTransaction(txHash common.Uint256) (*trace.TxTrace, error) {
+	return self.ldgStore.TraceTransaction(txHash)
+}
+
+func (self *Ledger) TracePreExecute(tx *types.Transaction) (*trace.TxTrace, error) {
+	return self.ldgStore.TracePreExecute(tx)
+}
+
+func (self *Ledger) ProfilePreExecute(tx *types.Transaction) (*profile.GasProfile, error) {
+	return self.ldgStore.ProfilePreExecute(tx)
+}
+
+func (self *Ledger) PreExecuteContractWithTracer(tx *types.Transaction, tracer context.Tracer) (*cstate.PreExecResult, error) {
+	return self.ldgStore.PreExecuteContractWithTracer(tx, tracer)
+}
+
+func (self *Ledger) EstimateGas(tx *types.Transaction) (*cstate.GasEstimate, error) {
+	return self.ldgStore.EstimateGas(tx)
+}
+
 func (self *Ledger) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
 	return self.ldgStore.GetEventNotifyByTx(tx)
 }
@@ -187,6 +226,22 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) ExportSnapshot(path string) (*snapshot.Manifest, error) {
+	return self.ldgStore.ExportSnapshot(path)
+}
+
+func (self *Ledger) ImportSnapshot(path string, stateDigest common.Uint256) error {
+	return self.ldgStore.ImportSnapshot(path, stateDigest)
+}
+
+func (self *Ledger) GetSnapshotManifest() *snapshot.Manifest {
+	return self.ldgStore.GetSnapshotManifest()
+}
+
+func (self *Ledger) GetSnapshotChunk(height, index uint32) ([]byte, error) {
+	return self.ldgStore.GetSnapshotChunk(height, index)
+}
+
 func (self *Ledger) Close() error {
 	return self.ldgStore.Close()
 }
@@ -0,0 +1,305 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package badgerstore provides the persist store backend on badger, a LSM tree store which keeps values in a
+//separate value log
+package badgerstore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/badger"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/store/common"
+)
+
+//Name of badger backend in store backend registry
+const BACKEND_NAME = "badger"
+
+const (
+	VLOG_GC_INTERVAL      = 10 * time.Minute //Interval of value log garbage collection
+	VLOG_GC_DISCARD_RATIO = 0.5              //Rewrite a value log file when at least half of it can be discarded
+)
+
+func init() {
+	common.RegisterBackend(BACKEND_NAME, func(path string) (common.PersistStore, error) {
+		return NewBadgerStore(path)
+	})
+}
+
+type batchOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+//BadgerStore persist store on badger
+type BadgerStore struct {
+	db        *badger.DB
+	batch     []batchOp
+	closeOnce sync.Once
+	exit      chan struct{}
+}
+
+//NewBadgerStore open the badger database at dir and return BadgerStore instance
+func NewBadgerStore(dir string) (*BadgerStore, error) {
+	opts := badger.DefaultOptions(dir).
+		WithSyncWrites(true).
+		WithTruncate(true).
+		WithLogger(logger{})
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	store := &BadgerStore{
+		db:   db,
+		exit: make(chan struct{}),
+	}
+	go store.runValueLogGC()
+	return store, nil
+}
+
+//runValueLogGC reclaim the space of value log periodically, values overwritten or deleted are only removed from
+//disk by it
+func (self *BadgerStore) runValueLogGC() {
+	ticker := time.NewTicker(VLOG_GC_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			for self.db.RunValueLogGC(VLOG_GC_DISCARD_RATIO) == nil {
+			}
+		case <-self.exit:
+			return
+		}
+	}
+}
+
+//Put a key-value pair to badger
+func (self *BadgerStore) Put(key []byte, value []byte) error {
+	return self.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(copyBytes(key), copyBytes(value))
+	})
+}
+
+//Get the value of a key from badger
+func (self *BadgerStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := self.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, common.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	return value, nil
+}
+
+//Has return whether the key is exist in badger
+func (self *BadgerStore) Has(key []byte) (bool, error) {
+	err := self.db.View(func(txn *badger.Txn) error {
+		_, err := txn.Get(key)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Delete the key in badger
+func (self *BadgerStore) Delete(key []byte) error {
+	return self.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(copyBytes(key))
+	})
+}
+
+//NewBatch start commit batch
+func (self *BadgerStore) NewBatch() {
+	self.batch = make([]batchOp, 0)
+}
+
+//BatchPut put a key-value pair to batch
+func (self *BadgerStore) BatchPut(key []byte, value []byte) {
+	self.batch = append(self.batch, batchOp{key: copyBytes(key), value: copyBytes(value)})
+}
+
+//BatchDelete delete a key to batch
+func (self *BadgerStore) BatchDelete(key []byte) {
+	self.batch = append(self.batch, batchOp{key: copyBytes(key), delete: true})
+}
+
+//BatchCommit commit batch to badger in one transaction. A batch exceeding the transaction size limit of badger is
+//split into several transactions, so it is only atomic within the limit
+func (self *BadgerStore) BatchCommit() error {
+	txn := self.db.NewTransaction(true)
+	defer func() { txn.Discard() }()
+	for _, op := range self.batch {
+		err := applyOp(txn, op)
+		if err == badger.ErrTxnTooBig {
+			if err = txn.Commit(); err != nil {
+				return err
+			}
+			txn = self.db.NewTransaction(true)
+			err = applyOp(txn, op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+	self.batch = nil
+	return nil
+}
+
+func applyOp(txn *badger.Txn, op batchOp) error {
+	if op.delete {
+		return txn.Delete(op.key)
+	}
+	return txn.Set(op.key, op.value)
+}
+
+//Close badger
+func (self *BadgerStore) Close() error {
+	var err error
+	self.closeOnce.Do(func() {
+		close(self.exit)
+		err = self.db.Close()
+	})
+	return err
+}
+
+//NewIterator return a iterator of the snapshot of badger with the key prefix, in key order
+func (self *BadgerStore) NewIterator(prefix []byte) common.StoreIterator {
+	txn := self.db.NewTransaction(false)
+	return &badgerIterator{
+		txn:    txn,
+		iter:   txn.NewIterator(badger.IteratorOptions{Prefix: prefix}),
+		prefix: copyBytes(prefix),
+	}
+}
+
+type badgerIterator struct {
+	txn      *badger.Txn
+	iter     *badger.Iterator
+	prefix   []byte
+	started  bool
+	released bool
+	err      error
+}
+
+func (self *badgerIterator) valid() bool {
+	return !self.released && self.started && self.iter.ValidForPrefix(self.prefix)
+}
+
+func (self *badgerIterator) Next() bool {
+	if self.released {
+		return false
+	}
+	if !self.started {
+		return self.First()
+	}
+	if !self.valid() {
+		return false
+	}
+	self.iter.Next()
+	return self.valid()
+}
+
+func (self *badgerIterator) First() bool {
+	if self.released {
+		return false
+	}
+	self.started = true
+	self.iter.Seek(self.prefix)
+	return self.valid()
+}
+
+func (self *badgerIterator) Key() []byte {
+	if !self.valid() {
+		return nil
+	}
+	return self.iter.Item().KeyCopy(nil)
+}
+
+func (self *badgerIterator) Value() []byte {
+	if !self.valid() {
+		return nil
+	}
+	value, err := self.iter.Item().ValueCopy(nil)
+	if err != nil {
+		self.err = err
+		return nil
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	return value
+}
+
+func (self *badgerIterator) Release() {
+	if self.released {
+		return
+	}
+	self.released = true
+	self.iter.Close()
+	self.txn.Discard()
+}
+
+func (self *badgerIterator) Error() error {
+	return self.err
+}
+
+//logger print the logs of badger by the log of node
+type logger struct{}
+
+func (logger) Errorf(format string, a ...interface{}) {
+	log.Errorf("[badger] "+format, a...)
+}
+
+func (logger) Warningf(format string, a ...interface{}) {
+	log.Warnf("[badger] "+format, a...)
+}
+
+func (logger) Infof(format string, a ...interface{}) {
+	log.Debugf("[badger] "+format, a...)
+}
+
+func (logger) Debugf(format string, a ...interface{}) {
+	log.Debugf("[badger] "+format, a...)
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return []byte{}
+	}
+	return append([]byte{}, data...)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+//NewPersistStoreFunc open the persist store at path
+type NewPersistStoreFunc func(path string) (PersistStore, error)
+
+var (
+	backends    = make(map[string]NewPersistStoreFunc)
+	backendLock sync.RWMutex
+)
+
+//RegisterBackend register persist store backend by name. Backend packages register themselves in init,
+//so import the backend package to make it selectable.
+func RegisterBackend(name string, newStore NewPersistStoreFunc) {
+	backendLock.Lock()
+	defer backendLock.Unlock()
+	if newStore == nil {
+		panic("store: register nil backend " + name)
+	}
+	if _, ok := backends[name]; ok {
+		panic("store: register backend twice " + name)
+	}
+	backends[name] = newStore
+}
+
+//NewPersistStore open the persist store at path by the backend registered with name
+func NewPersistStore(name, path string) (PersistStore, error) {
+	backendLock.RLock()
+	newStore, ok := backends[name]
+	backendLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown store backend %q, registered backends %v", name, Backends())
+	}
+	return newStore(path)
+}
+
+//Backends return the sorted names of registered backends
+func Backends() []string {
+	backendLock.RLock()
+	defer backendLock.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -27,6 +27,7 @@ const (
 	DATA_HEADER                            = 0x01 //Block hash => block hash key prefix
 	DATA_TRANSACTION                       = 0x02 //Transction hash = > transaction key prefix
 	DATA_STATE_MERKLE_ROOT                 = 0x21 // block height => write set hash + state merkle root
+	DATA_STATE_UNDO                        = 0x23 // block height => previous values of the states written by block
 
 	// Transaction
 	ST_BOOKKEEPER DataEntryPrefix = 0x03 //BookKeeper state key prefix
@@ -43,6 +44,7 @@ const (
 	SYS_CURRENT_STATE_ROOT DataEntryPrefix = 0x12 //no use
 	SYS_BLOCK_MERKLE_TREE  DataEntryPrefix = 0x13 // Block merkle tree root key prefix
 	SYS_STATE_MERKLE_TREE  DataEntryPrefix = 0x20 // state merkle tree root key prefix
+	SYS_PRUNED_HEIGHT      DataEntryPrefix = 0x22 // Pruned height key prefix
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
 )
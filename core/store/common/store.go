@@ -26,6 +26,8 @@ import (
 )
 
 var ErrNotFound = errors.New("not found")
+var ErrPruned = errors.New("pruned")
+var ErrNoStateHistory = errors.New("state history not kept")
 
 //Store iterator for iterate store
 type StoreIterator interface {
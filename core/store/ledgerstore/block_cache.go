@@ -74,6 +74,11 @@ func (this *BlockCache) GetBlock(blockHash common.Uint256) *types.Block {
 	return block.(*types.Block)
 }
 
+//RemoveBlock remove block from cache
+func (this *BlockCache) RemoveBlock(blockHash common.Uint256) {
+	this.blockCache.Remove(string(blockHash.ToArray()))
+}
+
 //ContainBlock return whether block is in cache
 func (this *BlockCache) ContainBlock(blockHash common.Uint256) bool {
 	return this.blockCache.Contains(string(blockHash.ToArray()))
@@ -102,3 +107,8 @@ func (this *BlockCache) GetTransaction(txHash common.Uint256) (*types.Transactio
 func (this *BlockCache) ContainTransaction(txHash common.Uint256) bool {
 	return this.transactionCache.Contains(string(txHash.ToArray()))
 }
+
+//RemoveTransaction remove transaction from cache
+func (this *BlockCache) RemoveTransaction(txHash common.Uint256) {
+	this.transactionCache.Remove(string(txHash.ToArray()))
+}
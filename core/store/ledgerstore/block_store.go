@@ -26,17 +26,18 @@ import (
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/serialization"
 	scom "github.com/dnaproject2/DNA/core/store/common"
-	"github.com/dnaproject2/DNA/core/store/leveldbstore"
 	"github.com/dnaproject2/DNA/core/types"
 	"io"
+	"sync/atomic"
 )
 
 //Block store save the data of block & transaction
 type BlockStore struct {
-	enableCache bool                       //Is enable lru cache
-	dbDir       string                     //The path of store file
-	cache       *BlockCache                //The cache of block, if have.
-	store       *leveldbstore.LevelDBStore //block store handler
+	enableCache  bool              //Is enable lru cache
+	dbDir        string            //The path of store file
+	cache        *BlockCache       //The cache of block, if have.
+	store        scom.PersistStore //block store handler
+	prunedHeight uint32            //Transactions of blocks below pruned height are pruned, except genesis block
 }
 
 //NewBlockStore return the block store instance
@@ -50,7 +51,7 @@ func NewBlockStore(dbDir string, enableCache bool) (*BlockStore, error) {
 		}
 	}
 
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := openPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +61,11 @@ func NewBlockStore(dbDir string, enableCache bool) (*BlockStore, error) {
 		store:       store,
 		cache:       cache,
 	}
+	prunedHeight, err := blockStore.loadPrunedHeight()
+	if err != nil {
+		return nil, fmt.Errorf("loadPrunedHeight error %s", err)
+	}
+	blockStore.prunedHeight = prunedHeight
 	return blockStore, nil
 }
 
@@ -120,9 +126,15 @@ func (this *BlockStore) GetBlock(blockHash common.Uint256) (*types.Block, error)
 	if err != nil {
 		return nil, err
 	}
+	if this.IsPruned(header.Height) {
+		return nil, scom.ErrPruned
+	}
 	txList := make([]*types.Transaction, 0, len(txHashes))
 	for _, txHash := range txHashes {
 		tx, _, err := this.GetTransaction(txHash)
+		if err == scom.ErrPruned {
+			return nil, err
+		}
 		if err != nil {
 			return nil, fmt.Errorf("GetTransaction %s error %s", txHash.ToHexString(), err)
 		}
@@ -421,6 +433,9 @@ func (this *BlockStore) loadTransaction(txHash common.Uint256) (*types.Transacti
 	if eof {
 		return nil, 0, io.ErrUnexpectedEOF
 	}
+	if source.Len() == 0 {
+		return nil, height, scom.ErrPruned
+	}
 	tx = new(types.Transaction)
 	err = tx.Deserialization(source)
 	if err != nil {
@@ -448,6 +463,71 @@ func (this *BlockStore) ContainTransaction(txHash common.Uint256) (bool, error)
 	return true, nil
 }
 
+//PruneBlock replace the transactions of block with the height only records, so that the block height of
+//transaction can still be found and the transaction won't be accepted again. Writes directly to store
+//without batch, because pruning runs in background of block saving
+func (this *BlockStore) PruneBlock(height uint32) ([]common.Uint256, error) {
+	blockHash, err := this.GetBlockHash(height)
+	if err != nil {
+		return nil, err
+	}
+	_, txHashes, err := this.loadHeaderWithTx(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if this.enableCache {
+		this.cache.RemoveBlock(blockHash)
+	}
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, height)
+	for _, txHash := range txHashes {
+		if this.enableCache {
+			this.cache.RemoveTransaction(txHash)
+		}
+		err = this.store.Put(this.getTransactionKey(txHash), value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return txHashes, nil
+}
+
+//IsPruned return whether the transactions of block at height are pruned
+func (this *BlockStore) IsPruned(height uint32) bool {
+	return height != 0 && height < this.GetPrunedHeight()
+}
+
+//GetPrunedHeight return the pruned height, transactions of blocks below it are pruned
+func (this *BlockStore) GetPrunedHeight() uint32 {
+	return atomic.LoadUint32(&this.prunedHeight)
+}
+
+//SavePrunedHeight persist the pruned height to store
+func (this *BlockStore) SavePrunedHeight(height uint32) error {
+	value := make([]byte, 4)
+	binary.LittleEndian.PutUint32(value, height)
+	err := this.store.Put(this.getPrunedHeightKey(), value)
+	if err != nil {
+		return err
+	}
+	atomic.StoreUint32(&this.prunedHeight, height)
+	return nil
+}
+
+func (this *BlockStore) loadPrunedHeight() (uint32, error) {
+	value, err := this.store.Get(this.getPrunedHeightKey())
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(value) != 4 {
+		return 0, fmt.Errorf("invalid pruned height")
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
 //GetVersion return the version of store
 func (this *BlockStore) GetVersion() (byte, error) {
 	key := this.getVersionKey()
@@ -476,6 +556,7 @@ func (this *BlockStore) ClearAll() error {
 	if err := iter.Error(); err != nil {
 		return err
 	}
+	atomic.StoreUint32(&this.prunedHeight, 0)
 	return this.CommitTo()
 }
 
@@ -519,6 +600,10 @@ func (this *BlockStore) getBlockMerkleTreeKey() []byte {
 	return []byte{byte(scom.SYS_BLOCK_MERKLE_TREE)}
 }
 
+func (this *BlockStore) getPrunedHeightKey() []byte {
+	return []byte{byte(scom.SYS_PRUNED_HEIGHT)}
+}
+
 func (this *BlockStore) getVersionKey() []byte {
 	return []byte{byte(scom.SYS_VERSION)}
 }
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+)
+
+const (
+	GAS_ESTIMATE_MARGIN = 20         //Percent of safety margin added to the estimated gas
+	GAS_PRICE_NAME      = "gasPrice" //Global param name of the minimum gas price
+)
+
+//EstimateGas pre-execute the transaction against the current states, and return the gas it needs together with
+//a suggested gas limit and the minimum gas price. Signature verification is not included, as the node only charges
+//the execution and code length of a transaction
+func (this *LedgerStoreImp) EstimateGas(tx *types.Transaction) (*sstate.GasEstimate, error) {
+	if tx.TxType != types.Invoke && tx.TxType != types.Deploy {
+		return nil, fmt.Errorf("transaction type %d not supported", tx.TxType)
+	}
+	result, err := this.preExecuteContract(tx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice, err := this.getMinGasPrice()
+	if err != nil {
+		return nil, err
+	}
+	gasLimit, overflow := common.SafeAdd(result.Gas, result.Gas/100*GAS_ESTIMATE_MARGIN)
+	if overflow {
+		gasLimit = result.Gas
+	}
+	if gasLimit < config.DefConfig.Common.GasLimit {
+		gasLimit = config.DefConfig.Common.GasLimit
+	}
+	return &sstate.GasEstimate{
+		State:    result.State,
+		Gas:      result.Gas,
+		GasLimit: gasLimit,
+		GasPrice: gasPrice,
+		Result:   result.Result,
+		Notify:   result.Notify,
+	}, nil
+}
+
+//getMinGasPrice return the gas price set in global params, or the local one if it is higher
+func (this *LedgerStoreImp) getMinGasPrice() (uint64, error) {
+	height := this.GetCurrentBlockHeight()
+	conf := &smartcontract.Config{
+		Time:      uint32(time.Now().Unix()),
+		Height:    height + 1,
+		BlockHash: this.GetBlockHash(height),
+	}
+	cache := storage.NewCacheDB(this.stateStore.NewOverlayDB())
+	params, err := this.getGlobalParams(conf, cache, []string{GAS_PRICE_NAME})
+	if err != nil {
+		return 0, err
+	}
+	gasPrice := params[GAS_PRICE_NAME]
+	if gasPrice < config.DefConfig.Common.GasPrice {
+		gasPrice = config.DefConfig.Common.GasPrice
+	}
+	return gasPrice, nil
+}
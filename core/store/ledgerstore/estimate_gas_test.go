@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/service/neovm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateGas(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "estimategas")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	deploy := &payload.DeployCode{Code: counterCode(), NeedStorage: true, Name: "counter"}
+	contract := deploy.Address()
+	deployTx := newTx(t, 0, types.Deploy, deploy)
+	result, err := store.EstimateGas(deployTx)
+	assert.Nil(t, err)
+	assert.Equal(t, neovm.CONTRACT_CREATE_GAS+calcGasByCodeLen(len(deploy.Code), neovm.UINT_DEPLOY_CODE_LEN_GAS), result.Gas)
+	assert.True(t, result.GasLimit >= result.Gas)
+	addBlock(t, store, makeSoloBlock(t, store, acc, deployTx))
+
+	result, err = store.EstimateGas(newIncreaseTx(t, 1, contract, "a"))
+	assert.Nil(t, err)
+	assert.Equal(t, byte(event.CONTRACT_STATE_SUCCESS), result.State)
+	assert.True(t, result.Gas >= neovm.MIN_TRANSACTION_GAS)
+	assert.True(t, result.GasLimit >= result.Gas*(100+GAS_ESTIMATE_MARGIN)/100)
+	assert.True(t, result.GasLimit >= config.DefConfig.Common.GasLimit)
+	assert.True(t, result.GasPrice >= config.DefConfig.Common.GasPrice)
+
+	//the estimated gas is what the transaction is charged when executed in block
+	unbind := newNativeTx(t, 2, utils.OntContractAddress, ont.TRANSFER_NAME,
+		[]interface{}{[]*ont.State{{From: acc.Address, To: acc.Address, Value: 1}}}, acc)
+	claim := newNativeTx(t, 4, utils.OngContractAddress, ont.TRANSFERFROM_NAME,
+		[]interface{}{&ont.TransferFrom{Sender: acc.Address, From: utils.OntContractAddress, To: acc.Address,
+			Value: 1000000}}, acc)
+	addBlock(t, store, makeSoloBlock(t, store, acc, unbind, claim))
+	paid := newPaidIncreaseTx(t, 5, contract, "a", acc)
+	result, err = store.EstimateGas(paid)
+	assert.Nil(t, err)
+	addBlock(t, store, makeSoloBlock(t, store, acc, paid))
+	notify, err := store.GetEventNotifyByTx(paid.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, event.CONTRACT_STATE_SUCCESS, notify.State)
+	assert.Equal(t, result.Gas*paid.GasPrice, notify.GasConsumed)
+
+	_, err = store.EstimateGas(newIncreaseTx(t, 3, common.Address{1}, "a"))
+	assert.NotNil(t, err)
+}
@@ -27,19 +27,18 @@ import (
 	"github.com/dnaproject2/DNA/common/log"
 	"github.com/dnaproject2/DNA/common/serialization"
 	scom "github.com/dnaproject2/DNA/core/store/common"
-	"github.com/dnaproject2/DNA/core/store/leveldbstore"
 	"github.com/dnaproject2/DNA/smartcontract/event"
 )
 
 //Saving event notifies gen by smart contract execution
 type EventStore struct {
-	dbDir string                     //Store path
-	store *leveldbstore.LevelDBStore //Store handler
+	dbDir string            //Store path
+	store scom.PersistStore //Store handler
 }
 
 //NewEventStore return event store instance
 func NewEventStore(dbDir string) (*EventStore, error) {
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := openPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -134,6 +133,22 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 	return evtNotifies, nil
 }
 
+//PruneBlock delete the event notifies of block and its transactions. Writes directly to store without batch,
+//because pruning runs in background of block saving
+func (this *EventStore) PruneBlock(height uint32, txHashs []common.Uint256) error {
+	for _, txHash := range txHashs {
+		err := this.store.Delete(this.getEventNotifyByTxKey(txHash))
+		if err != nil {
+			return err
+		}
+	}
+	key, err := this.getEventNotifyByBlockKey(height)
+	if err != nil {
+		return err
+	}
+	return this.store.Delete(key)
+}
+
 //CommitTo event store batch to store
 func (this *EventStore) CommitTo() error {
 	return this.store.BatchCommit()
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	vconfig "github.com/dnaproject2/DNA/consensus/vbft/config"
+	"github.com/dnaproject2/DNA/core/states"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func governanceReader(store *LedgerStoreImp) governance.StorageReader {
+	return func(key []byte) ([]byte, error) {
+		item, err := store.GetStorageItem(&states.StorageKey{
+			ContractAddress: utils.GovernanceContractAddress,
+			Key:             key[common.ADDR_LEN:],
+		})
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return item.Value, nil
+	}
+}
+
+func TestGovernanceQuery(t *testing.T) {
+	acc := account.NewAccount("")
+	peers := make([]*account.Account, 7)
+	for i := range peers {
+		peers[i] = account.NewAccount("")
+	}
+	defer setVbftGenesis(acc, peers)()
+
+	dir, err := ioutil.TempDir("", "governance")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+	commitDpos := func(nonce uint32) *types.Transaction {
+		return newNativeTx(t, nonce, utils.GovernanceContractAddress, governance.COMMIT_DPOS, []interface{}{""}, acc)
+	}
+	//fee is split by executeCommitDpos2 after NEW_VERSION_VIEW
+	var nonce uint32
+	for view := uint32(1); view <= governance.NEW_VERSION_VIEW; view++ {
+		addBlock(t, store, makeSoloBlock(t, store, acc, commitDpos(nonce)))
+		nonce++
+	}
+	//fee split is not recorded before the activation height
+	config.VIEW_SPLIT_FEE_HEIGHT[config.NETWORK_ID_SOLO_NET] = store.GetCurrentBlockHeight() + 2
+	defer func() { config.VIEW_SPLIT_FEE_HEIGHT[config.NETWORK_ID_SOLO_NET] = 0 }()
+	unrecorded := commitDpos(nonce)
+	addBlock(t, store, makeSoloBlock(t, store, acc, unrecorded))
+	nonce++
+	//give governance contract some ong as income of view
+	unbind := newNativeTx(t, nonce, utils.OntContractAddress, ont.TRANSFER_NAME,
+		[]interface{}{[]*ont.State{{From: acc.Address, To: acc.Address, Value: 1}}}, acc)
+	income := newNativeTx(t, nonce+1, utils.OngContractAddress, ont.TRANSFERFROM_NAME,
+		[]interface{}{&ont.TransferFrom{Sender: acc.Address, From: utils.OntContractAddress,
+			To: utils.GovernanceContractAddress, Value: 7000000}}, acc)
+	addBlock(t, store, makeSoloBlock(t, store, acc, unbind, income))
+	split := commitDpos(nonce + 2)
+	addBlock(t, store, makeSoloBlock(t, store, acc, split))
+	for _, tx := range []*types.Transaction{unbind, income, split, unrecorded} {
+		notify, err := store.GetEventNotifyByTx(tx.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, event.CONTRACT_STATE_SUCCESS, notify.State)
+	}
+
+	read := governanceReader(store)
+	governanceView, err := governance.ReadGovernanceView(read)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(governance.NEW_VERSION_VIEW+3), governanceView.View)
+	vbftConfig, err := governance.ReadConfig(read)
+	assert.Nil(t, err)
+	peerPoolMap, err := governance.ReadPeerPoolMap(read, governanceView.View)
+	assert.Nil(t, err)
+	assert.Equal(t, len(peers), len(peerPoolMap.PeerPoolMap))
+	ranked := governance.RankPeers(peerPoolMap)
+	assert.Equal(t, len(peers), len(ranked))
+	for i := 1; i < len(ranked); i++ {
+		assert.True(t, ranked[i-1].PeerPubkey > ranked[i].PeerPubkey)
+	}
+	peerPoolMap, err = governance.ReadPeerPoolMap(read, governanceView.View+1)
+	assert.Nil(t, err)
+	assert.Nil(t, peerPoolMap)
+
+	viewSplitFee, err := governance.ReadViewSplitFee(read, governance.NEW_VERSION_VIEW)
+	assert.Nil(t, err)
+	assert.Nil(t, viewSplitFee)
+	viewSplitFee, err = governance.ReadViewSplitFee(read, governance.NEW_VERSION_VIEW+1)
+	assert.Nil(t, err)
+	assert.Nil(t, viewSplitFee)
+	viewSplitFee, err = governance.ReadViewSplitFee(read, governance.NEW_VERSION_VIEW+2)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7000000), viewSplitFee.Income)
+	assert.Equal(t, uint64(0), viewSplitFee.DappFee)
+	assert.Equal(t, int(vbftConfig.K), len(viewSplitFee.Peers))
+	var total uint64
+	for _, peer := range viewSplitFee.Peers {
+		assert.True(t, peer.Consensus)
+		assert.Equal(t, viewSplitFee.Peers[0].Amount, peer.Amount)
+		assert.Equal(t, uint64(0), peer.StakeAmount)
+		splitFeeAddress, err := governance.ReadSplitFeeAddress(read, peer.Address)
+		assert.Nil(t, err)
+		assert.Equal(t, peer.Amount, splitFeeAddress.Amount)
+		total += peer.Amount
+	}
+	assert.True(t, total > 0 && total <= viewSplitFee.Income)
+
+	peer0 := vconfig.PubkeyID(peers[0].PublicKey)
+	peerAttributes, err := governance.ReadPeerAttributes(read, peer0)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), peerAttributes.TPeerCost)
+	authorizeInfo, err := governance.ReadAuthorizeInfo(read, peer0, acc.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, governance.AuthorizeInfo{PeerPubkey: peer0, Address: acc.Address}, *authorizeInfo)
+	totalStake, err := governance.ReadTotalStake(read, acc.Address)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), totalStake.Stake)
+}
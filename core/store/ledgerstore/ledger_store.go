@@ -40,16 +40,21 @@ import (
 	"github.com/dnaproject2/DNA/core/signature"
 	"github.com/dnaproject2/DNA/core/states"
 	"github.com/dnaproject2/DNA/core/store"
+	_ "github.com/dnaproject2/DNA/core/store/badgerstore" //register storage backends
 	scom "github.com/dnaproject2/DNA/core/store/common"
+	_ "github.com/dnaproject2/DNA/core/store/leveldbstore"
 	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
 	"github.com/dnaproject2/DNA/core/types"
 	"github.com/dnaproject2/DNA/errors"
 	"github.com/dnaproject2/DNA/events"
 	"github.com/dnaproject2/DNA/events/message"
 	"github.com/dnaproject2/DNA/smartcontract"
 	scommon "github.com/dnaproject2/DNA/smartcontract/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
 	"github.com/dnaproject2/DNA/smartcontract/event"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/proposal"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
 	"github.com/dnaproject2/DNA/smartcontract/service/neovm"
 	sstate "github.com/dnaproject2/DNA/smartcontract/states"
@@ -70,6 +75,15 @@ var (
 	MerkleTreeStorePath = "merkle_tree.db"
 )
 
+//openPersistStore open the persist store at dbDir with the storage backend in config
+func openPersistStore(dbDir string) (scom.PersistStore, error) {
+	backend := config.DefConfig.Common.StorageBackend
+	if backend == "" {
+		backend = config.DEFAULT_STORAGE_BACKEND
+	}
+	return scom.NewPersistStore(backend, dbDir)
+}
+
 //LedgerStoreImp is main store struct fo ledger
 type LedgerStoreImp struct {
 	blockStore           *BlockStore                      //BlockStore for saving block & transaction data
@@ -86,6 +100,12 @@ type LedgerStoreImp struct {
 	vbftPeerInfoblock    map[string]uint32 //pubInfo save pubkey,peerindex
 	lock                 sync.RWMutex
 	stateHashCheckHeight uint32
+	snapshotDir          string           //Directory of snapshots produced by this node
+	snapshotReader       *snapshot.Reader //The latest snapshot served to peers
+	snapshotLock         sync.RWMutex
+	snapshotting         uint32
+	pruning              uint32 //Is pruning running in background
+	pruneClosing         uint32 //Stop the background pruning
 }
 
 //NewLedgerStore return LedgerStoreImp instance
@@ -119,6 +139,9 @@ func NewLedgerStore(dataDir string, stateHashHeight uint32) (*LedgerStoreImp, er
 	}
 	ledgerStore.eventStore = eventState
 
+	ledgerStore.snapshotDir = fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirSnapshot)
+	ledgerStore.loadSnapshot()
+
 	return ledgerStore, nil
 }
 
@@ -179,46 +202,71 @@ func (this *LedgerStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.
 			return fmt.Errorf("init error %s", err)
 		}
 	}
-	//load vbft peerInfo
+	err = this.loadVbftPeerInfo()
+	if err != nil {
+		return err
+	}
+	// check and fix imcompatible states
+	err = this.stateStore.CheckStorage()
+	return err
+}
+
+//loadVbftPeerInfo load vbft peerInfo from the chain config of current block
+func (this *LedgerStoreImp) loadVbftPeerInfo() error {
 	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
-	if consensusType == "vbft" {
-		header, err := this.GetHeaderByHash(this.currBlockHash)
+	if consensusType != "vbft" {
+		return nil
+	}
+	header, err := this.GetHeaderByHash(this.currBlockHash)
+	if err != nil {
+		return err
+	}
+	peerInfo, err := getVbftPeerInfo(header, this.GetHeaderByHeight)
+	if err != nil {
+		return err
+	}
+	this.lock.Lock()
+	this.vbftPeerInfoheader = make(map[string]uint32)
+	this.vbftPeerInfoblock = make(map[string]uint32)
+	for id, index := range peerInfo {
+		this.vbftPeerInfoheader[id] = index
+		this.vbftPeerInfoblock[id] = index
+	}
+	this.lock.Unlock()
+	return nil
+}
+
+//getVbftPeerInfo return the vbft peers of the chain config which header is built on
+func getVbftPeerInfo(header *types.Header, getHeader func(height uint32) (*types.Header, error)) (map[string]uint32, error) {
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return nil, err
+	}
+	var cfg *vconfig.ChainConfig
+	if blkInfo.NewChainConfig != nil {
+		cfg = blkInfo.NewChainConfig
+	} else {
+		cfgHeader, err := getHeader(blkInfo.LastConfigBlockNum)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		blkInfo, err := vconfig.VbftBlock(header)
-		if err != nil {
-			return err
+		if cfgHeader == nil {
+			return nil, fmt.Errorf("cannot find config block num:%d", blkInfo.LastConfigBlockNum)
 		}
-		var cfg *vconfig.ChainConfig
-		if blkInfo.NewChainConfig != nil {
-			cfg = blkInfo.NewChainConfig
-		} else {
-			cfgHeader, err := this.GetHeaderByHeight(blkInfo.LastConfigBlockNum)
-			if err != nil {
-				return err
-			}
-			Info, err := vconfig.VbftBlock(cfgHeader)
-			if err != nil {
-				return err
-			}
-			if Info.NewChainConfig == nil {
-				return fmt.Errorf("getNewChainConfig error block num:%d", blkInfo.LastConfigBlockNum)
-			}
-			cfg = Info.NewChainConfig
+		Info, err := vconfig.VbftBlock(cfgHeader)
+		if err != nil {
+			return nil, err
 		}
-		this.lock.Lock()
-		this.vbftPeerInfoheader = make(map[string]uint32)
-		this.vbftPeerInfoblock = make(map[string]uint32)
-		for _, p := range cfg.Peers {
-			this.vbftPeerInfoheader[p.ID] = p.Index
-			this.vbftPeerInfoblock[p.ID] = p.Index
+		if Info.NewChainConfig == nil {
+			return nil, fmt.Errorf("getNewChainConfig error block num:%d", blkInfo.LastConfigBlockNum)
 		}
-		this.lock.Unlock()
+		cfg = Info.NewChainConfig
 	}
-	// check and fix imcompatible states
-	err = this.stateStore.CheckStorage()
-	return err
+	peerInfo := make(map[string]uint32)
+	for _, p := range cfg.Peers {
+		peerInfo[p.ID] = p.Index
+	}
+	return peerInfo, nil
 }
 
 func (this *LedgerStoreImp) hasAlreadyInitGenesisBlock() (bool, error) {
@@ -430,7 +478,12 @@ func (this *LedgerStoreImp) verifyHeader(header *types.Header, vbftPeerInfo map[
 	if prevHeader == nil {
 		return vbftPeerInfo, fmt.Errorf("cannot find pre header by blockHash %s", prevHeaderHash.ToHexString())
 	}
+	return verifyHeaderWithPrev(prevHeader, header, vbftPeerInfo)
+}
 
+//verifyHeaderWithPrev check header links to prevHeader and is signed by the bookkeepers of prevHeader,
+//return the vbft peers for verifying next header
+func verifyHeaderWithPrev(prevHeader, header *types.Header, vbftPeerInfo map[string]uint32) (map[string]uint32, error) {
 	if prevHeader.Height+1 != header.Height {
 		return vbftPeerInfo, fmt.Errorf("block height is incorrect")
 	}
@@ -454,7 +507,7 @@ func (this *LedgerStoreImp) verifyHeader(header *types.Header, vbftPeerInfo map[
 			}
 		}
 		hash := header.Hash()
-		err = signature.VerifyMultiSignature(hash[:], header.Bookkeepers, m, header.SigData)
+		err := signature.VerifyMultiSignature(hash[:], header.Bookkeepers, m, header.SigData)
 		if err != nil {
 			log.Errorf("VerifyMultiSignature:%s,Bookkeepers:%d,pubkey:%d,heigh:%d", err, len(header.Bookkeepers), len(vbftPeerInfo), header.Height)
 			return vbftPeerInfo, err
@@ -635,16 +688,36 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 		}
 	}
 
-	cache := storage.NewCacheDB(overlay)
-	for _, tx := range block.Transactions {
-		cache.Reset()
-		notify, e := this.handleTransaction(overlay, cache, block, tx)
-		if e != nil {
-			err = e
+	workers := int(config.DefConfig.Common.ExecWorkers)
+	if workers > 1 && len(block.Transactions) > 1 {
+		result.Notify, err = this.executeTxsParallel(overlay, block, workers)
+		if err != nil {
 			return
 		}
+	} else {
+		cache := storage.NewCacheDB(overlay)
+		for _, tx := range block.Transactions {
+			cache.Reset()
+			notify, e := this.handleTransaction(overlay, cache, block, tx)
+			if e != nil {
+				err = e
+				return
+			}
 
-		result.Notify = append(result.Notify, notify)
+			result.Notify = append(result.Notify, notify)
+		}
+	}
+
+	if block.Header.Height != 0 && proposal.Enabled(block.Header.Height) {
+		config := &smartcontract.Config{
+			Time:      block.Header.Timestamp,
+			Height:    block.Header.Height,
+			Tx:        &types.Transaction{},
+			BlockHash: block.Hash(),
+		}
+		if err = executeDueProposals(config, overlay, this); err != nil {
+			return
+		}
 	}
 
 	result.Hash = overlay.ChangeHash()
@@ -722,6 +795,16 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 
 	log.Debugf("the state transition hash of block %d is:%s", blockHeight, result.Hash.ToHexString())
 
+	if keep := config.DefConfig.Common.TraceHistoryBlocks; keep != 0 {
+		err = this.stateStore.SaveStateUndo(blockHeight, result.WriteSet)
+		if err != nil {
+			return fmt.Errorf("SaveStateUndo error %s", err)
+		}
+		if blockHeight >= keep {
+			this.stateStore.DeleteStateUndo(blockHeight - keep)
+		}
+	}
+
 	result.WriteSet.ForEach(func(key, val []byte) {
 		if len(val) == 0 {
 			this.stateStore.BatchDeleteRawKey(key)
@@ -815,6 +898,8 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 		return fmt.Errorf("stateStore.CommitTo height:%d error %s", blockHeight, err)
 	}
 	this.setCurrentBlock(blockHeight, blockHash)
+	this.trySnapshot(blockHeight)
+	this.tryPrune(blockHeight)
 
 	if events.DefActorPublisher != nil {
 		events.DefActorPublisher.Publish(
@@ -1008,16 +1093,42 @@ func (this *LedgerStoreImp) GetStorageItem(key *states.StorageKey) (*states.Stor
 
 //GetEventNotifyByTx return the events notify gen by executing of smart contract.  Wrap function of EventStore.GetEventNotifyByTx
 func (this *LedgerStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
-	return this.eventStore.GetEventNotifyByTx(tx)
+	notify, err := this.eventStore.GetEventNotifyByTx(tx)
+	if err == scom.ErrNotFound {
+		if _, _, e := this.blockStore.GetTransaction(tx); e == scom.ErrPruned {
+			return nil, scom.ErrPruned
+		}
+	}
+	return notify, err
 }
 
 //GetEventNotifyByBlock return the transaction hash which have event notice after execution of smart contract. Wrap function of EventStore.GetEventNotifyByBlock
 func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error) {
+	if this.blockStore.IsPruned(height) {
+		return nil, scom.ErrPruned
+	}
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
 //PreExecuteContract return the result of smart contract execution without commit to store
 func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.PreExecResult, error) {
+	return this.preExecuteContract(tx, nil, nil)
+}
+
+//PreExecuteContractWithOverride return the result of smart contract execution against the current states replaced by override
+func (this *LedgerStoreImp) PreExecuteContractWithOverride(tx *types.Transaction, override *sstate.StateOverride) (*sstate.PreExecResult, error) {
+	return this.preExecuteContract(tx, override, nil)
+}
+
+//PreExecuteContractWithTracer return the result of smart contract execution, and notify the execution steps to tracer
+func (this *LedgerStoreImp) PreExecuteContractWithTracer(tx *types.Transaction, tracer context.Tracer) (*sstate.PreExecResult, error) {
+	return this.preExecuteContract(tx, nil, tracer)
+}
+
+//preExecuteContract pre-execute the transaction with the states replaced by override, and notify the execution steps
+//to tracer if not nil
+func (this *LedgerStoreImp) preExecuteContract(tx *types.Transaction, override *sstate.StateOverride,
+	tracer context.Tracer) (*sstate.PreExecResult, error) {
 	height := this.GetCurrentBlockHeight()
 	stf := &sstate.PreExecResult{State: event.CONTRACT_STATE_FAIL, Gas: neovm.MIN_TRANSACTION_GAS, Result: nil}
 
@@ -1030,6 +1141,11 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 
 	overlay := this.stateStore.NewOverlayDB()
 	cache := storage.NewCacheDB(overlay)
+	if override != nil {
+		if err := applyStateOverride(config, cache, override); err != nil {
+			return stf, err
+		}
+	}
 	preGas, err := this.getPreGas(config, cache)
 	if err != nil {
 		return stf, err
@@ -1045,6 +1161,10 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 			Gas:     math.MaxUint64 - calcGasByCodeLen(len(invoke.Code), preGas[neovm.UINT_INVOKE_CODE_LEN_NAME]),
 			PreExec: true,
 		}
+		if tracer != nil {
+			sc.Tracer = tracer
+			cache.SetTracer(tracer)
+		}
 
 		//start the smart contract executive function
 		engine, _ := sc.NewExecuteEngine(invoke.Code)
@@ -1071,8 +1191,25 @@ func (this *LedgerStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.P
 }
 
 func (this *LedgerStoreImp) getPreGas(config *smartcontract.Config, cache *storage.CacheDB) (map[string]uint64, error) {
-	bf := new(bytes.Buffer)
 	names := []string{neovm.CONTRACT_CREATE_NAME, neovm.UINT_INVOKE_CODE_LEN_NAME, neovm.UINT_DEPLOY_CODE_LEN_NAME}
+	m, err := this.getGlobalParams(config, cache, names)
+	if err != nil {
+		return nil, err
+	}
+	//the param not set in global params is charged with the value of gas table by tx handler
+	for _, v := range names {
+		if _, ok := m[v]; ok {
+			continue
+		}
+		if gas, ok := neovm.GAS_TABLE.Load(v); ok {
+			m[v] = gas.(uint64)
+		}
+	}
+	return m, nil
+}
+
+func (this *LedgerStoreImp) getGlobalParams(config *smartcontract.Config, cache *storage.CacheDB, names []string) (map[string]uint64, error) {
+	bf := new(bytes.Buffer)
 	if err := utils.WriteVarUint(bf, uint64(len(names))); err != nil {
 		return nil, fmt.Errorf("write gas_table_keys length error:%s", err)
 	}
@@ -1120,6 +1257,8 @@ func (this *LedgerStoreImp) Close() error {
 	defer this.releaseSavingBlockLock()
 
 	this.closing = true
+	this.closeSnapshot()
+	this.closePrune()
 
 	err := this.blockStore.Close()
 	if err != nil {
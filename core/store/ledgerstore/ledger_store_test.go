@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/core/genesis"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/signature"
+	"github.com/dnaproject2/DNA/core/states"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/memorystore"
+	"github.com/dnaproject2/DNA/core/types"
+	cutils "github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func setSoloGenesis(acc *account.Account) func() {
+	genesisConfig, networkId := config.DefConfig.Genesis, config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ConsensusType: config.CONSENSUS_TYPE_SOLO,
+		SOLO: &config.SOLOConfig{
+			Bookkeepers: []string{hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey))},
+		},
+	}
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	return func() {
+		config.DefConfig.Genesis, config.DefConfig.P2PNode.NetworkId = genesisConfig, networkId
+	}
+}
+
+func newSoloLedger(t *testing.T, dir string, acc *account.Account) (*LedgerStoreImp, *types.Block) {
+	store, err := NewLedgerStore(dir, 0)
+	assert.Nil(t, err)
+	bookkeepers := []keypair.PublicKey{acc.PublicKey}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	err = store.InitLedgerStoreWithGenesisBlock(genesisBlock, bookkeepers)
+	assert.Nil(t, err)
+	return store, genesisBlock
+}
+
+func makeSoloBlock(t *testing.T, store *LedgerStoreImp, acc *account.Account, txs ...*types.Transaction) *types.Block {
+	nextBookkeeper, err := types.AddressFromBookkeepers([]keypair.PublicKey{acc.PublicKey})
+	assert.Nil(t, err)
+	height := store.GetCurrentBlockHeight()
+	prevHeader, err := store.GetHeaderByHeight(height)
+	assert.Nil(t, err)
+	txHashes := make([]common.Uint256, 0, len(txs))
+	for _, tx := range txs {
+		txHashes = append(txHashes, tx.Hash())
+	}
+	txRoot := common.ComputeMerkleRoot(txHashes)
+	header := &types.Header{
+		PrevBlockHash:    store.GetCurrentBlockHash(),
+		TransactionsRoot: txRoot,
+		BlockRoot:        store.GetBlockRootWithNewTxRoots(height+1, []common.Uint256{txRoot}),
+		Timestamp:        prevHeader.Timestamp + 1,
+		Height:           height + 1,
+		ConsensusData:    common.GetNonce(),
+		NextBookkeeper:   nextBookkeeper,
+	}
+	block := &types.Block{Header: header, Transactions: append([]*types.Transaction{}, txs...)}
+	hash := block.Hash()
+	sig, err := signature.Sign(acc, hash[:])
+	assert.Nil(t, err)
+	header.Bookkeepers = []keypair.PublicKey{acc.PublicKey}
+	header.SigData = [][]byte{sig}
+	return block
+}
+
+func addBlock(t *testing.T, store *LedgerStoreImp, block *types.Block) common.Uint256 {
+	result, err := store.ExecuteBlock(block)
+	assert.Nil(t, err)
+	err = store.SubmitBlock(block, result)
+	assert.Nil(t, err)
+	return result.MerkleRoot
+}
+
+func newSignedTx(t *testing.T, nonce uint32, txType types.TransactionType, pl types.Payload, signer *account.Account) *types.Transaction {
+	return newSignedTxWithGas(t, nonce, txType, pl, signer, 100000)
+}
+
+func newSignedTxWithGas(t *testing.T, nonce uint32, txType types.TransactionType, pl types.Payload,
+	signer *account.Account, gasLimit uint64) *types.Transaction {
+	mutable := &types.MutableTransaction{
+		TxType:   txType,
+		Nonce:    nonce,
+		GasLimit: gasLimit,
+		Payer:    signer.Address,
+		Payload:  pl,
+	}
+	hash := mutable.Hash()
+	sig, err := signature.Sign(signer, hash[:])
+	assert.Nil(t, err)
+	mutable.Sigs = []types.Sig{{PubKeys: []keypair.PublicKey{signer.PublicKey}, M: 1, SigData: [][]byte{sig}}}
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	return tx
+}
+
+//counterCode increase the counter of the key in arguments
+func counterCode() []byte {
+	sink := common.NewZeroCopySink(nil)
+	syscall := func(name string) {
+		sink.WriteByte(byte(neovm.SYSCALL))
+		sink.WriteVarBytes([]byte(name))
+	}
+	sink.WriteByte(byte(neovm.DUP))
+	syscall("System.Storage.GetContext")
+	syscall("System.Storage.Get")
+	sink.WriteByte(byte(neovm.INC))
+	sink.WriteByte(byte(neovm.SWAP))
+	syscall("System.Storage.GetContext")
+	syscall("System.Storage.Put")
+	sink.WriteByte(byte(neovm.RET))
+	return sink.Bytes()
+}
+
+func newTx(t *testing.T, nonce uint32, txType types.TransactionType, pl types.Payload) *types.Transaction {
+	mutable := &types.MutableTransaction{
+		TxType:   txType,
+		Nonce:    nonce,
+		GasLimit: 100000,
+		Payload:  pl,
+	}
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	return tx
+}
+
+func newIncreaseTx(t *testing.T, nonce uint32, contract common.Address, key string) *types.Transaction {
+	builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+	builder.EmitPushByteArray([]byte(key))
+	builder.EmitPushCall(contract[:])
+	return newTx(t, nonce, types.Invoke, &payload.InvokeCode{Code: builder.ToArray()})
+}
+
+func newNativeTx(t *testing.T, nonce uint32, contract common.Address, method string, params []interface{},
+	signer *account.Account) *types.Transaction {
+	code, err := cutils.BuildNativeInvokeCode(contract, 0, method, params)
+	assert.Nil(t, err)
+	return newSignedTx(t, nonce, types.Invoke, &payload.InvokeCode{Code: code}, signer)
+}
+
+func newInvokeTx(t *testing.T, nonce uint32) *types.Transaction {
+	mutable := &types.MutableTransaction{
+		TxType:  types.Invoke,
+		Nonce:   nonce,
+		Payload: &payload.InvokeCode{Code: []byte{0x51}},
+	}
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	return tx
+}
+
+func newOntIDTx(t *testing.T, nonce uint32, method string, param interface{}, signer *account.Account) *types.Transaction {
+	code, err := cutils.BuildNativeInvokeCode(utils.OntIDContractAddress, 0, method, []interface{}{param})
+	assert.Nil(t, err)
+	return newSignedTx(t, nonce, types.Invoke, &payload.InvokeCode{Code: code}, signer)
+}
+
+func getBalance(t *testing.T, store *LedgerStoreImp, asset, addr common.Address) uint64 {
+	item, err := store.GetStorageItem(&states.StorageKey{ContractAddress: asset, Key: addr[:]})
+	if err != nil {
+		return 0
+	}
+	value, err := serialization.ReadUint64(bytes.NewBuffer(item.Value))
+	assert.Nil(t, err)
+	return value
+}
+
+func TestLedgerStoreMemoryBackend(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+	backend := config.DefConfig.Common.StorageBackend
+	config.DefConfig.Common.StorageBackend = memorystore.BACKEND_NAME
+	defer func() { config.DefConfig.Common.StorageBackend = backend }()
+
+	dir, err := ioutil.TempDir("", "ledger")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+	tx := newInvokeTx(t, 0)
+	block := makeSoloBlock(t, store, acc, tx)
+	addBlock(t, store, block)
+	assert.Equal(t, uint32(1), store.GetCurrentBlockHeight())
+	saved, err := store.GetBlockByHeight(1)
+	assert.Nil(t, err)
+	assert.Equal(t, block.Hash(), saved.Hash())
+	_, height, err := store.GetTransaction(tx.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(1), height)
+	_, err = os.Stat(filepath.Join(dir, "ledger", DBDirBlock))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRecordDeployer(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+	config.CONTRACT_REGISTRY_HEIGHT[config.NETWORK_ID_SOLO_NET] = 2
+	defer func() { config.CONTRACT_REGISTRY_HEIGHT[config.NETWORK_ID_SOLO_NET] = 0 }()
+
+	dir, err := ioutil.TempDir("", "registry")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	deployer := account.NewAccount("")
+	early := &payload.DeployCode{Code: []byte{byte(neovm.PUSH1)}}
+	late := &payload.DeployCode{Code: []byte{byte(neovm.PUSH2)}}
+	addBlock(t, store, makeSoloBlock(t, store, acc, newSignedTx(t, 0, types.Deploy, early, deployer)))
+	addBlock(t, store, makeSoloBlock(t, store, acc, newSignedTx(t, 1, types.Deploy, late, deployer)))
+
+	//only the deployment from the activation height is recorded
+	getDeployer := func(contract common.Address) ([]byte, error) {
+		item, err := store.GetStorageItem(&states.StorageKey{
+			ContractAddress: utils.RegistryContractAddress,
+			Key:             registry.DeployerKey(contract)[common.ADDR_LEN:],
+		})
+		if err != nil {
+			return nil, err
+		}
+		return item.Value, nil
+	}
+	_, err = getDeployer(early.Address())
+	assert.Equal(t, scom.ErrNotFound, err)
+	value, err := getDeployer(late.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, deployer.Address[:], value)
+}
@@ -0,0 +1,458 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/core/store"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/errors"
+	"github.com/dnaproject2/DNA/merkle"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+var (
+	//Storage save path of light store
+	DBDirLight = "light"
+	//ErrLightStore is returned for the data light store does not keep
+	ErrLightStore = errors.NewErr("not supported by light store")
+)
+
+//LightStoreImp is the header only store of light node. Every header is verified by the bookkeepers
+//of its previous header, and the block root of header is checked against the local block merkle tree.
+//Blocks, transactions and states are not stored, which should be fetched from full node with proof.
+type LightStoreImp struct {
+	blockStore       *BlockStore               //BlockStore for saving headers
+	storedIndexCount uint32                    //record the count of have saved header index
+	currHeaderHeight uint32                    //Current header height
+	currHeaderHash   common.Uint256            //Current header hash
+	headerIndex      map[uint32]common.Uint256 //Header index, Mapping header height => block hash
+	merkleTree       *merkle.CompactMerkleTree //Merkle tree of block root
+	vbftPeerInfo     map[string]uint32         //pubInfo save pubkey,peerindex
+	lock             sync.RWMutex
+	addHeaderLock    sync.Mutex //Avoid adding headers concurrently
+}
+
+//NewLightStore return LightStoreImp instance
+func NewLightStore(dataDir string) (*LightStoreImp, error) {
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirLight), false)
+	if err != nil {
+		return nil, fmt.Errorf("NewBlockStore error %s", err)
+	}
+	return &LightStoreImp{
+		blockStore:   blockStore,
+		headerIndex:  make(map[uint32]common.Uint256),
+		merkleTree:   merkle.NewTree(0, nil, nil),
+		vbftPeerInfo: make(map[string]uint32),
+	}, nil
+}
+
+//InitLedgerStoreWithGenesisBlock init the light store with genesis header. It's the first operation after NewLightStore.
+func (this *LightStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.Block, defaultBookkeeper []keypair.PublicKey) error {
+	version, err := this.blockStore.GetVersion()
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("GetVersion error %s", err)
+	}
+	if version != SYSTEM_VERSION {
+		err = this.blockStore.ClearAll()
+		if err != nil {
+			return fmt.Errorf("blockStore.ClearAll error %s", err)
+		}
+		this.blockStore.NewBatch()
+		err = this.saveHeader(genesisBlock.Header)
+		if err != nil {
+			return fmt.Errorf("save genesis header error %s", err)
+		}
+		err = this.blockStore.CommitTo()
+		if err != nil {
+			return fmt.Errorf("blockStore.CommitTo error %s", err)
+		}
+		err = this.blockStore.SaveVersion(SYSTEM_VERSION)
+		if err != nil {
+			return fmt.Errorf("SaveVersion error %s", err)
+		}
+		genHash := genesisBlock.Hash()
+		log.Infof("GenesisBlock init success. GenesisBlock hash:%s\n", genHash.ToHexString())
+	} else {
+		err = this.init()
+		if err != nil {
+			return fmt.Errorf("init error %s", err)
+		}
+		if this.GetBlockHash(0) != genesisBlock.Hash() {
+			return fmt.Errorf("GenesisBlock arenot init correctly")
+		}
+	}
+	return this.loadVbftPeerInfo()
+}
+
+func (this *LightStoreImp) init() error {
+	currHeaderHash, currHeaderHeight, err := this.blockStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("LoadCurrentBlock error %s", err)
+	}
+	headerIndex, err := this.blockStore.GetHeaderIndexList()
+	if err != nil {
+		return fmt.Errorf("LoadHeaderIndexList error %s", err)
+	}
+	storeIndexCount := uint32(len(headerIndex))
+	for height := storeIndexCount; height <= currHeaderHeight; height++ {
+		blockHash, err := this.blockStore.GetBlockHash(height)
+		if err != nil {
+			return fmt.Errorf("LoadBlockHash height %d error %s", height, err)
+		}
+		if blockHash == common.UINT256_EMPTY {
+			return fmt.Errorf("LoadBlockHash height %d hash nil", height)
+		}
+		headerIndex[height] = blockHash
+	}
+	treeSize, hashes, err := this.getMerkleTree()
+	if err != nil {
+		return fmt.Errorf("LoadMerkleTree error %s", err)
+	}
+	if treeSize != currHeaderHeight+1 {
+		return fmt.Errorf("merkle tree size is inconsistent with header height: %d", currHeaderHeight+1)
+	}
+	log.Infof("InitCurrentHeader currentHeaderHash %s currentHeaderHeight %d", currHeaderHash.ToHexString(), currHeaderHeight)
+	this.lock.Lock()
+	this.currHeaderHash = currHeaderHash
+	this.currHeaderHeight = currHeaderHeight
+	this.headerIndex = headerIndex
+	this.storedIndexCount = storeIndexCount
+	this.merkleTree = merkle.NewTree(treeSize, hashes, nil)
+	this.lock.Unlock()
+	return nil
+}
+
+//loadVbftPeerInfo load vbft peerInfo from the chain config of current header
+func (this *LightStoreImp) loadVbftPeerInfo() error {
+	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
+	if consensusType != "vbft" {
+		return nil
+	}
+	header, err := this.GetHeaderByHash(this.GetCurrentHeaderHash())
+	if err != nil {
+		return err
+	}
+	peerInfo, err := getVbftPeerInfo(header, this.GetHeaderByHeight)
+	if err != nil {
+		return err
+	}
+	this.lock.Lock()
+	this.vbftPeerInfo = peerInfo
+	this.lock.Unlock()
+	return nil
+}
+
+func (this *LightStoreImp) getMerkleTree() (uint32, []common.Uint256, error) {
+	data, err := this.blockStore.store.Get(this.blockStore.getBlockMerkleTreeKey())
+	if err != nil {
+		return 0, nil, err
+	}
+	source := common.NewZeroCopySource(data)
+	treeSize, eof := source.NextUint32()
+	if eof {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	hashes := make([]common.Uint256, 0, source.Len()/common.UINT256_SIZE)
+	for source.Len() > 0 {
+		hash, eof := source.NextHash()
+		if eof {
+			return 0, nil, io.ErrUnexpectedEOF
+		}
+		hashes = append(hashes, hash)
+	}
+	return treeSize, hashes, nil
+}
+
+//saveHeader append header to the batch of block store, and move the current header to it
+func (this *LightStoreImp) saveHeader(header *types.Header) error {
+	blockHash := header.Hash()
+	height := header.Height
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.merkleTree.AppendHash(header.TransactionsRoot)
+	this.headerIndex[height] = blockHash
+	this.currHeaderHeight = height
+	this.currHeaderHash = blockHash
+
+	if height-this.storedIndexCount >= HEADER_INDEX_BATCH_SIZE {
+		headerList := make([]common.Uint256, HEADER_INDEX_BATCH_SIZE)
+		for i := uint32(0); i < HEADER_INDEX_BATCH_SIZE; i++ {
+			headerList[i] = this.headerIndex[this.storedIndexCount+i]
+		}
+		err := this.blockStore.SaveHeaderIndexList(this.storedIndexCount, headerList)
+		if err != nil {
+			return fmt.Errorf("SaveHeaderIndexList start %d error %s", this.storedIndexCount, err)
+		}
+		this.storedIndexCount += HEADER_INDEX_BATCH_SIZE
+	}
+	err := this.blockStore.SaveHeader(&types.Block{Header: header}, 0)
+	if err != nil {
+		return fmt.Errorf("SaveHeader height %d hash %s error %s", height, blockHash.ToHexString(), err)
+	}
+	this.blockStore.SaveBlockHash(height, blockHash)
+	err = this.blockStore.SaveCurrentBlock(height, blockHash)
+	if err != nil {
+		return fmt.Errorf("SaveCurrentBlock error %s", err)
+	}
+	treeSize := this.merkleTree.TreeSize()
+	hashes := this.merkleTree.Hashes()
+	value := common.NewZeroCopySink(make([]byte, 0, 4+len(hashes)*common.UINT256_SIZE))
+	value.WriteUint32(treeSize)
+	for _, hash := range hashes {
+		value.WriteHash(hash)
+	}
+	this.blockStore.store.BatchPut(this.blockStore.getBlockMerkleTreeKey(), value.Bytes())
+	return nil
+}
+
+//AddHeaders verify and persist the headers, which should follow the current header
+func (this *LightStoreImp) AddHeaders(headers []*types.Header) error {
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Height < headers[j].Height
+	})
+	this.addHeaderLock.Lock()
+	defer this.addHeaderLock.Unlock()
+	for _, header := range headers {
+		err := this.addHeader(header)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *LightStoreImp) addHeader(header *types.Header) error {
+	prevHeader, err := this.GetHeaderByHash(this.GetCurrentHeaderHash())
+	if err != nil {
+		return fmt.Errorf("get prev header error %s", err)
+	}
+	if header.PrevBlockHash != prevHeader.Hash() {
+		return fmt.Errorf("header height %d does not follow current header height %d", header.Height, prevHeader.Height)
+	}
+	this.vbftPeerInfo, err = verifyHeaderWithPrev(prevHeader, header, this.vbftPeerInfo)
+	if err != nil {
+		return fmt.Errorf("verifyHeader error %s", err)
+	}
+	blockRoot := this.GetBlockRootWithNewTxRoots(header.Height, []common.Uint256{header.TransactionsRoot})
+	if blockRoot != header.BlockRoot {
+		return fmt.Errorf("wrong block root at height:%d, expected:%s, got:%s",
+			header.Height, blockRoot.ToHexString(), header.BlockRoot.ToHexString())
+	}
+
+	this.blockStore.NewBatch()
+	err = this.saveHeader(header)
+	if err != nil {
+		return err
+	}
+	return this.blockStore.CommitTo()
+}
+
+//AddBlock only keeps the header of block. Using for the new blocks broadcast by full node.
+func (this *LightStoreImp) AddBlock(block *types.Block, stateMerkleRoot common.Uint256) error {
+	if block.Header.Height <= this.GetCurrentHeaderHeight() {
+		return nil
+	}
+	return this.AddHeaders([]*types.Header{block.Header})
+}
+
+func (this *LightStoreImp) ExecuteBlock(block *types.Block) (store.ExecuteResult, error) {
+	return store.ExecuteResult{}, ErrLightStore
+}
+
+func (this *LightStoreImp) SubmitBlock(block *types.Block, result store.ExecuteResult) error {
+	return ErrLightStore
+}
+
+func (this *LightStoreImp) GetStateMerkleRoot(height uint32) (common.Uint256, error) {
+	return common.UINT256_EMPTY, ErrLightStore
+}
+
+//GetCurrentBlockHash return the current header hash, which is the latest trusted block of light node
+func (this *LightStoreImp) GetCurrentBlockHash() common.Uint256 {
+	return this.GetCurrentHeaderHash()
+}
+
+//GetCurrentBlockHeight return the current header height, which is the latest trusted block of light node
+func (this *LightStoreImp) GetCurrentBlockHeight() uint32 {
+	return this.GetCurrentHeaderHeight()
+}
+
+//GetCurrentHeaderHeight return the current header height
+func (this *LightStoreImp) GetCurrentHeaderHeight() uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.currHeaderHeight
+}
+
+//GetCurrentHeaderHash return the current header hash
+func (this *LightStoreImp) GetCurrentHeaderHash() common.Uint256 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.currHeaderHash
+}
+
+//GetBlockHash return the block hash by block height
+func (this *LightStoreImp) GetBlockHash(height uint32) common.Uint256 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.headerIndex[height]
+}
+
+//GetHeaderByHash return the block header by block hash
+func (this *LightStoreImp) GetHeaderByHash(blockHash common.Uint256) (*types.Header, error) {
+	return this.blockStore.GetHeader(blockHash)
+}
+
+func (this *LightStoreImp) GetRawHeaderByHash(blockHash common.Uint256) (*types.RawHeader, error) {
+	return this.blockStore.GetRawHeader(blockHash)
+}
+
+//GetHeaderByHeight return the block header by block height
+func (this *LightStoreImp) GetHeaderByHeight(height uint32) (*types.Header, error) {
+	blockHash := this.GetBlockHash(height)
+	if blockHash == common.UINT256_EMPTY {
+		return nil, nil
+	}
+	return this.GetHeaderByHash(blockHash)
+}
+
+func (this *LightStoreImp) GetBlockByHash(blockHash common.Uint256) (*types.Block, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetBlockByHeight(height uint32) (*types.Block, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetTransaction(txHash common.Uint256) (*types.Transaction, uint32, error) {
+	return nil, 0, ErrLightStore
+}
+
+//IsContainBlock return whether the header of block is in store
+func (this *LightStoreImp) IsContainBlock(blockHash common.Uint256) (bool, error) {
+	return this.blockStore.ContainBlock(blockHash)
+}
+
+func (this *LightStoreImp) IsContainTransaction(txHash common.Uint256) (bool, error) {
+	return false, ErrLightStore
+}
+
+//GetBlockRootWithNewTxRoots return the block root(merkle root of blocks) after add a new tx root of block
+func (this *LightStoreImp) GetBlockRootWithNewTxRoots(startHeight uint32, txRoots []common.Uint256) common.Uint256 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.merkleTree.TreeSize() > startHeight+uint32(len(txRoots))-1 {
+		return common.UINT256_EMPTY
+	}
+	needs := txRoots[this.merkleTree.TreeSize()-startHeight:]
+	return this.merkleTree.GetRootWithNewLeaves(needs)
+}
+
+func (this *LightStoreImp) GetMerkleProof(proofHeight, rootHeight uint32) ([]common.Uint256, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetContractState(contractHash common.Address) (*payload.DeployCode, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetBookkeeperState() (*states.BookkeeperState, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetStorageItem(key *states.StorageKey) (*states.StorageItem, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) PreExecuteContract(tx *types.Transaction) (*sstate.PreExecResult, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) PreExecuteContractWithOverride(tx *types.Transaction, override *sstate.StateOverride) (*sstate.PreExecResult, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) TraceTransaction(txHash common.Uint256) (*trace.TxTrace, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) TracePreExecute(tx *types.Transaction) (*trace.TxTrace, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) ProfilePreExecute(tx *types.Transaction) (*profile.GasProfile, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) PreExecuteContractWithTracer(tx *types.Transaction, tracer context.Tracer) (*sstate.PreExecResult, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) EstimateGas(tx *types.Transaction) (*sstate.GasEstimate, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) ExportSnapshot(path string) (*snapshot.Manifest, error) {
+	return nil, ErrLightStore
+}
+
+func (this *LightStoreImp) ImportSnapshot(path string, stateDigest common.Uint256) error {
+	return ErrLightStore
+}
+
+func (this *LightStoreImp) GetSnapshotManifest() *snapshot.Manifest {
+	return nil
+}
+
+func (this *LightStoreImp) GetSnapshotChunk(height, index uint32) ([]byte, error) {
+	return nil, ErrLightStore
+}
+
+//Close light store
+func (this *LightStoreImp) Close() error {
+	return this.blockStore.Close()
+}
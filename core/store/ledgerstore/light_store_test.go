@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLightStore(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "light")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	full, genesisBlock := newSoloLedger(t, filepath.Join(dir, "full"), acc)
+	defer full.Close()
+	var headers []*types.Header
+	for i := 0; i < 5; i++ {
+		block := makeSoloBlock(t, full, acc)
+		addBlock(t, full, block)
+		headers = append(headers, block.Header)
+	}
+
+	light, err := NewLightStore(filepath.Join(dir, "light"))
+	assert.Nil(t, err)
+	err = light.InitLedgerStoreWithGenesisBlock(genesisBlock, []keypair.PublicKey{acc.PublicKey})
+	assert.Nil(t, err)
+	assert.Equal(t, genesisBlock.Hash(), light.GetCurrentBlockHash())
+
+	//header signed by other bookkeeper
+	other := account.NewAccount("")
+	forged := *headers[0]
+	forged.Bookkeepers = []keypair.PublicKey{other.PublicKey}
+	err = light.AddHeaders([]*types.Header{&forged})
+	assert.NotNil(t, err)
+	assert.Equal(t, uint32(0), light.GetCurrentHeaderHeight())
+
+	//header not following current header
+	err = light.AddHeaders(headers[1:2])
+	assert.NotNil(t, err)
+
+	err = light.AddHeaders(headers[:4])
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4), light.GetCurrentBlockHeight())
+	assert.Equal(t, full.GetBlockHash(4), light.GetCurrentBlockHash())
+	_, err = light.GetBlockByHeight(4)
+	assert.Equal(t, ErrLightStore, err)
+
+	block, err := full.GetBlockByHeight(5)
+	assert.Nil(t, err)
+	err = light.AddBlock(block, full.GetCurrentBlockHash())
+	assert.Nil(t, err)
+	assert.Equal(t, full.GetCurrentBlockHash(), light.GetCurrentHeaderHash())
+	assert.Equal(t, full.GetBlockRootWithNewTxRoots(6, []common.Uint256{{1}}),
+		light.GetBlockRootWithNewTxRoots(6, []common.Uint256{{1}}))
+	light.Close()
+
+	//reload from disk
+	light, err = NewLightStore(filepath.Join(dir, "light"))
+	assert.Nil(t, err)
+	defer light.Close()
+	err = light.InitLedgerStoreWithGenesisBlock(genesisBlock, []keypair.PublicKey{acc.PublicKey})
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(5), light.GetCurrentHeaderHeight())
+	header, err := light.GetHeaderByHeight(3)
+	assert.Nil(t, err)
+	assert.Equal(t, full.GetBlockHash(3), header.Hash())
+	block = makeSoloBlock(t, full, acc)
+	err = light.AddHeaders([]*types.Header{block.Header})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/core/states"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+)
+
+var errReadOnlyStore = errors.New("read only store")
+
+//feeKey is the key of ONG balance of governance contract, which receives the gas fee of every transaction
+var feeKey = append([]byte{byte(scom.ST_STORAGE)}, ont.GenBalanceKey(utils.OngContractAddress,
+	utils.GovernanceContractAddress)...)
+
+//txExecution is the execution result of a transaction in its own overlay
+type txExecution struct {
+	notify  *event.ExecuteNotify
+	overlay *overlaydb.OverlayDB
+	reads   *readRecorder
+	err     error
+}
+
+//readRecorder is the view of states at the beginning of block, and records the keys and prefixes read by a transaction.
+//The writes of transaction are kept in its overlay, so the store is never written. The fee key read while charging fee
+//is not recorded, the fee is applied in block order by mergeFee instead
+type readRecorder struct {
+	scom.PersistStore
+	keys        map[string]struct{}
+	prefixes    []string
+	chargingFee bool
+}
+
+func newReadRecorder(store scom.PersistStore) *readRecorder {
+	return &readRecorder{
+		PersistStore: store,
+		keys:         make(map[string]struct{}),
+	}
+}
+
+func (this *readRecorder) Get(key []byte) ([]byte, error) {
+	this.record(key)
+	return this.PersistStore.Get(key)
+}
+
+func (this *readRecorder) Has(key []byte) (bool, error) {
+	this.record(key)
+	return this.PersistStore.Has(key)
+}
+
+func (this *readRecorder) record(key []byte) {
+	if this.chargingFee && bytes.Equal(key, feeKey) {
+		return
+	}
+	this.keys[string(key)] = struct{}{}
+}
+
+func (this *readRecorder) NewIterator(prefix []byte) scom.StoreIterator {
+	this.prefixes = append(this.prefixes, string(prefix))
+	return this.PersistStore.NewIterator(prefix)
+}
+
+//conflict return whether the transaction read any key written by previous transactions of block
+func (this *readRecorder) conflict(written map[string]struct{}) bool {
+	for key := range this.keys {
+		if _, ok := written[key]; ok {
+			return true
+		}
+	}
+	if len(this.prefixes) == 0 {
+		return false
+	}
+	for key := range written {
+		for _, prefix := range this.prefixes {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//overlayStore is the read only PersistStore view of block overlay, used to re-execute conflicting transaction
+type overlayStore struct {
+	overlay *overlaydb.OverlayDB
+}
+
+func (this *overlayStore) Get(key []byte) ([]byte, error) {
+	value, err := this.overlay.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, scom.ErrNotFound
+	}
+	return value, nil
+}
+
+func (this *overlayStore) Has(key []byte) (bool, error) {
+	value, err := this.overlay.Get(key)
+	if err != nil {
+		return false, err
+	}
+	return len(value) != 0, nil
+}
+
+func (this *overlayStore) NewIterator(prefix []byte) scom.StoreIterator {
+	return this.overlay.NewIterator(prefix)
+}
+
+func (this *overlayStore) Put(key []byte, value []byte) error {
+	return errReadOnlyStore
+}
+
+func (this *overlayStore) Delete(key []byte) error {
+	return errReadOnlyStore
+}
+
+func (this *overlayStore) NewBatch() {}
+
+func (this *overlayStore) BatchPut(key []byte, value []byte) {}
+
+func (this *overlayStore) BatchDelete(key []byte) {}
+
+func (this *overlayStore) BatchCommit() error {
+	return errReadOnlyStore
+}
+
+func (this *overlayStore) Close() error {
+	return nil
+}
+
+//executeTx execute the transaction in a new overlay on backend
+func (this *LedgerStoreImp) executeTx(backend scom.PersistStore, block *types.Block, tx *types.Transaction) (*event.ExecuteNotify, *overlaydb.OverlayDB, error) {
+	overlay := overlaydb.NewOverlayDB(backend)
+	cache := storage.NewCacheDB(overlay)
+	notify, err := this.handleTransaction(overlay, cache, block, tx)
+	return notify, overlay, err
+}
+
+//decodeBalance decode the ONG balance in raw storage item, empty value is zero balance
+func decodeBalance(raw []byte) (uint64, error) {
+	if len(raw) == 0 {
+		return 0, nil
+	}
+	value, err := states.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint64(bytes.NewReader(value))
+}
+
+//mergeFee return the fee key value after applying the balance change of speculatively executed transaction, which
+//changed the balance from base at the beginning of block to val, on the current value of block overlay
+func mergeFee(overlay *overlaydb.OverlayDB, base, val []byte) ([]byte, error) {
+	current, err := overlay.Get(feeKey)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(current, base) {
+		return val, nil
+	}
+	balances := make([]uint64, 0, 3)
+	for _, raw := range [][]byte{current, base, val} {
+		balance, err := decodeBalance(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode fee balance error %s", err)
+		}
+		balances = append(balances, balance)
+	}
+	balance := balances[0] - balances[1] + balances[2]
+	if balance == 0 {
+		return nil, nil
+	}
+	return utils.GenUInt64StorageItem(balance).ToArray(), nil
+}
+
+//executeTxsParallel execute the transactions of block optimistically by workers. Every transaction runs speculatively against
+//the states at the beginning of block, then the results are validated in block order. The transaction read any key written
+//by previous transactions is re-executed against the block overlay, so the write set is exactly the same as sequential execution.
+//The gas fee charged to governance contract is not a conflict, the fee balance change is added to block overlay in order
+func (this *LedgerStoreImp) executeTxsParallel(overlay *overlaydb.OverlayDB, block *types.Block, workers int) ([]*event.ExecuteNotify, error) {
+	txs := block.Transactions
+	results := make([]*txExecution, len(txs))
+	next := int32(-1)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				index := int(atomic.AddInt32(&next, 1))
+				if index >= len(txs) {
+					return
+				}
+				reads := newReadRecorder(this.stateStore.store)
+				notify, txOverlay, err := this.executeTx(reads, block, txs[index])
+				results[index] = &txExecution{notify: notify, overlay: txOverlay, reads: reads, err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	baseFee, err := this.stateStore.store.Get(feeKey)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, err
+	}
+	written := make(map[string]struct{})
+	notifies := make([]*event.ExecuteNotify, 0, len(txs))
+	reExecuted := 0
+	for i, tx := range txs {
+		res := results[i]
+		speculative := true
+		if res.err != nil || res.reads.conflict(written) {
+			notify, txOverlay, err := this.executeTx(&overlayStore{overlay: overlay}, block, tx)
+			if err != nil {
+				return nil, err
+			}
+			res = &txExecution{notify: notify, overlay: txOverlay}
+			speculative = false
+			reExecuted++
+		}
+		var mergeErr error
+		res.overlay.GetWriteSet().ForEach(func(key, val []byte) {
+			if speculative && bytes.Equal(key, feeKey) {
+				merged, err := mergeFee(overlay, baseFee, val)
+				if err != nil {
+					mergeErr = err
+					return
+				}
+				val = merged
+			}
+			if len(val) == 0 {
+				overlay.Delete(key)
+			} else {
+				overlay.Put(key, val)
+			}
+			written[string(key)] = struct{}{}
+		})
+		if mergeErr != nil {
+			return nil, mergeErr
+		}
+		notifies = append(notifies, res.notify)
+	}
+	log.Debugf("executeTxsParallel block height:%d txs:%d re-executed:%d", block.Header.Height, len(txs), reExecuted)
+	return notifies, nil
+}
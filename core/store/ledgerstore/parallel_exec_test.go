@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/signature"
+	"github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/core/store"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSetOf(result store.ExecuteResult) map[string]string {
+	writeSet := make(map[string]string)
+	result.WriteSet.ForEach(func(key, val []byte) {
+		writeSet[string(key)] = string(val)
+	})
+	return writeSet
+}
+
+//newPaidIncreaseTx return the transaction to increase counter paying gas fee by payer
+func newPaidIncreaseTx(t *testing.T, nonce uint32, contract common.Address, key string,
+	payer *account.Account) *types.Transaction {
+	builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+	builder.EmitPushByteArray([]byte(key))
+	builder.EmitPushCall(contract[:])
+	mutable := &types.MutableTransaction{
+		TxType:   types.Invoke,
+		Nonce:    nonce,
+		GasPrice: 1,
+		GasLimit: 100000,
+		Payer:    payer.Address,
+		Payload:  &payload.InvokeCode{Code: builder.ToArray()},
+	}
+	hash := mutable.Hash()
+	sig, err := signature.Sign(payer, hash[:])
+	assert.Nil(t, err)
+	mutable.Sigs = []types.Sig{{PubKeys: []keypair.PublicKey{payer.PublicKey}, M: 1, SigData: [][]byte{sig}}}
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	return tx
+}
+
+//executeBoth execute block sequentially and in parallel, and check the results are the same before submitting
+func executeBoth(t *testing.T, sequential, parallel *LedgerStoreImp, block *types.Block) {
+	config.DefConfig.Common.ExecWorkers = 0
+	expect, err := sequential.ExecuteBlock(block)
+	assert.Nil(t, err)
+	config.DefConfig.Common.ExecWorkers = 4
+	result, err := parallel.ExecuteBlock(block)
+	assert.Nil(t, err)
+
+	assert.Equal(t, expect.Hash, result.Hash)
+	assert.Equal(t, expect.MerkleRoot, result.MerkleRoot)
+	assert.Equal(t, expect.Notify, result.Notify)
+	assert.Equal(t, writeSetOf(expect), writeSetOf(result))
+
+	assert.Nil(t, sequential.SubmitBlock(block, expect))
+	assert.Nil(t, parallel.SubmitBlock(block, result))
+}
+
+func TestParallelExecution(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+	workers := config.DefConfig.Common.ExecWorkers
+	defer func() { config.DefConfig.Common.ExecWorkers = workers }()
+
+	dir, err := ioutil.TempDir("", "parallel")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	sequential, _ := newSoloLedger(t, filepath.Join(dir, "sequential"), acc)
+	defer sequential.Close()
+	parallel, _ := newSoloLedger(t, filepath.Join(dir, "parallel"), acc)
+	defer parallel.Close()
+
+	deploy := &payload.DeployCode{Code: counterCode(), NeedStorage: true, Name: "counter"}
+	contract := deploy.Address()
+	blocks := [][]*types.Transaction{
+		{newTx(t, 0, types.Deploy, deploy), newIncreaseTx(t, 1, contract, "a")},
+		{
+			newIncreaseTx(t, 2, contract, "a"),
+			newIncreaseTx(t, 3, contract, "b"),
+			newIncreaseTx(t, 4, contract, "a"),
+			newInvokeTx(t, 5),
+			newIncreaseTx(t, 6, contract, "c"),
+			newIncreaseTx(t, 7, contract, "b"),
+			newIncreaseTx(t, 8, common.Address{1}, "a"),
+			newIncreaseTx(t, 9, contract, "a"),
+		},
+	}
+	var txs []*types.Transaction
+	for i := 0; i < 32; i++ {
+		txs = append(txs, newIncreaseTx(t, uint32(100+i), contract, string([]byte{'k', byte(i % 5)})))
+	}
+	blocks = append(blocks, txs)
+
+	for _, txs := range blocks {
+		executeBoth(t, sequential, parallel, makeSoloBlock(t, sequential, acc, txs...))
+	}
+	assert.Equal(t, sequential.GetCurrentBlockHash(), parallel.GetCurrentBlockHash())
+
+	for key, count := range map[string]int64{"a": 4, "b": 2, "c": 1, "k\x00": 7, "k\x04": 6} {
+		item, err := parallel.GetStorageItem(&states.StorageKey{ContractAddress: contract, Key: []byte(key)})
+		assert.Nil(t, err)
+		assert.Equal(t, common.BigIntToNeoBytes(big.NewInt(count)), item.Value)
+	}
+}
+
+func TestParallelExecutionFee(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+	workers := config.DefConfig.Common.ExecWorkers
+	defer func() { config.DefConfig.Common.ExecWorkers = workers }()
+
+	dir, err := ioutil.TempDir("", "parallel")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	sequential, _ := newSoloLedger(t, filepath.Join(dir, "sequential"), acc)
+	defer sequential.Close()
+	parallel, _ := newSoloLedger(t, filepath.Join(dir, "parallel"), acc)
+	defer parallel.Close()
+
+	deploy := &payload.DeployCode{Code: counterCode(), NeedStorage: true, Name: "counter"}
+	contract := deploy.Address()
+	unbind := newNativeTx(t, 1, utils.OntContractAddress, ont.TRANSFER_NAME,
+		[]interface{}{[]*ont.State{{From: acc.Address, To: acc.Address, Value: 1}}}, acc)
+	executeBoth(t, sequential, parallel, makeSoloBlock(t, sequential, acc, newTx(t, 0, types.Deploy, deploy), unbind))
+
+	payers := make([]*account.Account, 4)
+	funds := make([]*types.Transaction, len(payers))
+	for i := range payers {
+		payers[i] = account.NewAccount("")
+		funds[i] = newNativeTx(t, uint32(2+i), utils.OngContractAddress, ont.TRANSFERFROM_NAME,
+			[]interface{}{&ont.TransferFrom{Sender: acc.Address, From: utils.OntContractAddress,
+				To: payers[i].Address, Value: 1000000}}, acc)
+	}
+	executeBoth(t, sequential, parallel, makeSoloBlock(t, sequential, acc, funds...))
+
+	var txs []*types.Transaction
+	for i := 0; i < 3; i++ {
+		for j, payer := range payers {
+			txs = append(txs, newPaidIncreaseTx(t, uint32(100+4*i+j), contract, string([]byte{'k', byte(j)}), payer))
+		}
+	}
+	block := makeSoloBlock(t, sequential, acc, txs...)
+	//the gas fee paid to governance contract is not recorded as read, so it never conflicts with other transactions
+	written := map[string]struct{}{string(feeKey): {}}
+	for _, tx := range txs[:len(payers)] {
+		reads := newReadRecorder(parallel.stateStore.store)
+		notify, overlay, err := parallel.executeTx(reads, block, tx)
+		assert.Nil(t, err)
+		assert.Equal(t, event.CONTRACT_STATE_SUCCESS, notify.State)
+		value, _ := overlay.GetWriteSet().Get(feeKey)
+		assert.NotEmpty(t, value)
+		assert.False(t, reads.conflict(written))
+	}
+	fee := getBalance(t, parallel, utils.OngContractAddress, utils.GovernanceContractAddress)
+	executeBoth(t, sequential, parallel, block)
+
+	for _, tx := range txs {
+		notify, err := parallel.GetEventNotifyByTx(tx.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, event.CONTRACT_STATE_SUCCESS, notify.State)
+		fee += notify.GasConsumed
+	}
+	assert.Equal(t, fee, getBalance(t, parallel, utils.OngContractAddress, utils.GovernanceContractAddress))
+	assert.Equal(t, sequential.GetCurrentBlockHash(), parallel.GetCurrentBlockHash())
+}
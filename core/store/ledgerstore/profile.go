@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
+)
+
+//ProfilePreExecute pre-execute the transaction against the current states, and return the gas profile of execution
+func (this *LedgerStoreImp) ProfilePreExecute(tx *types.Transaction) (*profile.GasProfile, error) {
+	if tx.TxType != types.Invoke {
+		return nil, fmt.Errorf("transaction type %d not profilable", tx.TxType)
+	}
+	profiler := profile.NewGasProfiler()
+	stf, err := this.preExecuteContract(tx, nil, profiler)
+	result := profiler.Profile(stf.Gas)
+	result.TxHash = tx.Hash()
+	result.Height = this.GetCurrentBlockHeight() + 1
+	result.State = stf.State
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilePreExecute(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "profile")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	deploy := &payload.DeployCode{Code: counterCode(), NeedStorage: true, Name: "counter"}
+	contract := deploy.Address()
+	addBlock(t, store, makeSoloBlock(t, store, acc, newTx(t, 0, types.Deploy, deploy)))
+
+	tx := newIncreaseTx(t, 1, contract, "a")
+	preResult, err := store.PreExecuteContract(tx)
+	assert.Nil(t, err)
+	result, err := store.ProfilePreExecute(tx)
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash(), result.TxHash)
+	assert.Equal(t, byte(event.CONTRACT_STATE_SUCCESS), result.State)
+	assert.Equal(t, preResult.Gas, result.GasConsumed)
+
+	//the folded stacks add up to the gas consumed
+	entryAddr := common.AddressFromVmCode(tx.Payload.(*payload.InvokeCode).Code)
+	entry := entryAddr.ToHexString()
+	stacks := make(map[string]uint64)
+	total := uint64(0)
+	for _, line := range result.Folded {
+		i := strings.LastIndex(line, " ")
+		gas, err := strconv.ParseUint(line[i+1:], 10, 64)
+		assert.Nil(t, err)
+		stacks[line[:i]] = gas
+		total += gas
+	}
+	assert.Equal(t, result.GasConsumed, total)
+	putGas := stacks[entry+";"+contract.ToHexString()+";System.Storage.Put"]
+	assert.NotEqual(t, uint64(0), putGas)
+
+	var put *profile.GasItem
+	for _, item := range result.Syscalls {
+		if item.Name == "System.Storage.Put" {
+			put = item
+		}
+	}
+	assert.NotNil(t, put)
+	assert.Equal(t, uint64(1), put.Count)
+	assert.Equal(t, putGas, put.Gas)
+
+	assert.Equal(t, 1, len(result.Storage))
+	assert.Equal(t, contract.ToHexString(), result.Storage[0].Contract)
+	assert.Equal(t, common.ToHexString([]byte("a")), result.Storage[0].Key)
+	assert.Equal(t, putGas, result.Storage[0].Gas)
+
+	//the entry code calls counter contract, so consumes more in total
+	assert.Equal(t, 2, len(result.Contracts))
+	assert.Equal(t, entry, result.Contracts[0].Contract)
+	callee := result.Contracts[1]
+	assert.Equal(t, contract.ToHexString(), callee.Contract)
+	assert.Equal(t, uint64(1), callee.Calls)
+	assert.Equal(t, callee.SelfGas, callee.TotalGas)
+	assert.Equal(t, result.Contracts[0].SelfGas+callee.TotalGas, result.Contracts[0].TotalGas)
+
+	_, err = store.ProfilePreExecute(newTx(t, 2, types.Deploy, deploy))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/states"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/proposal"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/stretchr/testify/assert"
+)
+
+func getProposal(t *testing.T, store *LedgerStoreImp, id uint64) *proposal.Proposal {
+	item, err := store.GetStorageItem(&states.StorageKey{
+		ContractAddress: utils.ProposalContractAddress,
+		Key:             proposal.GenProposalKey(id)[common.ADDR_LEN:],
+	})
+	assert.Nil(t, err)
+	p, err := proposal.DecodeProposal(item.Value)
+	assert.Nil(t, err)
+	return p
+}
+
+func TestProposal(t *testing.T) {
+	acc := account.NewAccount("")
+	peers := make([]*account.Account, 7)
+	for i := range peers {
+		peers[i] = account.NewAccount("")
+	}
+	defer setVbftGenesis(acc, peers)()
+	height := config.PROPOSAL_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.PROPOSAL_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.PROPOSAL_HEIGHT[config.NETWORK_ID_SOLO_NET] = 2
+
+	dir, err := ioutil.TempDir("", "proposal")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	outsider := account.NewAccount("")
+	propose := func(nonce uint32, proposer *account.Account, contract common.Address, method string,
+		params []byte) *types.Transaction {
+		return newNativeTx(t, nonce, utils.ProposalContractAddress, proposal.PROPOSE_NAME,
+			[]interface{}{&proposal.ProposeParam{Proposer: proposer.Address, Contract: contract, Method: method,
+				Params: params}}, proposer)
+	}
+	vote := func(nonce uint32, voter *account.Account, id uint64, approve bool) *types.Transaction {
+		return newNativeTx(t, nonce, utils.ProposalContractAddress, proposal.VOTE_NAME,
+			[]interface{}{&proposal.VoteParam{Id: id, Voter: voter.Address, Approve: approve}}, voter)
+	}
+	execute := func(nonce uint32, id uint64) *types.Transaction {
+		return newNativeTx(t, nonce, utils.ProposalContractAddress, proposal.EXECUTE_NAME, []interface{}{id},
+			outsider)
+	}
+
+	//withdraw nothing at the time of next block, return the error
+	withdraw := func(nonce uint32, staker *account.Account) error {
+		tx := newNativeTx(t, nonce, utils.GovernanceContractAddress, governance.WITHDRAW,
+			[]interface{}{&governance.WithdrawParam{Address: staker.Address, PeerPubkeyList: []string{},
+				WithdrawList: []uint32{}}}, staker)
+		header, err := store.GetHeaderByHeight(store.GetCurrentBlockHeight())
+		assert.Nil(t, err)
+		_, err = store.PreExecuteContractWithOverride(tx, &sstate.StateOverride{Time: header.Timestamp + 1})
+		return err
+	}
+
+	//shorten the periods of proposal
+	periods := newNativeTx(t, 0, utils.ParamContractAddress, global_params.SET_GLOBAL_PARAM_NAME,
+		[]interface{}{global_params.Params{{Key: proposal.VOTING_PERIOD_PARAM, Value: "3"},
+			{Key: proposal.TIMELOCK_PARAM, Value: "2"}}}, acc)
+	snapshot := newNativeTx(t, 1, utils.ParamContractAddress, global_params.CREATE_SNAPSHOT_NAME,
+		[]interface{}{""}, acc)
+	//proposal contract is not activated yet
+	inactive := propose(24, peers[0], utils.ParamContractAddress, global_params.CREATE_SNAPSHOT_NAME, nil)
+	addBlock(t, store, makeSoloBlock(t, store, acc, periods, snapshot, inactive))
+
+	paramsBuf := new(bytes.Buffer)
+	assert.Nil(t, (&global_params.Params{{Key: "proposalTest", Value: "1"}}).Serialize(paramsBuf))
+	setParam := propose(2, peers[0], utils.ParamContractAddress, global_params.SET_GLOBAL_PARAM_NAME,
+		paramsBuf.Bytes())
+	createSnapshot := propose(3, peers[5], utils.ParamContractAddress, global_params.CREATE_SNAPSHOT_NAME, nil)
+	noStake := propose(4, outsider, utils.ParamContractAddress, global_params.CREATE_SNAPSHOT_NAME, nil)
+	notAllowed := propose(5, peers[1], utils.GovernanceContractAddress, governance.TRANSFER_PENALTY, nil)
+	autoBuf := new(bytes.Buffer)
+	assert.Nil(t, (&global_params.Params{{Key: "proposalAuto", Value: "1"}}).Serialize(autoBuf))
+	auto := propose(25, peers[1], utils.ParamContractAddress, global_params.SET_GLOBAL_PARAM_NAME, autoBuf.Bytes())
+	//params can not be deserialized, so its execution fails
+	broken := propose(26, peers[2], utils.ParamContractAddress, global_params.SET_GLOBAL_PARAM_NAME, nil)
+	addBlock(t, store, makeSoloBlock(t, store, acc, setParam, createSnapshot, noStake, notAllowed, auto, broken))
+
+	votes := []*types.Transaction{vote(6, peers[5], 2, true), vote(7, peers[6], 1, false),
+		vote(8, peers[4], 2, false)}
+	for i := 0; i < 4; i++ {
+		votes = append(votes, vote(uint32(9+i), peers[i], 1, true))
+	}
+	for i := 0; i < 4; i++ {
+		votes = append(votes, vote(uint32(27+i), peers[i], 3, true), vote(uint32(31+i), peers[i], 4, true))
+	}
+	voteTwice := vote(13, peers[0], 1, false)
+	voteNoStake := vote(14, outsider, 1, true)
+	executeVoting := execute(15, 1)
+	addBlock(t, store, makeSoloBlock(t, store, acc, append(votes, voteTwice, voteNoStake, executeVoting)...))
+	//stake of voters can not be withdrawn until voting ends
+	assert.Contains(t, withdraw(21, peers[0]).Error(), "locked by voting")
+	assert.Nil(t, withdraw(22, outsider))
+
+	addBlock(t, store, makeSoloBlock(t, store, acc))
+	voteEnded := vote(16, peers[4], 1, false)
+	executeQueued := execute(17, 1)
+	addBlock(t, store, makeSoloBlock(t, store, acc, voteEnded, executeQueued))
+	assert.NotContains(t, withdraw(23, peers[0]).Error(), "locked by voting")
+
+	addBlock(t, store, makeSoloBlock(t, store, acc))
+	executePassed := execute(18, 1)
+	executeRejected := execute(19, 2)
+	addBlock(t, store, makeSoloBlock(t, store, acc, executePassed, executeRejected))
+	executeTwice := execute(20, 1)
+	addBlock(t, store, makeSoloBlock(t, store, acc, executeTwice))
+
+	txStates := map[*types.Transaction]byte{
+		periods:         event.CONTRACT_STATE_SUCCESS,
+		inactive:        event.CONTRACT_STATE_FAIL,
+		snapshot:        event.CONTRACT_STATE_SUCCESS,
+		setParam:        event.CONTRACT_STATE_SUCCESS,
+		auto:            event.CONTRACT_STATE_SUCCESS,
+		broken:          event.CONTRACT_STATE_SUCCESS,
+		createSnapshot:  event.CONTRACT_STATE_SUCCESS,
+		noStake:         event.CONTRACT_STATE_FAIL,
+		notAllowed:      event.CONTRACT_STATE_FAIL,
+		voteTwice:       event.CONTRACT_STATE_FAIL,
+		voteNoStake:     event.CONTRACT_STATE_FAIL,
+		executeVoting:   event.CONTRACT_STATE_FAIL,
+		voteEnded:       event.CONTRACT_STATE_FAIL,
+		executeQueued:   event.CONTRACT_STATE_FAIL,
+		executePassed:   event.CONTRACT_STATE_SUCCESS,
+		executeRejected: event.CONTRACT_STATE_FAIL,
+		executeTwice:    event.CONTRACT_STATE_FAIL,
+	}
+	for _, tx := range votes {
+		txStates[tx] = event.CONTRACT_STATE_SUCCESS
+	}
+	for tx, state := range txStates {
+		notify, err := store.GetEventNotifyByTx(tx.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, state, notify.State)
+	}
+
+	notify, err := store.GetEventNotifyByTx(executePassed.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{proposal.EXECUTE_NAME, float64(1), utils.ParamContractAddress.ToHexString(),
+		global_params.SET_GLOBAL_PARAM_NAME}, notify.Notify[len(notify.Notify)-1].States)
+
+	passed := getProposal(t, store, 1)
+	assert.Equal(t, uint64(70000), passed.TotalStake)
+	assert.Equal(t, uint64(40000), passed.YesStake)
+	assert.Equal(t, uint64(10000), passed.NoStake)
+	assert.True(t, passed.Executed)
+	rejected := getProposal(t, store, 2)
+	assert.Equal(t, uint64(10000), rejected.YesStake)
+	assert.Equal(t, uint64(10000), rejected.NoStake)
+	assert.False(t, rejected.Passed())
+	//passed proposals are executed at the end of block after timelock, failed ones are left executable
+	assert.True(t, getProposal(t, store, 3).Executed)
+	assert.False(t, getProposal(t, store, 4).Executed)
+	pending, err := store.GetStorageItem(&states.StorageKey{
+		ContractAddress: utils.ProposalContractAddress,
+		Key:             proposal.GenPendingKey()[common.ADDR_LEN:],
+	})
+	assert.Equal(t, scom.ErrNotFound, err)
+	assert.Nil(t, pending)
+
+	item, err := store.GetStorageItem(&states.StorageKey{
+		ContractAddress: utils.ParamContractAddress,
+		Key:             append([]byte(global_params.PARAM), byte(global_params.PREPARE_VALUE)),
+	})
+	assert.Nil(t, err)
+	params := new(global_params.Params)
+	assert.Nil(t, params.Deserialize(bytes.NewBuffer(item.Value)))
+	_, param := params.GetParam("proposalTest")
+	assert.Equal(t, "1", param.Value)
+	_, param = params.GetParam("proposalAuto")
+	assert.Equal(t, "1", param.Value)
+}
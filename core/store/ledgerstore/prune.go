@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/log"
+)
+
+//Interval of blocks to persist the pruning progress
+const PRUNE_SAVE_INTERVAL = uint32(1000)
+
+//tryPrune start pruning in background, when there are blocks out of the keeping range not pruned.
+//Headers and block merkle tree are always kept.
+func (this *LedgerStoreImp) tryPrune(height uint32) {
+	if pruneTarget(height) <= this.blockStore.GetPrunedHeight() {
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&this.pruning, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreUint32(&this.pruning, 0)
+		//catch up with the blocks saved during pruning
+		for atomic.LoadUint32(&this.pruneClosing) == 0 {
+			target := pruneTarget(this.GetCurrentBlockHeight())
+			if target <= this.blockStore.GetPrunedHeight() {
+				return
+			}
+			err := this.prune(target)
+			if err != nil {
+				log.Errorf("prune blocks error %s", err)
+				return
+			}
+		}
+	}()
+}
+
+//pruneTarget return the lowest height keeping full data, when current block height is height
+func pruneTarget(height uint32) uint32 {
+	keep := config.DefConfig.Common.PruneKeepBlocks
+	if keep == 0 || height < keep {
+		return 0
+	}
+	return height - keep + 1
+}
+
+//prune the transactions and events of blocks below target height, genesis block is kept
+func (this *LedgerStoreImp) prune(target uint32) error {
+	start := this.blockStore.GetPrunedHeight()
+	if start == 0 {
+		start = 1
+	}
+	for height := start; height < target; height++ {
+		if atomic.LoadUint32(&this.pruneClosing) == 1 {
+			return this.blockStore.SavePrunedHeight(height)
+		}
+		txHashes, err := this.blockStore.PruneBlock(height)
+		if err != nil {
+			return fmt.Errorf("prune block height:%d error %s", height, err)
+		}
+		err = this.eventStore.PruneBlock(height, txHashes)
+		if err != nil {
+			return fmt.Errorf("prune event height:%d error %s", height, err)
+		}
+		if (height+1)%PRUNE_SAVE_INTERVAL == 0 {
+			err = this.blockStore.SavePrunedHeight(height + 1)
+			if err != nil {
+				return fmt.Errorf("SavePrunedHeight error %s", err)
+			}
+		}
+	}
+	err := this.blockStore.SavePrunedHeight(target)
+	if err != nil {
+		return fmt.Errorf("SavePrunedHeight error %s", err)
+	}
+	log.Debugf("pruned blocks below height %d", target)
+	return nil
+}
+
+//GetPrunedHeight return the height below which the transactions and events of blocks are pruned
+func (this *LedgerStoreImp) GetPrunedHeight() uint32 {
+	return this.blockStore.GetPrunedHeight()
+}
+
+//closePrune stop the background pruning and wait for it to exit
+func (this *LedgerStoreImp) closePrune() {
+	atomic.StoreUint32(&this.pruneClosing, 1)
+	for atomic.LoadUint32(&this.pruning) == 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
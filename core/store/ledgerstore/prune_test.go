@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common/config"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrune(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+	keep := config.DefConfig.Common.PruneKeepBlocks
+	config.DefConfig.Common.PruneKeepBlocks = 3
+	defer func() { config.DefConfig.Common.PruneKeepBlocks = keep }()
+
+	dir, err := ioutil.TempDir("", "prune")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	var txs []*types.Transaction
+	for i := 0; i < 6; i++ {
+		tx := newInvokeTx(t, uint32(i))
+		addBlock(t, store, makeSoloBlock(t, store, acc, tx))
+		txs = append(txs, tx)
+	}
+	for atomic.LoadUint32(&store.pruning) == 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	//blocks 4,5,6 keep full data
+	assert.Equal(t, uint32(4), store.GetPrunedHeight())
+
+	_, err = store.GetBlockByHeight(3)
+	assert.Equal(t, scom.ErrPruned, err)
+	_, height, err := store.GetTransaction(txs[2].Hash())
+	assert.Equal(t, scom.ErrPruned, err)
+	assert.Equal(t, uint32(3), height)
+	contain, err := store.IsContainTransaction(txs[2].Hash())
+	assert.Nil(t, err)
+	assert.True(t, contain)
+	_, err = store.GetEventNotifyByTx(txs[2].Hash())
+	assert.Equal(t, scom.ErrPruned, err)
+	_, err = store.GetEventNotifyByBlock(3)
+	assert.Equal(t, scom.ErrPruned, err)
+	header, err := store.GetHeaderByHeight(3)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(3), header.Height)
+
+	block, err := store.GetBlockByHeight(4)
+	assert.Nil(t, err)
+	assert.Equal(t, txs[3].Hash(), block.Transactions[0].Hash())
+	tx, _, err := store.GetTransaction(txs[3].Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, txs[3].Hash(), tx.Hash())
+	_, err = store.GetEventNotifyByTx(txs[3].Hash())
+	assert.Nil(t, err)
+	store.Close()
+
+	//pruned height is persisted
+	store, err = NewLedgerStore(filepath.Join(dir, "ledger"), 0)
+	assert.Nil(t, err)
+	defer store.Close()
+	assert.Equal(t, uint32(4), store.GetPrunedHeight())
+}
@@ -0,0 +1,228 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/constants"
+	vconfig "github.com/dnaproject2/DNA/consensus/vbft/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/signature"
+	"github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/core/types"
+	cutils "github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func setVbftGenesis(acc *account.Account, peers []*account.Account) func() {
+	genesisConfig, networkId := config.DefConfig.Genesis, config.DefConfig.P2PNode.NetworkId
+	vbft := *config.PolarisConfig.VBFT
+	vbft.Peers = make([]*config.VBFTPeerStakeInfo, 0, len(peers))
+	for i, peer := range peers {
+		vbft.Peers = append(vbft.Peers, &config.VBFTPeerStakeInfo{
+			Index:      uint32(i + 1),
+			PeerPubkey: vconfig.PubkeyID(peer.PublicKey),
+			Address:    peer.Address.ToBase58(),
+			InitPos:    10000,
+		})
+	}
+	config.DefConfig.Genesis = &config.GenesisConfig{
+		ConsensusType: config.CONSENSUS_TYPE_SOLO,
+		SOLO: &config.SOLOConfig{
+			Bookkeepers: []string{hex.EncodeToString(keypair.SerializePublicKey(acc.PublicKey))},
+		},
+		VBFT: &vbft,
+	}
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	return func() {
+		config.DefConfig.Genesis, config.DefConfig.P2PNode.NetworkId = genesisConfig, networkId
+	}
+}
+
+func signHeader(t *testing.T, signer *account.Account, height, proposer uint32, prevHash common.Uint256,
+	timestamp uint32, txRoot common.Uint256) ([]byte, []byte) {
+	payload, err := json.Marshal(&vconfig.VbftBlockInfo{Proposer: proposer})
+	assert.Nil(t, err)
+	header := &types.Header{
+		PrevBlockHash:    prevHash,
+		TransactionsRoot: txRoot,
+		Timestamp:        timestamp,
+		Height:           height,
+		ConsensusPayload: payload,
+	}
+	hash := header.Hash()
+	sig, err := signature.Sign(signer, hash[:])
+	assert.Nil(t, err)
+	return header.ToArray(), sig
+}
+
+func newSlashTx(t *testing.T, nonce uint32, evidence *governance.EquivocationEvidence, signer *account.Account) *types.Transaction {
+	code, err := cutils.BuildNativeInvokeCode(utils.GovernanceContractAddress, 0, governance.SLASH_EQUIVOCATION,
+		[]interface{}{evidence})
+	assert.Nil(t, err)
+	return newSignedTx(t, nonce, types.Invoke, &payload.InvokeCode{Code: code}, signer)
+}
+
+func getPeerPool(t *testing.T, store *LedgerStoreImp, view uint32) *governance.PeerPoolMap {
+	viewBytes, err := governance.GetUint32Bytes(view)
+	assert.Nil(t, err)
+	item, err := store.GetStorageItem(&states.StorageKey{
+		ContractAddress: utils.GovernanceContractAddress,
+		Key:             append([]byte(governance.PEER_POOL), viewBytes...),
+	})
+	assert.Nil(t, err)
+	peerPoolMap := new(governance.PeerPoolMap)
+	assert.Nil(t, peerPoolMap.Deserialize(bytes.NewBuffer(item.Value)))
+	return peerPoolMap
+}
+
+func TestSlashEquivocation(t *testing.T) {
+	acc := account.NewAccount("")
+	peers := make([]*account.Account, 7)
+	for i := range peers {
+		peers[i] = account.NewAccount("")
+	}
+	defer setVbftGenesis(acc, peers)()
+
+	dir, err := ioutil.TempDir("", "slash")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, genesisBlock := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+	addBlock(t, store, makeSoloBlock(t, store, acc))
+
+	prevHash := genesisBlock.Hash()
+	timestamp := constants.GENESIS_BLOCK_TIMESTAMP + 1
+	root1 := common.Uint256{1}
+	root2 := common.Uint256{2}
+	evidence := func(msgType uint8, peer *account.Account, h1, s1, h2, s2 []byte) *governance.EquivocationEvidence {
+		return &governance.EquivocationEvidence{MsgType: msgType, PeerPubkey: vconfig.PubkeyID(peer.PublicKey),
+			Header1: h1, Sig1: s1, Header2: h2, Sig2: s2}
+	}
+
+	//full and empty block of one proposal is not equivocation
+	h1, s1 := signHeader(t, peers[0], 1, 1, prevHash, timestamp, root1)
+	h2, s2 := signHeader(t, peers[0], 1, 1, prevHash, timestamp, root2)
+	sibling := newSlashTx(t, 0, evidence(governance.EVIDENCE_PROPOSAL, peers[0], h1, s1, h2, s2), acc)
+	//endorsing blocks of different proposers is not equivocation
+	h1, s1 = signHeader(t, peers[1], 1, 1, prevHash, timestamp, root1)
+	h2, s2 = signHeader(t, peers[1], 1, 3, prevHash, timestamp+1, root2)
+	otherProposer := newSlashTx(t, 1, evidence(governance.EVIDENCE_ENDORSE, peers[1], h1, s1, h2, s2), acc)
+	//nor is committing blocks of different proposers, since a commit is signed the same way as an endorsement
+	crossCommit := newSlashTx(t, 9, evidence(governance.EVIDENCE_COMMIT, peers[1], h1, s1, h2, s2), acc)
+	//headers must be signed by the slashed peer
+	h1, s1 = signHeader(t, peers[2], 1, 3, prevHash, timestamp, root1)
+	h2, s2 = signHeader(t, peers[2], 1, 3, prevHash, timestamp+1, root2)
+	wrongSigner := newSlashTx(t, 2, evidence(governance.EVIDENCE_PROPOSAL, peers[3], h1, s1, h2, s2), acc)
+	//proposal evidence must be proposed by the slashed peer
+	h1, s1 = signHeader(t, peers[4], 1, 1, prevHash, timestamp, root1)
+	h2, s2 = signHeader(t, peers[4], 1, 1, common.Uint256{3}, timestamp, root1)
+	notProposer := newSlashTx(t, 3, evidence(governance.EVIDENCE_PROPOSAL, peers[4], h1, s1, h2, s2), acc)
+	endorse := newSlashTx(t, 4, evidence(governance.EVIDENCE_ENDORSE, peers[4], h1, s1, h2, s2), acc)
+	//conflicting proposals
+	h1, s1 = signHeader(t, peers[2], 1, 3, prevHash, timestamp, root1)
+	h2, s2 = signHeader(t, peers[2], 1, 3, prevHash, timestamp+1, root2)
+	proposal := newSlashTx(t, 5, evidence(governance.EVIDENCE_PROPOSAL, peers[2], h1, s1, h2, s2), acc)
+	//evidence can not be from the future
+	h1, s1 = signHeader(t, peers[5], 10, 6, prevHash, timestamp, root1)
+	h2, s2 = signHeader(t, peers[5], 10, 6, prevHash, timestamp+1, root2)
+	future := newSlashTx(t, 6, evidence(governance.EVIDENCE_PROPOSAL, peers[5], h1, s1, h2, s2), acc)
+	addBlock(t, store, makeSoloBlock(t, store, acc, sibling, otherProposer, crossCommit, wrongSigner, notProposer,
+		endorse, proposal, future))
+
+	replay := newSlashTx(t, 7, evidence(governance.EVIDENCE_PROPOSAL, peers[2], h1, s1, h2, s2), acc)
+	addBlock(t, store, makeSoloBlock(t, store, acc, replay))
+
+	//like blackNode, slashing a consensus peer executes commitDpos at once, which needs K candidates left
+	for tx, state := range map[*types.Transaction]byte{
+		sibling:       event.CONTRACT_STATE_FAIL,
+		otherProposer: event.CONTRACT_STATE_FAIL,
+		crossCommit:   event.CONTRACT_STATE_FAIL,
+		wrongSigner:   event.CONTRACT_STATE_FAIL,
+		notProposer:   event.CONTRACT_STATE_FAIL,
+		endorse:       event.CONTRACT_STATE_FAIL,
+		proposal:      event.CONTRACT_STATE_FAIL,
+		future:        event.CONTRACT_STATE_FAIL,
+		replay:        event.CONTRACT_STATE_FAIL,
+	} {
+		notify, err := store.GetEventNotifyByTx(tx.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, state, notify.State)
+	}
+	peerPoolMap := getPeerPool(t, store, 1)
+	for _, peer := range peers {
+		assert.Equal(t, governance.ConsensusStatus, peerPoolMap.PeerPoolMap[vconfig.PubkeyID(peer.PublicKey)].Status)
+	}
+
+	//add candidates, so that commitDpos can be executed after slashing
+	for i := 0; i < 2; i++ {
+		candidate := account.NewAccount("")
+		peerPoolMap.PeerPoolMap[vconfig.PubkeyID(candidate.PublicKey)] = &governance.PeerPoolItem{
+			Index:      uint32(len(peers) + i + 1),
+			PeerPubkey: vconfig.PubkeyID(candidate.PublicKey),
+			Address:    candidate.Address,
+			Status:     governance.CandidateStatus,
+			InitPos:    10000,
+		}
+	}
+	buf := new(bytes.Buffer)
+	assert.Nil(t, peerPoolMap.Serialize(buf))
+	viewBytes, err := governance.GetUint32Bytes(1)
+	assert.Nil(t, err)
+	override := &sstate.StateOverride{Storage: []*sstate.StorageOverride{{Contract: utils.GovernanceContractAddress,
+		Key: append([]byte(governance.PEER_POOL), viewBytes...), Value: buf.Bytes()}},
+		Balances: []*sstate.BalanceOverride{{Asset: utils.OntContractAddress, Address: utils.GovernanceContractAddress,
+			Balance: 100000}}}
+
+	//committing two blocks of one proposer is equivocation
+	h1, s1 = signHeader(t, peers[6], 1, 1, prevHash, timestamp, root1)
+	h2, s2 = signHeader(t, peers[6], 1, 1, prevHash, timestamp+1, root2)
+	commit := newSlashTx(t, 8, evidence(governance.EVIDENCE_COMMIT, peers[6], h1, s1, h2, s2), acc)
+	for _, tx := range []*types.Transaction{endorse, proposal, commit} {
+		_, err := store.PreExecuteContractWithOverride(tx, nil)
+		assert.NotNil(t, err)
+		result, err := store.PreExecuteContractWithOverride(tx, override)
+		assert.Nil(t, err)
+		assert.Equal(t, byte(event.CONTRACT_STATE_SUCCESS), result.State)
+		assert.Equal(t, governance.SLASH_EQUIVOCATION, result.Notify[len(result.Notify)-1].States.([]interface{})[0])
+	}
+
+	//slashEquivocation is not registered before the activation height
+	height := config.SLASH_EQUIVOCATION_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.SLASH_EQUIVOCATION_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.SLASH_EQUIVOCATION_HEIGHT[config.NETWORK_ID_SOLO_NET] = store.GetCurrentBlockHeight() + 2
+	_, err = store.PreExecuteContractWithOverride(commit, override)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,622 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/consensus/vbft/config"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+var (
+	DBDirSnapshot       = "snapshot" //Directory of the snapshots produced by this node
+	SnapshotFileExt     = ".snap"
+	snapshotTmpFileName = "snapshot.tmp"
+)
+
+//Key prefixes exported in snapshot. Transactions are only exported for the block at snapshot height.
+var (
+	snapshotBlockPrefixes = []byte{byte(scom.DATA_BLOCK), byte(scom.DATA_HEADER), byte(scom.IX_HEADER_HASH_LIST)}
+	snapshotStatePrefixes = []byte{byte(scom.ST_BOOKKEEPER), byte(scom.ST_CONTRACT), byte(scom.ST_STORAGE)}
+)
+
+//ExportSnapshot write the ledger state at current block height to a snapshot file at path.
+//Block saving is only blocked while the stores are captured, not during the export.
+func (this *LedgerStoreImp) ExportSnapshot(path string) (*snapshot.Manifest, error) {
+	writer, err := snapshot.NewWriter(path)
+	if err != nil {
+		return nil, err
+	}
+	source, err := this.captureSnapshot()
+	if err != nil {
+		writer.Abort()
+		return nil, err
+	}
+	defer source.release()
+	manifest, err := exportSnapshot(writer, source)
+	if err != nil {
+		writer.Abort()
+		return nil, err
+	}
+	return manifest, nil
+}
+
+//snapshotSource is the ledger captured at a block height for export. Store iterators see the data at the time
+//they are created, so blocks can be saved while the snapshot is written.
+type snapshotSource struct {
+	manifest   *snapshot.Manifest
+	blockIters []scom.StoreIterator //Iterators of snapshotBlockPrefixes
+	txKeys     [][]byte             //Transactions of the block at snapshot height
+	txValues   [][]byte
+	stateIters []scom.StoreIterator //Iterators of snapshotStatePrefixes
+	rootKey    []byte               //State merkle root entry at snapshot height, nil below state hash check height
+	rootValue  []byte
+}
+
+func (this *snapshotSource) release() {
+	for _, iter := range append(this.blockIters, this.stateIters...) {
+		iter.Release()
+	}
+}
+
+func (this *LedgerStoreImp) captureSnapshot() (*snapshotSource, error) {
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+	if this.closing {
+		return nil, fmt.Errorf("export snapshot error: ledger is closing")
+	}
+	height, blockHash := this.GetCurrentBlock()
+	source := &snapshotSource{
+		manifest: &snapshot.Manifest{
+			Version:   snapshot.SNAPSHOT_VERSION,
+			Height:    height,
+			BlockHash: blockHash,
+		},
+	}
+	manifest := source.manifest
+	var err error
+	manifest.BlockTreeSize, manifest.BlockTreeHashes, err = this.stateStore.GetBlockMerkleTree()
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockMerkleTree error %s", err)
+	}
+	if height >= this.stateHashCheckHeight {
+		manifest.StateTreeSize, manifest.StateTreeHashes, err = this.stateStore.GetStateMerkleTree()
+		if err != nil {
+			return nil, fmt.Errorf("GetStateMerkleTree error %s", err)
+		}
+		manifest.StateMerkleRoot, err = this.stateStore.GetStateMerkleRoot(height)
+		if err != nil {
+			return nil, fmt.Errorf("GetStateMerkleRoot error %s", err)
+		}
+		source.rootKey = this.stateStore.genStateMerkleRootKey(height)
+		source.rootValue, err = this.stateStore.store.Get(source.rootKey)
+		if err != nil {
+			return nil, fmt.Errorf("get state merkle root error %s", err)
+		}
+	}
+	_, txHashes, err := this.blockStore.loadHeaderWithTx(blockHash)
+	if err != nil {
+		return nil, fmt.Errorf("load header %s error %s", blockHash.ToHexString(), err)
+	}
+	for _, txHash := range txHashes {
+		key := this.blockStore.getTransactionKey(txHash)
+		value, err := this.blockStore.store.Get(key)
+		if err != nil {
+			return nil, fmt.Errorf("get transaction %s error %s", txHash.ToHexString(), err)
+		}
+		source.txKeys = append(source.txKeys, key)
+		source.txValues = append(source.txValues, value)
+	}
+	for _, prefix := range snapshotBlockPrefixes {
+		source.blockIters = append(source.blockIters, this.blockStore.store.NewIterator([]byte{prefix}))
+	}
+	for _, prefix := range snapshotStatePrefixes {
+		source.stateIters = append(source.stateIters, this.stateStore.store.NewIterator([]byte{prefix}))
+	}
+	return source, nil
+}
+
+func exportSnapshot(writer *snapshot.Writer, source *snapshotSource) (*snapshot.Manifest, error) {
+	manifest := source.manifest
+	for _, iter := range source.blockIters {
+		if err := exportIterator(writer, snapshot.STORE_BLOCK, iter, nil); err != nil {
+			return nil, err
+		}
+	}
+	for i, key := range source.txKeys {
+		if err := writer.Add(snapshot.STORE_BLOCK, key, source.txValues[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	hasher := snapshot.NewStateHasher(manifest.Height, manifest.BlockHash)
+	for _, iter := range source.stateIters {
+		if err := exportIterator(writer, snapshot.STORE_STATE, iter, hasher); err != nil {
+			return nil, err
+		}
+	}
+	if source.rootKey != nil {
+		hasher.Add(source.rootKey, source.rootValue)
+		if err := writer.Add(snapshot.STORE_STATE, source.rootKey, source.rootValue); err != nil {
+			return nil, err
+		}
+	}
+	manifest.StateDigest = hasher.Sum()
+
+	if err := writer.Finish(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func exportIterator(writer *snapshot.Writer, store byte, iter scom.StoreIterator, hasher *snapshot.StateHasher) error {
+	for iter.Next() {
+		if hasher != nil {
+			hasher.Add(iter.Key(), iter.Value())
+		}
+		if err := writer.Add(store, iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return iter.Error()
+}
+
+//ImportSnapshot replace the ledger with the snapshot at path. The ledger must only contain the genesis block.
+//The header chain in snapshot is verified from genesis block, and the state entries must match stateDigest,
+//which is trusted by the caller. The ledger is reset to genesis block if import failed.
+func (this *LedgerStoreImp) ImportSnapshot(path string, stateDigest common.Uint256) error {
+	reader, err := snapshot.Open(path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	manifest := reader.Manifest()
+	err = this.checkSnapshotManifest(manifest, stateDigest)
+	if err != nil {
+		return err
+	}
+
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+	if this.closing {
+		return fmt.Errorf("import snapshot error: ledger is closing")
+	}
+	if this.GetCurrentBlockHeight() != 0 {
+		return fmt.Errorf("import snapshot error: ledger is not empty, current block height %d", this.GetCurrentBlockHeight())
+	}
+	genesisBlock, err := this.GetBlockByHeight(0)
+	if err != nil || genesisBlock == nil {
+		return fmt.Errorf("import snapshot error: cannot get genesis block %v", err)
+	}
+	bookkeeperState, err := this.stateStore.GetBookkeeperState()
+	if err != nil {
+		return fmt.Errorf("import snapshot error: GetBookkeeperState error %s", err)
+	}
+
+	err = this.importSnapshot(reader, manifest, genesisBlock.Hash())
+	if err != nil {
+		log.Errorf("import snapshot at height %d error %s, reset ledger to genesis block", manifest.Height, err)
+		if e := this.resetToGenesis(genesisBlock, bookkeeperState.CurrBookkeeper); e != nil {
+			return fmt.Errorf("import snapshot error %s, reset to genesis block error %s", err, e)
+		}
+		return err
+	}
+	log.Infof("import snapshot success, block height %d, block hash %s", manifest.Height, manifest.BlockHash.ToHexString())
+	return nil
+}
+
+func (this *LedgerStoreImp) checkSnapshotManifest(manifest *snapshot.Manifest, stateDigest common.Uint256) error {
+	err := manifest.Verify()
+	if err != nil {
+		return fmt.Errorf("invalid snapshot manifest: %s", err)
+	}
+	if manifest.Height == 0 {
+		return fmt.Errorf("invalid snapshot manifest: snapshot at genesis block")
+	}
+	if manifest.Height < this.stateHashCheckHeight {
+		if manifest.StateTreeSize != 0 {
+			return fmt.Errorf("invalid snapshot manifest: unexpected state tree below height %d", this.stateHashCheckHeight)
+		}
+	} else if manifest.StateTreeSize != manifest.Height-this.stateHashCheckHeight+1 {
+		return fmt.Errorf("invalid snapshot manifest: state tree size %d mismatch height %d", manifest.StateTreeSize, manifest.Height)
+	}
+	if stateDigest == common.UINT256_EMPTY {
+		return fmt.Errorf("trusted state digest is required")
+	}
+	if manifest.StateDigest != stateDigest {
+		return fmt.Errorf("state digest mismatch, expected:%s, got:%s",
+			stateDigest.ToHexString(), manifest.StateDigest.ToHexString())
+	}
+	return nil
+}
+
+func (this *LedgerStoreImp) importSnapshot(reader *snapshot.Reader, manifest *snapshot.Manifest, genesisHash common.Uint256) error {
+	err := this.clearStores()
+	if err != nil {
+		return err
+	}
+	stateRootKey := this.stateStore.genStateMerkleRootKey(manifest.Height)
+	hasher := snapshot.NewStateHasher(manifest.Height, manifest.BlockHash)
+	handler := func(store byte, key, value []byte) error {
+		if len(key) == 0 {
+			return fmt.Errorf("empty key in snapshot")
+		}
+		switch store {
+		case snapshot.STORE_BLOCK:
+			if key[0] != byte(scom.DATA_TRANSACTION) && bytes.IndexByte(snapshotBlockPrefixes, key[0]) < 0 {
+				return fmt.Errorf("unexpected block store key prefix %d", key[0])
+			}
+			this.blockStore.store.BatchPut(key, value)
+		case snapshot.STORE_STATE:
+			if !bytes.Equal(key, stateRootKey) && bytes.IndexByte(snapshotStatePrefixes, key[0]) < 0 {
+				return fmt.Errorf("unexpected state store key prefix %d", key[0])
+			}
+			hasher.Add(key, value)
+			this.stateStore.store.BatchPut(key, value)
+		}
+		return nil
+	}
+	for i := uint32(0); i < reader.ChunkCount(); i++ {
+		data, err := reader.GetChunk(i)
+		if err != nil {
+			return err
+		}
+		this.blockStore.NewBatch()
+		this.stateStore.NewBatch()
+		err = snapshot.DecodeChunk(data, handler)
+		if err != nil {
+			return fmt.Errorf("decode chunk %d error %s", i, err)
+		}
+		err = this.blockStore.CommitTo()
+		if err != nil {
+			return fmt.Errorf("blockStore.CommitTo error %s", err)
+		}
+		err = this.stateStore.CommitTo()
+		if err != nil {
+			return fmt.Errorf("stateStore.CommitTo error %s", err)
+		}
+	}
+
+	if hasher.Sum() != manifest.StateDigest {
+		return fmt.Errorf("state entries mismatch state digest")
+	}
+	err = this.verifySnapshotBlocks(manifest, genesisHash)
+	if err != nil {
+		return err
+	}
+	if manifest.Height >= this.stateHashCheckHeight {
+		root, err := this.stateStore.GetStateMerkleRoot(manifest.Height)
+		if err != nil {
+			return fmt.Errorf("GetStateMerkleRoot error %s", err)
+		}
+		if root != manifest.StateMerkleRoot {
+			return fmt.Errorf("state merkle root mismatch manifest")
+		}
+	}
+
+	this.blockStore.NewBatch()
+	this.blockStore.SaveCurrentBlock(manifest.Height, manifest.BlockHash)
+	err = this.blockStore.CommitTo()
+	if err != nil {
+		return fmt.Errorf("blockStore.CommitTo error %s", err)
+	}
+	//blocks below snapshot height come without transactions
+	err = this.blockStore.SavePrunedHeight(manifest.Height)
+	if err != nil {
+		return fmt.Errorf("SavePrunedHeight error %s", err)
+	}
+	this.eventStore.NewBatch()
+	this.eventStore.SaveCurrentBlock(manifest.Height, manifest.BlockHash)
+	err = this.eventStore.CommitTo()
+	if err != nil {
+		return fmt.Errorf("eventStore.CommitTo error %s", err)
+	}
+	this.stateStore.NewBatch()
+	this.stateStore.SaveCurrentBlock(manifest.Height, manifest.BlockHash)
+	this.stateStore.saveMerkleTree(this.stateStore.genBlockMerkleTreeKey(), manifest.BlockTreeSize, manifest.BlockTreeHashes)
+	if manifest.StateTreeSize > 0 {
+		this.stateStore.saveMerkleTree(this.stateStore.genStateMerkleTreeKey(), manifest.StateTreeSize, manifest.StateTreeHashes)
+	}
+	err = this.stateStore.CommitTo()
+	if err != nil {
+		return fmt.Errorf("stateStore.CommitTo error %s", err)
+	}
+
+	err = this.reloadStores(manifest.Height)
+	if err != nil {
+		return err
+	}
+	// version is saved at last, an interrupted import is cleared at next start
+	return this.initGenesisBlock()
+}
+
+//verifySnapshotBlocks check the imported header chain links to genesis block with valid signatures,
+//and ends at the block and block root recorded in manifest
+func (this *LedgerStoreImp) verifySnapshotBlocks(manifest *snapshot.Manifest, genesisHash common.Uint256) error {
+	blockHash, err := this.blockStore.GetBlockHash(0)
+	if err != nil {
+		return fmt.Errorf("get genesis block hash error %s", err)
+	}
+	if blockHash != genesisHash {
+		return fmt.Errorf("genesis block mismatch, expected:%s, got:%s", genesisHash.ToHexString(), blockHash.ToHexString())
+	}
+	header, err := this.blockStore.GetHeader(genesisHash)
+	if err != nil {
+		return fmt.Errorf("get genesis header error %s", err)
+	}
+	var vbftPeerInfo map[string]uint32
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) == "vbft" {
+		blkInfo, err := vconfig.VbftBlock(header)
+		if err != nil {
+			return err
+		}
+		if blkInfo.NewChainConfig == nil {
+			return fmt.Errorf("genesis block without chain config")
+		}
+		vbftPeerInfo = make(map[string]uint32)
+		for _, p := range blkInfo.NewChainConfig.Peers {
+			vbftPeerInfo[p.ID] = p.Index
+		}
+	}
+
+	prevHash := genesisHash
+	for height := uint32(1); height <= manifest.Height; height++ {
+		blockHash, err = this.blockStore.GetBlockHash(height)
+		if err != nil {
+			return fmt.Errorf("get block hash height:%d error %s", height, err)
+		}
+		header, err = this.blockStore.GetHeader(blockHash)
+		if err != nil {
+			return fmt.Errorf("get header height:%d error %s", height, err)
+		}
+		if header.Height != height || header.PrevBlockHash != prevHash || header.Hash() != blockHash {
+			return fmt.Errorf("header chain broken at height:%d", height)
+		}
+		vbftPeerInfo, err = this.verifyHeader(header, vbftPeerInfo)
+		if err != nil {
+			return fmt.Errorf("verify header height:%d error %s", height, err)
+		}
+		prevHash = blockHash
+	}
+	if prevHash != manifest.BlockHash {
+		return fmt.Errorf("block hash mismatch manifest at height:%d", manifest.Height)
+	}
+	if header.BlockRoot != manifest.BlockTreeRoot() {
+		return fmt.Errorf("block root mismatch manifest at height:%d", manifest.Height)
+	}
+
+	//transactions are only kept for the block at snapshot height
+	_, txHashes, err := this.blockStore.loadHeaderWithTx(prevHash)
+	if err != nil {
+		return err
+	}
+	if common.ComputeMerkleRoot(append([]common.Uint256{}, txHashes...)) != header.TransactionsRoot {
+		return fmt.Errorf("transactions root mismatch at height:%d", manifest.Height)
+	}
+	for _, txHash := range txHashes {
+		tx, _, err := this.blockStore.GetTransaction(txHash)
+		if err != nil {
+			return fmt.Errorf("get transaction %s error %s", txHash.ToHexString(), err)
+		}
+		if tx.Hash() != txHash {
+			return fmt.Errorf("transaction %s hash mismatch", txHash.ToHexString())
+		}
+	}
+	iter := this.blockStore.store.NewIterator([]byte{byte(scom.DATA_TRANSACTION)})
+	count := 0
+	for iter.Next() {
+		count++
+	}
+	iter.Release()
+	if count != len(txHashes) {
+		return fmt.Errorf("unexpected transactions in snapshot")
+	}
+	return iter.Error()
+}
+
+func (this *LedgerStoreImp) clearStores() error {
+	err := this.blockStore.ClearAll()
+	if err != nil {
+		return fmt.Errorf("blockStore.ClearAll error %s", err)
+	}
+	err = this.stateStore.ClearAll()
+	if err != nil {
+		return fmt.Errorf("stateStore.ClearAll error %s", err)
+	}
+	err = this.eventStore.ClearAll()
+	if err != nil {
+		return fmt.Errorf("eventStore.ClearAll error %s", err)
+	}
+	return nil
+}
+
+//reloadStores reset the memory state of ledger to the stores at height
+func (this *LedgerStoreImp) reloadStores(height uint32) error {
+	this.stateStore.closeMerkleHashStore()
+	err := this.stateStore.init(height)
+	if err != nil {
+		return fmt.Errorf("stateStore.init error %s", err)
+	}
+	this.lock.Lock()
+	this.headerCache = make(map[common.Uint256]*types.Header, 0)
+	this.headerIndex = make(map[uint32]common.Uint256)
+	this.storedIndexCount = 0
+	this.currBlockHeight = 0
+	this.currBlockHash = common.Uint256{}
+	this.lock.Unlock()
+	if height == 0 {
+		return nil
+	}
+	err = this.init()
+	if err != nil {
+		return fmt.Errorf("init error %s", err)
+	}
+	return this.loadVbftPeerInfo()
+}
+
+func (this *LedgerStoreImp) resetToGenesis(genesisBlock *types.Block, defaultBookkeeper []keypair.PublicKey) error {
+	err := this.clearStores()
+	if err != nil {
+		return err
+	}
+	err = this.reloadStores(0)
+	if err != nil {
+		return err
+	}
+	return this.InitLedgerStoreWithGenesisBlock(genesisBlock, defaultBookkeeper)
+}
+
+func (this *LedgerStoreImp) snapshotPath(height uint32) string {
+	return filepath.Join(this.snapshotDir, fmt.Sprintf("%d%s", height, SnapshotFileExt))
+}
+
+//loadSnapshot open the latest snapshot produced before restart
+func (this *LedgerStoreImp) loadSnapshot() {
+	files, err := ioutil.ReadDir(this.snapshotDir)
+	if err != nil {
+		return
+	}
+	latest := int64(-1)
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), SnapshotFileExt) {
+			continue
+		}
+		height, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), SnapshotFileExt), 10, 32)
+		if err == nil && int64(height) > latest {
+			latest = int64(height)
+		}
+	}
+	if latest < 0 {
+		return
+	}
+	reader, err := snapshot.Open(this.snapshotPath(uint32(latest)))
+	if err != nil {
+		log.Warnf("load snapshot error %s", err)
+		return
+	}
+	this.setSnapshot(reader)
+}
+
+//trySnapshot produce a snapshot in background every SnapshotInterval blocks
+func (this *LedgerStoreImp) trySnapshot(height uint32) {
+	interval := config.DefConfig.Common.SnapshotInterval
+	if interval == 0 || height == 0 || height%interval != 0 {
+		return
+	}
+	if !atomic.CompareAndSwapUint32(&this.snapshotting, 0, 1) {
+		return
+	}
+	go func() {
+		defer atomic.StoreUint32(&this.snapshotting, 0)
+		err := this.makeSnapshot()
+		if err != nil {
+			log.Errorf("make snapshot error %s", err)
+		}
+	}()
+}
+
+func (this *LedgerStoreImp) makeSnapshot() error {
+	err := os.MkdirAll(this.snapshotDir, 0755)
+	if err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(this.snapshotDir, snapshotTmpFileName)
+	manifest, err := this.ExportSnapshot(tmpPath)
+	if err != nil {
+		return err
+	}
+	path := this.snapshotPath(manifest.Height)
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return err
+	}
+	reader, err := snapshot.Open(path)
+	if err != nil {
+		return err
+	}
+	this.setSnapshot(reader)
+	log.Infof("make snapshot at height %d, chunks %d, state digest %s", manifest.Height, len(manifest.ChunkHashes),
+		manifest.StateDigest.ToHexString())
+	return nil
+}
+
+//setSnapshot replace the served snapshot and remove the older snapshot files
+func (this *LedgerStoreImp) setSnapshot(reader *snapshot.Reader) {
+	this.snapshotLock.Lock()
+	old := this.snapshotReader
+	this.snapshotReader = reader
+	this.snapshotLock.Unlock()
+	if old != nil {
+		old.Close()
+	}
+	current := this.snapshotPath(reader.Manifest().Height)
+	files, err := ioutil.ReadDir(this.snapshotDir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		path := filepath.Join(this.snapshotDir, file.Name())
+		if strings.HasSuffix(file.Name(), SnapshotFileExt) && path != current {
+			os.Remove(path)
+		}
+	}
+}
+
+//GetSnapshotManifest return the manifest of the latest snapshot, nil if no snapshot available
+func (this *LedgerStoreImp) GetSnapshotManifest() *snapshot.Manifest {
+	this.snapshotLock.RLock()
+	defer this.snapshotLock.RUnlock()
+	if this.snapshotReader == nil {
+		return nil
+	}
+	return this.snapshotReader.Manifest()
+}
+
+//GetSnapshotChunk return the chunk of latest snapshot by height and chunk index
+func (this *LedgerStoreImp) GetSnapshotChunk(height, index uint32) ([]byte, error) {
+	this.snapshotLock.RLock()
+	defer this.snapshotLock.RUnlock()
+	if this.snapshotReader == nil || this.snapshotReader.Manifest().Height != height {
+		return nil, fmt.Errorf("snapshot at height %d not available", height)
+	}
+	return this.snapshotReader.GetChunk(index)
+}
+
+func (this *LedgerStoreImp) closeSnapshot() {
+	this.snapshotLock.Lock()
+	defer this.snapshotLock.Unlock()
+	if this.snapshotReader != nil {
+		this.snapshotReader.Close()
+		this.snapshotReader = nil
+	}
+}
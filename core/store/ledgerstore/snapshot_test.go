@@ -0,0 +1,166 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotExportImport(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	src, _ := newSoloLedger(t, filepath.Join(dir, "src"), acc)
+	defer src.Close()
+	for i := 0; i < 5; i++ {
+		addBlock(t, src, makeSoloBlock(t, src, acc))
+	}
+	path := filepath.Join(dir, "test.snap")
+	manifest, err := src.ExportSnapshot(path)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(5), manifest.Height)
+	root, err := src.GetStateMerkleRoot(5)
+	assert.Nil(t, err)
+	assert.Equal(t, root, manifest.StateMerkleRoot)
+
+	dst, _ := newSoloLedger(t, filepath.Join(dir, "dst"), acc)
+	defer dst.Close()
+
+	//untrusted or missing state digest
+	err = dst.ImportSnapshot(path, common.Uint256{1})
+	assert.NotNil(t, err)
+	err = dst.ImportSnapshot(path, common.UINT256_EMPTY)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint32(0), dst.GetCurrentBlockHeight())
+
+	err = dst.ImportSnapshot(path, manifest.StateDigest)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(5), dst.GetCurrentBlockHeight())
+	assert.Equal(t, uint32(5), dst.GetCurrentHeaderHeight())
+	assert.Equal(t, src.GetCurrentBlockHash(), dst.GetCurrentBlockHash())
+	block, err := dst.GetBlockByHeight(5)
+	assert.Nil(t, err)
+	assert.Equal(t, src.GetCurrentBlockHash(), block.Hash())
+	//blocks below snapshot height have no transactions
+	_, err = dst.GetBlockByHeight(4)
+	assert.Equal(t, scom.ErrPruned, err)
+
+	//the imported ledger continues from snapshot
+	block = makeSoloBlock(t, src, acc)
+	time.Sleep(time.Millisecond)
+	assert.Equal(t, addBlock(t, src, block), addBlock(t, dst, block))
+	assert.Equal(t, uint32(6), dst.GetCurrentBlockHeight())
+
+	//non empty ledger refuses import
+	err = dst.ImportSnapshot(path, manifest.StateDigest)
+	assert.NotNil(t, err)
+}
+
+func TestSnapshotImportInvalidChain(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	src, _ := newSoloLedger(t, filepath.Join(dir, "src"), acc)
+	defer src.Close()
+	for i := 0; i < 3; i++ {
+		addBlock(t, src, makeSoloBlock(t, src, acc))
+	}
+	path := filepath.Join(dir, "test.snap")
+	manifest, err := src.ExportSnapshot(path)
+	assert.Nil(t, err)
+
+	//snapshot of another chain
+	other := account.NewAccount("")
+	defer setSoloGenesis(other)()
+	dst, genesisBlock := newSoloLedger(t, filepath.Join(dir, "dst"), other)
+	defer dst.Close()
+	err = dst.ImportSnapshot(path, manifest.StateDigest)
+	assert.NotNil(t, err)
+	assert.Equal(t, uint32(0), dst.GetCurrentBlockHeight())
+	assert.Equal(t, genesisBlock.Hash(), dst.GetCurrentBlockHash())
+	assert.Equal(t, genesisBlock.Hash(), dst.GetCurrentHeaderHash())
+}
+
+func TestSnapshotImportTamperedState(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	src, _ := newSoloLedger(t, filepath.Join(dir, "src"), acc)
+	defer src.Close()
+	addBlock(t, src, makeSoloBlock(t, src, acc))
+	path := filepath.Join(dir, "test.snap")
+	manifest, err := src.ExportSnapshot(path)
+	assert.Nil(t, err)
+
+	//a peer rewrites a state entry and the chunk hashes, keeping the trusted state digest in manifest
+	reader, err := snapshot.Open(path)
+	assert.Nil(t, err)
+	tampered := filepath.Join(dir, "tampered.snap")
+	writer, err := snapshot.NewWriter(tampered)
+	assert.Nil(t, err)
+	changed := false
+	for i := uint32(0); i < reader.ChunkCount(); i++ {
+		data, err := reader.GetChunk(i)
+		assert.Nil(t, err)
+		err = snapshot.DecodeChunk(data, func(store byte, key, value []byte) error {
+			if store == snapshot.STORE_STATE && key[0] == byte(scom.ST_STORAGE) && !changed {
+				value = append([]byte{}, value...)
+				value[len(value)-1]++
+				changed = true
+			}
+			return writer.Add(store, key, value)
+		})
+		assert.Nil(t, err)
+	}
+	reader.Close()
+	assert.True(t, changed)
+	forged := *manifest
+	forged.ChunkHashes = nil
+	assert.Nil(t, writer.Finish(&forged))
+
+	dst, genesisBlock := newSoloLedger(t, filepath.Join(dir, "dst"), acc)
+	defer dst.Close()
+	err = dst.ImportSnapshot(tampered, manifest.StateDigest)
+	assert.NotNil(t, err)
+	assert.Equal(t, genesisBlock.Hash(), dst.GetCurrentBlockHash())
+	err = dst.ImportSnapshot(path, manifest.StateDigest)
+	assert.Nil(t, err)
+}
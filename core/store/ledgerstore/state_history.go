@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+package ledgerstore
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/dnaproject2/DNA/common"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/overlaydb"
+)
+
+//stateUndo is the previous value of a state written by block. Empty value means the state did not exist
+type stateUndo struct {
+	key   []byte
+	value []byte
+}
+
+//SaveStateUndo put the previous values of the states in the write set of block to batch, used to rebuild the states before block
+func (self *StateStore) SaveStateUndo(height uint32, writeSet *overlaydb.MemDB) error {
+	var undos []*stateUndo
+	var err error
+	writeSet.ForEach(func(key, _ []byte) {
+		if err != nil {
+			return
+		}
+		value, e := self.store.Get(key)
+		if e != nil && e != scom.ErrNotFound {
+			err = e
+			return
+		}
+		undos = append(undos, &stateUndo{key: key, value: value})
+	})
+	if err != nil {
+		return err
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(undos)))
+	for _, undo := range undos {
+		sink.WriteVarBytes(undo.key)
+		sink.WriteVarBytes(undo.value)
+	}
+	self.store.BatchPut(self.genStateUndoKey(height), sink.Bytes())
+	return nil
+}
+
+//DeleteStateUndo delete the undo record of block in batch
+func (self *StateStore) DeleteStateUndo(height uint32) {
+	self.store.BatchDelete(self.genStateUndoKey(height))
+}
+
+//HasStateUndo return whether the undo record of block is kept
+func (self *StateStore) HasStateUndo(height uint32) bool {
+	has, err := self.store.Has(self.genStateUndoKey(height))
+	return err == nil && has
+}
+
+func (self *StateStore) getStateUndo(height uint32) ([]*stateUndo, error) {
+	value, err := self.store.Get(self.genStateUndoKey(height))
+	if err != nil {
+		return nil, err
+	}
+	source := common.NewZeroCopySource(value)
+	count, _, irregular, eof := source.NextVarUint()
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	undos := make([]*stateUndo, 0, count)
+	for i := uint64(0); i < count; i++ {
+		key, _, irregular, eof := source.NextVarBytes()
+		if irregular {
+			return nil, common.ErrIrregularData
+		}
+		value, _, irregular, eof2 := source.NextVarBytes()
+		if irregular {
+			return nil, common.ErrIrregularData
+		}
+		if eof || eof2 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		undos = append(undos, &stateUndo{key: key, value: value})
+	}
+	return undos, nil
+}
+
+//NewHistoryOverlayDB return the overlay of the states before block at height, by reverting the blocks from height with
+//their undo records. The height of the last block reverted is returned
+func (self *StateStore) NewHistoryOverlayDB(height uint32) (*overlaydb.OverlayDB, uint32, error) {
+	var blocks [][]*stateUndo
+	for h := height; ; h++ {
+		undos, err := self.getStateUndo(h)
+		if err == scom.ErrNotFound {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		blocks = append(blocks, undos)
+	}
+	if len(blocks) == 0 {
+		return nil, 0, scom.ErrNoStateHistory
+	}
+	overlay := self.NewOverlayDB()
+	//revert from the latest block, so the value before block at height is kept
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for _, undo := range blocks[i] {
+			if len(undo.value) == 0 {
+				overlay.Delete(undo.key)
+			} else {
+				overlay.Put(undo.key, undo.value)
+			}
+		}
+	}
+	return overlay, height + uint32(len(blocks)) - 1, nil
+}
+
+func (self *StateStore) genStateUndoKey(height uint32) []byte {
+	key := make([]byte, 5, 5)
+	key[0] = byte(scom.DATA_STATE_UNDO)
+	binary.LittleEndian.PutUint32(key[1:], height)
+	return key
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+)
+
+//applyStateOverride replace the execution config and the states in cache by override
+func applyStateOverride(config *smartcontract.Config, cache *storage.CacheDB, override *sstate.StateOverride) error {
+	if override.Height != 0 {
+		config.Height = override.Height
+	}
+	if override.Time != 0 {
+		config.Time = override.Time
+	}
+	for _, balance := range override.Balances {
+		cache.Put(ont.GenBalanceKey(balance.Asset, balance.Address), utils.GenUInt64StorageItem(balance.Balance).ToArray())
+	}
+	for _, item := range override.Storage {
+		key := make([]byte, 0, len(item.Contract)+len(item.Key))
+		key = append(key, item.Contract[:]...)
+		key = append(key, item.Key...)
+		if item.Value == nil {
+			cache.Delete(key)
+		} else {
+			cache.Put(key, states.GenRawStorageItem(item.Value))
+		}
+	}
+	for _, code := range override.Code {
+		contract, err := cache.GetContract(code.Contract)
+		if err != nil {
+			return err
+		}
+		if contract == nil {
+			contract = &payload.DeployCode{NeedStorage: true}
+		}
+		contract.Code = code.Code
+		if err := cache.PutContractAt(code.Contract, contract); err != nil {
+			return err
+		}
+	}
+	return nil
+}
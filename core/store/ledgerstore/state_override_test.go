@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	nutils "github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/stretchr/testify/assert"
+)
+
+func newInvokeCodeTx(t *testing.T, nonce uint32, code []byte) *types.Transaction {
+	return newTx(t, nonce, types.Invoke, &payload.InvokeCode{Code: code})
+}
+
+func TestPreExecuteWithOverride(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "override")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	//getter return the storage value of key argument
+	sink := common.NewZeroCopySink(nil)
+	for _, name := range []string{"System.Storage.GetContext", "System.Storage.Get"} {
+		sink.WriteByte(byte(neovm.SYSCALL))
+		sink.WriteVarBytes([]byte(name))
+	}
+	sink.WriteByte(byte(neovm.RET))
+	deploy := &payload.DeployCode{Code: sink.Bytes(), NeedStorage: true, Name: "getter"}
+	getter := deploy.Address()
+	addBlock(t, store, makeSoloBlock(t, store, acc, newTx(t, 0, types.Deploy, deploy)))
+
+	preExec := func(tx *types.Transaction, override *sstate.StateOverride) interface{} {
+		result, err := store.PreExecuteContractWithOverride(tx, override)
+		assert.Nil(t, err)
+		assert.Equal(t, byte(event.CONTRACT_STATE_SUCCESS), result.State)
+		return result.Result
+	}
+	get := newIncreaseTx(t, 1, getter, "a")
+	assert.Equal(t, "", preExec(get, nil))
+
+	//storage
+	override := &sstate.StateOverride{Storage: []*sstate.StorageOverride{{Contract: getter, Key: []byte("a"), Value: []byte{42}}}}
+	assert.Equal(t, "2a", preExec(get, override))
+	assert.Equal(t, "", preExec(get, nil))
+
+	//code of deployed and undeployed contract
+	override = &sstate.StateOverride{Code: []*sstate.CodeOverride{{Contract: getter, Code: []byte{byte(neovm.PUSH7), byte(neovm.RET)}}}}
+	assert.Equal(t, "07", preExec(get, override))
+	override.Code[0].Contract = common.Address{9}
+	assert.Equal(t, "07", preExec(newIncreaseTx(t, 2, common.Address{9}, "a"), override))
+	assert.Equal(t, "", preExec(get, nil))
+
+	//balance
+	holder := common.Address{8}
+	code, err := utils.BuildNativeInvokeCode(nutils.OngContractAddress, 0, "balanceOf", []interface{}{holder[:]})
+	assert.Nil(t, err)
+	balanceOf := newInvokeCodeTx(t, 3, code)
+	assert.Equal(t, "", preExec(balanceOf, nil))
+	override = &sstate.StateOverride{Balances: []*sstate.BalanceOverride{{Asset: nutils.OngContractAddress, Address: holder, Balance: 12345}}}
+	assert.Equal(t, common.ToHexString(common.BigIntToNeoBytes(big.NewInt(12345))), preExec(balanceOf, override))
+
+	//block time
+	builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+	builder.Emit(neovm.SYSCALL)
+	builder.EmitPushByteArray([]byte("System.Runtime.GetTime"))
+	getTime := newInvokeCodeTx(t, 4, builder.ToArray())
+	override = &sstate.StateOverride{Time: 1000}
+	assert.Equal(t, common.ToHexString(common.BigIntToNeoBytes(big.NewInt(1000))), preExec(getTime, override))
+}
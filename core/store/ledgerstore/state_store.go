@@ -56,7 +56,7 @@ type StateStore struct {
 //NewStateStore return state store instance
 func NewStateStore(dbDir, merklePath string, stateHashCheckHeight uint32) (*StateStore, error) {
 	var err error
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := openPersistStore(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -410,9 +410,25 @@ func (self *StateStore) ClearAll() error {
 	return self.store.BatchCommit()
 }
 
+func (self *StateStore) saveMerkleTree(key []byte, treeSize uint32, hashes []common.Uint256) {
+	value := common.NewZeroCopySink(make([]byte, 0, 4+len(hashes)*common.UINT256_SIZE))
+	value.WriteUint32(treeSize)
+	for _, hash := range hashes {
+		value.WriteHash(hash)
+	}
+	self.store.BatchPut(key, value.Bytes())
+}
+
+func (self *StateStore) closeMerkleHashStore() {
+	if self.merkleHashStore != nil {
+		self.merkleHashStore.Close()
+		self.merkleHashStore = nil
+	}
+}
+
 //Close state store
 func (self *StateStore) Close() error {
-	self.merkleHashStore.Close()
+	self.closeMerkleHashStore()
 	return self.store.Close()
 }
 
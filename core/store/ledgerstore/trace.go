@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
+)
+
+const TRACE_RETRY_TIMES = 3 //Retry times of tracing when new block saved during tracing
+
+//TraceTransaction re-execute the transaction against the states before it in its block, and return the execution trace.
+//The previous states of the block must be kept by config TraceHistoryBlocks
+func (this *LedgerStoreImp) TraceTransaction(txHash common.Uint256) (*trace.TxTrace, error) {
+	tx, height, err := this.GetTransaction(txHash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < TRACE_RETRY_TIMES; i++ {
+		current := this.GetCurrentBlockHeight()
+		overlay, last, err := this.stateStore.NewHistoryOverlayDB(height)
+		if err != nil {
+			return nil, err
+		}
+		if last < current {
+			return nil, scom.ErrNoStateHistory
+		}
+		cache := storage.NewCacheDB(overlay)
+		for _, prev := range block.Transactions {
+			if prev.Hash() == txHash {
+				break
+			}
+			cache.Reset()
+			if _, err := this.handleTransaction(overlay, cache, block, prev); err != nil {
+				return nil, err
+			}
+		}
+		result, err := this.traceTransaction(overlay, block, tx)
+		if err != nil {
+			return nil, err
+		}
+		//the states read may be changed by the block saved during tracing
+		if !this.stateStore.HasStateUndo(last + 1) {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("trace transaction %s failed: states changed by new blocks", txHash.ToHexString())
+}
+
+func (this *LedgerStoreImp) traceTransaction(overlay *overlaydb.OverlayDB, block *types.Block, tx *types.Transaction) (*trace.TxTrace, error) {
+	tracer := trace.NewStructTracer()
+	cache := storage.NewCacheDB(overlay)
+	cache.SetTracer(tracer)
+	notify := &event.ExecuteNotify{TxHash: tx.Hash(), State: event.CONTRACT_STATE_FAIL}
+	var err error
+	switch tx.TxType {
+	case types.Deploy:
+		err = this.stateStore.HandleDeployTransaction(this, overlay, cache, tx, block, notify)
+	case types.Invoke:
+		err = this.stateStore.handleInvokeTransaction(this, overlay, cache, tx, block, notify, tracer)
+	}
+	if overlay.Error() != nil {
+		return nil, overlay.Error()
+	}
+	result := tracer.Trace()
+	result.TxHash = notify.TxHash
+	result.Height = block.Header.Height
+	result.State = notify.State
+	result.GasConsumed = notify.GasConsumed
+	result.Notify = notify.Notify
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+//TracePreExecute pre-execute the transaction against the current states, and return the execution trace
+func (this *LedgerStoreImp) TracePreExecute(tx *types.Transaction) (*trace.TxTrace, error) {
+	if tx.TxType != types.Invoke {
+		return nil, fmt.Errorf("transaction type %d not traceable", tx.TxType)
+	}
+	tracer := trace.NewStructTracer()
+	stf, err := this.preExecuteContract(tx, nil, tracer)
+	result := tracer.Trace()
+	result.TxHash = tx.Hash()
+	result.Height = this.GetCurrentBlockHeight() + 1
+	result.State = stf.State
+	result.GasConsumed = stf.Gas
+	result.Notify = stf.Notify
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
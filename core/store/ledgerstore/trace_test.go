@@ -0,0 +1,135 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/states"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertCounterStorage(t *testing.T, result *trace.TxTrace, contract common.Address, before, after int64) {
+	assert.Equal(t, 2, len(result.Storage))
+	for i, expect := range []struct {
+		op    string
+		value int64
+	}{{storage.STORAGE_GET, before}, {storage.STORAGE_PUT, after}} {
+		log := result.Storage[i]
+		assert.Equal(t, expect.op, log.Op)
+		assert.Equal(t, contract.ToHexString(), log.Contract)
+		assert.Equal(t, common.ToHexString([]byte("a")), log.Key)
+		var value []byte
+		if log.Value != "" {
+			raw, err := common.HexToBytes(log.Value)
+			assert.Nil(t, err)
+			value, err = states.GetValueFromRawStorageItem(raw)
+			assert.Nil(t, err)
+		}
+		if expect.value == 0 {
+			assert.Equal(t, 0, len(value))
+		} else {
+			assert.Equal(t, common.BigIntToNeoBytes(big.NewInt(expect.value)), value)
+		}
+	}
+}
+
+func TestTraceTransaction(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+	keep := config.DefConfig.Common.TraceHistoryBlocks
+	config.DefConfig.Common.TraceHistoryBlocks = 3
+	defer func() { config.DefConfig.Common.TraceHistoryBlocks = keep }()
+
+	dir, err := ioutil.TempDir("", "trace")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	deploy := &payload.DeployCode{Code: counterCode(), NeedStorage: true, Name: "counter"}
+	contract := deploy.Address()
+	tx1 := newIncreaseTx(t, 1, contract, "a")
+	tx2 := newIncreaseTx(t, 2, contract, "a")
+	tx3 := newIncreaseTx(t, 3, contract, "a")
+	addBlock(t, store, makeSoloBlock(t, store, acc, newTx(t, 0, types.Deploy, deploy), tx1))
+	addBlock(t, store, makeSoloBlock(t, store, acc, tx2, tx3))
+
+	result, err := store.TraceTransaction(tx1.Hash())
+	assert.Nil(t, err)
+	assertCounterStorage(t, result, contract, 0, 1)
+
+	result, err = store.TraceTransaction(tx3.Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, tx3.Hash(), result.TxHash)
+	assert.Equal(t, uint32(2), result.Height)
+	assert.Equal(t, byte(event.CONTRACT_STATE_SUCCESS), result.State)
+	assertCounterStorage(t, result, contract, 2, 3)
+	assert.Equal(t, 1, len(result.Calls))
+	assert.Equal(t, context.NEOVM_CONTRACT, result.Calls[0].Type)
+	assert.Equal(t, 1, len(result.Calls[0].Calls))
+	assert.Equal(t, contract.ToHexString(), result.Calls[0].Calls[0].Contract)
+	var syscalls []string
+	for _, op := range result.Ops {
+		if op.Op == "SYSCALL" {
+			syscalls = append(syscalls, op.Syscall)
+		}
+	}
+	assert.Equal(t, []string{"System.Storage.GetContext", "System.Storage.Get",
+		"System.Storage.GetContext", "System.Storage.Put"}, syscalls)
+
+	//pre-execute against the current states
+	result, err = store.TracePreExecute(newIncreaseTx(t, 4, contract, "a"))
+	assert.Nil(t, err)
+	assert.Equal(t, byte(event.CONTRACT_STATE_SUCCESS), result.State)
+	assertCounterStorage(t, result, contract, 3, 4)
+
+	//failed transaction
+	result, err = store.TracePreExecute(newIncreaseTx(t, 5, common.Address{1}, "a"))
+	assert.Nil(t, err)
+	assert.Equal(t, byte(event.CONTRACT_STATE_FAIL), result.State)
+	assert.NotEqual(t, "", result.Error)
+
+	_, err = store.TraceTransaction(common.Uint256{1})
+	assert.Equal(t, scom.ErrNotFound, err)
+
+	//state history of block 1 is deleted at block 4
+	addBlock(t, store, makeSoloBlock(t, store, acc))
+	addBlock(t, store, makeSoloBlock(t, store, acc))
+	_, err = store.TraceTransaction(tx1.Hash())
+	assert.Equal(t, scom.ErrNoStateHistory, err)
+	result, err = store.TraceTransaction(tx3.Hash())
+	assert.Nil(t, err)
+	assertCounterStorage(t, result, contract, 2, 3)
+}
@@ -35,10 +35,14 @@ import (
 	"github.com/dnaproject2/DNA/core/types"
 	"github.com/dnaproject2/DNA/errors"
 	"github.com/dnaproject2/DNA/smartcontract"
+	"github.com/dnaproject2/DNA/smartcontract/context"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
 	ninit "github.com/dnaproject2/DNA/smartcontract/service/native/init"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/proposal"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
 	"github.com/dnaproject2/DNA/smartcontract/service/neovm"
 	"github.com/dnaproject2/DNA/smartcontract/storage"
@@ -90,7 +94,7 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, overlay
 
 		}
 		gasConsumed = gasLimit * tx.GasPrice
-		notifies, err = chargeCostGas(tx.Payer, gasConsumed, config, cache, store)
+		notifies, err = chargeCostGas(tx.Payer, gasConsumed, config, overlay, cache, store)
 		if err != nil {
 			return err
 		}
@@ -106,6 +110,12 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, overlay
 	}
 	if dep == nil {
 		cache.PutContract(deploy)
+		if registry.Enabled(block.Header.Height) {
+			version := registry.NewContractVersion(deploy, block.Header.Height, tx.Hash())
+			if err := registry.RecordDeploy(cache, address, tx.Payer, version); err != nil {
+				return err
+			}
+		}
 	}
 	cache.Commit()
 
@@ -118,6 +128,12 @@ func (self *StateStore) HandleDeployTransaction(store store.LedgerStore, overlay
 //HandleInvokeTransaction deal with smart contract invoke transaction
 func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay *overlaydb.OverlayDB, cache *storage.CacheDB,
 	tx *types.Transaction, block *types.Block, notify *event.ExecuteNotify) error {
+	return self.handleInvokeTransaction(store, overlay, cache, tx, block, notify, nil)
+}
+
+//handleInvokeTransaction execute the invoke transaction, and notify the execution steps to tracer if not nil
+func (self *StateStore) handleInvokeTransaction(store store.LedgerStore, overlay *overlaydb.OverlayDB, cache *storage.CacheDB,
+	tx *types.Transaction, block *types.Block, notify *event.ExecuteNotify, tracer context.Tracer) error {
 	invoke := tx.Payload.(*payload.InvokeCode)
 	code := invoke.Code
 	sysTransFlag := bytes.Compare(code, ninit.COMMIT_DPOS_BYTES) == 0 || block.Header.Height == 0
@@ -193,6 +209,7 @@ func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay
 		CacheDB: cache,
 		Store:   store,
 		Gas:     availableGasLimit - codeLenGasLimit,
+		Tracer:  tracer,
 	}
 
 	//start the smart contract executive function
@@ -229,7 +246,7 @@ func (self *StateStore) HandleInvokeTransaction(store store.LedgerStore, overlay
 			return fmt.Errorf("gas insufficient, balance:%d < costGas:%d", newBalance, costGas)
 		}
 
-		notifies, err = chargeCostGas(tx.Payer, costGas, config, sc.CacheDB, store)
+		notifies, err = chargeCostGas(tx.Payer, costGas, config, overlay, sc.CacheDB, store)
 		if err != nil {
 			return err
 		}
@@ -272,8 +289,14 @@ func isBalanceSufficient(payer common.Address, cache *storage.CacheDB, config *s
 	return balance, nil
 }
 
-func chargeCostGas(payer common.Address, gas uint64, config *smartcontract.Config,
+//chargeCostGas transfer the gas fee from payer to governance contract. In parallel execution the fee accounting is left
+//out of conflict detection, and applied in block order when merging the result of transaction
+func chargeCostGas(payer common.Address, gas uint64, config *smartcontract.Config, overlay *overlaydb.OverlayDB,
 	cache *storage.CacheDB, store store.LedgerStore) ([]*event.NotifyEventInfo, error) {
+	if reads, ok := overlay.GetStore().(*readRecorder); ok {
+		reads.chargingFee = true
+		defer func() { reads.chargingFee = false }()
+	}
 
 	params := genNativeTransferCode(payer, utils.GovernanceContractAddress, gas)
 
@@ -335,6 +358,45 @@ func refreshGlobalParam(config *smartcontract.Config, cache *storage.CacheDB, st
 	return nil
 }
 
+//executeDueProposals execute the passed proposals whose timelock expired at the end of block. Each proposal is
+//executed in its own cache, a failed one is dropped from pending list without affecting others
+func executeDueProposals(config *smartcontract.Config, overlay *overlaydb.OverlayDB, store store.LedgerStore) error {
+	newService := func(cache *storage.CacheDB) *native.NativeService {
+		sc := smartcontract.SmartContract{
+			Config:  config,
+			CacheDB: cache,
+			Store:   store,
+			Gas:     math.MaxUint64,
+		}
+		service, _ := sc.NewNativeService()
+		return service
+	}
+
+	cache := storage.NewCacheDB(overlay)
+	ids, err := proposal.DueProposals(newService(cache))
+	if err != nil {
+		return fmt.Errorf("get due proposals error:%s", err)
+	}
+	cache.Commit()
+	for _, id := range ids {
+		sink := common.NewZeroCopySink(nil)
+		utils.EncodeVarUint(sink, id)
+		cache = storage.NewCacheDB(overlay)
+		if _, err := newService(cache).NativeCall(utils.ProposalContractAddress, proposal.EXECUTE_NAME,
+			sink.Bytes()); err != nil {
+			log.Warnf("[executeDueProposals] execute proposal %d at height %d error:%s", id, config.Height, err)
+			cache = storage.NewCacheDB(overlay)
+			if err := proposal.DropProposal(newService(cache), id); err != nil {
+				return fmt.Errorf("drop proposal %d error:%s", id, err)
+			}
+		} else {
+			log.Infof("[executeDueProposals] proposal %d executed at height %d", id, config.Height)
+		}
+		cache.Commit()
+	}
+	return overlay.Error()
+}
+
 func getBalanceFromNative(config *smartcontract.Config, cache *storage.CacheDB, store store.LedgerStore, address common.Address) (uint64, error) {
 	bf := new(bytes.Buffer)
 	if err := utils.WriteAddress(bf, address); err != nil {
@@ -358,7 +420,7 @@ func getBalanceFromNative(config *smartcontract.Config, cache *storage.CacheDB,
 func costInvalidGas(address common.Address, gas uint64, config *smartcontract.Config, overlay *overlaydb.OverlayDB,
 	store store.LedgerStore, notify *event.ExecuteNotify) error {
 	cache := storage.NewCacheDB(overlay)
-	notifies, err := chargeCostGas(address, gas, config, cache, store)
+	notifies, err := chargeCostGas(address, gas, config, overlay, cache, store)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/core/types"
+	cutils "github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/auth"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+//newAdminContractTxs return the transactions to deploy and invoke a contract, which sets its admin by calling auth
+//contract
+func newAdminContractTxs(t *testing.T, nonce uint32, admin []byte) (common.Address, []*types.Transaction) {
+	initCode, err := cutils.BuildNativeInvokeCode(utils.AuthContractAddress, 0, "initContractAdmin",
+		[]interface{}{&auth.InitContractAdminParam{AdminOntID: admin}})
+	assert.Nil(t, err)
+	deploy := &payload.DeployCode{Code: initCode, Name: "authed"}
+	contract := deploy.Address()
+	builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+	builder.EmitPushCall(contract[:])
+	return contract, []*types.Transaction{newTx(t, nonce, types.Deploy, deploy),
+		newTx(t, nonce+1, types.Invoke, &payload.InvokeCode{Code: builder.ToArray()})}
+}
+
+func newUpgradeTx(t *testing.T, nonce uint32, param *auth.UpgradeContractParam, signer *account.Account) *types.Transaction {
+	code, err := cutils.BuildNativeInvokeCode(utils.AuthContractAddress, 0, "upgradeContract", []interface{}{param})
+	assert.Nil(t, err)
+	//upgrade costs the gas of deploying code
+	return newSignedTxWithGas(t, nonce, types.Invoke, &payload.InvokeCode{Code: code}, signer, 30000000)
+}
+
+func assertCounter(t *testing.T, store *LedgerStoreImp, contract common.Address, key string, value int64) {
+	item, err := store.GetStorageItem(&states.StorageKey{ContractAddress: contract, Key: []byte(key)})
+	assert.Nil(t, err)
+	assert.Equal(t, common.BigIntToNeoBytes(big.NewInt(value)), item.Value)
+}
+
+//contextCounterCode increase the counter as counterCode, with the storage context got from the executing contract
+func contextCounterCode() []byte {
+	sink := common.NewZeroCopySink(nil)
+	syscall := func(name string) {
+		sink.WriteByte(byte(neovm.SYSCALL))
+		sink.WriteVarBytes([]byte(name))
+	}
+	storageContext := func() {
+		syscall("System.ExecutionEngine.GetExecutingScriptHash")
+		syscall("System.Blockchain.GetContract")
+		syscall("System.Contract.GetStorageContext")
+	}
+	sink.WriteByte(byte(neovm.DUP))
+	storageContext()
+	syscall("System.Storage.Get")
+	sink.WriteByte(byte(neovm.INC))
+	sink.WriteByte(byte(neovm.SWAP))
+	storageContext()
+	syscall("System.Storage.Put")
+	sink.WriteByte(byte(neovm.RET))
+	return sink.Bytes()
+}
+
+//TestUpgradedStorageContext runs on a ledger, since the contract got by the executing script hash is read from the
+//ledger store
+func TestUpgradedStorageContext(t *testing.T) {
+	acc := account.NewAccount("")
+	defer setSoloGenesis(acc)()
+
+	dir, err := ioutil.TempDir("", "upgrade")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, _ := newSoloLedger(t, filepath.Join(dir, "ledger"), acc)
+	defer store.Close()
+
+	owner := account.NewAccount("")
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	regID := newOntIDTx(t, 0, "regIDWithPublicKey", &struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(id), keypair.SerializePublicKey(owner.PublicKey)}, owner)
+	contract, adminTxs := newAdminContractTxs(t, 1, []byte(id))
+	addBlock(t, store, makeSoloBlock(t, store, acc, append([]*types.Transaction{regID}, adminTxs...)...))
+
+	//the storage context of upgraded contract is at its address rather than its code hash
+	addBlock(t, store, makeSoloBlock(t, store, acc, newUpgradeTx(t, 3, &auth.UpgradeContractParam{
+		ContractAddr: contract,
+		Code:         contextCounterCode(),
+		NeedStorage:  true,
+		Version:      "2.0",
+		KeyNo:        1,
+	}, owner)))
+	addBlock(t, store, makeSoloBlock(t, store, acc, newIncreaseTx(t, 4, contract, "a")))
+	addBlock(t, store, makeSoloBlock(t, store, acc, newIncreaseTx(t, 5, contract, "a")))
+	assertCounter(t, store, contract, "a", 2)
+
+	item, err := store.GetStorageItem(&states.StorageKey{
+		ContractAddress: utils.RegistryContractAddress,
+		Key:             registry.VersionsSubKey(contract),
+	})
+	assert.Nil(t, err)
+	versions, err := registry.DecodeVersions(item.Value)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(versions.Versions))
+	assert.Equal(t, adminTxs[0].Hash(), versions.Versions[0].TxHash)
+	assert.Equal(t, "2.0", versions.Versions[1].Version)
+}
@@ -39,6 +39,15 @@ type LevelDBStore struct {
 // too small will lead to high false positive rate.
 const BITSPERKEY = 10
 
+//Name of leveldb backend in store backend registry
+const BACKEND_NAME = "leveldb"
+
+func init() {
+	common.RegisterBackend(BACKEND_NAME, func(path string) (common.PersistStore, error) {
+		return NewLevelDBStore(path)
+	})
+}
+
 //NewLevelDBStore return LevelDBStore instance
 func NewLevelDBStore(file string) (*LevelDBStore, error) {
 	openFileCache := opt.DefaultOpenFilesCacheCapacity
@@ -0,0 +1,235 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package memorystore provides the in-memory persist store backend, which is used by tests. It is not imported by
+//ledger, so a node can not be started on it and lose the chain on restart
+package memorystore
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dnaproject2/DNA/core/store/common"
+)
+
+//Name of memory backend in store backend registry
+const BACKEND_NAME = "memory"
+
+var errClosed = errors.New("memory store closed")
+
+func init() {
+	common.RegisterBackend(BACKEND_NAME, func(path string) (common.PersistStore, error) {
+		return NewMemoryStore(), nil
+	})
+}
+
+type batchOp struct {
+	key    string
+	value  []byte
+	delete bool
+}
+
+//MemoryStore keep all the key-value pairs in memory. Data is lost after close
+type MemoryStore struct {
+	lock   sync.RWMutex
+	data   map[string][]byte
+	batch  []batchOp
+	closed bool
+}
+
+//NewMemoryStore return MemoryStore instance
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: make(map[string][]byte),
+	}
+}
+
+//Put a key-value pair to store
+func (self *MemoryStore) Put(key []byte, value []byte) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if self.closed {
+		return errClosed
+	}
+	self.data[string(key)] = copyBytes(value)
+	return nil
+}
+
+//Get the value of a key from store
+func (self *MemoryStore) Get(key []byte) ([]byte, error) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	if self.closed {
+		return nil, errClosed
+	}
+	value, ok := self.data[string(key)]
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	return copyBytes(value), nil
+}
+
+//Has return whether the key is exist in store
+func (self *MemoryStore) Has(key []byte) (bool, error) {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	if self.closed {
+		return false, errClosed
+	}
+	_, ok := self.data[string(key)]
+	return ok, nil
+}
+
+//Delete the key in store
+func (self *MemoryStore) Delete(key []byte) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if self.closed {
+		return errClosed
+	}
+	delete(self.data, string(key))
+	return nil
+}
+
+//NewBatch start commit batch
+func (self *MemoryStore) NewBatch() {
+	self.batch = make([]batchOp, 0)
+}
+
+//BatchPut put a key-value pair to batch
+func (self *MemoryStore) BatchPut(key []byte, value []byte) {
+	self.batch = append(self.batch, batchOp{key: string(key), value: copyBytes(value)})
+}
+
+//BatchDelete delete a key to batch
+func (self *MemoryStore) BatchDelete(key []byte) {
+	self.batch = append(self.batch, batchOp{key: string(key), delete: true})
+}
+
+//BatchCommit commit batch to store atomically
+func (self *MemoryStore) BatchCommit() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if self.closed {
+		return errClosed
+	}
+	for _, op := range self.batch {
+		if op.delete {
+			delete(self.data, op.key)
+		} else {
+			self.data[op.key] = op.value
+		}
+	}
+	self.batch = nil
+	return nil
+}
+
+//Close store
+func (self *MemoryStore) Close() error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.closed = true
+	self.data = nil
+	return nil
+}
+
+//NewIterator return a iterator of the snapshot of store with the key prefix, in key order
+func (self *MemoryStore) NewIterator(prefix []byte) common.StoreIterator {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	iter := &memoryIterator{index: -1}
+	if self.closed {
+		iter.err = errClosed
+		return iter
+	}
+	p := string(prefix)
+	for key, value := range self.data {
+		if strings.HasPrefix(key, p) {
+			iter.keys = append(iter.keys, []byte(key))
+			iter.values = append(iter.values, value)
+		}
+	}
+	sort.Sort(iter)
+	return iter
+}
+
+type memoryIterator struct {
+	keys   [][]byte
+	values [][]byte
+	index  int
+	err    error
+}
+
+func (self *memoryIterator) Len() int {
+	return len(self.keys)
+}
+
+func (self *memoryIterator) Less(i, j int) bool {
+	return bytes.Compare(self.keys[i], self.keys[j]) < 0
+}
+
+func (self *memoryIterator) Swap(i, j int) {
+	self.keys[i], self.keys[j] = self.keys[j], self.keys[i]
+	self.values[i], self.values[j] = self.values[j], self.values[i]
+}
+
+func (self *memoryIterator) Next() bool {
+	if self.index < len(self.keys) {
+		self.index++
+	}
+	return self.index < len(self.keys)
+}
+
+func (self *memoryIterator) First() bool {
+	self.index = 0
+	return len(self.keys) > 0
+}
+
+func (self *memoryIterator) Key() []byte {
+	if self.index < 0 || self.index >= len(self.keys) {
+		return nil
+	}
+	return self.keys[self.index]
+}
+
+func (self *memoryIterator) Value() []byte {
+	if self.index < 0 || self.index >= len(self.keys) {
+		return nil
+	}
+	return self.values[self.index]
+}
+
+func (self *memoryIterator) Release() {
+	self.keys = nil
+	self.values = nil
+	self.index = -1
+}
+
+func (self *memoryIterator) Error() error {
+	return self.err
+}
+
+func copyBytes(data []byte) []byte {
+	if data == nil {
+		return []byte{}
+	}
+	return append([]byte{}, data...)
+}
@@ -96,6 +96,11 @@ func (self *OverlayDB) GetWriteSet() *MemDB {
 	return self.memdb
 }
 
+//GetStore return the store backing the overlay
+func (self *OverlayDB) GetStore() common.PersistStore {
+	return self.store
+}
+
 func (self *OverlayDB) ChangeHash() comm.Uint256 {
 	stateDiff := sha256.New()
 	self.memdb.ForEach(func(key, val []byte) {
@@ -0,0 +1,470 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package snapshot implements the file format of ledger state snapshots, used to
+//bootstrap a node at a recent height instead of replaying every block
+package snapshot
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/merkle"
+)
+
+const (
+	SNAPSHOT_VERSION = byte(2)          //Version of snapshot format
+	MAX_CHUNK_SIZE   = 1024 * 512       //Chunk is closed once its entries reach this size
+	MAX_CHUNK_DATA   = 16 * 1024 * 1024 //Max bytes of one chunk, including the last entry
+	MAX_CHUNK_COUNT  = 1 << 20          //Max chunk count of one snapshot
+	TRAILER_SIZE     = 8                //Size of manifest offset at the end of file
+)
+
+//Store a snapshot entry belongs to
+const (
+	STORE_BLOCK = byte(0) //Block store entry, headers and block hash index
+	STORE_STATE = byte(1) //State store entry, contracts, storage and bookkeeper
+)
+
+//Manifest describe a snapshot at a block height. Chunks are verified by ChunkHashes,
+//and the merkle trees are verified against the state merkle root and the block root
+//of the header at Height. The state merkle root only commits to the write sets of blocks,
+//so the state entries are verified by StateDigest, which must be trusted by the importer.
+type Manifest struct {
+	Version         byte
+	Height          uint32
+	BlockHash       common.Uint256
+	StateMerkleRoot common.Uint256
+	StateDigest     common.Uint256
+	StateTreeSize   uint32
+	StateTreeHashes []common.Uint256
+	BlockTreeSize   uint32
+	BlockTreeHashes []common.Uint256
+	ChunkHashes     []common.Uint256
+}
+
+func writeHashes(sink *common.ZeroCopySink, hashes []common.Uint256) {
+	sink.WriteVarUint(uint64(len(hashes)))
+	for _, hash := range hashes {
+		sink.WriteHash(hash)
+	}
+}
+
+func readHashes(source *common.ZeroCopySource, max uint64) ([]common.Uint256, error) {
+	n, _, irregular, eof := source.NextVarUint()
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if n > max || n*common.UINT256_SIZE > source.Len() {
+		return nil, fmt.Errorf("too many hashes:%d", n)
+	}
+	hashes := make([]common.Uint256, 0, n)
+	for i := uint64(0); i < n; i++ {
+		hash, eof := source.NextHash()
+		if eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+func (this *Manifest) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteByte(this.Version)
+	sink.WriteUint32(this.Height)
+	sink.WriteHash(this.BlockHash)
+	sink.WriteHash(this.StateMerkleRoot)
+	sink.WriteHash(this.StateDigest)
+	sink.WriteUint32(this.StateTreeSize)
+	writeHashes(sink, this.StateTreeHashes)
+	sink.WriteUint32(this.BlockTreeSize)
+	writeHashes(sink, this.BlockTreeHashes)
+	writeHashes(sink, this.ChunkHashes)
+}
+
+func (this *Manifest) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	var err error
+	this.Version, eof = source.NextByte()
+	this.Height, eof = source.NextUint32()
+	this.BlockHash, eof = source.NextHash()
+	this.StateMerkleRoot, eof = source.NextHash()
+	this.StateDigest, eof = source.NextHash()
+	this.StateTreeSize, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Version != SNAPSHOT_VERSION {
+		return fmt.Errorf("unsupported snapshot version:%d", this.Version)
+	}
+	this.StateTreeHashes, err = readHashes(source, 32)
+	if err != nil {
+		return err
+	}
+	this.BlockTreeSize, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.BlockTreeHashes, err = readHashes(source, 32)
+	if err != nil {
+		return err
+	}
+	this.ChunkHashes, err = readHashes(source, MAX_CHUNK_COUNT)
+	return err
+}
+
+//StateHasher compute the state digest of snapshot from the height, the block hash and the state entries in
+//the order of snapshot file
+type StateHasher struct {
+	hash hash.Hash
+}
+
+//NewStateHasher return a StateHasher of the snapshot at height
+func NewStateHasher(height uint32, blockHash common.Uint256) *StateHasher {
+	hasher := &StateHasher{hash: sha256.New()}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint32(height)
+	sink.WriteHash(blockHash)
+	hasher.hash.Write(sink.Bytes())
+	return hasher
+}
+
+//Add a state entry to digest
+func (this *StateHasher) Add(key, value []byte) {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(key)
+	sink.WriteVarBytes(value)
+	this.hash.Write(sink.Bytes())
+}
+
+//Sum return the state digest
+func (this *StateHasher) Sum() common.Uint256 {
+	var digest common.Uint256
+	copy(digest[:], this.hash.Sum(nil))
+	return digest
+}
+
+//Hash return the identity of the manifest, peers serving the same snapshot report the same hash
+func (this *Manifest) Hash() common.Uint256 {
+	sink := common.NewZeroCopySink(nil)
+	this.Serialization(sink)
+	return common.Uint256(sha256.Sum256(sink.Bytes()))
+}
+
+//StateTreeRoot return the root of the state merkle tree recorded in the manifest
+func (this *Manifest) StateTreeRoot() common.Uint256 {
+	return merkle.NewTree(this.StateTreeSize, this.StateTreeHashes, nil).Root()
+}
+
+//BlockTreeRoot return the root of the block merkle tree recorded in the manifest
+func (this *Manifest) BlockTreeRoot() common.Uint256 {
+	return merkle.NewTree(this.BlockTreeSize, this.BlockTreeHashes, nil).Root()
+}
+
+//Verify check the manifest is self consistent
+func (this *Manifest) Verify() error {
+	if this.BlockTreeSize != this.Height+1 {
+		return fmt.Errorf("block tree size %d mismatch height %d", this.BlockTreeSize, this.Height)
+	}
+	if this.StateTreeSize > 0 && this.StateTreeRoot() != this.StateMerkleRoot {
+		return errors.New("state merkle root mismatch state tree")
+	}
+	if len(this.ChunkHashes) == 0 {
+		return errors.New("snapshot without chunk")
+	}
+	return nil
+}
+
+//VerifyChunk check the chunk data match the hash of index in manifest
+func (this *Manifest) VerifyChunk(index uint32, data []byte) error {
+	if uint64(index) >= uint64(len(this.ChunkHashes)) {
+		return fmt.Errorf("chunk index %d out of range", index)
+	}
+	if common.Uint256(sha256.Sum256(data)) != this.ChunkHashes[index] {
+		return fmt.Errorf("chunk %d hash mismatch", index)
+	}
+	return nil
+}
+
+//DecodeChunk call fn for every entry in chunk data
+func DecodeChunk(data []byte, fn func(store byte, key, value []byte) error) error {
+	source := common.NewZeroCopySource(data)
+	for source.Len() > 0 {
+		store, eof := source.NextByte()
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+		key, _, irregular, eof := source.NextVarBytes()
+		if irregular {
+			return common.ErrIrregularData
+		}
+		value, _, irregular, eof2 := source.NextVarBytes()
+		if irregular {
+			return common.ErrIrregularData
+		}
+		if eof || eof2 {
+			return io.ErrUnexpectedEOF
+		}
+		if store != STORE_BLOCK && store != STORE_STATE {
+			return fmt.Errorf("unknown snapshot store:%d", store)
+		}
+		if err := fn(store, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Writer create a snapshot file. Entries are packed into chunks, the manifest is
+//written at the end of the file followed by its offset.
+type Writer struct {
+	file        *os.File
+	writer      *bufio.Writer
+	offset      uint64
+	chunk       *common.ZeroCopySink
+	chunkHashes []common.Uint256
+}
+
+//NewWriter create snapshot file at path
+func NewWriter(path string) (*Writer, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		file:   file,
+		writer: bufio.NewWriter(file),
+		chunk:  common.NewZeroCopySink(nil),
+	}, nil
+}
+
+//Add append an entry of store to the snapshot
+func (this *Writer) Add(store byte, key, value []byte) error {
+	this.chunk.WriteByte(store)
+	this.chunk.WriteVarBytes(key)
+	this.chunk.WriteVarBytes(value)
+	if this.chunk.Size() >= MAX_CHUNK_SIZE {
+		return this.flushChunk()
+	}
+	return nil
+}
+
+func (this *Writer) flushChunk() error {
+	if this.chunk.Size() == 0 {
+		return nil
+	}
+	err := this.AddChunk(this.chunk.Bytes())
+	this.chunk = common.NewZeroCopySink(nil)
+	return err
+}
+
+//AddChunk append an encoded chunk, used when the chunks are downloaded from peers
+func (this *Writer) AddChunk(data []byte) error {
+	if len(this.chunkHashes) >= MAX_CHUNK_COUNT {
+		return errors.New("too many chunks")
+	}
+	if len(data) > MAX_CHUNK_DATA {
+		return fmt.Errorf("chunk size %d exceed max %d", len(data), MAX_CHUNK_DATA)
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(data)
+	if _, err := this.writer.Write(sink.Bytes()); err != nil {
+		return err
+	}
+	this.offset += sink.Size()
+	this.chunkHashes = append(this.chunkHashes, common.Uint256(sha256.Sum256(data)))
+	return nil
+}
+
+//Finish write the manifest and close the file. The chunk hashes of manifest are
+//filled by the writer, or checked against the written chunks if already set.
+func (this *Writer) Finish(manifest *Manifest) error {
+	defer this.file.Close()
+	if err := this.flushChunk(); err != nil {
+		return err
+	}
+	if len(manifest.ChunkHashes) == 0 {
+		manifest.ChunkHashes = this.chunkHashes
+	} else if len(manifest.ChunkHashes) != len(this.chunkHashes) {
+		return fmt.Errorf("chunk count %d mismatch manifest %d", len(this.chunkHashes), len(manifest.ChunkHashes))
+	} else {
+		for i, hash := range this.chunkHashes {
+			if hash != manifest.ChunkHashes[i] {
+				return fmt.Errorf("chunk %d hash mismatch", i)
+			}
+		}
+	}
+
+	sink := common.NewZeroCopySink(nil)
+	manifest.Serialization(sink)
+	sink.WriteUint64(this.offset)
+	if _, err := this.writer.Write(sink.Bytes()); err != nil {
+		return err
+	}
+	if err := this.writer.Flush(); err != nil {
+		return err
+	}
+	return this.file.Sync()
+}
+
+//Abort close and remove the unfinished file
+func (this *Writer) Abort() {
+	this.file.Close()
+	os.Remove(this.file.Name())
+}
+
+//Reader give random access to the chunks of a snapshot file
+type Reader struct {
+	file     *os.File
+	manifest *Manifest
+	offsets  []uint64
+}
+
+//Open a snapshot file created by Writer
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("open snapshot %s error:%s", path, err)
+	}
+	return reader, nil
+}
+
+func newReader(file *os.File) (*Reader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(info.Size())
+	if size < TRAILER_SIZE {
+		return nil, io.ErrUnexpectedEOF
+	}
+	buf := make([]byte, TRAILER_SIZE)
+	if _, err := file.ReadAt(buf, int64(size-TRAILER_SIZE)); err != nil {
+		return nil, err
+	}
+	manifestOffset, _ := common.NewZeroCopySource(buf).NextUint64()
+	if manifestOffset > size-TRAILER_SIZE {
+		return nil, errors.New("invalid manifest offset")
+	}
+	buf = make([]byte, size-TRAILER_SIZE-manifestOffset)
+	if _, err := file.ReadAt(buf, int64(manifestOffset)); err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := manifest.Deserialization(common.NewZeroCopySource(buf)); err != nil {
+		return nil, err
+	}
+
+	//index the chunks by scanning their length prefix
+	offsets := make([]uint64, 0, len(manifest.ChunkHashes))
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, int64(manifestOffset)))
+	offset := uint64(0)
+	for range manifest.ChunkHashes {
+		n, size, err := readVarUint(reader)
+		if err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, offset)
+		offset += size + n
+		if offset > manifestOffset {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if _, err := reader.Discard(int(n)); err != nil {
+			return nil, err
+		}
+	}
+	return &Reader{file: file, manifest: manifest, offsets: offsets}, nil
+}
+
+//readVarUint return the var uint and its encoded size
+func readVarUint(reader *bufio.Reader) (uint64, uint64, error) {
+	buf, err := reader.Peek(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	size := 1
+	switch buf[0] {
+	case 0xfd:
+		size = 3
+	case 0xfe:
+		size = 5
+	case 0xff:
+		size = 9
+	}
+	buf = make([]byte, size)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return 0, 0, err
+	}
+	n, _, irregular, eof := common.NewZeroCopySource(buf).NextVarUint()
+	if irregular || eof {
+		return 0, 0, common.ErrIrregularData
+	}
+	return n, uint64(size), nil
+}
+
+//Manifest return the manifest of the snapshot
+func (this *Reader) Manifest() *Manifest {
+	return this.manifest
+}
+
+//ChunkCount return the number of chunks
+func (this *Reader) ChunkCount() uint32 {
+	return uint32(len(this.offsets))
+}
+
+//GetChunk return the verified chunk data of index
+func (this *Reader) GetChunk(index uint32) ([]byte, error) {
+	if index >= this.ChunkCount() {
+		return nil, fmt.Errorf("chunk index %d out of range", index)
+	}
+	reader := bufio.NewReader(io.NewSectionReader(this.file, int64(this.offsets[index]), MAX_CHUNK_DATA+TRAILER_SIZE))
+	n, _, err := readVarUint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if n > MAX_CHUNK_DATA {
+		return nil, fmt.Errorf("chunk %d too large", index)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, err
+	}
+	if err := this.manifest.VerifyChunk(index, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+//Close the snapshot file
+func (this *Reader) Close() error {
+	return this.file.Close()
+}
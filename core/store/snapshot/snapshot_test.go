@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package snapshot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/merkle"
+	"github.com/stretchr/testify/assert"
+)
+
+type entry struct {
+	store byte
+	key   string
+	value string
+}
+
+func TestSnapshotWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test.snap")
+
+	writer, err := NewWriter(path)
+	assert.Nil(t, err)
+	value := make([]byte, 1024)
+	var entries []entry
+	for i := 0; i < 2000; i++ {
+		e := entry{store: byte(i % 2), key: fmt.Sprintf("key%d", i), value: string(value)}
+		entries = append(entries, e)
+		assert.Nil(t, writer.Add(e.store, []byte(e.key), []byte(e.value)))
+	}
+	stateTree := merkle.NewTree(0, nil, nil)
+	stateTree.AppendHash(common.Uint256{1})
+	manifest := &Manifest{
+		Version:         SNAPSHOT_VERSION,
+		Height:          0,
+		StateMerkleRoot: stateTree.Root(),
+		StateTreeSize:   stateTree.TreeSize(),
+		StateTreeHashes: stateTree.Hashes(),
+		BlockTreeSize:   1,
+		BlockTreeHashes: []common.Uint256{{2}},
+	}
+	assert.Nil(t, writer.Finish(manifest))
+	assert.True(t, len(manifest.ChunkHashes) > 1)
+	assert.Nil(t, manifest.Verify())
+
+	reader, err := Open(path)
+	assert.Nil(t, err)
+	defer reader.Close()
+	assert.Equal(t, manifest.Hash(), reader.Manifest().Hash())
+	assert.Equal(t, uint32(len(manifest.ChunkHashes)), reader.ChunkCount())
+
+	var decoded []entry
+	for i := uint32(0); i < reader.ChunkCount(); i++ {
+		data, err := reader.GetChunk(i)
+		assert.Nil(t, err)
+		err = DecodeChunk(data, func(store byte, key, value []byte) error {
+			decoded = append(decoded, entry{store: store, key: string(key), value: string(value)})
+			return nil
+		})
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, entries, decoded)
+
+	data, _ := reader.GetChunk(0)
+	assert.NotNil(t, manifest.VerifyChunk(1, data))
+}
+
+func TestSnapshotCopyChunks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writer, err := NewWriter(filepath.Join(dir, "src.snap"))
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Add(STORE_STATE, []byte("key"), []byte("value")))
+	manifest := &Manifest{Version: SNAPSHOT_VERSION, BlockTreeSize: 1}
+	assert.Nil(t, writer.Finish(manifest))
+
+	copied, err := NewWriter(filepath.Join(dir, "dst.snap"))
+	assert.Nil(t, err)
+	assert.Nil(t, copied.AddChunk([]byte("tampered")))
+	assert.NotNil(t, copied.Finish(manifest))
+}
@@ -23,9 +23,13 @@ import (
 	"github.com/dnaproject2/DNA/core/payload"
 	"github.com/dnaproject2/DNA/core/states"
 	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
 	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract/context"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
 	cstates "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
 	"github.com/ontio/ontology-crypto/keypair"
 )
 
@@ -64,6 +68,16 @@ type LedgerStore interface {
 	GetBookkeeperState() (*states.BookkeeperState, error)
 	GetStorageItem(key *states.StorageKey) (*states.StorageItem, error)
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
+	PreExecuteContractWithOverride(tx *types.Transaction, override *cstates.StateOverride) (*cstates.PreExecResult, error)
+	TraceTransaction(txHash common.Uint256) (*trace.TxTrace, error)
+	TracePreExecute(tx *types.Transaction) (*trace.TxTrace, error)
+	ProfilePreExecute(tx *types.Transaction) (*profile.GasProfile, error)
+	PreExecuteContractWithTracer(tx *types.Transaction, tracer context.Tracer) (*cstates.PreExecResult, error)
+	EstimateGas(tx *types.Transaction) (*cstates.GasEstimate, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	ExportSnapshot(path string) (*snapshot.Manifest, error)
+	ImportSnapshot(path string, stateDigest common.Uint256) error
+	GetSnapshotManifest() *snapshot.Manifest
+	GetSnapshotChunk(height, index uint32) ([]byte, error)
 }
@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package storetest provides the conformance test suite of persist store, every store backend must pass it
+package storetest
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/dnaproject2/DNA/core/store/common"
+	"github.com/stretchr/testify/assert"
+)
+
+//TestPersistStore run the conformance test suite on the stores created by newStore.
+//Every case gets a new empty store, and closes it at the end.
+func TestPersistStore(t *testing.T, newStore func(t *testing.T) common.PersistStore) {
+	cases := []struct {
+		name string
+		test func(t *testing.T, store common.PersistStore)
+	}{
+		{"PutGet", testPutGet},
+		{"Delete", testDelete},
+		{"Batch", testBatch},
+		{"BatchOverwrite", testBatchOverwrite},
+		{"NewBatchDiscard", testNewBatchDiscard},
+		{"Iterator", testIterator},
+		{"IteratorFirst", testIteratorFirst},
+		{"IteratorSnapshot", testIteratorSnapshot},
+	}
+	for _, c := range cases {
+		test := c.test
+		t.Run(c.name, func(t *testing.T) {
+			store := newStore(t)
+			defer func() {
+				assert.Nil(t, store.Close())
+			}()
+			test(t, store)
+		})
+	}
+}
+
+func testPutGet(t *testing.T, store common.PersistStore) {
+	_, err := store.Get([]byte("missing"))
+	assert.Equal(t, common.ErrNotFound, err)
+	has, err := store.Has([]byte("missing"))
+	assert.Nil(t, err)
+	assert.False(t, has)
+
+	value := []byte("value")
+	assert.Nil(t, store.Put([]byte("key"), value))
+	value[0] = 'V'
+	data, err := store.Get([]byte("key"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value"), data)
+	data[0] = 'V'
+	data, err = store.Get([]byte("key"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value"), data)
+	has, err = store.Has([]byte("key"))
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	assert.Nil(t, store.Put([]byte("key"), []byte("other")))
+	data, err = store.Get([]byte("key"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("other"), data)
+
+	assert.Nil(t, store.Put([]byte("empty"), []byte{}))
+	data, err = store.Get([]byte("empty"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(data))
+}
+
+func testDelete(t *testing.T, store common.PersistStore) {
+	assert.Nil(t, store.Put([]byte("key"), []byte("value")))
+	assert.Nil(t, store.Delete([]byte("key")))
+	_, err := store.Get([]byte("key"))
+	assert.Equal(t, common.ErrNotFound, err)
+	assert.Nil(t, store.Delete([]byte("missing")))
+}
+
+func testBatch(t *testing.T, store common.PersistStore) {
+	assert.Nil(t, store.Put([]byte("del"), []byte("value")))
+	store.NewBatch()
+	store.BatchPut([]byte("key1"), []byte("value1"))
+	store.BatchPut([]byte("key2"), []byte("value2"))
+	store.BatchDelete([]byte("del"))
+
+	//not visible before commit
+	_, err := store.Get([]byte("key1"))
+	assert.Equal(t, common.ErrNotFound, err)
+	_, err = store.Get([]byte("del"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.BatchCommit())
+	data, err := store.Get([]byte("key1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value1"), data)
+	data, err = store.Get([]byte("key2"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value2"), data)
+	_, err = store.Get([]byte("del"))
+	assert.Equal(t, common.ErrNotFound, err)
+}
+
+func testBatchOverwrite(t *testing.T, store common.PersistStore) {
+	store.NewBatch()
+	key := []byte("key")
+	value := []byte("value1")
+	store.BatchPut(key, value)
+	value[5] = '2'
+	store.BatchPut(key, value)
+	store.BatchPut([]byte("del"), []byte("value"))
+	store.BatchDelete([]byte("del"))
+	assert.Nil(t, store.BatchCommit())
+
+	data, err := store.Get(key)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("value2"), data)
+	_, err = store.Get([]byte("del"))
+	assert.Equal(t, common.ErrNotFound, err)
+}
+
+func testNewBatchDiscard(t *testing.T, store common.PersistStore) {
+	store.NewBatch()
+	store.BatchPut([]byte("discard"), []byte("value"))
+	store.NewBatch()
+	store.BatchPut([]byte("key"), []byte("value"))
+	assert.Nil(t, store.BatchCommit())
+
+	_, err := store.Get([]byte("discard"))
+	assert.Equal(t, common.ErrNotFound, err)
+	_, err = store.Get([]byte("key"))
+	assert.Nil(t, err)
+}
+
+func testIterator(t *testing.T, store common.PersistStore) {
+	store.NewBatch()
+	for _, i := range []int{3, 1, 4, 0, 2} {
+		store.BatchPut([]byte(fmt.Sprintf("a%d", i)), []byte(fmt.Sprintf("value%d", i)))
+	}
+	store.BatchPut([]byte("b0"), []byte("other"))
+	store.BatchPut([]byte("0"), []byte("other"))
+	assert.Nil(t, store.BatchCommit())
+
+	iter := store.NewIterator([]byte("a"))
+	count := 0
+	for iter.Next() {
+		assert.Equal(t, []byte(fmt.Sprintf("a%d", count)), iter.Key())
+		assert.Equal(t, []byte(fmt.Sprintf("value%d", count)), iter.Value())
+		count++
+	}
+	assert.Nil(t, iter.Error())
+	iter.Release()
+	assert.Equal(t, 5, count)
+
+	iter = store.NewIterator([]byte("c"))
+	assert.False(t, iter.Next())
+	iter.Release()
+
+	//iterate all keys in order
+	iter = store.NewIterator(nil)
+	var keys [][]byte
+	for iter.Next() {
+		keys = append(keys, append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	assert.Equal(t, 7, len(keys))
+	for i := 1; i < len(keys); i++ {
+		assert.True(t, bytes.Compare(keys[i-1], keys[i]) < 0)
+	}
+}
+
+func testIteratorFirst(t *testing.T, store common.PersistStore) {
+	iter := store.NewIterator([]byte("a"))
+	assert.False(t, iter.First())
+	iter.Release()
+
+	assert.Nil(t, store.Put([]byte("a1"), []byte("value1")))
+	assert.Nil(t, store.Put([]byte("a2"), []byte("value2")))
+	iter = store.NewIterator([]byte("a"))
+	defer iter.Release()
+	assert.True(t, iter.First())
+	assert.Equal(t, []byte("a1"), iter.Key())
+	assert.True(t, iter.Next())
+	assert.Equal(t, []byte("a2"), iter.Key())
+	assert.False(t, iter.Next())
+	assert.True(t, iter.First())
+	assert.Equal(t, []byte("a1"), iter.Key())
+	assert.Equal(t, []byte("value1"), iter.Value())
+}
+
+func testIteratorSnapshot(t *testing.T, store common.PersistStore) {
+	assert.Nil(t, store.Put([]byte("a1"), []byte("value1")))
+	iter := store.NewIterator([]byte("a"))
+	defer iter.Release()
+	assert.Nil(t, store.Put([]byte("a2"), []byte("value2")))
+	assert.Nil(t, store.Delete([]byte("a1")))
+
+	assert.True(t, iter.Next())
+	assert.Equal(t, []byte("a1"), iter.Key())
+	assert.Equal(t, []byte("value1"), iter.Value())
+	assert.False(t, iter.Next())
+}
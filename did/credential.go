@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dnaproject2/DNA/core/signature"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+	s "github.com/ontio/ontology-crypto/signature"
+)
+
+const (
+	CREDENTIAL_CONTEXT     = "https://www.w3.org/2018/credentials/v1"
+	CREDENTIAL_TYPE        = "VerifiableCredential"
+	CREDENTIAL_STATUS_TYPE = "AttestContract"
+	JWT_TYPE               = "JWT"
+)
+
+type JwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+//CredentialStatus point to the claim record contract where the status of credential is anchored
+type CredentialStatus struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type VerifiableCredential struct {
+	Context           []string               `json:"@context"`
+	Type              []string               `json:"type"`
+	CredentialSubject map[string]interface{} `json:"credentialSubject"`
+	CredentialStatus  *CredentialStatus      `json:"credentialStatus,omitempty"`
+}
+
+//CredentialClaims is the JWT payload of credential, ID is the claim id committed in claim record contract
+type CredentialClaims struct {
+	Issuer     string                `json:"iss"`
+	Subject    string                `json:"sub"`
+	ID         string                `json:"jti"`
+	IssuedAt   int64                 `json:"iat"`
+	Expiration int64                 `json:"exp,omitempty"`
+	Credential *VerifiableCredential `json:"vc"`
+}
+
+//Credential is a parsed JWT credential
+type Credential struct {
+	Header       *JwtHeader
+	Claims       *CredentialClaims
+	Signature    []byte
+	signingInput string
+}
+
+//NewCredentialClaims create the payload of a credential with a random claim id, the expiration is unix time and zero
+//means never expire
+func NewCredentialClaims(issuer, subject string, types []string, subjectClaims map[string]interface{},
+	issuedAt, expiration int64) (*CredentialClaims, error) {
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate claim id error, %s", err)
+	}
+	credSubject := make(map[string]interface{}, len(subjectClaims)+1)
+	for k, v := range subjectClaims {
+		credSubject[k] = v
+	}
+	credSubject["id"] = subject
+	return &CredentialClaims{
+		Issuer:     issuer,
+		Subject:    subject,
+		ID:         hex.EncodeToString(nonce),
+		IssuedAt:   issuedAt,
+		Expiration: expiration,
+		Credential: &VerifiableCredential{
+			Context:           []string{CREDENTIAL_CONTEXT},
+			Type:              append([]string{CREDENTIAL_TYPE}, types...),
+			CredentialSubject: credSubject,
+			CredentialStatus: &CredentialStatus{
+				ID:   utils.ClaimRecordContractAddress.ToHexString(),
+				Type: CREDENTIAL_STATUS_TYPE,
+			},
+		},
+	}, nil
+}
+
+//IssueCredential sign the credential by the key of issuer and encode it as JWT, keyNo is the index of signer's
+//public key in issuer ID
+func IssueCredential(signer signature.Signer, keyNo uint32, claims *CredentialClaims) (string, error) {
+	header := &JwtHeader{
+		Alg: jwtAlgorithm(signer.Scheme()),
+		Kid: KeyID(claims.Issuer, keyNo),
+		Typ: JWT_TYPE,
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(p)
+	sig, err := signature.Sign(signer, []byte(input))
+	if err != nil {
+		return "", fmt.Errorf("sign credential error, %s", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+//ParseCredential decode the JWT credential without verification
+func ParseCredential(jwt string) (*Credential, error) {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid credential format")
+	}
+	cred := &Credential{
+		Header:       new(JwtHeader),
+		Claims:       new(CredentialClaims),
+		signingInput: parts[0] + "." + parts[1],
+	}
+	if err := decodeJwtPart(parts[0], cred.Header); err != nil {
+		return nil, fmt.Errorf("invalid credential header, %s", err)
+	}
+	if err := decodeJwtPart(parts[1], cred.Claims); err != nil {
+		return nil, fmt.Errorf("invalid credential payload, %s", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential signature, %s", err)
+	}
+	cred.Signature = sig
+	return cred, nil
+}
+
+//VerifySignature verify the signature of credential by the key in the DID document of issuer
+func (this *Credential) VerifySignature(doc *Document) error {
+	if doc.ID != this.Claims.Issuer || !strings.HasPrefix(this.Header.Kid, doc.ID+"#") {
+		return fmt.Errorf("key %s does not belong to issuer %s", this.Header.Kid, this.Claims.Issuer)
+	}
+	for _, method := range doc.VerificationMethod {
+		if method.ID != this.Header.Kid {
+			continue
+		}
+		data, err := hex.DecodeString(method.PublicKeyHex)
+		if err != nil {
+			return fmt.Errorf("invalid public key of %s, %s", method.ID, err)
+		}
+		pk, err := keypair.DeserializePublicKey(data)
+		if err != nil {
+			return fmt.Errorf("invalid public key of %s, %s", method.ID, err)
+		}
+		return signature.Verify(pk, []byte(this.signingInput), this.Signature)
+	}
+	return fmt.Errorf("key %s not found or revoked", this.Header.Kid)
+}
+
+//Expired check whether the credential is expired at the unix time
+func (this *Credential) Expired(now int64) bool {
+	return this.Claims.Expiration != 0 && now >= this.Claims.Expiration
+}
+
+func decodeJwtPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+//jwtAlgorithm return the JWT algorithm name of signature scheme, only the signature of SHA256withECDSA is serialized
+//as JWT defined, the other schemes are named by the scheme name
+func jwtAlgorithm(scheme s.SignatureScheme) string {
+	if scheme == s.SHA256withECDSA {
+		return "ES256"
+	}
+	return scheme.Name()
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+//Package did resolve the ID registered in ontid contract into W3C DID document
+package did
+
+const (
+	DID_CONTEXT            = "https://www.w3.org/ns/did/v1"
+	DID_RESOLUTION_CONTEXT = "https://w3id.org/did-resolution/v1"
+	DID_CONTENT_TYPE       = "application/did+ld+json"
+
+	//the resolution errors defined by DID core spec
+	ERR_INVALID_DID = "invalidDid"
+	ERR_NOT_FOUND   = "notFound"
+
+	//the attribute whose type has this prefix is resolved as a service, the rest of type is the service type and
+	//the value is the service endpoint
+	SERVICE_ATTR_PREFIX = "service:"
+)
+
+//verification method types of the public key algorithms
+const (
+	KEY_TYPE_SECP256R1 = "EcdsaSecp256r1VerificationKey2019"
+	KEY_TYPE_ECDSA     = "EcdsaVerificationKey2019"
+	KEY_TYPE_SM2       = "SM2VerificationKey2019"
+	KEY_TYPE_ED25519   = "Ed25519VerificationKey2018"
+)
+
+type Document struct {
+	Context            []string              `json:"@context"`
+	ID                 string                `json:"id"`
+	Controller         []string              `json:"controller,omitempty"`
+	VerificationMethod []*VerificationMethod `json:"verificationMethod,omitempty"`
+	Authentication     []string              `json:"authentication,omitempty"`
+	Service            []*Service            `json:"service,omitempty"`
+}
+
+type VerificationMethod struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Controller   string `json:"controller"`
+	PublicKeyHex string `json:"publicKeyHex"`
+}
+
+type Service struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	ServiceEndpoint string `json:"serviceEndpoint"`
+}
+
+type ResolutionMetadata struct {
+	ContentType string `json:"contentType,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type DocumentMetadata struct {
+	Deactivated bool `json:"deactivated,omitempty"`
+}
+
+//ResolutionResult is the result of DID resolution, Document is nil if the resolution failed
+type ResolutionResult struct {
+	Context            string              `json:"@context"`
+	Document           *Document           `json:"didDocument"`
+	ResolutionMetadata *ResolutionMetadata `json:"didResolutionMetadata"`
+	DocumentMetadata   *DocumentMetadata   `json:"didDocumentMetadata"`
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"crypto/elliptic"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ontid"
+	"github.com/ontio/ontology-crypto/ec"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+//Resolve resolve the ID into DID document by reading the state of ontid contract, the failure of resolution is
+//reported in the resolution metadata, and error is returned only if the storage can not be read
+func Resolve(id string, read ontid.StorageReader) (*ResolutionResult, error) {
+	if !account.VerifyID(id) {
+		return failure(ERR_INVALID_DID), nil
+	}
+	state, err := ontid.GetIDState(read, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s error, %s", id, err)
+	}
+	if state == nil {
+		return failure(ERR_NOT_FOUND), nil
+	}
+	doc, err := NewDocument(id, state)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s error, %s", id, err)
+	}
+	return &ResolutionResult{
+		Context:            DID_RESOLUTION_CONTEXT,
+		Document:           doc,
+		ResolutionMetadata: &ResolutionMetadata{ContentType: DID_CONTENT_TYPE},
+		DocumentMetadata:   &DocumentMetadata{Deactivated: state.Revoked},
+	}, nil
+}
+
+func failure(reason string) *ResolutionResult {
+	return &ResolutionResult{
+		Context:            DID_RESOLUTION_CONTEXT,
+		ResolutionMetadata: &ResolutionMetadata{Error: reason},
+		DocumentMetadata:   &DocumentMetadata{},
+	}
+}
+
+//NewDocument build the DID document of ID state, the revoked public keys are not included
+func NewDocument(id string, state *ontid.IDState) (*Document, error) {
+	doc := &Document{
+		Context: []string{DID_CONTEXT},
+		ID:      id,
+	}
+	if state.Controller != "" {
+		doc.Controller = []string{state.Controller}
+	} else if state.ControllerGroup != nil {
+		doc.Controller = groupMembers(state.ControllerGroup, nil)
+	}
+	for _, pk := range state.PublicKeys {
+		if pk.Revoked {
+			continue
+		}
+		keyType, err := verificationKeyType(pk.Key)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d error, %s", pk.Index, err)
+		}
+		method := &VerificationMethod{
+			ID:           KeyID(id, pk.Index),
+			Type:         keyType,
+			Controller:   id,
+			PublicKeyHex: hex.EncodeToString(pk.Key),
+		}
+		doc.VerificationMethod = append(doc.VerificationMethod, method)
+		doc.Authentication = append(doc.Authentication, method.ID)
+	}
+	for _, attr := range state.Attributes {
+		typ := string(attr.Type)
+		if !strings.HasPrefix(typ, SERVICE_ATTR_PREFIX) {
+			continue
+		}
+		doc.Service = append(doc.Service, &Service{
+			ID:              id + "#" + string(attr.Key),
+			Type:            typ[len(SERVICE_ATTR_PREFIX):],
+			ServiceEndpoint: string(attr.Value),
+		})
+	}
+	return doc, nil
+}
+
+//KeyID return the DID URL of the public key of ID
+func KeyID(id string, index uint32) string {
+	return fmt.Sprintf("%s#keys-%d", id, index)
+}
+
+//groupMembers collect the IDs in group and its sub groups
+func groupMembers(g *ontid.Group, ids []string) []string {
+	for _, m := range g.Members {
+		switch t := m.(type) {
+		case string:
+			ids = append(ids, t)
+		case *ontid.Group:
+			ids = groupMembers(t, ids)
+		}
+	}
+	return ids
+}
+
+func verificationKeyType(data []byte) (string, error) {
+	pk, err := keypair.DeserializePublicKey(data)
+	if err != nil {
+		return "", err
+	}
+	switch t := pk.(type) {
+	case *ec.PublicKey:
+		if t.Algorithm == ec.SM2 {
+			return KEY_TYPE_SM2, nil
+		}
+		if t.Curve.Params().Name == elliptic.P256().Params().Name {
+			return KEY_TYPE_SECP256R1, nil
+		}
+		return KEY_TYPE_ECDSA, nil
+	default:
+		if keypair.GetKeyType(pk) == keypair.PK_EDDSA {
+			return KEY_TYPE_ED25519, nil
+		}
+		return "", fmt.Errorf("unsupported key type")
+	}
+}
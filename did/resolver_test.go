@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package did
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ontid"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	ontid.Init()
+}
+
+type idAttribute struct {
+	Key   []byte
+	Type  []byte
+	Value []byte
+}
+
+func TestResolve(t *testing.T) {
+	suite := testsuite.NewNativeSuite()
+	invoke := func(method string, param interface{}, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(utils.OntIDContractAddress, method, []interface{}{param}, signer)
+		return err
+	}
+
+	owner := account.NewAccount("")
+	other := account.NewAccount("")
+	ownerKey := keypair.SerializePublicKey(owner.PublicKey)
+	otherKey := keypair.SerializePublicKey(other.PublicKey)
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	revoked, err := account.GenerateID()
+	assert.Nil(t, err)
+
+	assert.Nil(t, invoke("regIDWithAttributes", &struct {
+		ID         []byte
+		PubKey     []byte
+		Attributes []*idAttribute
+	}{[]byte(id), ownerKey, []*idAttribute{
+		{[]byte("hub"), []byte("service:IdentityHub"), []byte("https://hub.example.com")},
+		{[]byte("name"), []byte("string"), []byte("alice")},
+	}}, owner))
+	assert.Nil(t, invoke("addKey", &struct {
+		ID       []byte
+		PubKey   []byte
+		Operator []byte
+	}{[]byte(id), otherKey, ownerKey}, owner))
+	assert.Nil(t, invoke("regIDWithPublicKey", &struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(revoked), otherKey}, other))
+	assert.Nil(t, invoke("revokeID", &struct {
+		ID    []byte
+		Index uint64
+	}{[]byte(revoked), 1}, other))
+
+	//a group member shorter than the DID prefix is rejected instead of panicking
+	group := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteVarUint(group, 1))
+	assert.Nil(t, serialization.WriteVarBytes(group, []byte("did")))
+	assert.Nil(t, utils.WriteVarUint(group, 1))
+	assert.NotNil(t, invoke("addRecovery", &struct {
+		ID    []byte
+		Group []byte
+		Index uint64
+	}{[]byte(id), group.Bytes(), 1}, owner))
+
+	result, err := Resolve(id, suite.Get)
+	assert.Nil(t, err)
+	assert.Equal(t, DID_CONTENT_TYPE, result.ResolutionMetadata.ContentType)
+	assert.False(t, result.DocumentMetadata.Deactivated)
+	doc := result.Document
+	assert.Equal(t, id, doc.ID)
+	assert.Equal(t, 2, len(doc.VerificationMethod))
+	assert.Equal(t, KeyID(id, 1), doc.VerificationMethod[0].ID)
+	assert.Equal(t, KEY_TYPE_SECP256R1, doc.VerificationMethod[0].Type)
+	assert.Equal(t, id, doc.VerificationMethod[0].Controller)
+	assert.Equal(t, hex.EncodeToString(ownerKey), doc.VerificationMethod[0].PublicKeyHex)
+	assert.Equal(t, hex.EncodeToString(otherKey), doc.VerificationMethod[1].PublicKeyHex)
+	assert.Equal(t, []string{KeyID(id, 1), KeyID(id, 2)}, doc.Authentication)
+	assert.Equal(t, []*Service{{ID: id + "#hub", Type: "IdentityHub", ServiceEndpoint: "https://hub.example.com"}},
+		doc.Service)
+
+	result, err = Resolve(revoked, suite.Get)
+	assert.Nil(t, err)
+	assert.True(t, result.DocumentMetadata.Deactivated)
+	assert.Equal(t, revoked, result.Document.ID)
+	assert.Empty(t, result.Document.VerificationMethod)
+
+	unknown, err := account.GenerateID()
+	assert.Nil(t, err)
+	result, err = Resolve(unknown, suite.Get)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Document)
+	assert.Equal(t, ERR_NOT_FOUND, result.ResolutionMetadata.Error)
+
+	result, err = Resolve("did:dna:invalid", suite.Get)
+	assert.Nil(t, err)
+	assert.Equal(t, ERR_INVALID_DID, result.ResolutionMetadata.Error)
+}
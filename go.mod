@@ -3,7 +3,9 @@ module github.com/dnaproject2/DNA
 go 1.12
 
 require (
+	github.com/dgraph-io/badger v1.6.2
 	github.com/ethereum/go-ethereum v1.8.23
+	github.com/golang/snappy v0.0.0-20180518054509-2e65f85255db
 	github.com/gorilla/websocket v1.2.0
 	github.com/gosuri/uiprogress v0.0.1
 	github.com/hashicorp/golang-lru v0.5.3
@@ -13,7 +15,7 @@ require (
 	github.com/ontio/ontology-crypto v1.0.5
 	github.com/ontio/ontology-eventbus v0.9.1
 	github.com/pborman/uuid v1.2.0
-	github.com/stretchr/testify v1.3.0
+	github.com/stretchr/testify v1.4.0
 	github.com/syndtr/goleveldb v1.0.0
 	github.com/urfave/cli v1.21.0
 	github.com/valyala/bytebufferpool v1.0.0
@@ -24,7 +24,9 @@ import (
 	"github.com/dnaproject2/DNA/core/payload"
 	"github.com/dnaproject2/DNA/core/types"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
 	cstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
 )
 
 const (
@@ -37,6 +39,11 @@ func GetHeaderByHeight(height uint32) (*types.Header, error) {
 	return ledger.DefLedger.GetHeaderByHeight(height)
 }
 
+//GetHeaderFromStore from ledger
+func GetHeaderFromStore(hash common.Uint256) (*types.Header, error) {
+	return ledger.DefLedger.GetHeaderByHash(hash)
+}
+
 //GetBlockByHeight from ledger
 func GetBlockByHeight(height uint32) (*types.Block, error) {
 	return ledger.DefLedger.GetBlockByHeight(height)
@@ -89,6 +96,31 @@ func PreExecuteContract(tx *types.Transaction) (*cstate.PreExecResult, error) {
 	return ledger.DefLedger.PreExecuteContract(tx)
 }
 
+//PreExecuteContractWithOverride from ledger
+func PreExecuteContractWithOverride(tx *types.Transaction, override *cstate.StateOverride) (*cstate.PreExecResult, error) {
+	return ledger.DefLedger.PreExecuteContractWithOverride(tx, override)
+}
+
+//TraceTransaction from ledger
+func TraceTransaction(txHash common.Uint256) (*trace.TxTrace, error) {
+	return ledger.DefLedger.TraceTransaction(txHash)
+}
+
+//TracePreExecute from ledger
+func TracePreExecute(tx *types.Transaction) (*trace.TxTrace, error) {
+	return ledger.DefLedger.TracePreExecute(tx)
+}
+
+//ProfilePreExecute from ledger
+func ProfilePreExecute(tx *types.Transaction) (*profile.GasProfile, error) {
+	return ledger.DefLedger.ProfilePreExecute(tx)
+}
+
+//EstimateGas from ledger
+func EstimateGas(tx *types.Transaction) (*cstate.GasEstimate, error) {
+	return ledger.DefLedger.EstimateGas(tx)
+}
+
 //GetEventNotifyByTxHash from ledger
 func GetEventNotifyByTxHash(txHash common.Uint256) (*event.ExecuteNotify, error) {
 	return ledger.DefLedger.GetEventNotifyByTx(txHash)
@@ -22,7 +22,10 @@ import (
 	"errors"
 	"time"
 
+	comm "github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/p2pserver"
 	ac "github.com/dnaproject2/DNA/p2pserver/actor/server"
 	"github.com/dnaproject2/DNA/p2pserver/common"
 	"github.com/ontio/ontology-eventbus/actor"
@@ -204,3 +207,21 @@ func GetNodeType() (uint64, error) {
 	}
 	return r.NodeType, nil
 }
+
+//GetVerifiedTransaction from netSever actor, the transaction is proved by the merkle proof of full nodes. Using by light node
+func GetVerifiedTransaction(hash comm.Uint256) (*types.Transaction, uint32, error) {
+	if netServerPid == nil {
+		return nil, 0, errors.New("net server is not started")
+	}
+	future := netServerPid.RequestFuture(&ac.GetVerifiedTxReq{TxHash: hash}, (p2pserver.TX_PROOF_TIMEOUT+REQ_TIMEOUT)*time.Second)
+	result, err := future.Result()
+	if err != nil {
+		log.Errorf(ERR_ACTOR_COMM, err)
+		return nil, 0, err
+	}
+	r, ok := result.(*ac.GetVerifiedTxRsp)
+	if !ok {
+		return nil, 0, errors.New("fail")
+	}
+	return r.Tx, r.Height, r.Error
+}
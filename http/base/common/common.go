@@ -22,23 +22,34 @@ package common
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"github.com/dnaproject2/DNA/cmd/abi"
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/constants"
 	"github.com/dnaproject2/DNA/common/log"
 	"github.com/dnaproject2/DNA/common/serialization"
 	"github.com/dnaproject2/DNA/core/ledger"
 	"github.com/dnaproject2/DNA/core/payload"
+	scom "github.com/dnaproject2/DNA/core/store/common"
 	"github.com/dnaproject2/DNA/core/types"
 	cutils "github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/did"
 	ontErrors "github.com/dnaproject2/DNA/errors"
 	bactor "github.com/dnaproject2/DNA/http/base/actor"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/profile"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/auth"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/nft"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
 	cstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/trace"
 	"github.com/dnaproject2/DNA/vm/neovm"
 	"github.com/ontio/ontology-crypto/keypair"
+	"sort"
 	"strings"
 	"time"
 )
@@ -80,9 +91,184 @@ type PreExecuteResult struct {
 	Notify []NotifyEventInfo
 }
 
+//StateOverrideInfo is the states replaced in pre-execution, with bytes in hex and address in hex or base58
+type StateOverrideInfo struct {
+	Height   uint32
+	Time     uint32
+	Balances []BalanceOverrideInfo
+	Storage  []StorageOverrideInfo
+	Code     []CodeOverrideInfo
+}
+
+type BalanceOverrideInfo struct {
+	Asset   string //ont, ong or native contract address
+	Address string
+	Balance uint64
+}
+
+type StorageOverrideInfo struct {
+	Contract string
+	Key      string
+	Value    string //empty value deletes the key
+}
+
+type CodeOverrideInfo struct {
+	Contract string
+	Code     string
+}
+
+//GasEstimateResult is the gas estimate of a transaction. Signature verification of the transaction is done before
+//execution and not charged by the node, so it is not included in Gas, SigVerifyIncluded is always false
+type GasEstimateResult struct {
+	State             byte
+	Gas               uint64
+	GasLimit          uint64
+	GasPrice          uint64
+	SigVerifyIncluded bool
+	Result            interface{}
+	Notify            []NotifyEventInfo
+}
+
+type TxTraceInfo struct {
+	TxHash      string
+	Height      uint32
+	State       byte
+	GasConsumed uint64
+	Error       string
+	Notify      []NotifyEventInfo
+	Calls       []*trace.CallFrame
+	Ops         []*trace.OpLog
+	Storage     []*trace.StorageLog
+	Truncated   bool
+}
+
+type GasProfileInfo struct {
+	TxHash      string
+	Height      uint32
+	State       byte
+	GasConsumed uint64
+	Error       string
+	Opcodes     []*profile.GasItem
+	Syscalls    []*profile.GasItem
+	Storage     []*profile.StorageGas
+	Contracts   []*profile.ContractGas
+	Folded      []string
+}
+
+type ContractMetadataInfo struct {
+	Contract   string
+	Abi        json.RawMessage
+	SourceHash string
+	Publisher  string
+	Height     uint32
+}
+
+type NftTokenInfo struct {
+	TokenId      string
+	Issuer       string
+	Owner        string
+	URI          string
+	MetadataHash string
+	MintHeight   uint32
+}
+
+type NftTokenList struct {
+	Owner  string
+	Total  uint64
+	Start  uint64
+	Tokens []NftTokenInfo
+}
+
+type GovernancePeerInfo struct {
+	Index        uint32
+	PeerPubkey   string
+	Address      string
+	Status       string
+	NextStatus   string
+	InitPos      uint64
+	TotalPos     uint64
+	Stake        uint64
+	MaxAuthorize uint64
+	PeerCost     uint64
+}
+
+type GovernancePeersInfo struct {
+	View               uint32
+	Height             uint32
+	K                  uint32
+	ConsensusPeers     []string
+	NextConsensusPeers []string
+	Peers              []GovernancePeerInfo
+}
+
+type AuthorizationInfo struct {
+	PeerPubkey           string
+	ConsensusPos         uint64
+	CandidatePos         uint64
+	NewPos               uint64
+	WithdrawConsensusPos uint64
+	WithdrawCandidatePos uint64
+	WithdrawUnfreezePos  uint64
+	Withdrawable         uint64
+}
+
+type GovernanceStakeInfo struct {
+	Address        string
+	View           uint32
+	TotalStake     uint64
+	WithdrawFee    uint64
+	Authorizations []AuthorizationInfo
+}
+
+type PeerFeeSplitInfo struct {
+	PeerPubkey  string
+	Address     string
+	Consensus   bool
+	Amount      uint64
+	StakeAmount uint64
+	PeerAmount  uint64
+}
+
+type FeeSplitInfo struct {
+	View    uint32
+	Income  uint64
+	DappFee uint64
+	Peers   []PeerFeeSplitInfo
+}
+
+type RoleAuthInfo struct {
+	Role    string
+	Funcs   []string
+	Holders []string
+}
+
+type DelegationAuthInfo struct {
+	OntID      string
+	Root       string
+	Role       string
+	Level      uint8
+	ExpireTime uint32
+}
+
+type ContractAuthInfo struct {
+	Contract    string
+	Admin       string
+	Time        uint32
+	Roles       []RoleAuthInfo
+	Delegations []DelegationAuthInfo
+}
+
+type ContractVersionInfo struct {
+	Version  string
+	CodeHash string
+	Height   uint32
+	TxHash   string
+}
+
 type NotifyEventInfo struct {
 	ContractAddress string
 	States          interface{}
+	Decoded         *abi.Event `json:",omitempty"` //States decoded by contract abi, if requested and abi registered
 }
 
 type TxAttributeInfo struct {
@@ -181,21 +367,463 @@ func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify)
 	evts := []NotifyEventInfo{}
 	var contractAddrs = make(map[string]bool)
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States})
 		contractAddrs[v.ContractAddress.ToHexString()] = true
 	}
 	txhash := obj.TxHash.ToHexString()
 	return contractAddrs, ExecuteNotify{txhash, obj.State, obj.GasConsumed, evts}
 }
 
+//DecodeExecuteNotify decode the events of notify by the registered contract abi
+func DecodeExecuteNotify(notify *ExecuteNotify) {
+	onChainAbis := make(map[string]*abi.NeovmContractAbi)
+	for i := range notify.Notify {
+		evt := &notify.Notify[i]
+		evt.Decoded = abi.DefAbiMgr.DecodeEvent(evt.ContractAddress, evt.States)
+		if evt.Decoded != nil || abi.DefAbiMgr.GetNativeAbi(evt.ContractAddress) != nil {
+			continue
+		}
+		//fall back to the abi published in registry contract
+		neovmAbi, ok := onChainAbis[evt.ContractAddress]
+		if !ok {
+			neovmAbi = getPublishedAbi(evt.ContractAddress)
+			onChainAbis[evt.ContractAddress] = neovmAbi
+		}
+		if neovmAbi != nil {
+			evt.Decoded = abi.DecodeNeovmEvent(neovmAbi, evt.States)
+		}
+	}
+}
+
+func getPublishedAbi(address string) *abi.NeovmContractAbi {
+	contract, err := common.AddressFromHexString(address)
+	if err != nil {
+		return nil
+	}
+	meta, err := GetContractMetadata(contract)
+	if err != nil || meta == nil {
+		return nil
+	}
+	neovmAbi := &abi.NeovmContractAbi{}
+	if err := json.Unmarshal(meta.Abi, neovmAbi); err != nil {
+		return nil
+	}
+	return neovmAbi
+}
+
+//GetContractMetadata return the metadata published in registry contract, or nil if not published
+func GetContractMetadata(contract common.Address) (*registry.Metadata, error) {
+	value, err := bactor.GetStorageItem(utils.RegistryContractAddress, registry.MetadataSubKey(contract))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return registry.DecodeMetadata(value)
+}
+
+//GetContractVersions return the version history recorded in registry contract, or nil if not recorded
+func GetContractVersions(contract common.Address) (*registry.ContractVersions, error) {
+	value, err := bactor.GetStorageItem(utils.RegistryContractAddress, registry.VersionsSubKey(contract))
+	if err != nil {
+		if err == scom.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(value) == 0 {
+		return nil, nil
+	}
+	return registry.DecodeVersions(value)
+}
+
+//ResolveDID resolve the ID registered in ontid contract into DID document
+func ResolveDID(id string) (*did.ResolutionResult, error) {
+	return did.Resolve(id, readOntIDStorage)
+}
+
+func readOntIDStorage(key []byte) ([]byte, error) {
+	value, err := bactor.GetStorageItem(utils.OntIDContractAddress, key[common.ADDR_LEN:])
+	if err == scom.ErrNotFound {
+		return nil, nil
+	}
+	return value, err
+}
+
+//GetNftToken return the token in nft contract, or nil if the token not exists
+func GetNftToken(tokenId []byte) (*NftTokenInfo, error) {
+	token, err := nft.GetToken(readNftStorage, tokenId)
+	if err != nil || token == nil {
+		return nil, err
+	}
+	info := ConvertNftToken(token)
+	return &info, nil
+}
+
+//GetNftTokens return a page of tokens owned by owner in nft contract
+func GetNftTokens(owner common.Address, start, limit uint64) (*NftTokenList, error) {
+	total, err := nft.GetBalance(readNftStorage, owner)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := nft.GetTokensOf(readNftStorage, owner, start, limit)
+	if err != nil {
+		return nil, err
+	}
+	list := &NftTokenList{
+		Owner:  owner.ToBase58(),
+		Total:  total,
+		Start:  start,
+		Tokens: make([]NftTokenInfo, 0, len(ids)),
+	}
+	for _, id := range ids {
+		token, err := nft.GetToken(readNftStorage, id)
+		if err != nil {
+			return nil, err
+		}
+		if token == nil {
+			return nil, fmt.Errorf("token %x not found", id)
+		}
+		list.Tokens = append(list.Tokens, ConvertNftToken(token))
+	}
+	return list, nil
+}
+
+func readNftStorage(key []byte) ([]byte, error) {
+	value, err := bactor.GetStorageItem(utils.NftContractAddress, key[common.ADDR_LEN:])
+	if err == scom.ErrNotFound {
+		return nil, nil
+	}
+	return value, err
+}
+
+func ConvertNftToken(token *nft.TokenState) NftTokenInfo {
+	return NftTokenInfo{
+		TokenId:      common.ToHexString(token.TokenId),
+		Issuer:       token.Issuer.ToBase58(),
+		Owner:        token.Owner.ToBase58(),
+		URI:          token.URI,
+		MetadataHash: common.ToHexString(token.MetadataHash),
+		MintHeight:   token.MintHeight,
+	}
+}
+
+//GetGovernancePeers return the peers of current view with stake and status, the next consensus peers are the
+//first K peers ranked by stake, which take effect at next commitDpos if the stakes are not changed
+func GetGovernancePeers() (*GovernancePeersInfo, error) {
+	governanceView, err := governance.ReadGovernanceView(readGovernanceStorage)
+	if err != nil {
+		return nil, err
+	}
+	config, err := governance.ReadConfig(readGovernanceStorage)
+	if err != nil {
+		return nil, err
+	}
+	peerPoolMap, err := governance.ReadPeerPoolMap(readGovernanceStorage, governanceView.View)
+	if err != nil {
+		return nil, err
+	}
+	if peerPoolMap == nil {
+		return nil, fmt.Errorf("peer pool of view %d not found", governanceView.View)
+	}
+	nextStatus := make(map[string]string)
+	info := &GovernancePeersInfo{
+		View:               governanceView.View,
+		Height:             governanceView.Height,
+		K:                  config.K,
+		ConsensusPeers:     make([]string, 0),
+		NextConsensusPeers: make([]string, 0),
+		Peers:              make([]GovernancePeerInfo, 0, len(peerPoolMap.PeerPoolMap)),
+	}
+	for i, peer := range governance.RankPeers(peerPoolMap) {
+		if i < int(config.K) {
+			nextStatus[peer.PeerPubkey] = PeerStatusName(governance.ConsensusStatus)
+			info.NextConsensusPeers = append(info.NextConsensusPeers, peer.PeerPubkey)
+		} else {
+			nextStatus[peer.PeerPubkey] = PeerStatusName(governance.CandidateStatus)
+		}
+	}
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		peerAttributes, err := governance.ReadPeerAttributes(readGovernanceStorage, peerPoolItem.PeerPubkey)
+		if err != nil {
+			return nil, err
+		}
+		info.Peers = append(info.Peers, GovernancePeerInfo{
+			Index:        peerPoolItem.Index,
+			PeerPubkey:   peerPoolItem.PeerPubkey,
+			Address:      peerPoolItem.Address.ToBase58(),
+			Status:       PeerStatusName(peerPoolItem.Status),
+			NextStatus:   nextStatus[peerPoolItem.PeerPubkey],
+			InitPos:      peerPoolItem.InitPos,
+			TotalPos:     peerPoolItem.TotalPos,
+			Stake:        peerPoolItem.InitPos + peerPoolItem.TotalPos,
+			MaxAuthorize: peerAttributes.MaxAuthorize,
+			PeerCost:     peerAttributes.TPeerCost,
+		})
+	}
+	sort.Slice(info.Peers, func(i, j int) bool {
+		return info.Peers[i].Index < info.Peers[j].Index
+	})
+	for _, peer := range info.Peers {
+		if peer.Status == PeerStatusName(governance.ConsensusStatus) {
+			info.ConsensusPeers = append(info.ConsensusPeers, peer.PeerPubkey)
+		}
+	}
+	return info, nil
+}
+
+//GetGovernanceStake return the stake of address, its authorizations to the peers of current view and the fee
+//not withdrawn yet
+func GetGovernanceStake(address common.Address) (*GovernanceStakeInfo, error) {
+	governanceView, err := governance.ReadGovernanceView(readGovernanceStorage)
+	if err != nil {
+		return nil, err
+	}
+	peerPoolMap, err := governance.ReadPeerPoolMap(readGovernanceStorage, governanceView.View)
+	if err != nil {
+		return nil, err
+	}
+	if peerPoolMap == nil {
+		return nil, fmt.Errorf("peer pool of view %d not found", governanceView.View)
+	}
+	totalStake, err := governance.ReadTotalStake(readGovernanceStorage, address)
+	if err != nil {
+		return nil, err
+	}
+	splitFeeAddress, err := governance.ReadSplitFeeAddress(readGovernanceStorage, address)
+	if err != nil {
+		return nil, err
+	}
+	info := &GovernanceStakeInfo{
+		Address:        address.ToBase58(),
+		View:           governanceView.View,
+		TotalStake:     totalStake.Stake,
+		WithdrawFee:    splitFeeAddress.Amount,
+		Authorizations: make([]AuthorizationInfo, 0),
+	}
+	for peerPubkey := range peerPoolMap.PeerPoolMap {
+		authorizeInfo, err := governance.ReadAuthorizeInfo(readGovernanceStorage, peerPubkey, address)
+		if err != nil {
+			return nil, err
+		}
+		authorization := AuthorizationInfo{
+			PeerPubkey:           peerPubkey,
+			ConsensusPos:         authorizeInfo.ConsensusPos,
+			CandidatePos:         authorizeInfo.CandidatePos,
+			NewPos:               authorizeInfo.NewPos,
+			WithdrawConsensusPos: authorizeInfo.WithdrawConsensusPos,
+			WithdrawCandidatePos: authorizeInfo.WithdrawCandidatePos,
+			WithdrawUnfreezePos:  authorizeInfo.WithdrawUnfreezePos,
+			Withdrawable:         authorizeInfo.WithdrawUnfreezePos,
+		}
+		if authorization == (AuthorizationInfo{PeerPubkey: peerPubkey}) {
+			continue
+		}
+		info.Authorizations = append(info.Authorizations, authorization)
+	}
+	sort.Slice(info.Authorizations, func(i, j int) bool {
+		return info.Authorizations[i].PeerPubkey < info.Authorizations[j].PeerPubkey
+	})
+	return info, nil
+}
+
+//GetFeeSplit return the fee split at the end of view, or nil if the fee split of view is not recorded
+func GetFeeSplit(view uint32) (*FeeSplitInfo, error) {
+	viewSplitFee, err := governance.ReadViewSplitFee(readGovernanceStorage, view)
+	if err != nil || viewSplitFee == nil {
+		return nil, err
+	}
+	info := &FeeSplitInfo{
+		View:    viewSplitFee.View,
+		Income:  viewSplitFee.Income,
+		DappFee: viewSplitFee.DappFee,
+		Peers:   make([]PeerFeeSplitInfo, 0, len(viewSplitFee.Peers)),
+	}
+	for _, peer := range viewSplitFee.Peers {
+		info.Peers = append(info.Peers, PeerFeeSplitInfo{
+			PeerPubkey:  peer.PeerPubkey,
+			Address:     peer.Address.ToBase58(),
+			Consensus:   peer.Consensus,
+			Amount:      peer.Amount,
+			StakeAmount: peer.StakeAmount,
+			PeerAmount:  peer.Amount - peer.StakeAmount,
+		})
+	}
+	return info, nil
+}
+
+//PeerStatusName return the readable name of peer status
+func PeerStatusName(status governance.Status) string {
+	switch status {
+	case governance.RegisterCandidateStatus:
+		return "registered"
+	case governance.CandidateStatus:
+		return "candidate"
+	case governance.ConsensusStatus:
+		return "consensus"
+	case governance.QuitConsensusStatus:
+		return "quitconsensus"
+	case governance.QuitingStatus:
+		return "quiting"
+	case governance.BlackStatus:
+		return "black"
+	default:
+		return fmt.Sprintf("unknown(%d)", status)
+	}
+}
+
+func readGovernanceStorage(key []byte) ([]byte, error) {
+	value, err := bactor.GetStorageItem(utils.GovernanceContractAddress, key[common.ADDR_LEN:])
+	if err == scom.ErrNotFound {
+		return nil, nil
+	}
+	return value, err
+}
+
+//GetContractAuth return the admin, roles and delegations active at the latest block of contract in auth contract.
+//Roles are in hex since they can be any bytes
+func GetContractAuth(contract common.Address) (*ContractAuthInfo, error) {
+	header, err := bactor.GetHeaderByHeight(bactor.GetCurrentBlockHeight())
+	if err != nil {
+		return nil, err
+	}
+	//roles and delegations are enumerated by iterating storage, which is only available in contract execution
+	mutable, err := NewNativeInvokeTransaction(0, 0, utils.AuthContractAddress, 0, "getContractAuth",
+		[]interface{}{&auth.GetContractAuthParam{ContractAddr: contract}})
+	if err != nil {
+		return nil, fmt.Errorf("NewNativeInvokeTransaction error:%s", err)
+	}
+	tx, err := mutable.IntoImmutable()
+	if err != nil {
+		return nil, err
+	}
+	preResult, err := bactor.PreExecuteContractWithOverride(tx, &cstate.StateOverride{Time: header.Timestamp})
+	if err != nil {
+		return nil, fmt.Errorf("PreExecuteContract error:%s", err)
+	}
+	data, err := hex.DecodeString(preResult.Result.(string))
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	result := new(auth.ContractAuth)
+	if err := result.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("deserialize contract auth error:%s", err)
+	}
+	info := &ContractAuthInfo{
+		Contract:    contract.ToHexString(),
+		Admin:       string(result.Admin),
+		Time:        header.Timestamp,
+		Roles:       make([]RoleAuthInfo, 0, len(result.Roles)),
+		Delegations: make([]DelegationAuthInfo, 0, len(result.Delegations.Delegations)),
+	}
+	for _, role := range result.Roles {
+		holders := make([]string, 0, len(role.Holders))
+		for _, holder := range role.Holders {
+			holders = append(holders, string(holder))
+		}
+		info.Roles = append(info.Roles, RoleAuthInfo{
+			Role:    common.ToHexString(role.Role),
+			Funcs:   role.Funcs,
+			Holders: holders,
+		})
+	}
+	for _, d := range result.Delegations.Delegations {
+		info.Delegations = append(info.Delegations, DelegationAuthInfo{
+			OntID:      string(d.OntID),
+			Root:       string(d.Root),
+			Role:       common.ToHexString(d.Role),
+			Level:      d.Level,
+			ExpireTime: d.ExpireTime,
+		})
+	}
+	return info, nil
+}
+
+func ConvertContractVersions(versions *registry.ContractVersions) []ContractVersionInfo {
+	infos := make([]ContractVersionInfo, 0, len(versions.Versions))
+	for _, v := range versions.Versions {
+		infos = append(infos, ContractVersionInfo{
+			Version:  v.Version,
+			CodeHash: v.CodeHash.ToHexString(),
+			Height:   v.Height,
+			TxHash:   v.TxHash.ToHexString(),
+		})
+	}
+	return infos
+}
+
+func ConvertContractMetadata(contract common.Address, meta *registry.Metadata) ContractMetadataInfo {
+	return ContractMetadataInfo{
+		Contract:   contract.ToHexString(),
+		Abi:        json.RawMessage(meta.Abi),
+		SourceHash: common.ToHexString(meta.SourceHash),
+		Publisher:  meta.Publisher.ToBase58(),
+		Height:     meta.Height,
+	}
+}
+
 func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	evts := []NotifyEventInfo{}
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States})
 	}
 	return PreExecuteResult{obj.State, obj.Gas, obj.Result, evts}
 }
 
+func ConvertGasEstimate(obj *cstate.GasEstimate) GasEstimateResult {
+	evts := []NotifyEventInfo{}
+	for _, v := range obj.Notify {
+		evts = append(evts, NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States})
+	}
+	return GasEstimateResult{
+		State:             obj.State,
+		Gas:               obj.Gas,
+		GasLimit:          obj.GasLimit,
+		GasPrice:          obj.GasPrice,
+		SigVerifyIncluded: false,
+		Result:            obj.Result,
+		Notify:            evts,
+	}
+}
+
+func ConvertTxTrace(obj *trace.TxTrace) TxTraceInfo {
+	evts := []NotifyEventInfo{}
+	for _, v := range obj.Notify {
+		evts = append(evts, NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States})
+	}
+	return TxTraceInfo{
+		TxHash:      obj.TxHash.ToHexString(),
+		Height:      obj.Height,
+		State:       obj.State,
+		GasConsumed: obj.GasConsumed,
+		Error:       obj.Error,
+		Notify:      evts,
+		Calls:       obj.Calls,
+		Ops:         obj.Ops,
+		Storage:     obj.Storage,
+		Truncated:   obj.Truncated,
+	}
+}
+
+func ConvertGasProfile(obj *profile.GasProfile) GasProfileInfo {
+	return GasProfileInfo{
+		TxHash:      obj.TxHash.ToHexString(),
+		Height:      obj.Height,
+		State:       obj.State,
+		GasConsumed: obj.GasConsumed,
+		Error:       obj.Error,
+		Opcodes:     obj.Opcodes,
+		Syscalls:    obj.Syscalls,
+		Storage:     obj.Storage,
+		Contracts:   obj.Contracts,
+		Folded:      obj.Folded,
+	}
+}
+
 func TransArryByteToHexString(ptx *types.Transaction) *Transactions {
 	trans := new(Transactions)
 	trans.TxType = ptx.TxType
@@ -233,34 +861,39 @@ func SendTxToPool(txn *types.Transaction) (ontErrors.ErrCode, string) {
 	return ontErrors.ErrNoError, ""
 }
 
-func GetBlockInfo(block *types.Block) BlockInfo {
-	hash := block.Hash()
+func GetBlockHeadInfo(header *types.Header) *BlockHead {
+	hash := header.Hash()
 	var bookkeepers = []string{}
 	var sigData = []string{}
-	for i := 0; i < len(block.Header.SigData); i++ {
-		s := common.ToHexString(block.Header.SigData[i])
+	for i := 0; i < len(header.SigData); i++ {
+		s := common.ToHexString(header.SigData[i])
 		sigData = append(sigData, s)
 	}
-	for i := 0; i < len(block.Header.Bookkeepers); i++ {
-		e := block.Header.Bookkeepers[i]
+	for i := 0; i < len(header.Bookkeepers); i++ {
+		e := header.Bookkeepers[i]
 		key := keypair.SerializePublicKey(e)
 		bookkeepers = append(bookkeepers, common.ToHexString(key))
 	}
 
-	blockHead := &BlockHead{
-		Version:          block.Header.Version,
-		PrevBlockHash:    block.Header.PrevBlockHash.ToHexString(),
-		TransactionsRoot: block.Header.TransactionsRoot.ToHexString(),
-		BlockRoot:        block.Header.BlockRoot.ToHexString(),
-		Timestamp:        block.Header.Timestamp,
-		Height:           block.Header.Height,
-		ConsensusData:    block.Header.ConsensusData,
-		ConsensusPayload: common.ToHexString(block.Header.ConsensusPayload),
-		NextBookkeeper:   block.Header.NextBookkeeper.ToBase58(),
+	return &BlockHead{
+		Version:          header.Version,
+		PrevBlockHash:    header.PrevBlockHash.ToHexString(),
+		TransactionsRoot: header.TransactionsRoot.ToHexString(),
+		BlockRoot:        header.BlockRoot.ToHexString(),
+		Timestamp:        header.Timestamp,
+		Height:           header.Height,
+		ConsensusData:    header.ConsensusData,
+		ConsensusPayload: common.ToHexString(header.ConsensusPayload),
+		NextBookkeeper:   header.NextBookkeeper.ToBase58(),
 		Bookkeepers:      bookkeepers,
 		SigData:          sigData,
 		Hash:             hash.ToHexString(),
 	}
+}
+
+func GetBlockInfo(block *types.Block) BlockInfo {
+	hash := block.Hash()
+	blockHead := GetBlockHeadInfo(block.Header)
 
 	trans := make([]*Transactions, len(block.Transactions))
 	for i := 0; i < len(block.Transactions); i++ {
@@ -328,6 +961,66 @@ func GetAllowance(asset string, from, to common.Address) (string, error) {
 	return fmt.Sprintf("%v", allowance), nil
 }
 
+//ParseStateOverride parse the state override decoded from json request
+func ParseStateOverride(obj interface{}) (*cstate.StateOverride, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	info := new(StateOverrideInfo)
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("invalid state override:%s", err)
+	}
+	override := &cstate.StateOverride{Height: info.Height, Time: info.Time}
+	for _, v := range info.Balances {
+		var asset common.Address
+		switch strings.ToLower(v.Asset) {
+		case "ont":
+			asset = utils.OntContractAddress
+		case "ong":
+			asset = utils.OngContractAddress
+		default:
+			if asset, err = GetAddress(v.Asset); err != nil {
+				return nil, fmt.Errorf("invalid asset %s", v.Asset)
+			}
+		}
+		address, err := GetAddress(v.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s", v.Address)
+		}
+		override.Balances = append(override.Balances, &cstate.BalanceOverride{Asset: asset, Address: address, Balance: v.Balance})
+	}
+	for _, v := range info.Storage {
+		contract, err := GetAddress(v.Contract)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract %s", v.Contract)
+		}
+		key, err := common.HexToBytes(v.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid storage key %s", v.Key)
+		}
+		var value []byte
+		if v.Value != "" {
+			if value, err = common.HexToBytes(v.Value); err != nil {
+				return nil, fmt.Errorf("invalid storage value %s", v.Value)
+			}
+		}
+		override.Storage = append(override.Storage, &cstate.StorageOverride{Contract: contract, Key: key, Value: value})
+	}
+	for _, v := range info.Code {
+		contract, err := GetAddress(v.Contract)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract %s", v.Contract)
+		}
+		code, err := common.HexToBytes(v.Code)
+		if err != nil || len(code) == 0 {
+			return nil, fmt.Errorf("invalid code of contract %s", v.Contract)
+		}
+		override.Code = append(override.Code, &cstate.CodeOverride{Contract: contract, Code: code})
+	}
+	return override, nil
+}
+
 func GetContractBalance(cVersion byte, contractAddr, accAddr common.Address) (uint64, error) {
 	mutable, err := NewNativeInvokeTransaction(0, 0, contractAddr, cVersion, "balanceOf", []interface{}{accAddr[:]})
 	if err != nil {
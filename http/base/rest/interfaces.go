@@ -20,6 +20,7 @@ package rest
 
 import (
 	"bytes"
+	"github.com/dnaproject2/DNA/cmd/abi"
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
@@ -30,6 +31,7 @@ import (
 	bcomn "github.com/dnaproject2/DNA/http/base/common"
 	berr "github.com/dnaproject2/DNA/http/base/error"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	cstates "github.com/dnaproject2/DNA/smartcontract/states"
 	"strconv"
 )
 
@@ -94,6 +96,9 @@ func GetBlockHash(cmd map[string]interface{}) map[string]interface{} {
 
 func getBlock(hash common.Uint256, getTxBytes bool) (interface{}, int64) {
 	block, err := bactor.GetBlockFromStore(hash)
+	if err == scom.ErrPruned {
+		return nil, berr.DATA_PRUNED
+	}
 	if err != nil {
 		return nil, berr.UNKNOWN_BLOCK
 	}
@@ -141,10 +146,10 @@ func GetBlockHeightByTxHash(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	height, tx, err := bactor.GetTxnWithHeightByTxHash(hash)
-	if err != nil {
+	if err != nil && err != scom.ErrPruned {
 		return ResponsePack(berr.INTERNAL_ERROR)
 	}
-	if tx == nil {
+	if tx == nil && err == nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	resp["Result"] = height
@@ -169,6 +174,9 @@ func GetBlockTxsByHeight(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	block, err := bactor.GetBlockFromStore(hash)
+	if err == scom.ErrPruned {
+		return ResponsePack(berr.DATA_PRUNED)
+	}
 	if err != nil {
 		return ResponsePack(berr.UNKNOWN_BLOCK)
 	}
@@ -197,6 +205,9 @@ func GetBlockByHeight(cmd map[string]interface{}) map[string]interface{} {
 	}
 	index := uint32(height)
 	block, err := bactor.GetBlockByHeight(index)
+	if err == scom.ErrPruned {
+		return ResponsePack(berr.DATA_PRUNED)
+	}
 	if err != nil || block == nil {
 		return ResponsePack(berr.UNKNOWN_BLOCK)
 	}
@@ -221,6 +232,9 @@ func GetTransactionByHash(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	height, tx, err := bactor.GetTxnWithHeightByTxHash(hash)
+	if err == scom.ErrPruned {
+		return ResponsePack(berr.DATA_PRUNED)
+	}
 	if tx == nil {
 		return ResponsePack(berr.UNKNOWN_TRANSACTION)
 	}
@@ -242,6 +256,58 @@ func GetTransactionByHash(cmd map[string]interface{}) map[string]interface{} {
 	return resp
 }
 
+//estimate the gas of raw transaction, signature verification is not included as the node does not charge it
+func EstimateGas(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+
+	str, ok := cmd["Data"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	bys, err := common.HexToBytes(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	txn, err := types.TransactionFromRawBytes(bys)
+	if err != nil {
+		return ResponsePack(berr.INVALID_TRANSACTION)
+	}
+	result, err := bactor.EstimateGas(txn)
+	if err != nil {
+		resp = ResponsePack(berr.SMARTCODE_ERROR)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	resp["Result"] = bcomn.ConvertGasEstimate(result)
+	return resp
+}
+
+//profile the gas of raw transaction in pre-execution
+func ProfileGas(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+
+	str, ok := cmd["Data"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	bys, err := common.HexToBytes(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	txn, err := types.TransactionFromRawBytes(bys)
+	if err != nil {
+		return ResponsePack(berr.INVALID_TRANSACTION)
+	}
+	result, err := bactor.ProfilePreExecute(txn)
+	if err != nil {
+		resp = ResponsePack(berr.SMARTCODE_ERROR)
+		resp["Result"] = err.Error()
+		return resp
+	}
+	resp["Result"] = bcomn.ConvertGasProfile(result)
+	return resp
+}
+
 //send raw transaction
 func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -264,7 +330,16 @@ func SendRawTransaction(cmd map[string]interface{}) map[string]interface{} {
 	log.Debugf("SendRawTransaction recv %s", hash.ToHexString())
 	if txn.TxType == types.Invoke || txn.TxType == types.Deploy {
 		if preExec, ok := cmd["PreExec"].(string); ok && preExec == "1" {
-			rst, err := bactor.PreExecuteContract(txn)
+			var override *cstates.StateOverride
+			if obj, ok := cmd["Override"]; ok {
+				override, err = bcomn.ParseStateOverride(obj)
+				if err != nil {
+					resp = ResponsePack(berr.INVALID_PARAMS)
+					resp["Result"] = err.Error()
+					return resp
+				}
+			}
+			rst, err := bactor.PreExecuteContractWithOverride(txn, override)
 			if err != nil {
 				log.Infof("PreExec: ", err)
 				resp = ResponsePack(berr.SMARTCODE_ERROR)
@@ -308,11 +383,18 @@ func GetSmartCodeEventTxsByHeight(cmd map[string]interface{}) map[string]interfa
 		if scom.ErrNotFound == err {
 			return ResponsePack(berr.SUCCESS)
 		}
+		if scom.ErrPruned == err {
+			return ResponsePack(berr.DATA_PRUNED)
+		}
 		return ResponsePack(berr.INTERNAL_ERROR)
 	}
+	decode, _ := cmd["Decode"].(string)
 	eInfos := make([]*bcomn.ExecuteNotify, 0, len(eventInfos))
 	for _, eventInfo := range eventInfos {
 		_, notify := bcomn.GetExecuteNotify(eventInfo)
+		if decode == "1" {
+			bcomn.DecodeExecuteNotify(&notify)
+		}
 		eInfos = append(eInfos, &notify)
 	}
 	resp["Result"] = eInfos
@@ -340,16 +422,215 @@ func GetSmartCodeEventByTxHash(cmd map[string]interface{}) map[string]interface{
 		if scom.ErrNotFound == err {
 			return ResponsePack(berr.SUCCESS)
 		}
+		if scom.ErrPruned == err {
+			return ResponsePack(berr.DATA_PRUNED)
+		}
 		return ResponsePack(berr.INTERNAL_ERROR)
 	}
 	if eventInfo == nil {
 		return ResponsePack(berr.INVALID_TRANSACTION)
 	}
 	_, notify := bcomn.GetExecuteNotify(eventInfo)
+	if decode, ok := cmd["Decode"].(string); ok && decode == "1" {
+		bcomn.DecodeExecuteNotify(&notify)
+	}
 	resp["Result"] = notify
 	return resp
 }
 
+//get the abi of contract used to decode events
+func GetAbi(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := common.AddressFromHexString(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	if nativeAbi := abi.DefAbiMgr.GetNativeAbi(address.ToHexString()); nativeAbi != nil {
+		resp["Result"] = nativeAbi
+	} else if neovmAbi := abi.DefAbiMgr.GetNeovmAbi(address.ToHexString()); neovmAbi != nil {
+		resp["Result"] = neovmAbi
+	}
+	return resp
+}
+
+//get the abi and source hash published in registry contract
+func GetContractAbi(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	meta, err := bcomn.GetContractMetadata(address)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	if meta != nil {
+		resp["Result"] = bcomn.ConvertContractMetadata(address, meta)
+	}
+	return resp
+}
+
+//get the admin, roles and active delegations of contract in auth contract
+func GetContractAuth(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	info, err := bcomn.GetContractAuth(address)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = info
+	return resp
+}
+
+//get the code version history of contract
+func GetContractHistory(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Hash"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	versions, err := bcomn.GetContractVersions(address)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	if versions != nil {
+		resp["Result"] = bcomn.ConvertContractVersions(versions)
+	}
+	return resp
+}
+
+//resolve the ID registered in ontid contract into W3C DID document
+func ResolveDID(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	id, ok := cmd["ID"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	result, err := bcomn.ResolveDID(id)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = result
+	return resp
+}
+
+//get the token of nft contract by token id
+func GetNftToken(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Id"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	tokenId, err := common.HexToBytes(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	token, err := bcomn.GetNftToken(tokenId)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	if token == nil {
+		return ResponsePack(berr.UNKNOWN_ASSET)
+	}
+	resp["Result"] = token
+	return resp
+}
+
+//get the tokens of nft contract owned by address, the start and limit of page are optional
+func GetNftTokens(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	owner, err := bcomn.GetAddress(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	var page [2]uint64
+	for i, name := range []string{"Start", "Limit"} {
+		if str, ok := cmd[name].(string); ok && str != "" {
+			if page[i], err = strconv.ParseUint(str, 10, 64); err != nil {
+				return ResponsePack(berr.INVALID_PARAMS)
+			}
+		}
+	}
+	list, err := bcomn.GetNftTokens(owner, page[0], page[1])
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = list
+	return resp
+}
+
+//get the peers of current view with stake and status, and the consensus peers of next view
+func GetGovernancePeers(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	info, err := bcomn.GetGovernancePeers()
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = info
+	return resp
+}
+
+//get the authorizations of address to peers, and the stake and fee can be withdrawn
+func GetGovernanceStake(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["Addr"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	info, err := bcomn.GetGovernanceStake(address)
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = info
+	return resp
+}
+
+//get the fee split of peers at the end of view
+func GetFeeSplit(cmd map[string]interface{}) map[string]interface{} {
+	resp := ResponsePack(berr.SUCCESS)
+	str, ok := cmd["View"].(string)
+	if !ok {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	view, err := strconv.ParseUint(str, 10, 32)
+	if err != nil {
+		return ResponsePack(berr.INVALID_PARAMS)
+	}
+	info, err := bcomn.GetFeeSplit(uint32(view))
+	if err != nil {
+		return ResponsePack(berr.INTERNAL_ERROR)
+	}
+	resp["Result"] = info
+	return resp
+}
+
 //get contract state
 func GetContractState(cmd map[string]interface{}) map[string]interface{} {
 	resp := ResponsePack(berr.SUCCESS)
@@ -436,10 +717,10 @@ func GetMerkleProof(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	height, tx, err := bactor.GetTxnWithHeightByTxHash(hash)
-	if err != nil {
+	if err != nil && err != scom.ErrPruned {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
-	if tx == nil {
+	if tx == nil && err == nil {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	header, err := bactor.GetHeaderByHeight(height)
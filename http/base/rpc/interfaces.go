@@ -21,6 +21,7 @@ package rpc
 import (
 	"bytes"
 	"encoding/hex"
+	"github.com/dnaproject2/DNA/cmd/abi"
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
@@ -32,6 +33,8 @@ import (
 	bcomn "github.com/dnaproject2/DNA/http/base/common"
 	berr "github.com/dnaproject2/DNA/http/base/error"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	cstates "github.com/dnaproject2/DNA/smartcontract/states"
+	"math"
 )
 
 //get best block hash
@@ -69,6 +72,9 @@ func GetBlock(params []interface{}) map[string]interface{} {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
 	block, err := bactor.GetBlockFromStore(hash)
+	if err == scom.ErrPruned {
+		return responsePack(berr.DATA_PRUNED, "block pruned")
+	}
 	if err != nil {
 		return responsePack(berr.UNKNOWN_BLOCK, "unknown block")
 	}
@@ -187,6 +193,9 @@ func GetRawTransaction(params []interface{}) map[string]interface{} {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
 		h, t, err := bactor.GetTxnWithHeightByTxHash(hash)
+		if err == scom.ErrPruned {
+			return responsePack(berr.DATA_PRUNED, "transaction pruned")
+		}
 		if err != nil {
 			return responsePack(berr.UNKNOWN_TRANSACTION, "unknown transaction")
 		}
@@ -259,6 +268,11 @@ func GetStorage(params []interface{}) map[string]interface{} {
 //send raw transaction
 // A JSON example for sendrawtransaction method as following:
 //   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex"], "id": 0}
+// to pre-execute the transaction against the states replaced by override:
+//   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex", 1,
+//     {"Height": 0, "Time": 0, "Balances": [{"Asset": "ong", "Address": "base58 address", "Balance": 100}],
+//     "Storage": [{"Contract": "contract address", "Key": "hex key", "Value": "hex value"}],
+//     "Code": [{"Contract": "contract address", "Code": "hex code"}]}], "id": 0}
 func SendRawTransaction(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
@@ -281,7 +295,14 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 			if len(params) > 1 {
 				preExec, ok := params[1].(float64)
 				if ok && preExec == 1 {
-					result, err := bactor.PreExecuteContract(txn)
+					var override *cstates.StateOverride
+					if len(params) > 2 {
+						override, err = bcomn.ParseStateOverride(params[2])
+						if err != nil {
+							return responsePack(berr.INVALID_PARAMS, err.Error())
+						}
+					}
+					result, err := bactor.PreExecuteContractWithOverride(txn, override)
 					if err != nil {
 						log.Infof("PreExec: ", err)
 						return responsePack(berr.SMARTCODE_ERROR, err.Error())
@@ -303,6 +324,115 @@ func SendRawTransaction(params []interface{}) map[string]interface{} {
 	return responseSuccess(hash.ToHexString())
 }
 
+//estimate the gas of transaction, signature verification is not included as the node does not charge it
+// A JSON example for estimategas method as following:
+//   {"jsonrpc": "2.0", "method": "estimategas", "params": ["raw transaction in hex"], "id": 0}
+func EstimateGas(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	raw, err := common.HexToBytes(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	txn, err := types.TransactionFromRawBytes(raw)
+	if err != nil {
+		return responsePack(berr.INVALID_TRANSACTION, "")
+	}
+	result, err := bactor.EstimateGas(txn)
+	if err != nil {
+		return responsePack(berr.SMARTCODE_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.ConvertGasEstimate(result))
+}
+
+//profile the gas of transaction in pre-execution, attributed to opcodes, syscalls, storage writes and contracts
+// A JSON example for profilegas method as following:
+//   {"jsonrpc": "2.0", "method": "profilegas", "params": ["raw transaction in hex"], "id": 0}
+// the Folded field of result is in folded stack format, which can be rendered by flame graph tools
+func ProfileGas(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	raw, err := common.HexToBytes(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	txn, err := types.TransactionFromRawBytes(raw)
+	if err != nil {
+		return responsePack(berr.INVALID_TRANSACTION, "")
+	}
+	result, err := bactor.ProfilePreExecute(txn)
+	if err != nil {
+		return responsePack(berr.SMARTCODE_ERROR, err.Error())
+	}
+	return responseSuccess(bcomn.ConvertGasProfile(result))
+}
+
+//trace the execution of transaction
+// A JSON example for tracetransaction method as following:
+//   {"jsonrpc": "2.0", "method": "tracetransaction", "params": ["transaction hash in hex"], "id": 0}
+// the transaction on chain is re-executed against the states before it, which are kept by --trace-history.
+// to trace the pre-execution of a new transaction:
+//   {"jsonrpc": "2.0", "method": "tracetransaction", "params": ["raw transaction in hex", 1], "id": 0}
+func TraceTransaction(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	preExec := false
+	if len(params) > 1 {
+		flag, ok := params[1].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		preExec = flag == 1
+	}
+	if preExec {
+		raw, err := common.HexToBytes(str)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		txn, err := types.TransactionFromRawBytes(raw)
+		if err != nil {
+			return responsePack(berr.INVALID_TRANSACTION, "")
+		}
+		result, err := bactor.TracePreExecute(txn)
+		if err != nil {
+			return responsePack(berr.SMARTCODE_ERROR, err.Error())
+		}
+		return responseSuccess(bcomn.ConvertTxTrace(result))
+	}
+	hash, err := common.Uint256FromHexString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	result, err := bactor.TraceTransaction(hash)
+	switch err {
+	case nil:
+		return responseSuccess(bcomn.ConvertTxTrace(result))
+	case scom.ErrNotFound:
+		return responsePack(berr.UNKNOWN_TRANSACTION, "unknown transaction")
+	case scom.ErrPruned:
+		return responsePack(berr.DATA_PRUNED, "transaction pruned")
+	case scom.ErrNoStateHistory:
+		return responsePack(berr.NO_STATE_HISTORY, "")
+	default:
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+}
+
 //get node version
 func GetNodeVersion(params []interface{}) map[string]interface{} {
 	return responseSuccess(config.Version)
@@ -351,6 +481,8 @@ func GetContractState(params []interface{}) map[string]interface{} {
 }
 
 //get smartconstract event
+//with the optional second param 1, the events of contracts with registered abi are decoded to named fields, e.g.
+//   {"jsonrpc": "2.0", "method": "getsmartcodeevent", "params": ["transaction hash in hex", 1], "id": 0}
 func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if !config.DefConfig.Common.EnableEventLog {
 		return responsePack(berr.INVALID_METHOD, "")
@@ -358,6 +490,14 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
 	}
+	decode := false
+	if len(params) > 1 {
+		flag, ok := params[1].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		decode = flag == 1
+	}
 
 	switch (params[0]).(type) {
 	// block height
@@ -368,11 +508,17 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 			if err == scom.ErrNotFound {
 				return responseSuccess(nil)
 			}
+			if err == scom.ErrPruned {
+				return responsePack(berr.DATA_PRUNED, "event pruned")
+			}
 			return responsePack(berr.INTERNAL_ERROR, "")
 		}
 		eInfos := make([]*bcomn.ExecuteNotify, 0, len(eventInfos))
 		for _, eventInfo := range eventInfos {
 			_, notify := bcomn.GetExecuteNotify(eventInfo)
+			if decode {
+				bcomn.DecodeExecuteNotify(&notify)
+			}
 			eInfos = append(eInfos, &notify)
 		}
 		return responseSuccess(eInfos)
@@ -388,9 +534,15 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 			if scom.ErrNotFound == err {
 				return responseSuccess(nil)
 			}
+			if scom.ErrPruned == err {
+				return responsePack(berr.DATA_PRUNED, "event pruned")
+			}
 			return responsePack(berr.INTERNAL_ERROR, "")
 		}
 		_, notify := bcomn.GetExecuteNotify(eventInfo)
+		if decode {
+			bcomn.DecodeExecuteNotify(&notify)
+		}
 		return responseSuccess(notify)
 	default:
 		return responsePack(berr.INVALID_PARAMS, "")
@@ -398,6 +550,236 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	return responsePack(berr.INVALID_PARAMS, "")
 }
 
+//get the abi of contract used to decode events, including the native contract abi
+// A JSON example for getabi method as following:
+//   {"jsonrpc": "2.0", "method": "getabi", "params": ["contract address in hex"], "id": 0}
+func GetAbi(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := common.AddressFromHexString(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	if nativeAbi := abi.DefAbiMgr.GetNativeAbi(address.ToHexString()); nativeAbi != nil {
+		return responseSuccess(nativeAbi)
+	}
+	if neovmAbi := abi.DefAbiMgr.GetNeovmAbi(address.ToHexString()); neovmAbi != nil {
+		return responseSuccess(neovmAbi)
+	}
+	return responseSuccess(nil)
+}
+
+//get the abi and source hash published in registry contract by the deployer
+// A JSON example for getcontractabi method as following:
+//   {"jsonrpc": "2.0", "method": "getcontractabi", "params": ["contract address in hex"], "id": 0}
+func GetContractAbi(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	meta, err := bcomn.GetContractMetadata(address)
+	if err != nil {
+		log.Errorf("GetContractAbi error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	if meta == nil {
+		return responseSuccess(nil)
+	}
+	return responseSuccess(bcomn.ConvertContractMetadata(address, meta))
+}
+
+//get the admin, roles with functions and holders, and active delegations of contract in auth contract
+// A JSON example for getcontractauth method as following:
+//   {"jsonrpc": "2.0", "method": "getcontractauth", "params": ["contract address in hex"], "id": 0}
+func GetContractAuth(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	info, err := bcomn.GetContractAuth(address)
+	if err != nil {
+		log.Errorf("GetContractAuth error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(info)
+}
+
+//get the code version history of contract, from the first deployment to the latest upgrade
+// A JSON example for getcontracthistory method as following:
+//   {"jsonrpc": "2.0", "method": "getcontracthistory", "params": ["contract address in hex"], "id": 0}
+func GetContractHistory(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	versions, err := bcomn.GetContractVersions(address)
+	if err != nil {
+		log.Errorf("GetContractHistory error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	if versions == nil {
+		return responseSuccess(nil)
+	}
+	return responseSuccess(bcomn.ConvertContractVersions(versions))
+}
+
+//resolve the ID registered in ontid contract into W3C DID document
+// A JSON example for resolvedid method as following:
+//   {"jsonrpc": "2.0", "method": "resolvedid", "params": ["did:dna:..."], "id": 0}
+func ResolveDID(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	id, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	result, err := bcomn.ResolveDID(id)
+	if err != nil {
+		log.Errorf("ResolveDID error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(result)
+}
+
+//get the token of nft contract by token id
+// A JSON example for getnfttoken method as following:
+//   {"jsonrpc": "2.0", "method": "getnfttoken", "params": ["token id in hex"], "id": 0}
+func GetNftToken(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	tokenId, err := common.HexToBytes(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	token, err := bcomn.GetNftToken(tokenId)
+	if err != nil {
+		log.Errorf("GetNftToken error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	if token == nil {
+		return responsePack(berr.UNKNOWN_ASSET, "")
+	}
+	return responseSuccess(token)
+}
+
+//get the tokens of nft contract owned by address, the start and limit of page are optional
+// A JSON example for getnfttokens method as following:
+//   {"jsonrpc": "2.0", "method": "getnfttokens", "params": ["address", 0, 20], "id": 0}
+func GetNftTokens(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	owner, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var page [2]uint64
+	for i := 1; i < len(params) && i <= len(page); i++ {
+		v, ok := params[i].(float64)
+		if !ok || v < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		page[i-1] = uint64(v)
+	}
+	list, err := bcomn.GetNftTokens(owner, page[0], page[1])
+	if err != nil {
+		log.Errorf("GetNftTokens error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(list)
+}
+
+//get the peers of current view with stake and status, and the consensus peers of next view
+// A JSON example for getgovernancepeers method as following:
+//   {"jsonrpc": "2.0", "method": "getgovernancepeers", "params": [], "id": 0}
+func GetGovernancePeers(params []interface{}) map[string]interface{} {
+	info, err := bcomn.GetGovernancePeers()
+	if err != nil {
+		log.Errorf("GetGovernancePeers error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(info)
+}
+
+//get the authorizations of address to peers, and the stake and fee can be withdrawn
+// A JSON example for getgovernancestake method as following:
+//   {"jsonrpc": "2.0", "method": "getgovernancestake", "params": ["address"], "id": 0}
+func GetGovernanceStake(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	str, ok := params[0].(string)
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	address, err := bcomn.GetAddress(str)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	info, err := bcomn.GetGovernanceStake(address)
+	if err != nil {
+		log.Errorf("GetGovernanceStake error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(info)
+}
+
+//get the fee split of peers at the end of view, the result is null if the fee split of view is not recorded,
+//e.g. views committed before the fee split recording is activated
+// A JSON example for getfeesplit method as following:
+//   {"jsonrpc": "2.0", "method": "getfeesplit", "params": [view], "id": 0}
+func GetFeeSplit(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	view, ok := params[0].(float64)
+	if !ok || view < 0 || view > math.MaxUint32 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	info, err := bcomn.GetFeeSplit(uint32(view))
+	if err != nil {
+		log.Errorf("GetFeeSplit error:%s", err)
+		return responsePack(berr.INTERNAL_ERROR, "")
+	}
+	return responseSuccess(info)
+}
+
 //get block height by transaction hash
 func GetBlockHeightByTxHash(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -413,7 +795,7 @@ func GetBlockHeightByTxHash(params []interface{}) map[string]interface{} {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
 		height, _, err := bactor.GetTxnWithHeightByTxHash(hash)
-		if err != nil {
+		if err != nil && err != scom.ErrPruned {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
 		return responseSuccess(height)
@@ -489,7 +871,7 @@ func GetMerkleProof(params []interface{}) map[string]interface{} {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
 	height, _, err := bactor.GetTxnWithHeightByTxHash(hash)
-	if err != nil {
+	if err != nil && err != scom.ErrPruned {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
 	header, err := bactor.GetHeaderByHeight(height)
@@ -527,6 +909,9 @@ func GetBlockTxsByHeight(params []interface{}) map[string]interface{} {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
 		block, err := bactor.GetBlockFromStore(hash)
+		if err == scom.ErrPruned {
+			return responsePack(berr.DATA_PRUNED, "block pruned")
+		}
 		if err != nil {
 			return responsePack(berr.UNKNOWN_BLOCK, "")
 		}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rpc
+
+import (
+	"bytes"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/types"
+	bactor "github.com/dnaproject2/DNA/http/base/actor"
+	bcomn "github.com/dnaproject2/DNA/http/base/common"
+	berr "github.com/dnaproject2/DNA/http/base/error"
+)
+
+//get block header by height or hash, the headers are verified by light node
+// Input JSON string examples for getheader method as following:
+//   {"jsonrpc": "2.0", "method": "getheader", "params": [1], "id": 0}
+//   {"jsonrpc": "2.0", "method": "getheader", "params": ["aabbcc.."], "id": 0}
+func GetHeader(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	var err error
+	var hash common.Uint256
+	switch (params[0]).(type) {
+	// block height
+	case float64:
+		index := uint32(params[0].(float64))
+		hash = bactor.GetBlockHashFromStore(index)
+		if hash == common.UINT256_EMPTY {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		// block hash
+	case string:
+		str := params[0].(string)
+		hash, err = common.Uint256FromHexString(str)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	header, err := bactor.GetHeaderFromStore(hash)
+	if err != nil || header == nil {
+		return responsePack(berr.UNKNOWN_BLOCK, "unknown block")
+	}
+	if len(params) >= 2 {
+		switch (params[1]).(type) {
+		case float64:
+			json := uint32(params[1].(float64))
+			if json == 1 {
+				return responseSuccess(bcomn.GetBlockHeadInfo(header))
+			}
+		default:
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	return responseSuccess(common.ToHexString(header.ToArray()))
+}
+
+//get transaction by hash from full nodes, the transaction is verified by merkle proof to the local header
+//   {"jsonrpc": "2.0", "method": "getrawtransaction", "params": ["transaction hash", 1], "id": 0}
+func GetVerifiedRawTransaction(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	var tx *types.Transaction
+	var height uint32
+	switch params[0].(type) {
+	case string:
+		str := params[0].(string)
+		hash, err := common.Uint256FromHexString(str)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		t, h, err := bactor.GetVerifiedTransaction(hash)
+		if err != nil {
+			log.Debugf("GetVerifiedRawTransaction %s error:%s", str, err)
+			return responsePack(berr.UNKNOWN_TRANSACTION, "unknown transaction")
+		}
+		height = h
+		tx = t
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+
+	if len(params) >= 2 {
+		switch (params[1]).(type) {
+		case float64:
+			json := uint32(params[1].(float64))
+			if json == 1 {
+				txinfo := bcomn.TransArryByteToHexString(tx)
+				txinfo.Height = height
+				return responseSuccess(txinfo)
+			}
+		default:
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	}
+	w := bytes.NewBuffer(nil)
+	tx.Serialize(w)
+	return responseSuccess(common.ToHexString(w.Bytes()))
+}
+
+//relay raw transaction to full nodes. Light node has no txpool, so the transaction is not verified before relay
+//   {"jsonrpc": "2.0", "method": "sendrawtransaction", "params": ["raw transactioin in hex"], "id": 0}
+func RelayRawTransaction(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, nil)
+	}
+	var hash common.Uint256
+	switch params[0].(type) {
+	case string:
+		str := params[0].(string)
+		raw, err := common.HexToBytes(str)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		txn, err := types.TransactionFromRawBytes(raw)
+		if err != nil {
+			return responsePack(berr.INVALID_TRANSACTION, "")
+		}
+		hash = txn.Hash()
+		log.Debugf("RelayRawTransaction recv %s", hash.ToHexString())
+		if err := bactor.Xmit(txn); err != nil {
+			return responsePack(berr.INTERNAL_ERROR, err.Error())
+		}
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	return responseSuccess(hash.ToHexString())
+}
@@ -19,9 +19,11 @@
 package rpc
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 
+	"github.com/dnaproject2/DNA/cmd/abi"
 	"github.com/dnaproject2/DNA/common/log"
 	bactor "github.com/dnaproject2/DNA/http/base/actor"
 	"github.com/dnaproject2/DNA/http/base/common"
@@ -122,3 +124,35 @@ func SetDebugInfo(params []interface{}) map[string]interface{} {
 	}
 	return responsePack(berr.SUCCESS, true)
 }
+
+//register the abi of neovm contract, used to decode the contract events
+// A JSON example for registerabi method as following:
+//   {"jsonrpc": "2.0", "method": "registerabi", "params": [{"hash": "contract address in hex", "events": [...]}], "id": 0}
+func RegisterAbi(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	var data []byte
+	switch v := params[0].(type) {
+	case string:
+		data = []byte(v)
+	case map[string]interface{}:
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+	default:
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	neovmAbi := &abi.NeovmContractAbi{}
+	err := json.Unmarshal(data, neovmAbi)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	err = abi.DefAbiMgr.RegisterNeovmAbi(neovmAbi)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	return responsePack(berr.SUCCESS, true)
+}
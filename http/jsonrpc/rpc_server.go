@@ -42,6 +42,19 @@ func StartRPCServer() error {
 
 	rpc.HandleFunc("getrawtransaction", rpc.GetRawTransaction)
 	rpc.HandleFunc("sendrawtransaction", rpc.SendRawTransaction)
+	rpc.HandleFunc("tracetransaction", rpc.TraceTransaction)
+	rpc.HandleFunc("estimategas", rpc.EstimateGas)
+	rpc.HandleFunc("profilegas", rpc.ProfileGas)
+	rpc.HandleFunc("getabi", rpc.GetAbi)
+	rpc.HandleFunc("getcontractabi", rpc.GetContractAbi)
+	rpc.HandleFunc("getcontracthistory", rpc.GetContractHistory)
+	rpc.HandleFunc("getcontractauth", rpc.GetContractAuth)
+	rpc.HandleFunc("resolvedid", rpc.ResolveDID)
+	rpc.HandleFunc("getnfttoken", rpc.GetNftToken)
+	rpc.HandleFunc("getnfttokens", rpc.GetNftTokens)
+	rpc.HandleFunc("getgovernancepeers", rpc.GetGovernancePeers)
+	rpc.HandleFunc("getgovernancestake", rpc.GetGovernanceStake)
+	rpc.HandleFunc("getfeesplit", rpc.GetFeeSplit)
 	rpc.HandleFunc("getstorage", rpc.GetStorage)
 	rpc.HandleFunc("getversion", rpc.GetNodeVersion)
 	rpc.HandleFunc("getnetworkid", rpc.GetNetworkId)
@@ -66,3 +79,26 @@ func StartRPCServer() error {
 	}
 	return nil
 }
+
+//StartLightRPCServer start the reduced rpc server of light node, which only serves verified headers and transactions
+func StartLightRPCServer() error {
+	log.Debug()
+	http.HandleFunc("/", rpc.Handle)
+
+	rpc.HandleFunc("getbestblockhash", rpc.GetBestBlockHash)
+	rpc.HandleFunc("getblockcount", rpc.GetBlockCount)
+	rpc.HandleFunc("getblockhash", rpc.GetBlockHash)
+	rpc.HandleFunc("getheader", rpc.GetHeader)
+	rpc.HandleFunc("getconnectioncount", rpc.GetConnectionCount)
+
+	rpc.HandleFunc("getrawtransaction", rpc.GetVerifiedRawTransaction)
+	rpc.HandleFunc("sendrawtransaction", rpc.RelayRawTransaction)
+	rpc.HandleFunc("getversion", rpc.GetNodeVersion)
+	rpc.HandleFunc("getnetworkid", rpc.GetNetworkId)
+
+	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
+	if err != nil {
+		return fmt.Errorf("ListenAndServe error:%s", err)
+	}
+	return nil
+}
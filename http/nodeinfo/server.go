@@ -28,6 +28,7 @@ import (
 
 	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/core/ledger"
+	"github.com/dnaproject2/DNA/p2pserver/common"
 	p2p "github.com/dnaproject2/DNA/p2pserver/net/protocol"
 )
 
@@ -48,6 +49,7 @@ type Info struct {
 const (
 	VERIFYNODE  = "Verify Node"
 	SERVICENODE = "Service Node"
+	LIGHTNODE   = "Light Node"
 )
 
 var node p2p.P2P
@@ -82,11 +84,17 @@ func viewHandler(w http.ResponseWriter, r *http.Request) {
 	var ngbVersion string
 
 	curNodeType := SERVICENODE
+	if config.DefConfig.Common.EnableLightMode {
+		curNodeType = LIGHTNODE
+	}
 
 	ngbrNoders := node.GetNeighbors()
 	ngbrsLen := len(ngbrNoders)
 	for i := 0; i < ngbrsLen; i++ {
 		ngbType = SERVICENODE
+		if ngbrNoders[i].GetServices()&common.LIGHT_NODE != 0 {
+			ngbType = LIGHTNODE
+		}
 		ngbAddr = ngbrNoders[i].GetAddr()
 		ngbInfoPort = ngbrNoders[i].GetHttpInfoPort()
 		ngbInfoState = ngbrNoders[i].GetHttpInfoState()
@@ -73,10 +73,22 @@ const (
 	GET_GRANTONG          = "/api/v1/grantong/:addr"
 	GET_MEMPOOL_TXCOUNT   = "/api/v1/mempool/txcount"
 	GET_MEMPOOL_TXSTATE   = "/api/v1/mempool/txstate/:hash"
+	GET_ABI               = "/api/v1/abi/:hash"
+	GET_CONTRACT_ABI      = "/api/v1/contractabi/:hash"
+	GET_CONTRACT_HISTORY  = "/api/v1/contracthistory/:hash"
+	GET_CONTRACT_AUTH     = "/api/v1/contractauth/:hash"
+	GET_RESOLVE_DID       = "/api/v1/resolvedid/:did"
+	GET_NFT_TOKEN         = "/api/v1/nfttoken/:id"
+	GET_NFT_TOKENS        = "/api/v1/nfttokens/:addr"
+	GET_GOVERNANCE_PEERS  = "/api/v1/governance/peers"
+	GET_GOVERNANCE_STAKE  = "/api/v1/governance/stake/:addr"
+	GET_FEE_SPLIT         = "/api/v1/governance/feesplit/:view"
 	GET_VERSION           = "/api/v1/version"
 	GET_NETWORKID         = "/api/v1/networkid"
 
-	POST_RAW_TX = "/api/v1/transaction"
+	POST_RAW_TX       = "/api/v1/transaction"
+	POST_ESTIMATE_GAS = "/api/v1/estimategas"
+	POST_PROFILE_GAS  = "/api/v1/profilegas"
 )
 
 //init restful server
@@ -153,12 +165,24 @@ func (this *restServer) registryMethod() {
 		GET_GRANTONG:          {name: "getgrantong", handler: rest.GetGrantOng},
 		GET_MEMPOOL_TXCOUNT:   {name: "getmempooltxcount", handler: rest.GetMemPoolTxCount},
 		GET_MEMPOOL_TXSTATE:   {name: "getmempooltxstate", handler: rest.GetMemPoolTxState},
+		GET_ABI:               {name: "getabi", handler: rest.GetAbi},
+		GET_CONTRACT_ABI:      {name: "getcontractabi", handler: rest.GetContractAbi},
+		GET_CONTRACT_HISTORY:  {name: "getcontracthistory", handler: rest.GetContractHistory},
+		GET_CONTRACT_AUTH:     {name: "getcontractauth", handler: rest.GetContractAuth},
+		GET_RESOLVE_DID:       {name: "resolvedid", handler: rest.ResolveDID},
+		GET_NFT_TOKEN:         {name: "getnfttoken", handler: rest.GetNftToken},
+		GET_NFT_TOKENS:        {name: "getnfttokens", handler: rest.GetNftTokens},
+		GET_GOVERNANCE_PEERS:  {name: "getgovernancepeers", handler: rest.GetGovernancePeers},
+		GET_GOVERNANCE_STAKE:  {name: "getgovernancestake", handler: rest.GetGovernanceStake},
+		GET_FEE_SPLIT:         {name: "getfeesplit", handler: rest.GetFeeSplit},
 		GET_VERSION:           {name: "getversion", handler: rest.GetNodeVersion},
 		GET_NETWORKID:         {name: "getnetworkid", handler: rest.GetNetworkId},
 	}
 
 	postMethodMap := map[string]Action{
-		POST_RAW_TX: {name: "sendrawtransaction", handler: rest.SendRawTransaction},
+		POST_RAW_TX:       {name: "sendrawtransaction", handler: rest.SendRawTransaction},
+		POST_ESTIMATE_GAS: {name: "estimategas", handler: rest.EstimateGas},
+		POST_PROFILE_GAS:  {name: "profilegas", handler: rest.ProfileGas},
 	}
 	this.postMap = postMethodMap
 	this.getMap = getMethodMap
@@ -197,6 +221,24 @@ func (this *restServer) getPath(url string) string {
 		return GET_GRANTONG
 	} else if strings.Contains(url, strings.TrimRight(GET_MEMPOOL_TXSTATE, ":hash")) {
 		return GET_MEMPOOL_TXSTATE
+	} else if strings.Contains(url, strings.TrimRight(GET_ABI, ":hash")) {
+		return GET_ABI
+	} else if strings.Contains(url, strings.TrimRight(GET_CONTRACT_ABI, ":hash")) {
+		return GET_CONTRACT_ABI
+	} else if strings.Contains(url, strings.TrimRight(GET_CONTRACT_HISTORY, ":hash")) {
+		return GET_CONTRACT_HISTORY
+	} else if strings.Contains(url, strings.TrimRight(GET_CONTRACT_AUTH, ":hash")) {
+		return GET_CONTRACT_AUTH
+	} else if strings.Contains(url, strings.TrimRight(GET_RESOLVE_DID, ":did")) {
+		return GET_RESOLVE_DID
+	} else if strings.Contains(url, strings.TrimRight(GET_NFT_TOKEN, ":id")) {
+		return GET_NFT_TOKEN
+	} else if strings.Contains(url, strings.TrimRight(GET_NFT_TOKENS, ":addr")) {
+		return GET_NFT_TOKENS
+	} else if strings.Contains(url, strings.TrimRight(GET_GOVERNANCE_STAKE, ":addr")) {
+		return GET_GOVERNANCE_STAKE
+	} else if strings.Contains(url, strings.TrimRight(GET_FEE_SPLIT, ":view")) {
+		return GET_FEE_SPLIT
 	}
 	return url
 }
@@ -223,9 +265,9 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 	case GET_STORAGE:
 		req["Hash"], req["Key"] = getParam(r, "hash"), getParam(r, "key")
 	case GET_SMTCOCE_EVT_TXS:
-		req["Height"] = getParam(r, "height")
+		req["Height"], req["Decode"] = getParam(r, "height"), r.FormValue("decode")
 	case GET_SMTCOCE_EVTS:
-		req["Hash"] = getParam(r, "hash")
+		req["Hash"], req["Decode"] = getParam(r, "hash"), r.FormValue("decode")
 	case GET_BLK_HGT_BY_TXHASH:
 		req["Hash"] = getParam(r, "hash")
 	case GET_BALANCE:
@@ -241,6 +283,25 @@ func (this *restServer) getParams(r *http.Request, url string, req map[string]in
 		req["Addr"] = getParam(r, "addr")
 	case GET_MEMPOOL_TXSTATE:
 		req["Hash"] = getParam(r, "hash")
+	case GET_ABI:
+		req["Hash"] = getParam(r, "hash")
+	case GET_CONTRACT_ABI:
+		req["Hash"] = getParam(r, "hash")
+	case GET_CONTRACT_HISTORY:
+		req["Hash"] = getParam(r, "hash")
+	case GET_CONTRACT_AUTH:
+		req["Hash"] = getParam(r, "hash")
+	case GET_RESOLVE_DID:
+		req["ID"] = getParam(r, "did")
+	case GET_NFT_TOKEN:
+		req["Id"] = getParam(r, "id")
+	case GET_NFT_TOKENS:
+		req["Addr"] = getParam(r, "addr")
+		req["Start"], req["Limit"] = r.FormValue("start"), r.FormValue("limit")
+	case GET_GOVERNANCE_STAKE:
+		req["Addr"] = getParam(r, "addr")
+	case GET_FEE_SPLIT:
+		req["View"] = getParam(r, "view")
 	default:
 	}
 	return req
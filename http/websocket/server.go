@@ -79,27 +79,39 @@ func pushSmartCodeEvent(v interface{}) {
 		switch object := rs.Result.(type) {
 		case *event.LogEventArgs:
 			contractAddrs, evts := bcomn.GetLogEvent(object)
-			pushEvent(contractAddrs, rs.TxHash.ToHexString(), rs.Error, rs.Action, evts)
+			pushEvent(contractAddrs, rs.TxHash.ToHexString(), rs.Error, rs.Action, evts, nil)
 		case *event.ExecuteNotify:
 			contractAddrs, notify := bcomn.GetExecuteNotify(object)
-			pushEvent(contractAddrs, rs.TxHash.ToHexString(), rs.Error, rs.Action, notify)
+			decoded := notify
+			decoded.Notify = append([]bcomn.NotifyEventInfo(nil), notify.Notify...)
+			bcomn.DecodeExecuteNotify(&decoded)
+			pushEvent(contractAddrs, rs.TxHash.ToHexString(), rs.Error, rs.Action, notify, decoded)
 		default:
 		}
 	}()
 }
 
-func pushEvent(contractAddrs map[string]bool, txHash string, errcode int64, action string, result interface{}) {
+func pushEvent(contractAddrs map[string]bool, txHash string, errcode int64, action string, result, decoded interface{}) {
 	if ws != nil {
-		resp := rest.ResponsePack(Err.SUCCESS)
-		resp["Result"] = result
-		resp["Error"] = errcode
-		resp["Action"] = action
-		resp["Desc"] = Err.ErrMap[resp["Error"].(int64)]
-		ws.PushTxResult(contractAddrs, txHash, resp)
-		ws.BroadcastToSubscribers(contractAddrs, websocket.WSTOPIC_EVENT, resp)
+		resp := eventResp(errcode, action, result)
+		var decodedResp map[string]interface{}
+		if decoded != nil {
+			decodedResp = eventResp(errcode, action, decoded)
+		}
+		ws.PushTxResult(contractAddrs, txHash, resp, decodedResp)
+		ws.BroadcastEvent(contractAddrs, resp, decodedResp)
 	}
 }
 
+func eventResp(errcode int64, action string, result interface{}) map[string]interface{} {
+	resp := rest.ResponsePack(Err.SUCCESS)
+	resp["Result"] = result
+	resp["Error"] = errcode
+	resp["Action"] = action
+	resp["Desc"] = Err.ErrMap[resp["Error"].(int64)]
+	return resp
+}
+
 func pushBlock(v interface{}) {
 	if ws == nil {
 		return
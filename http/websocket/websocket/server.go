@@ -58,6 +58,7 @@ type subscribe struct {
 	SubscribeJsonBlock    bool     `json:"SubscribeJsonBlock"`
 	SubscribeRawBlock     bool     `json:"SubscribeRawBlock"`
 	SubscribeBlockTxHashs bool     `json:"SubscribeBlockTxHashs"`
+	DecodeEvent           bool     `json:"DecodeEvent"` //Push the events decoded by registered contract abi
 }
 type WsServer struct {
 	sync.RWMutex
@@ -157,6 +158,9 @@ func (self *WsServer) registryMethod() {
 		if b, ok := cmd["SubscribeBlockTxHashs"].(bool); ok {
 			sub.SubscribeBlockTxHashs = b
 		}
+		if b, ok := cmd["DecodeEvent"].(bool); ok {
+			sub.DecodeEvent = b
+		}
 		if ctsf, ok := cmd["ContractsFilter"].([]interface{}); ok {
 			sub.ContractsFilter = []string{}
 			for _, v := range ctsf {
@@ -388,11 +392,13 @@ func marshalResp(resp map[string]interface{}) []byte {
 	return data
 }
 
-func (self *WsServer) PushTxResult(contractAddrs map[string]bool, txHashStr string, resp map[string]interface{}) {
+//PushTxResult push the result of transaction to the session sending it, decoded is pushed instead of resp if the
+//session subscribes DecodeEvent and decoded is not nil
+func (self *WsServer) PushTxResult(contractAddrs map[string]bool, txHashStr string, resp, decoded map[string]interface{}) {
 	self.Lock()
 	sessionId := self.TxHashMap[txHashStr]
 	delete(self.TxHashMap, txHashStr)
-	//avoid twice, will send in BroadcastToSubscribers
+	//avoid twice, will send in BroadcastEvent
 	sub := self.SubscribeMap[sessionId]
 	if sub.SubscribeEvent {
 		if len(sub.ContractsFilter) == 0 {
@@ -409,7 +415,12 @@ func (self *WsServer) PushTxResult(contractAddrs map[string]bool, txHashStr stri
 	self.Unlock()
 
 	s := self.SessionList.GetSessionById(sessionId)
-	if s != nil {
+	if s == nil {
+		return
+	}
+	if sub.DecodeEvent && decoded != nil {
+		s.Send(marshalResp(decoded))
+	} else {
 		s.Send(marshalResp(resp))
 	}
 }
@@ -429,17 +440,44 @@ func (self *WsServer) BroadcastToSubscribers(contractAddrs map[string]bool, sub
 			s.Send(data)
 		} else if sub == WSTOPIC_TXHASHS && v.SubscribeBlockTxHashs {
 			s.Send(data)
-		} else if sub == WSTOPIC_EVENT && v.SubscribeEvent {
-			if len(v.ContractsFilter) == 0 {
-				s.Send(data)
-				continue
+		}
+	}
+}
+
+//BroadcastEvent push the event to the sessions subscribing event of contracts, decoded is pushed instead of resp to
+//the sessions subscribing DecodeEvent if decoded is not nil
+func (self *WsServer) BroadcastEvent(contractAddrs map[string]bool, resp, decoded map[string]interface{}) {
+	self.Lock()
+	defer self.Unlock()
+	var data, decodedData []byte
+	for sid, v := range self.SubscribeMap {
+		if !v.SubscribeEvent {
+			continue
+		}
+		s := self.SessionList.GetSessionById(sid)
+		if s == nil {
+			continue
+		}
+		matched := len(v.ContractsFilter) == 0
+		for _, addr := range v.ContractsFilter {
+			if contractAddrs[addr] {
+				matched = true
+				break
 			}
-			for _, addr := range v.ContractsFilter {
-				if contractAddrs[addr] {
-					s.Send(data)
-					break
-				}
+		}
+		if !matched {
+			continue
+		}
+		if v.DecodeEvent && decoded != nil {
+			if decodedData == nil {
+				decodedData = marshalResp(decoded)
+			}
+			s.Send(decodedData)
+		} else {
+			if data == nil {
+				data = marshalResp(resp)
 			}
+			s.Send(data)
 		}
 	}
 }
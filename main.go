@@ -30,6 +30,7 @@ import (
 
 	"github.com/dnaproject2/DNA/account"
 	"github.com/dnaproject2/DNA/cmd"
+	"github.com/dnaproject2/DNA/cmd/abi"
 	cmdcom "github.com/dnaproject2/DNA/cmd/common"
 	"github.com/dnaproject2/DNA/cmd/utils"
 	"github.com/dnaproject2/DNA/common"
@@ -71,6 +72,8 @@ func setupAPP() *cli.App {
 		cmd.AccountCommand,
 		cmd.InfoCommand,
 		cmd.ContractCommand,
+		cmd.CredentialCommand,
+		cmd.StakeCommand,
 		cmd.ImportCommand,
 		cmd.ExportCommand,
 		cmd.TxCommond,
@@ -87,6 +90,12 @@ func setupAPP() *cli.App {
 		utils.DisableLogFileFlag,
 		utils.DisableEventLogFlag,
 		utils.DataDirFlag,
+		utils.StorageBackendFlag,
+		utils.SnapshotIntervalFlag,
+		utils.PruneKeepBlocksFlag,
+		utils.ExecWorkersFlag,
+		utils.TraceHistoryFlag,
+		utils.EnableLightModeFlag,
 		//account setting
 		utils.ExecutorFileFlag,
 		utils.AccountAddressFlag,
@@ -110,6 +119,9 @@ func setupAPP() *cli.App {
 		utils.MaxConnOutBoundFlag,
 		utils.MaxConnInBoundForSingleIPFlag,
 		utils.CertPathFlag,
+		utils.DisableCompressionFlag,
+		utils.EnableFastSyncFlag,
+		utils.FastSyncStateDigestFlag,
 		//test mode setting
 		utils.EnableTestModeFlag,
 		utils.TestModeGenBlockTimeFlag,
@@ -125,6 +137,8 @@ func setupAPP() *cli.App {
 		//ws setting
 		utils.WsEnabledFlag,
 		utils.WsPortFlag,
+		//abi setting
+		utils.CliABIPathFlag,
 	}
 	app.Before = func(context *cli.Context) error {
 		runtime.GOMAXPROCS(runtime.NumCPU())
@@ -178,6 +192,7 @@ func startDNA(ctx *cli.Context) {
 		log.Errorf("initConsensus error:%s", err)
 		return
 	}
+	initAbi(ctx)
 	err = initRpc(ctx)
 	if err != nil {
 		log.Errorf("initRpc error:%s", err)
@@ -251,7 +266,11 @@ func initLedger(ctx *cli.Context, stateHashHeight uint32) (*ledger.Ledger, error
 
 	var err error
 	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
-	ledger.DefLedger, err = ledger.NewLedger(dbDir, stateHashHeight)
+	if config.DefConfig.Common.EnableLightMode {
+		ledger.DefLedger, err = ledger.NewLightLedger(dbDir)
+	} else {
+		ledger.DefLedger, err = ledger.NewLedger(dbDir, stateHashHeight)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("NewLedger error:%s", err)
 	}
@@ -274,6 +293,9 @@ func initLedger(ctx *cli.Context, stateHashHeight uint32) (*ledger.Ledger, error
 }
 
 func initTxPool(ctx *cli.Context) (*proc.TXPoolServer, error) {
+	if config.DefConfig.Common.EnableLightMode {
+		return nil, nil
+	}
 	disablePreExec := ctx.GlobalBool(utils.GetFlagName(utils.TxpoolPreExecDisableFlag))
 	bactor.DisableSyncVerifyTx = ctx.GlobalBool(utils.GetFlagName(utils.DisableSyncVerifyTxFlag))
 	disableBroadcastNetTx := ctx.GlobalBool(utils.GetFlagName(utils.DisableBroadcastNetTxFlag))
@@ -314,8 +336,10 @@ func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, acc *account.Ac
 	if err != nil {
 		return nil, nil, fmt.Errorf("p2p service start error %s", err)
 	}
-	netreqactor.SetTxnPoolPid(txpoolSvr.GetPID(tc.TxActor))
-	txpoolSvr.RegisterActor(tc.NetActor, p2pPID)
+	if txpoolSvr != nil {
+		netreqactor.SetTxnPoolPid(txpoolSvr.GetPID(tc.TxActor))
+		txpoolSvr.RegisterActor(tc.NetActor, p2pPID)
+	}
 	hserver.SetNetServerPID(p2pPID)
 	p2p.WaitForPeersStart()
 	log.Infof("P2P init success")
@@ -342,6 +366,12 @@ func initConsensus(ctx *cli.Context, p2pPid *actor.PID, txpoolSvr *proc.TXPoolSe
 	return consensusService, nil
 }
 
+func initAbi(ctx *cli.Context) {
+	//the abi of contracts is used to decode the contract events in http api
+	abiPath := ctx.GlobalString(utils.GetFlagName(utils.CliABIPathFlag))
+	abi.DefAbiMgr.Init(abiPath)
+}
+
 func initRpc(ctx *cli.Context) error {
 	if !config.DefConfig.Rpc.EnableHttpJsonRpc {
 		return nil
@@ -349,7 +379,11 @@ func initRpc(ctx *cli.Context) error {
 	var err error
 	exitCh := make(chan interface{}, 0)
 	go func() {
-		err = jsonrpc.StartRPCServer()
+		if config.DefConfig.Common.EnableLightMode {
+			err = jsonrpc.StartLightRPCServer()
+		} else {
+			err = jsonrpc.StartRPCServer()
+		}
 		close(exitCh)
 	}()
 
@@ -367,7 +401,7 @@ func initRpc(ctx *cli.Context) error {
 }
 
 func initLocalRpc(ctx *cli.Context) error {
-	if !ctx.GlobalBool(utils.GetFlagName(utils.RPCLocalEnableFlag)) {
+	if !ctx.GlobalBool(utils.GetFlagName(utils.RPCLocalEnableFlag)) || config.DefConfig.Common.EnableLightMode {
 		return nil
 	}
 	var err error
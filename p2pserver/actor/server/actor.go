@@ -90,6 +90,16 @@ func (this *P2PActor) Receive(ctx actor.Context) {
 		this.server.OnHeaderReceive(msg.FromID, msg.Headers)
 	case *common.AppendBlock:
 		this.server.OnBlockReceive(msg.FromID, msg.BlockSize, msg.Block, msg.MerkleRoot)
+	case *common.AppendSnapshotInfo:
+		this.server.OnSnapshotInfoReceive(msg.FromID, msg.Manifest)
+	case *common.AppendSnapshotChunk:
+		this.server.OnSnapshotChunkReceive(msg.FromID, msg.Height, msg.Index, msg.Data)
+	case *common.AppendTxProof:
+		this.server.OnTxProofReceive(msg.FromID, msg.Height, msg.Index, msg.Tx, msg.Proof)
+	case *common.AppendNotFound:
+		this.server.OnTxNotFound(msg.FromID, msg.Hash)
+	case *GetVerifiedTxReq:
+		this.handleGetVerifiedTxReq(ctx, msg)
 	default:
 		err := this.server.Xmit(ctx.Message())
 		if nil != err {
@@ -214,3 +224,20 @@ func (this *P2PActor) handleTransmitConsensusMsgReq(ctx actor.Context, req *Tran
 		log.Warnf("[p2p]can`t transmit consensus msg:no valid neighbor peer: %d\n", req.Target)
 	}
 }
+
+//get transaction proved by full nodes handler
+func (this *P2PActor) handleGetVerifiedTxReq(ctx actor.Context, req *GetVerifiedTxReq) {
+	sender := ctx.Sender()
+	if sender == nil {
+		return
+	}
+	//waiting for the proof from net should not block the actor
+	go func() {
+		tx, height, err := this.server.GetVerifiedTransaction(req.TxHash)
+		sender.Tell(&GetVerifiedTxRsp{
+			Tx:     tx,
+			Height: height,
+			Error:  err,
+		})
+	}()
+}
@@ -19,6 +19,8 @@
 package server
 
 import (
+	comm "github.com/dnaproject2/DNA/common"
+	ctypes "github.com/dnaproject2/DNA/core/types"
 	types "github.com/dnaproject2/DNA/p2pserver/common"
 	ptypes "github.com/dnaproject2/DNA/p2pserver/message/types"
 )
@@ -113,3 +115,15 @@ type TransmitConsensusMsgReq struct {
 	Target uint64
 	Msg    ptypes.Message
 }
+
+//get transaction proved by full nodes request
+type GetVerifiedTxReq struct {
+	TxHash comm.Uint256
+}
+
+//response of transaction proved by full nodes
+type GetVerifiedTxRsp struct {
+	Tx     *ctypes.Transaction
+	Height uint32
+	Error  error
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
 	"github.com/dnaproject2/DNA/core/ledger"
 	"github.com/dnaproject2/DNA/core/types"
@@ -339,6 +340,10 @@ func (this *BlockSyncMgr) checkTimeout() {
 }
 
 func (this *BlockSyncMgr) sync() {
+	//Waiting for the ledger bootstrapped from snapshot
+	if !this.server.snapSync.Finished() {
+		return
+	}
 	this.syncHeader()
 	this.syncBlock()
 }
@@ -621,6 +626,9 @@ func (this *BlockSyncMgr) saveBlock() {
 		return
 	}
 	defer this.releaseSaveBlockLock()
+	if !this.server.snapSync.Finished() {
+		return
+	}
 	curBlockHeight := this.ledger.GetCurrentBlockHeight()
 	nextBlockHeight := curBlockHeight + 1
 	this.lock.Lock()
@@ -799,7 +807,7 @@ func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
 		if n == nil {
 			continue
 		}
-		if n.GetState() != p2pComm.ESTABLISH {
+		if n.GetState() != p2pComm.ESTABLISH || !canServeBlock(n, nextBlockHeight) {
 			continue
 		}
 		nodeBlockHeight := n.GetHeight()
@@ -809,6 +817,18 @@ func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
 	}
 }
 
+//canServeBlock return whether the peer keeps the full data of block at height.
+//A pruned peer keeps at least the latest MIN_PRUNE_KEEP_BLOCKS blocks.
+func canServeBlock(p *peer.Peer, height uint32) bool {
+	if isLightNode(p) {
+		return false
+	}
+	if p.GetServices()&p2pComm.PRUNED_NODE == 0 {
+		return true
+	}
+	return uint64(height)+config.MIN_PRUNE_KEEP_BLOCKS > p.GetHeight()
+}
+
 func (this *BlockSyncMgr) getNodeWithMinFailedTimes(flightInfo *SyncFlightInfo, curBlockHeight uint32) *peer.Peer {
 	var minFailedTimes = math.MaxInt64
 	var minFailedTimesNode *peer.Peer
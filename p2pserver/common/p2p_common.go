@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	com "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
 	"github.com/dnaproject2/DNA/core/types"
 )
 
@@ -31,6 +32,8 @@ import (
 const (
 	VERIFY_NODE  = 1 //peer involved in consensus
 	SERVICE_NODE = 2 //peer only sync with consensus peer
+	LIGHT_NODE   = 4 //peer only sync headers, can not serve blocks
+	PRUNED_NODE  = 8 //peer prunes transactions of old blocks, only serve latest blocks
 )
 
 //link and concurrent const
@@ -89,6 +92,15 @@ const (
 //cap flag
 const (
 	HTTP_INFO_FLAG = 0 //peer`s http info bit in cap field
+	COMPRESS_FLAG  = 1 //peer`s supported compression algorithms in cap field
+)
+
+//msg compression const
+const (
+	COMPRESS_NONE      = 0x00            //payload sent uncompressed
+	COMPRESS_SNAPPY    = 0x01            //payload compressed with snappy
+	COMPRESS_SUPPORTED = COMPRESS_SNAPPY //bitmask of algorithms this node can decode
+	COMPRESS_MIN_LEN   = 1024            //payload shorter than this is never compressed
 )
 
 //actor const
@@ -116,22 +128,29 @@ type PeerAddr struct {
 
 //const channel msg id and type
 const (
-	VERSION_TYPE     = "version"    //peer`s information
-	VERACK_TYPE      = "verack"     //ack msg after version recv
-	GetADDR_TYPE     = "getaddr"    //req nbr address from peer
-	ADDR_TYPE        = "addr"       //nbr address
-	PING_TYPE        = "ping"       //ping  sync height
-	PONG_TYPE        = "pong"       //pong  recv nbr height
-	GET_HEADERS_TYPE = "getheaders" //req blk hdr
-	HEADERS_TYPE     = "headers"    //blk hdr
-	INV_TYPE         = "inv"        //inv payload
-	GET_DATA_TYPE    = "getdata"    //req data from peer
-	BLOCK_TYPE       = "block"      //blk payload
-	TX_TYPE          = "tx"         //transaction
-	CONSENSUS_TYPE   = "consensus"  //consensus payload
-	GET_BLOCKS_TYPE  = "getblocks"  //req blks from peer
-	NOT_FOUND_TYPE   = "notfound"   //peer can`t find blk according to the hash
-	DISCONNECT_TYPE  = "disconnect" //peer disconnect info raise by link
+	VERSION_TYPE        = "version"      //peer`s information
+	VERACK_TYPE         = "verack"       //ack msg after version recv
+	GetADDR_TYPE        = "getaddr"      //req nbr address from peer
+	ADDR_TYPE           = "addr"         //nbr address
+	PING_TYPE           = "ping"         //ping  sync height
+	PONG_TYPE           = "pong"         //pong  recv nbr height
+	GET_HEADERS_TYPE    = "getheaders"   //req blk hdr
+	HEADERS_TYPE        = "headers"      //blk hdr
+	INV_TYPE            = "inv"          //inv payload
+	GET_DATA_TYPE       = "getdata"      //req data from peer
+	BLOCK_TYPE          = "block"        //blk payload
+	TX_TYPE             = "tx"           //transaction
+	CONSENSUS_TYPE      = "consensus"    //consensus payload
+	GET_BLOCKS_TYPE     = "getblocks"    //req blks from peer
+	NOT_FOUND_TYPE      = "notfound"     //peer can`t find blk according to the hash
+	DISCONNECT_TYPE     = "disconnect"   //peer disconnect info raise by link
+	COMPRESSED_TYPE     = "compressed"   //compressed payload of another msg
+	GET_SNAP_INFO_TYPE  = "getsnapinfo"  //req latest state snapshot manifest
+	SNAP_INFO_TYPE      = "snapinfo"     //state snapshot manifest
+	GET_SNAP_CHUNK_TYPE = "getsnapchunk" //req state snapshot chunk
+	SNAP_CHUNK_TYPE     = "snapchunk"    //state snapshot chunk
+	GET_TX_PROOF_TYPE   = "gettxproof"   //req transaction with merkle proof
+	TX_PROOF_TYPE       = "txproof"      //transaction with merkle proof
 )
 
 type AppendPeerID struct {
@@ -154,6 +173,40 @@ type AppendBlock struct {
 	MerkleRoot com.Uint256  // MerkleRoot
 }
 
+type AppendSnapshotInfo struct {
+	FromID   uint64             // The peer id
+	Manifest *snapshot.Manifest // Manifest of the latest snapshot of peer
+}
+
+type AppendSnapshotChunk struct {
+	FromID uint64 // The peer id
+	Height uint32 // Height of the snapshot
+	Index  uint32 // Chunk index
+	Data   []byte // Chunk data
+}
+
+type AppendTxProof struct {
+	FromID uint64             // The peer id
+	Height uint32             // Height of the block including tx
+	Index  uint32             // Index of tx in block
+	Tx     *types.Transaction // The transaction
+	Proof  []com.Uint256      // Merkle proof of tx hash to transactions root of block
+}
+
+type AppendNotFound struct {
+	FromID uint64      // The peer id
+	Hash   com.Uint256 // Hash of the data not found
+}
+
+//NegotiateCompression return the algorithm used to send to a peer,
+//given the local and remote compression bitmask from version cap
+func NegotiateCompression(local, remote uint8) uint8 {
+	if local&remote&COMPRESS_SNAPPY != 0 {
+		return COMPRESS_SNAPPY
+	}
+	return COMPRESS_NONE
+}
+
 //ParseIPAddr return ip address
 func ParseIPAddr(s string) (string, error) {
 	i := strings.Index(s, ":")
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
 	comm "github.com/dnaproject2/DNA/common"
@@ -40,6 +41,7 @@ type Link struct {
 	time      time.Time              // The latest time the node activity
 	recvChan  chan *types.MsgPayload //msgpayload channel
 	reqRecord map[string]int64       //Map RequestId to Timestamp, using for rejecting duplicate request in specific time
+	compress  uint32                 //compression algorithm negotiated with the peer, accessed atomically
 }
 
 func NewLink() *Link {
@@ -59,6 +61,16 @@ func (this *Link) GetID() uint64 {
 	return this.id
 }
 
+//SetCompression set compression algorithm used to send msg
+func (this *Link) SetCompression(algorithm uint8) {
+	atomic.StoreUint32(&this.compress, uint32(algorithm))
+}
+
+//GetCompression return compression algorithm used to send msg
+func (this *Link) GetCompression() uint8 {
+	return uint8(atomic.LoadUint32(&this.compress))
+}
+
 //If there is connection return true
 func (this *Link) Valid() bool {
 	return this.conn != nil
@@ -169,7 +181,7 @@ func (this *Link) CloseConn() {
 
 func (this *Link) Send(msg types.Message) error {
 	sink := comm.NewZeroCopySink(nil)
-	types.WriteMessage(sink, msg)
+	types.WriteCompressedMessage(sink, msg, this.GetCompression())
 
 	return this.SendRaw(sink.Bytes())
 }
@@ -19,7 +19,10 @@
 package link
 
 import (
+	"io"
+	"io/ioutil"
 	"math/rand"
+	"net"
 	"testing"
 	"time"
 
@@ -196,3 +199,29 @@ func TestUnpackBufNode(t *testing.T) {
 	sink := comm.NewZeroCopySink(nil)
 	mt.WriteMessage(sink, msg)
 }
+
+func TestSendWhileSetCompression(t *testing.T) {
+	local, remote := net.Pipe()
+	defer remote.Close()
+	go io.Copy(ioutil.Discard, remote)
+
+	link := NewLink()
+	link.SetConn(local)
+	defer link.CloseConn()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			link.SetCompression(uint8(i % 2))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := link.Send(&mt.NotFound{Hash: comm.UINT256_EMPTY}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	if link.GetCompression() != common.COMPRESS_SNAPPY {
+		t.Fatal("link GetCompression failed")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
 	ct "github.com/dnaproject2/DNA/core/types"
 	msgCommon "github.com/dnaproject2/DNA/p2pserver/common"
 	mt "github.com/dnaproject2/DNA/p2pserver/message/types"
@@ -175,6 +176,9 @@ func NewVersion(n p2pnet.P2P, height uint32) mt.Message {
 	} else {
 		version.P.Cap[msgCommon.HTTP_INFO_FLAG] = 0x00
 	}
+	if !config.DefConfig.P2PNode.DisableCompression {
+		version.P.Cap[msgCommon.COMPRESS_FLAG] = msgCommon.COMPRESS_SUPPORTED
+	}
 	return &version
 }
 
@@ -207,3 +211,62 @@ func NewConsensusDataReq(hash common.Uint256) mt.Message {
 
 	return &dataReq
 }
+
+//state snapshot manifest req package
+func NewSnapshotInfoReq() mt.Message {
+	log.Trace()
+	var req mt.SnapshotInfoReq
+
+	return &req
+}
+
+//state snapshot manifest package
+func NewSnapshotInfo(manifest *snapshot.Manifest) mt.Message {
+	log.Trace()
+	var info mt.SnapshotInfo
+	info.Manifest = *manifest
+
+	return &info
+}
+
+//state snapshot chunk req package
+func NewSnapshotChunkReq(height, index uint32) mt.Message {
+	log.Trace()
+	var req mt.SnapshotChunkReq
+	req.Height = height
+	req.Index = index
+
+	return &req
+}
+
+//state snapshot chunk package
+func NewSnapshotChunk(height, index uint32, data []byte) mt.Message {
+	log.Trace()
+	var chunk mt.SnapshotChunk
+	chunk.Height = height
+	chunk.Index = index
+	chunk.Data = data
+
+	return &chunk
+}
+
+//transaction proof request package
+func NewTxProofReq(txHash common.Uint256) mt.Message {
+	log.Trace()
+	var req mt.TxProofReq
+	req.TxHash = txHash
+
+	return &req
+}
+
+//transaction proof package
+func NewTxProof(height, index uint32, tx *ct.Transaction, proof []common.Uint256) mt.Message {
+	log.Trace()
+	var txProof mt.TxProof
+	txProof.Height = height
+	txProof.Index = index
+	txProof.Tx = tx
+	txProof.Proof = proof
+
+	return &txProof
+}
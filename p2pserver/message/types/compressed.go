@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+
+	comm "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/p2pserver/common"
+)
+
+//Compressed wraps the payload of another msg compressed with an algorithm
+//negotiated in version handshake. It is only sent to peers advertising the
+//algorithm in version cap, and is unwrapped by ReadMessage.
+type Compressed struct {
+	Algorithm uint8
+	Cmd       string
+	Data      []byte
+}
+
+//compressible return whether msg payload of cmdType is worth compressing
+func compressible(cmdType string) bool {
+	switch cmdType {
+	case common.BLOCK_TYPE, common.HEADERS_TYPE, common.ADDR_TYPE, common.SNAP_CHUNK_TYPE:
+		return true
+	}
+	return false
+}
+
+//NewCompressed compress the serialized payload of cmdType msg with algorithm
+func NewCompressed(cmdType string, payload []byte, algorithm uint8) (*Compressed, error) {
+	var data []byte
+	switch algorithm {
+	case common.COMPRESS_SNAPPY:
+		data = snappy.Encode(nil, payload)
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm:%d", algorithm)
+	}
+
+	return &Compressed{
+		Algorithm: algorithm,
+		Cmd:       cmdType,
+		Data:      data,
+	}, nil
+}
+
+//Uncompress decode the wrapped msg
+func (this *Compressed) Uncompress() (Message, error) {
+	var buf []byte
+	switch this.Algorithm {
+	case common.COMPRESS_SNAPPY:
+		n, err := snappy.DecodedLen(this.Data)
+		if err != nil {
+			return nil, err
+		}
+		if n > common.MAX_PAYLOAD_LEN {
+			return nil, fmt.Errorf("uncompressed payload length:%d exceed max payload size: %d",
+				n, common.MAX_PAYLOAD_LEN)
+		}
+		buf, err = snappy.Decode(nil, this.Data)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm:%d", this.Algorithm)
+	}
+
+	if this.Cmd == common.COMPRESSED_TYPE {
+		return nil, errors.New("nested compressed msg")
+	}
+	msg, err := MakeEmptyMessage(this.Cmd)
+	if err != nil {
+		return nil, err
+	}
+	err = msg.Deserialization(comm.NewZeroCopySource(buf))
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+//Serialize message payload
+func (this *Compressed) Serialization(sink *comm.ZeroCopySink) {
+	sink.WriteUint8(this.Algorithm)
+	sink.WriteString(this.Cmd)
+	sink.WriteBytes(this.Data)
+}
+
+func (this *Compressed) CmdType() string {
+	return common.COMPRESSED_TYPE
+}
+
+//Deserialize message payload
+func (this *Compressed) Deserialization(source *comm.ZeroCopySource) error {
+	var irregular, eof bool
+	this.Algorithm, eof = source.NextUint8()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Cmd, _, irregular, eof = source.NextString()
+	if irregular {
+		return comm.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Data, _ = source.NextBytes(source.Len())
+
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dnaproject2/DNA/common"
+	comm "github.com/dnaproject2/DNA/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAddr(cnt int) *Addr {
+	var msg Addr
+	for i := 0; i < cnt; i++ {
+		msg.NodeAddrs = append(msg.NodeAddrs, comm.PeerAddr{
+			Time:     12345678,
+			Services: 100,
+			Port:     20338,
+			ID:       uint64(i),
+		})
+	}
+	return &msg
+}
+
+func TestCompressedMessage(t *testing.T) {
+	msg := newTestAddr(comm.MAX_ADDR_NODE_CNT)
+
+	plain := common.NewZeroCopySink(nil)
+	WriteMessage(plain, msg)
+	sink := common.NewZeroCopySink(nil)
+	WriteCompressedMessage(sink, msg, comm.COMPRESS_SNAPPY)
+	assert.True(t, sink.Size() < plain.Size())
+
+	hdr, err := readMessageHeader(bytes.NewBuffer(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, comm.COMPRESSED_TYPE, string(bytes.TrimRight(hdr.CMD[:], "\x00")))
+
+	demsg, _, err := ReadMessage(bytes.NewBuffer(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, msg, demsg)
+}
+
+func TestCompressedMessageFallback(t *testing.T) {
+	small := newTestAddr(1)
+	plain := common.NewZeroCopySink(nil)
+	WriteMessage(plain, small)
+	sink := common.NewZeroCopySink(nil)
+	WriteCompressedMessage(sink, small, comm.COMPRESS_SNAPPY)
+	assert.Equal(t, plain.Bytes(), sink.Bytes())
+
+	large := newTestAddr(comm.MAX_ADDR_NODE_CNT)
+	plain = common.NewZeroCopySink(nil)
+	WriteMessage(plain, large)
+	sink = common.NewZeroCopySink(nil)
+	WriteCompressedMessage(sink, large, comm.COMPRESS_NONE)
+	assert.Equal(t, plain.Bytes(), sink.Bytes())
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	assert.Equal(t, uint8(comm.COMPRESS_SNAPPY), comm.NegotiateCompression(comm.COMPRESS_SUPPORTED, comm.COMPRESS_SNAPPY))
+	assert.Equal(t, uint8(comm.COMPRESS_NONE), comm.NegotiateCompression(comm.COMPRESS_SUPPORTED, 0))
+}
@@ -102,6 +102,29 @@ func WriteMessage(sink *comm.ZeroCopySink, msg Message) {
 	sink.NextBytes(payLen)
 }
 
+//WriteCompressedMessage write msg with its payload compressed by algorithm
+//when the msg type and size are worth it, otherwise same as WriteMessage
+func WriteCompressedMessage(sink *comm.ZeroCopySink, msg Message, algorithm uint8) {
+	if algorithm == common.COMPRESS_NONE || !compressible(msg.CmdType()) {
+		WriteMessage(sink, msg)
+		return
+	}
+
+	payload := comm.NewZeroCopySink(nil)
+	msg.Serialization(payload)
+	if payload.Size() < common.COMPRESS_MIN_LEN {
+		WriteMessage(sink, msg)
+		return
+	}
+
+	compressed, err := NewCompressed(msg.CmdType(), payload.Bytes(), algorithm)
+	if err != nil || uint64(len(compressed.Data)) >= payload.Size() {
+		WriteMessage(sink, msg)
+		return
+	}
+	WriteMessage(sink, compressed)
+}
+
 func ReadMessage(reader io.Reader) (Message, uint32, error) {
 	hdr, err := readMessageHeader(reader)
 	if err != nil {
@@ -129,7 +152,7 @@ func ReadMessage(reader io.Reader) (Message, uint32, error) {
 		return nil, 0, fmt.Errorf("message checksum mismatch: %x != %x ", hdr.Checksum, checksum)
 	}
 
-	cmdType := string(bytes.TrimRight(hdr.CMD[:], string(0)))
+	cmdType := string(bytes.TrimRight(hdr.CMD[:], "\x00"))
 	msg, err := MakeEmptyMessage(cmdType)
 	if err != nil {
 		return nil, 0, err
@@ -142,6 +165,13 @@ func ReadMessage(reader io.Reader) (Message, uint32, error) {
 		return nil, 0, err
 	}
 
+	if compressed, ok := msg.(*Compressed); ok {
+		msg, err = compressed.Uncompress()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
 	return msg, hdr.Length, nil
 }
 
@@ -179,6 +209,20 @@ func MakeEmptyMessage(cmdType string) (Message, error) {
 		return &Disconnected{}, nil
 	case common.GET_BLOCKS_TYPE:
 		return &BlocksReq{}, nil
+	case common.COMPRESSED_TYPE:
+		return &Compressed{}, nil
+	case common.GET_SNAP_INFO_TYPE:
+		return &SnapshotInfoReq{}, nil
+	case common.SNAP_INFO_TYPE:
+		return &SnapshotInfo{}, nil
+	case common.GET_SNAP_CHUNK_TYPE:
+		return &SnapshotChunkReq{}, nil
+	case common.SNAP_CHUNK_TYPE:
+		return &SnapshotChunk{}, nil
+	case common.GET_TX_PROOF_TYPE:
+		return &TxProofReq{}, nil
+	case common.TX_PROOF_TYPE:
+		return &TxProof{}, nil
 	default:
 		return nil, errors.New("unsupported cmd type:" + cmdType)
 	}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"fmt"
+	"io"
+
+	comm "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
+	"github.com/dnaproject2/DNA/p2pserver/common"
+)
+
+//SnapshotInfoReq request the manifest of the latest state snapshot of peer
+type SnapshotInfoReq struct{}
+
+//Serialize message payload
+func (this *SnapshotInfoReq) Serialization(sink *comm.ZeroCopySink) {
+}
+
+func (this *SnapshotInfoReq) CmdType() string {
+	return common.GET_SNAP_INFO_TYPE
+}
+
+//Deserialize message payload
+func (this *SnapshotInfoReq) Deserialization(source *comm.ZeroCopySource) error {
+	return nil
+}
+
+//SnapshotInfo is the manifest of the latest state snapshot of peer
+type SnapshotInfo struct {
+	Manifest snapshot.Manifest
+}
+
+//Serialize message payload
+func (this *SnapshotInfo) Serialization(sink *comm.ZeroCopySink) {
+	this.Manifest.Serialization(sink)
+}
+
+func (this *SnapshotInfo) CmdType() string {
+	return common.SNAP_INFO_TYPE
+}
+
+//Deserialize message payload
+func (this *SnapshotInfo) Deserialization(source *comm.ZeroCopySource) error {
+	return this.Manifest.Deserialization(source)
+}
+
+//SnapshotChunkReq request a chunk of the snapshot at height
+type SnapshotChunkReq struct {
+	Height uint32
+	Index  uint32
+}
+
+//Serialize message payload
+func (this *SnapshotChunkReq) Serialization(sink *comm.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.Index)
+}
+
+func (this *SnapshotChunkReq) CmdType() string {
+	return common.GET_SNAP_CHUNK_TYPE
+}
+
+//Deserialize message payload
+func (this *SnapshotChunkReq) Deserialization(source *comm.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	this.Index, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//SnapshotChunk is a chunk of the snapshot at height
+type SnapshotChunk struct {
+	Height uint32
+	Index  uint32
+	Data   []byte
+}
+
+//Serialize message payload
+func (this *SnapshotChunk) Serialization(sink *comm.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.Index)
+	sink.WriteVarBytes(this.Data)
+}
+
+func (this *SnapshotChunk) CmdType() string {
+	return common.SNAP_CHUNK_TYPE
+}
+
+//Deserialize message payload
+func (this *SnapshotChunk) Deserialization(source *comm.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	this.Index, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	data, _, irregular, eof := source.NextVarBytes()
+	if irregular {
+		return comm.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if len(data) > snapshot.MAX_CHUNK_DATA {
+		return fmt.Errorf("snapshot chunk size %d exceed max %d", len(data), snapshot.MAX_CHUNK_DATA)
+	}
+	this.Data = data
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	comm "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
+	"github.com/dnaproject2/DNA/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotInfoReqSerializationDeserialization(t *testing.T) {
+	MessageTest(t, &SnapshotInfoReq{})
+}
+
+func TestSnapshotInfoSerializationDeserialization(t *testing.T) {
+	hash, _ := comm.Uint256FromHexString("8932da73f52b1e22f30c609988ed1f693b6144f74fed9a2a20869afa7abfdf5e")
+	msg := &SnapshotInfo{
+		Manifest: snapshot.Manifest{
+			Version:         snapshot.SNAPSHOT_VERSION,
+			Height:          100,
+			BlockHash:       hash,
+			StateTreeHashes: []comm.Uint256{},
+			BlockTreeSize:   101,
+			BlockTreeHashes: []comm.Uint256{hash, hash},
+			ChunkHashes:     []comm.Uint256{hash},
+		},
+	}
+	MessageTest(t, msg)
+}
+
+func TestSnapshotChunkReqSerializationDeserialization(t *testing.T) {
+	MessageTest(t, &SnapshotChunkReq{Height: 100, Index: 3})
+}
+
+func TestSnapshotChunkCompressed(t *testing.T) {
+	msg := &SnapshotChunk{Height: 100, Index: 3, Data: bytes.Repeat([]byte("snapshot"), 1024)}
+	MessageTest(t, msg)
+
+	sink := comm.NewZeroCopySink(nil)
+	WriteCompressedMessage(sink, msg, common.COMPRESS_SNAPPY)
+	plain := comm.NewZeroCopySink(nil)
+	WriteMessage(plain, msg)
+	assert.True(t, sink.Size() < plain.Size())
+
+	demsg, _, err := ReadMessage(bytes.NewBuffer(sink.Bytes()))
+	assert.Nil(t, err)
+	assert.Equal(t, msg, demsg)
+}
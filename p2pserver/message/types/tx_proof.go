@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"fmt"
+	"io"
+
+	comm "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/p2pserver/common"
+)
+
+//MAX_TX_PROOF_LEN is the max depth of the transactions merkle tree of block
+const MAX_TX_PROOF_LEN = 32
+
+//TxProofReq request the transaction with the merkle proof to its block
+type TxProofReq struct {
+	TxHash comm.Uint256
+}
+
+//Serialize message payload
+func (this *TxProofReq) Serialization(sink *comm.ZeroCopySink) {
+	sink.WriteHash(this.TxHash)
+}
+
+func (this *TxProofReq) CmdType() string {
+	return common.GET_TX_PROOF_TYPE
+}
+
+//Deserialize message payload
+func (this *TxProofReq) Deserialization(source *comm.ZeroCopySource) error {
+	var eof bool
+	this.TxHash, eof = source.NextHash()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//TxProof is the transaction at Index of block Height, with the merkle proof to the transactions root of block
+type TxProof struct {
+	Height uint32
+	Index  uint32
+	Tx     *types.Transaction
+	Proof  []comm.Uint256
+}
+
+//Serialize message payload
+func (this *TxProof) Serialization(sink *comm.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.Index)
+	this.Tx.Serialization(sink)
+	sink.WriteVarUint(uint64(len(this.Proof)))
+	for _, hash := range this.Proof {
+		sink.WriteHash(hash)
+	}
+}
+
+func (this *TxProof) CmdType() string {
+	return common.TX_PROOF_TYPE
+}
+
+//Deserialize message payload
+func (this *TxProof) Deserialization(source *comm.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint32()
+	this.Index, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	tx := &types.Transaction{}
+	err := tx.Deserialization(source)
+	if err != nil {
+		return err
+	}
+	this.Tx = tx
+	count, _, irregular, eof := source.NextVarUint()
+	if irregular {
+		return comm.ErrIrregularData
+	}
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if count > MAX_TX_PROOF_LEN {
+		return fmt.Errorf("tx proof length %d exceed max %d", count, MAX_TX_PROOF_LEN)
+	}
+	this.Proof = make([]comm.Uint256, 0, count)
+	for i := uint64(0); i < count; i++ {
+		hash, eof := source.NextHash()
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+		this.Proof = append(this.Proof, hash)
+	}
+	return nil
+}
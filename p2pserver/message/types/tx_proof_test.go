@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	comm "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxProofReqSerializationDeserialization(t *testing.T) {
+	hash, _ := comm.Uint256FromHexString("8932da73f52b1e22f30c609988ed1f693b6144f74fed9a2a20869afa7abfdf5e")
+	MessageTest(t, &TxProofReq{TxHash: hash})
+}
+
+func TestTxProofSerializationDeserialization(t *testing.T) {
+	mutable := &types.MutableTransaction{
+		TxType:  types.Invoke,
+		Nonce:   1,
+		Payload: &payload.InvokeCode{Code: []byte{1, 2, 3}},
+	}
+	tx, err := mutable.IntoImmutable()
+	assert.Nil(t, err)
+	hash, _ := comm.Uint256FromHexString("8932da73f52b1e22f30c609988ed1f693b6144f74fed9a2a20869afa7abfdf5e")
+	msg := &TxProof{Height: 100, Index: 2, Tx: tx, Proof: []comm.Uint256{hash, hash}}
+
+	sink := comm.NewZeroCopySink(nil)
+	WriteMessage(sink, msg)
+	demsg, _, err := ReadMessage(bytes.NewBuffer(sink.Bytes()))
+	assert.Nil(t, err)
+	proof := demsg.(*TxProof)
+	assert.Equal(t, msg.Height, proof.Height)
+	assert.Equal(t, msg.Index, proof.Index)
+	assert.Equal(t, msg.Proof, proof.Proof)
+	assert.Equal(t, tx.Hash(), proof.Tx.Hash())
+
+	msg.Proof = make([]comm.Uint256, MAX_TX_PROOF_LEN+1)
+	sink = comm.NewZeroCopySink(nil)
+	WriteMessage(sink, msg)
+	_, _, err = ReadMessage(bytes.NewBuffer(sink.Bytes()))
+	assert.NotNil(t, err)
+}
@@ -202,6 +202,145 @@ func BlockHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args
 	}
 }
 
+// SnapshotInfoReqHandle handles the state snapshot manifest request from peer
+func SnapshotInfoReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive snapshot info request message", data.Addr, data.Id)
+
+	manifest := ledger.DefLedger.GetSnapshotManifest()
+	if manifest == nil {
+		return
+	}
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debugf("[p2p]remotePeer invalid in SnapshotInfoReqHandle, peer id: %d", data.Id)
+		return
+	}
+	msg := msgpack.NewSnapshotInfo(manifest)
+	err := p2p.Send(remotePeer, msg)
+	if err != nil {
+		log.Warn(err)
+	}
+}
+
+// SnapshotInfoHandle handles the state snapshot manifest from peer
+func SnapshotInfoHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive snapshot info message", data.Addr, data.Id)
+	if pid != nil {
+		var info = data.Payload.(*msgTypes.SnapshotInfo)
+		input := &msgCommon.AppendSnapshotInfo{
+			FromID:   data.Id,
+			Manifest: &info.Manifest,
+		}
+		pid.Tell(input)
+	}
+}
+
+// SnapshotChunkReqHandle handles the state snapshot chunk request from peer
+func SnapshotChunkReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive snapshot chunk request message", data.Addr, data.Id)
+
+	req := data.Payload.(*msgTypes.SnapshotChunkReq)
+	chunk, err := ledger.DefLedger.GetSnapshotChunk(req.Height, req.Index)
+	if err != nil {
+		log.Debugf("[p2p]get snapshot chunk height:%d index:%d error: %s", req.Height, req.Index, err)
+		return
+	}
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debugf("[p2p]remotePeer invalid in SnapshotChunkReqHandle, peer id: %d", data.Id)
+		return
+	}
+	msg := msgpack.NewSnapshotChunk(req.Height, req.Index, chunk)
+	err = p2p.Send(remotePeer, msg)
+	if err != nil {
+		log.Warn(err)
+	}
+}
+
+// SnapshotChunkHandle handles the state snapshot chunk from peer
+func SnapshotChunkHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive snapshot chunk message", data.Addr, data.Id)
+	if pid != nil {
+		var chunk = data.Payload.(*msgTypes.SnapshotChunk)
+		input := &msgCommon.AppendSnapshotChunk{
+			FromID: data.Id,
+			Height: chunk.Height,
+			Index:  chunk.Index,
+			Data:   chunk.Data,
+		}
+		pid.Tell(input)
+	}
+}
+
+// TxProofReqHandle handles the transaction proof request from light peer
+func TxProofReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive tx proof request message", data.Addr, data.Id)
+
+	req := data.Payload.(*msgTypes.TxProofReq)
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debugf("[p2p]remotePeer invalid in TxProofReqHandle, peer id: %d", data.Id)
+		return
+	}
+	msg := newTxProof(req.TxHash)
+	if msg == nil {
+		msg = msgpack.NewNotFound(req.TxHash)
+	}
+	err := p2p.Send(remotePeer, msg)
+	if err != nil {
+		log.Warn(err)
+	}
+}
+
+//newTxProof return the transaction proof message of txHash, nil if the transaction or its block not found
+func newTxProof(txHash common.Uint256) msgTypes.Message {
+	tx, height, err := ledger.DefLedger.GetTransactionWithHeight(txHash)
+	if err != nil || tx == nil {
+		log.Debugf("[p2p]can't get transaction by hash: %s, send not found message", txHash.ToHexString())
+		return nil
+	}
+	block, err := ledger.DefLedger.GetBlockByHeight(height)
+	if err != nil || block == nil {
+		log.Debugf("[p2p]can't get block by height: %d, send not found message", height)
+		return nil
+	}
+	hashes := make([]common.Uint256, 0, len(block.Transactions))
+	index := -1
+	for i, t := range block.Transactions {
+		hash := t.Hash()
+		if hash == txHash {
+			index = i
+		}
+		hashes = append(hashes, hash)
+	}
+	if index < 0 {
+		log.Warnf("[p2p]transaction %s not in block %d", txHash.ToHexString(), height)
+		return nil
+	}
+	proof, err := common.ComputeMerkleProof(hashes, uint32(index))
+	if err != nil {
+		log.Warn(err)
+		return nil
+	}
+	return msgpack.NewTxProof(height, uint32(index), tx, proof)
+}
+
+// TxProofHandle handles the transaction proof from peer
+func TxProofHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive tx proof message", data.Addr, data.Id)
+	if pid != nil {
+		var txProof = data.Payload.(*msgTypes.TxProof)
+		input := &msgCommon.AppendTxProof{
+			FromID: data.Id,
+			Height: txProof.Height,
+			Index:  txProof.Index,
+			Tx:     txProof.Tx,
+			Proof:  txProof.Proof,
+		}
+		pid.Tell(input)
+	}
+}
+
 // ConsensusHandle handles the consensus message from peer
 func ConsensusHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	log.Debugf("[p2p]receive consensus message:%v,%d", data.Addr, data.Id)
@@ -221,12 +360,22 @@ func ConsensusHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID,
 func NotFoundHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	var notFound = data.Payload.(*msgTypes.NotFound)
 	log.Debug("[p2p]receive notFound message, hash is ", notFound.Hash)
+	if pid != nil && config.DefConfig.Common.EnableLightMode {
+		input := &msgCommon.AppendNotFound{
+			FromID: data.Id,
+			Hash:   notFound.Hash,
+		}
+		pid.Tell(input)
+	}
 }
 
 // TransactionHandle handles the transaction message from peer
 func TransactionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	log.Trace("[p2p]receive transaction message", data.Addr, data.Id)
 
+	if config.DefConfig.Common.EnableLightMode {
+		return
+	}
 	var trn = data.Payload.(*msgTypes.Trn)
 
 	if !txCache.Contains(trn.Txn.Hash()) {
@@ -351,6 +500,11 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 	}
 	remotePeer.SetHttpInfoPort(version.P.HttpInfoPort)
 
+	if !config.DefConfig.P2PNode.DisableCompression {
+		remotePeer.Link.SetCompression(msgCommon.NegotiateCompression(msgCommon.COMPRESS_SUPPORTED,
+			version.P.Cap[msgCommon.COMPRESS_FLAG]))
+	}
+
 	remotePeer.UpdateInfo(time.Now(), version.P.Version,
 		version.P.Services, version.P.SyncPort, version.P.Nonce,
 		version.P.Relay, version.P.StartHeight, version.P.SoftVersion)
@@ -570,8 +724,8 @@ func DataReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 			err = p2p.Send(remotePeer, msg)
 			if err != nil {
 				log.Warn(err)
-				return
 			}
+			return
 		}
 		msg := msgpack.NewTxn(txn)
 		err = p2p.Send(remotePeer, msg)
@@ -666,6 +666,63 @@ func TestDataReqHandle(t *testing.T) {
 	network.DelNbrNode(testID)
 }
 
+// peerMockP2P is a MockP2P which resolves the given peer without a neighbor table
+type peerMockP2P struct {
+	*MockP2P
+	remote *peer.Peer
+}
+
+func (mock *peerMockP2P) GetPeer(id uint64) *peer.Peer {
+	if mock.remote.GetID() == id {
+		return mock.remote
+	}
+	return nil
+}
+
+// TestDataReqHandleTxNotFound tests Function DataReqHandle only replying not found for an unknown transaction
+func TestDataReqHandleTxNotFound(t *testing.T) {
+	remotePeer := peer.NewPeer()
+	remotePeer.UpdateInfo(time.Now(), 1, 12345678, 20336,
+		12345, 0, 12345, "1.5.2")
+	mock := &peerMockP2P{&MockP2P{SentMsgs: make([]types.Message, 0)}, remotePeer}
+
+	var txHash common.Uint256
+	txHash[0] = 1
+	msg := &types.MsgPayload{
+		Id:      12345,
+		Addr:    "127.0.0.1:50010",
+		Payload: msgpack.NewTxnDataReq(txHash),
+	}
+
+	DataReqHandle(msg, mock, nil)
+	assert.Equal(t, 1, len(mock.SentMsgs))
+	notFound, ok := mock.SentMsgs[0].(*types.NotFound)
+	assert.True(t, ok)
+	assert.Equal(t, txHash, notFound.Hash)
+}
+
+// TestTxProofReqHandleNotFound tests Function TxProofReqHandle replying not found for an unknown transaction
+func TestTxProofReqHandleNotFound(t *testing.T) {
+	remotePeer := peer.NewPeer()
+	remotePeer.UpdateInfo(time.Now(), 1, 12345678, 20336,
+		12345, 0, 12345, "1.5.2")
+	mock := &peerMockP2P{&MockP2P{SentMsgs: make([]types.Message, 0)}, remotePeer}
+
+	var txHash common.Uint256
+	txHash[0] = 1
+	msg := &types.MsgPayload{
+		Id:      12345,
+		Addr:    "127.0.0.1:50010",
+		Payload: msgpack.NewTxProofReq(txHash),
+	}
+
+	TxProofReqHandle(msg, mock, nil)
+	assert.Equal(t, 1, len(mock.SentMsgs))
+	notFound, ok := mock.SentMsgs[0].(*types.NotFound)
+	assert.True(t, ok)
+	assert.Equal(t, txHash, notFound.Hash)
+}
+
 // TestInvHandle tests Function InvHandle handling an inventory message
 func TestInvHandle(t *testing.T) {
 	var testID uint64
@@ -93,11 +93,16 @@ type PeerAddrMap struct {
 func (this *NetServer) init() error {
 	this.base.SetVersion(common.PROTOCOL_VERSION)
 
-	if config.DefConfig.Consensus.EnableConsensus {
+	if config.DefConfig.Common.EnableLightMode {
+		this.base.SetServices(uint64(common.LIGHT_NODE))
+	} else if config.DefConfig.Consensus.EnableConsensus {
 		this.base.SetServices(uint64(common.VERIFY_NODE))
 	} else {
 		this.base.SetServices(uint64(common.SERVICE_NODE))
 	}
+	if config.DefConfig.Common.PruneKeepBlocks != 0 {
+		this.base.SetServices(this.base.GetServices() | common.PRUNED_NODE)
+	}
 
 	if config.DefConfig.P2PNode.NodePort == 0 {
 		log.Error("[p2p]link port invalid")
@@ -35,6 +35,7 @@ import (
 	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
 	"github.com/dnaproject2/DNA/core/ledger"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
 	"github.com/dnaproject2/DNA/core/types"
 	"github.com/dnaproject2/DNA/p2pserver/common"
 	msgpack "github.com/dnaproject2/DNA/p2pserver/message/msg_pack"
@@ -52,6 +53,8 @@ type P2PServer struct {
 	msgRouter *utils.MessageRouter
 	pid       *evtActor.PID
 	blockSync *BlockSyncMgr
+	snapSync  *SnapshotSyncMgr
+	txProof   *TxProofMgr
 	ledger    *ledger.Ledger
 	ReconnectAddrs
 	recentPeers    map[uint32][]string
@@ -76,7 +79,9 @@ func NewServer() *P2PServer {
 	}
 
 	p.msgRouter = utils.NewMsgRouter(p.network)
+	p.snapSync = NewSnapshotSyncMgr(p)
 	p.blockSync = NewBlockSyncMgr(p)
+	p.txProof = NewTxProofMgr(p)
 	p.recentPeers = make(map[uint32][]string)
 	p.quitSyncRecent = make(chan bool)
 	p.quitOnline = make(chan bool)
@@ -106,6 +111,7 @@ func (this *P2PServer) Start() error {
 	go this.syncUpRecentPeers()
 	go this.keepOnlineService()
 	go this.heartBeatService()
+	go this.snapSync.Start()
 	go this.blockSync.Start()
 	return nil
 }
@@ -117,6 +123,7 @@ func (this *P2PServer) Stop() {
 	this.quitOnline <- true
 	this.quitHeartBeat <- true
 	this.msgRouter.Stop()
+	this.snapSync.Close()
 	this.blockSync.Close()
 }
 
@@ -205,6 +212,31 @@ func (this *P2PServer) OnBlockReceive(fromID uint64, blockSize uint32,
 	this.blockSync.OnBlockReceive(fromID, blockSize, block, merkleRoot)
 }
 
+// OnSnapshotInfoReceive adds the snapshot manifest of peer
+func (this *P2PServer) OnSnapshotInfoReceive(fromID uint64, manifest *snapshot.Manifest) {
+	this.snapSync.OnSnapshotInfoReceive(fromID, manifest)
+}
+
+// OnSnapshotChunkReceive adds the snapshot chunk from network
+func (this *P2PServer) OnSnapshotChunkReceive(fromID uint64, height, index uint32, data []byte) {
+	this.snapSync.OnSnapshotChunkReceive(fromID, height, index, data)
+}
+
+// OnTxProofReceive adds the transaction proof from network
+func (this *P2PServer) OnTxProofReceive(fromID uint64, height, index uint32, tx *types.Transaction, proof []comm.Uint256) {
+	this.txProof.OnTxProofReceive(fromID, height, index, tx, proof)
+}
+
+// OnTxNotFound fails the transaction proof request after all requested full nodes reply not found
+func (this *P2PServer) OnTxNotFound(fromID uint64, txHash comm.Uint256) {
+	this.txProof.OnTxNotFound(fromID, txHash)
+}
+
+// GetVerifiedTransaction returns the transaction and its block height proved by full nodes
+func (this *P2PServer) GetVerifiedTransaction(txHash comm.Uint256) (*types.Transaction, uint32, error) {
+	return this.txProof.GetVerifiedTransaction(txHash)
+}
+
 // Todo: remove it if no use
 func (this *P2PServer) GetConnectionState() uint32 {
 	return common.INIT
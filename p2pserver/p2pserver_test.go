@@ -21,9 +21,13 @@ package p2pserver
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	comm "github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/log"
 	"github.com/dnaproject2/DNA/p2pserver/common"
+	"github.com/dnaproject2/DNA/p2pserver/peer"
 )
 
 func init() {
@@ -48,3 +52,51 @@ func TestNewP2PServer(t *testing.T) {
 		t.Error("TestNewP2PServer sync port error")
 	}
 }
+
+func TestCanServeBlock(t *testing.T) {
+	full := peer.NewPeer()
+	full.UpdateInfo(time.Now(), 1, common.SERVICE_NODE, 20338, 1, 0, 5000, "")
+	pruned := peer.NewPeer()
+	pruned.UpdateInfo(time.Now(), 1, common.SERVICE_NODE|common.PRUNED_NODE, 20338, 2, 0, 5000, "")
+	light := peer.NewPeer()
+	light.UpdateInfo(time.Now(), 1, common.LIGHT_NODE, 20338, 3, 0, 5000, "")
+
+	if !canServeBlock(full, 1) {
+		t.Error("full node should serve old block")
+	}
+	if canServeBlock(pruned, 1) {
+		t.Error("pruned node should not serve old block")
+	}
+	if !canServeBlock(pruned, 5000-config.MIN_PRUNE_KEEP_BLOCKS+1) {
+		t.Error("pruned node should serve latest block")
+	}
+	if canServeBlock(light, 5000) {
+		t.Error("light node should not serve block")
+	}
+}
+
+func TestTxProofNotFound(t *testing.T) {
+	mgr := &TxProofMgr{
+		pending: make(map[comm.Uint256][]chan *VerifiedTx),
+		asked:   make(map[comm.Uint256]map[uint64]bool),
+	}
+	var txHash comm.Uint256
+	txHash[0] = 1
+	ch := make(chan *VerifiedTx, 1)
+	mgr.pending[txHash] = []chan *VerifiedTx{ch}
+	mgr.asked[txHash] = map[uint64]bool{1: true, 2: true}
+
+	mgr.OnTxNotFound(3, txHash)
+	mgr.OnTxNotFound(1, txHash)
+	mgr.OnTxNotFound(1, txHash)
+	if len(ch) != 0 {
+		t.Fatal("request should wait for all requested full nodes")
+	}
+	mgr.OnTxNotFound(2, txHash)
+	if len(ch) != 1 || <-ch != nil {
+		t.Fatal("request should fail after all requested full nodes reply not found")
+	}
+	if len(mgr.pending) != 0 || len(mgr.asked) != 0 {
+		t.Error("request should be removed")
+	}
+}
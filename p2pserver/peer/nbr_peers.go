@@ -35,14 +35,22 @@ type NbrPeers struct {
 
 //Broadcast tranfer msg buffer to all establish peer
 func (this *NbrPeers) Broadcast(msg types.Message) {
-	sink := comm.NewZeroCopySink(nil)
-	types.WriteMessage(sink, msg)
+	//serialize once for each compression algorithm in use
+	raws := make(map[uint8][]byte)
 
 	this.RLock()
 	defer this.RUnlock()
 	for _, node := range this.List {
 		if node.linkState == common.ESTABLISH && node.GetRelay() == true {
-			node.SendRaw(msg.CmdType(), sink.Bytes())
+			algorithm := node.Link.GetCompression()
+			raw, ok := raws[algorithm]
+			if !ok {
+				sink := comm.NewZeroCopySink(nil)
+				types.WriteCompressedMessage(sink, msg, algorithm)
+				raw = sink.Bytes()
+				raws[algorithm] = raw
+			}
+			node.SendRaw(msg.CmdType(), raw)
 		}
 	}
 }
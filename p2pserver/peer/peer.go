@@ -274,7 +274,7 @@ func (this *Peer) AttachChan(msgchan chan *types.MsgPayload) {
 //Send transfer buffer by sync or cons link
 func (this *Peer) Send(msg types.Message) error {
 	sink := comm.NewZeroCopySink(nil)
-	types.WriteMessage(sink, msg)
+	types.WriteCompressedMessage(sink, msg, this.Link.GetCompression())
 
 	return this.SendRaw(msg.CmdType(), sink.Bytes())
 }
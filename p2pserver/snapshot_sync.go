@@ -0,0 +1,381 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/ledger"
+	"github.com/dnaproject2/DNA/core/store/snapshot"
+	p2pComm "github.com/dnaproject2/DNA/p2pserver/common"
+	"github.com/dnaproject2/DNA/p2pserver/message/msg_pack"
+	"github.com/dnaproject2/DNA/p2pserver/peer"
+)
+
+const (
+	SNAPSHOT_SYNC_MAX_FLIGHT_CHUNK = 8               //Number of chunks on flight
+	SNAPSHOT_SYNC_MAX_CHUNK_CACHE  = 64              //Chunks received ahead of the next chunk written to file
+	SNAPSHOT_CHUNK_REQUEST_TIMEOUT = 10              //s, Request chunk timeout time. If chunk haven't received after SNAPSHOT_CHUNK_REQUEST_TIMEOUT second, retry
+	SNAPSHOT_INFO_REQUEST_INTERVAL = 5               //s, Interval of requesting snapshot manifest from peers
+	SNAPSHOT_SYNC_WAIT_TIMEOUT     = 120             //s, Fall back to block sync if no snapshot is agreed after SNAPSHOT_SYNC_WAIT_TIMEOUT second
+	SNAPSHOT_SYNC_FILE             = "fastsync.snap" //Temp file of the downloading snapshot
+)
+
+//SnapshotSyncMgr bootstrap an empty ledger from the state snapshot served by peers.
+//Only the snapshot matching the trusted state digest configured is used, peers can not
+//vouch for a snapshot. The digest is not committed on chain, so the imported state is
+//trusted as far as the operator trusts the source of the digest. Block sync waits until it is finished.
+type SnapshotSyncMgr struct {
+	server         *P2PServer
+	ledger         *ledger.Ledger
+	path           string                        //Path of the downloading snapshot
+	trustedDigest  common.Uint256                //Trusted state digest of snapshot
+	finished       bool                          //Whether fast sync is finished or disabled
+	importing      bool                          //Whether the downloaded snapshot is importing to ledger
+	startTime      time.Time                     //Start time of waiting for snapshot
+	lastInfoReq    time.Time                     //Last time of requesting snapshot manifest
+	manifests      map[uint64]*snapshot.Manifest //Map NodeID => the latest snapshot manifest of peer
+	manifestHashes map[uint64]common.Uint256     //Map NodeID => the hash of manifest
+	badSnapshots   map[common.Uint256]bool       //Hash of manifest failed to import
+	manifest       *snapshot.Manifest            //Manifest of the downloading snapshot
+	manifestHash   common.Uint256                //Hash of the downloading manifest
+	writer         *snapshot.Writer              //Writer of the downloading snapshot
+	chunks         map[uint32][]byte             //Map chunk index => data received ahead of next chunk
+	nextChunk      uint32                        //Index of next chunk written to file
+	flightChunks   map[uint32]*SyncFlightInfo    //Map chunk index => SyncFlightInfo
+	exitCh         chan interface{}              //ExitCh to receive exit signal
+	lock           sync.Mutex                    //lock
+}
+
+//NewSnapshotSyncMgr return a SnapshotSyncMgr instance. It is finished at once if fast sync is disabled or
+//the ledger is not empty
+func NewSnapshotSyncMgr(server *P2PServer) *SnapshotSyncMgr {
+	mgr := &SnapshotSyncMgr{
+		server:         server,
+		ledger:         server.ledger,
+		path:           filepath.Join(config.DefConfig.Common.DataDir, SNAPSHOT_SYNC_FILE),
+		finished:       true,
+		manifests:      make(map[uint64]*snapshot.Manifest),
+		manifestHashes: make(map[uint64]common.Uint256),
+		badSnapshots:   make(map[common.Uint256]bool),
+		chunks:         make(map[uint32][]byte),
+		flightChunks:   make(map[uint32]*SyncFlightInfo),
+		exitCh:         make(chan interface{}, 1),
+	}
+	if !config.DefConfig.P2PNode.EnableFastSync {
+		return mgr
+	}
+	if mgr.ledger.GetCurrentBlockHeight() != 0 {
+		log.Infof("[p2p]ledger is not empty, skip fast sync")
+		return mgr
+	}
+	digest := config.DefConfig.P2PNode.FastSyncStateDigest
+	if digest == "" {
+		log.Errorf("[p2p]trusted state digest not configured, skip fast sync")
+		return mgr
+	}
+	trustedDigest, err := common.Uint256FromHexString(digest)
+	if err != nil || trustedDigest == common.UINT256_EMPTY {
+		log.Errorf("[p2p]invalid fast sync state digest %s: %v, skip fast sync", digest, err)
+		return mgr
+	}
+	mgr.trustedDigest = trustedDigest
+	mgr.finished = false
+	return mgr
+}
+
+//Finished return whether the fast sync is finished, or disabled
+func (this *SnapshotSyncMgr) Finished() bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.finished
+}
+
+//Start to sync
+func (this *SnapshotSyncMgr) Start() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-this.exitCh:
+			return
+		case <-ticker.C:
+			if this.Finished() {
+				return
+			}
+			this.sync()
+		}
+	}
+}
+
+//Stop to sync
+func (this *SnapshotSyncMgr) Close() {
+	close(this.exitCh)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if !this.importing {
+		this.resetSnapshot()
+	}
+}
+
+func (this *SnapshotSyncMgr) sync() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.finished || this.importing {
+		return
+	}
+	peers := this.server.network.GetNeighbors()
+	if len(peers) == 0 {
+		return
+	}
+	if this.startTime.IsZero() {
+		this.startTime = time.Now()
+	}
+	this.requestSnapshotInfo(peers)
+	if this.manifest == nil {
+		this.selectSnapshot()
+		if this.manifest == nil {
+			if time.Since(this.startTime) >= SNAPSHOT_SYNC_WAIT_TIMEOUT*time.Second {
+				log.Warnf("[p2p]no state snapshot agreed after %d s, fall back to block sync", SNAPSHOT_SYNC_WAIT_TIMEOUT)
+				this.finished = true
+			}
+			return
+		}
+	}
+	this.checkTimeout()
+	this.requestChunks()
+}
+
+func (this *SnapshotSyncMgr) requestSnapshotInfo(peers []*peer.Peer) {
+	if time.Since(this.lastInfoReq) < SNAPSHOT_INFO_REQUEST_INTERVAL*time.Second {
+		return
+	}
+	this.lastInfoReq = time.Now()
+	msg := msgpack.NewSnapshotInfoReq()
+	for _, p := range peers {
+		if p.GetState() != p2pComm.ESTABLISH {
+			continue
+		}
+		err := this.server.Send(p, msg, false)
+		if err != nil {
+			log.Warnf("[p2p]requestSnapshotInfo to %d error: %s", p.GetID(), err)
+		}
+	}
+}
+
+//selectSnapshot choose the snapshot matching the trusted state digest
+func (this *SnapshotSyncMgr) selectSnapshot() {
+	counts := make(map[common.Uint256]int)
+	for id, hash := range this.manifestHashes {
+		if this.server.getNode(id) == nil || this.badSnapshots[hash] {
+			continue
+		}
+		counts[hash]++
+	}
+	var best *snapshot.Manifest
+	var bestHash common.Uint256
+	for id, manifest := range this.manifests {
+		hash := this.manifestHashes[id]
+		if _, ok := counts[hash]; !ok || manifest.StateDigest != this.trustedDigest {
+			continue
+		}
+		if best == nil || manifest.Height > best.Height {
+			best = manifest
+			bestHash = hash
+		}
+	}
+	if best == nil {
+		return
+	}
+	err := os.MkdirAll(filepath.Dir(this.path), 0755)
+	if err != nil {
+		log.Errorf("[p2p]create fast sync dir error: %s", err)
+		return
+	}
+	writer, err := snapshot.NewWriter(this.path)
+	if err != nil {
+		log.Errorf("[p2p]create fast sync file error: %s", err)
+		return
+	}
+	this.manifest = best
+	this.manifestHash = bestHash
+	this.writer = writer
+	log.Infof("[p2p]fast sync from snapshot at height %d, block hash %s, chunks %d, peers %d",
+		best.Height, best.BlockHash.ToHexString(), len(best.ChunkHashes), counts[bestHash])
+}
+
+//snapshotPeers return the connected peers serving the downloading snapshot
+func (this *SnapshotSyncMgr) snapshotPeers() []*peer.Peer {
+	peers := make([]*peer.Peer, 0)
+	for id, hash := range this.manifestHashes {
+		if hash != this.manifestHash {
+			continue
+		}
+		p := this.server.getNode(id)
+		if p == nil || p.GetState() != p2pComm.ESTABLISH {
+			continue
+		}
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+func (this *SnapshotSyncMgr) checkTimeout() {
+	peers := this.snapshotPeers()
+	for index, flightInfo := range this.flightChunks {
+		if time.Since(flightInfo.GetStartTime()) < SNAPSHOT_CHUNK_REQUEST_TIMEOUT*time.Second {
+			continue
+		}
+		flightInfo.MarkFailedNode()
+		if len(peers) == 0 {
+			delete(this.flightChunks, index)
+			continue
+		}
+		log.Debugf("[p2p]snapshot chunk %d from id:%d timeout after:%d s times:%d", index, flightInfo.GetNodeId(),
+			SNAPSHOT_CHUNK_REQUEST_TIMEOUT, flightInfo.GetTotalFailedTimes())
+		p := peers[(int(index)+flightInfo.GetTotalFailedTimes())%len(peers)]
+		flightInfo.SetNodeId(p.GetID())
+		flightInfo.ResetStartTime()
+		err := this.server.Send(p, msgpack.NewSnapshotChunkReq(this.manifest.Height, index), false)
+		if err != nil {
+			log.Warnf("[p2p]checkTimeout reqNode ID:%d Send error:%s", p.GetID(), err)
+		}
+	}
+}
+
+func (this *SnapshotSyncMgr) requestChunks() {
+	peers := this.snapshotPeers()
+	if len(peers) == 0 {
+		return
+	}
+	total := uint32(len(this.manifest.ChunkHashes))
+	for index := this.nextChunk; index < total && index < this.nextChunk+SNAPSHOT_SYNC_MAX_CHUNK_CACHE; index++ {
+		if len(this.flightChunks) >= SNAPSHOT_SYNC_MAX_FLIGHT_CHUNK {
+			return
+		}
+		if _, ok := this.chunks[index]; ok {
+			continue
+		}
+		if _, ok := this.flightChunks[index]; ok {
+			continue
+		}
+		p := peers[int(index)%len(peers)]
+		err := this.server.Send(p, msgpack.NewSnapshotChunkReq(this.manifest.Height, index), false)
+		if err != nil {
+			log.Warnf("[p2p]requestChunks reqNode ID:%d Send error:%s", p.GetID(), err)
+			continue
+		}
+		this.flightChunks[index] = NewSyncFlightInfo(index, p.GetID())
+	}
+}
+
+//OnSnapshotInfoReceive record the snapshot manifest of peer
+func (this *SnapshotSyncMgr) OnSnapshotInfoReceive(fromID uint64, manifest *snapshot.Manifest) {
+	if err := manifest.Verify(); err != nil {
+		log.Debugf("[p2p]invalid snapshot manifest from id:%d: %s", fromID, err)
+		return
+	}
+	hash := manifest.Hash()
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.finished {
+		return
+	}
+	this.manifests[fromID] = manifest
+	this.manifestHashes[fromID] = hash
+}
+
+//OnSnapshotChunkReceive verify the chunk and write it to the downloading snapshot in order
+func (this *SnapshotSyncMgr) OnSnapshotChunkReceive(fromID uint64, height, index uint32, data []byte) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.finished || this.importing || this.manifest == nil || height != this.manifest.Height {
+		return
+	}
+	if _, ok := this.flightChunks[index]; !ok {
+		return
+	}
+	delete(this.flightChunks, index)
+	if err := this.manifest.VerifyChunk(index, data); err != nil {
+		log.Warnf("[p2p]invalid snapshot chunk from id:%d: %s", fromID, err)
+		delete(this.manifests, fromID)
+		delete(this.manifestHashes, fromID)
+		return
+	}
+	this.chunks[index] = data
+	for {
+		chunk, ok := this.chunks[this.nextChunk]
+		if !ok {
+			break
+		}
+		if err := this.writer.AddChunk(chunk); err != nil {
+			log.Errorf("[p2p]write snapshot chunk %d error: %s", this.nextChunk, err)
+			this.resetSnapshot()
+			return
+		}
+		delete(this.chunks, this.nextChunk)
+		this.nextChunk++
+	}
+	if this.nextChunk == uint32(len(this.manifest.ChunkHashes)) {
+		this.importing = true
+		go this.importSnapshot(this.writer, this.manifest, this.manifestHash)
+	}
+}
+
+func (this *SnapshotSyncMgr) importSnapshot(writer *snapshot.Writer, manifest *snapshot.Manifest, hash common.Uint256) {
+	log.Infof("[p2p]snapshot at height %d downloaded, importing to ledger", manifest.Height)
+	m := *manifest
+	err := writer.Finish(&m)
+	if err == nil {
+		err = this.ledger.ImportSnapshot(this.path, this.trustedDigest)
+	}
+	os.Remove(this.path)
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.importing = false
+	if err != nil {
+		log.Errorf("[p2p]import snapshot at height %d error: %s", manifest.Height, err)
+		this.badSnapshots[hash] = true
+		this.resetSnapshot()
+		return
+	}
+	this.finished = true
+	this.resetSnapshot()
+	log.Infof("[p2p]fast sync finished at height %d", manifest.Height)
+}
+
+//resetSnapshot discard the downloading snapshot
+func (this *SnapshotSyncMgr) resetSnapshot() {
+	if this.writer != nil {
+		this.writer.Abort()
+		this.writer = nil
+	}
+	this.manifest = nil
+	this.manifestHash = common.UINT256_EMPTY
+	this.chunks = make(map[uint32][]byte)
+	this.flightChunks = make(map[uint32]*SyncFlightInfo)
+	this.nextChunk = 0
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/log"
+	"github.com/dnaproject2/DNA/core/ledger"
+	"github.com/dnaproject2/DNA/core/types"
+	p2pComm "github.com/dnaproject2/DNA/p2pserver/common"
+	"github.com/dnaproject2/DNA/p2pserver/message/msg_pack"
+	"github.com/dnaproject2/DNA/p2pserver/peer"
+)
+
+const (
+	TX_PROOF_REQUEST_PEERS = 3  //Number of full nodes requested for a transaction proof
+	TX_PROOF_TIMEOUT       = 10 //s, Request transaction proof timeout time
+)
+
+//VerifiedTx is the transaction proved to be in the block at Height
+type VerifiedTx struct {
+	Tx     *types.Transaction
+	Height uint32
+}
+
+//TxProofMgr request transactions with merkle proof from full nodes, and verify them by local headers. Using by light node
+type TxProofMgr struct {
+	server  *P2PServer
+	ledger  *ledger.Ledger
+	pending map[common.Uint256][]chan *VerifiedTx //Map TxHash => waiting requests
+	asked   map[common.Uint256]map[uint64]bool    //Map TxHash => requested full nodes not replied not found
+	lock    sync.Mutex
+}
+
+//NewTxProofMgr return a TxProofMgr instance
+func NewTxProofMgr(server *P2PServer) *TxProofMgr {
+	return &TxProofMgr{
+		server:  server,
+		ledger:  server.ledger,
+		pending: make(map[common.Uint256][]chan *VerifiedTx),
+		asked:   make(map[common.Uint256]map[uint64]bool),
+	}
+}
+
+//GetVerifiedTransaction request the transaction from full nodes, return it after the merkle proof is verified by local header
+func (this *TxProofMgr) GetVerifiedTransaction(txHash common.Uint256) (*types.Transaction, uint32, error) {
+	peers := this.getFullNodes()
+	if len(peers) == 0 {
+		return nil, 0, errors.New("[p2p]no full node connected")
+	}
+	var requested []*peer.Peer
+	for _, i := range rand.Perm(len(peers)) {
+		if len(requested) == TX_PROOF_REQUEST_PEERS {
+			break
+		}
+		requested = append(requested, peers[i])
+	}
+	ch := make(chan *VerifiedTx, 1)
+	this.lock.Lock()
+	this.pending[txHash] = append(this.pending[txHash], ch)
+	if this.asked[txHash] == nil {
+		this.asked[txHash] = make(map[uint64]bool)
+	}
+	for _, p := range requested {
+		this.asked[txHash][p.GetID()] = true
+	}
+	this.lock.Unlock()
+	defer this.delPending(txHash, ch)
+
+	msg := msgpack.NewTxProofReq(txHash)
+	for _, p := range requested {
+		err := this.server.Send(p, msg, false)
+		if err != nil {
+			log.Warnf("[p2p]GetVerifiedTransaction send to peer %d error:%s", p.GetID(), err)
+		}
+	}
+	select {
+	case verified := <-ch:
+		if verified == nil {
+			return nil, 0, errors.New("[p2p]transaction not found by full nodes")
+		}
+		return verified.Tx, verified.Height, nil
+	case <-time.After(TX_PROOF_TIMEOUT * time.Second):
+		return nil, 0, errors.New("[p2p]request transaction proof timeout")
+	}
+}
+
+//OnTxProofReceive verify the transaction proof from net, and wake up the waiting requests
+func (this *TxProofMgr) OnTxProofReceive(fromID uint64, height, index uint32, tx *types.Transaction, proof []common.Uint256) {
+	txHash := tx.Hash()
+	this.lock.Lock()
+	_, ok := this.pending[txHash]
+	this.lock.Unlock()
+	if !ok {
+		return
+	}
+	header, err := this.ledger.GetHeaderByHeight(height)
+	if err != nil || header == nil {
+		log.Debugf("[p2p]OnTxProofReceive header of height %d not synced", height)
+		return
+	}
+	if !common.VerifyMerkleProof(txHash, index, proof, header.TransactionsRoot) {
+		log.Warnf("[p2p]OnTxProofReceive invalid proof of tx %s from peer %d", txHash.ToHexString(), fromID)
+		return
+	}
+	this.lock.Lock()
+	chs := this.pending[txHash]
+	delete(this.pending, txHash)
+	delete(this.asked, txHash)
+	this.lock.Unlock()
+	for _, ch := range chs {
+		ch <- &VerifiedTx{Tx: tx, Height: height}
+	}
+}
+
+//OnTxNotFound wake up the waiting requests with nil, after all requested full nodes reply not found
+func (this *TxProofMgr) OnTxNotFound(fromID uint64, txHash common.Uint256) {
+	this.lock.Lock()
+	asked, ok := this.asked[txHash]
+	if !ok || !asked[fromID] {
+		this.lock.Unlock()
+		return
+	}
+	delete(asked, fromID)
+	if len(asked) != 0 {
+		this.lock.Unlock()
+		return
+	}
+	chs := this.pending[txHash]
+	delete(this.pending, txHash)
+	delete(this.asked, txHash)
+	this.lock.Unlock()
+	for _, ch := range chs {
+		ch <- nil
+	}
+}
+
+func (this *TxProofMgr) delPending(txHash common.Uint256, ch chan *VerifiedTx) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	chs := this.pending[txHash]
+	for i, c := range chs {
+		if c == ch {
+			chs = append(chs[:i], chs[i+1:]...)
+			break
+		}
+	}
+	if len(chs) == 0 {
+		delete(this.pending, txHash)
+		delete(this.asked, txHash)
+	} else {
+		this.pending[txHash] = chs
+	}
+}
+
+//getFullNodes return the established peers which keep blocks
+func (this *TxProofMgr) getFullNodes() []*peer.Peer {
+	var peers []*peer.Peer
+	for _, p := range this.server.network.GetNeighbors() {
+		if p.GetState() != p2pComm.ESTABLISH || isLightNode(p) {
+			continue
+		}
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+//isLightNode return whether the peer only keeps headers
+func isLightNode(p *peer.Peer) bool {
+	return p.GetServices()&p2pComm.LIGHT_NODE != 0
+}
@@ -46,6 +46,8 @@ func setupSigSvr() *cli.App {
 		utils.CliAddressFlag,
 		utils.CliRpcPortFlag,
 		utils.CliABIPathFlag,
+		//node json rpc port, to fetch the contract abi published on chain
+		utils.RPCPortFlag,
 	}
 	app.Commands = []cli.Command{
 		cmdsvr.ImportExecutorCommand,
@@ -89,6 +91,8 @@ func startSigSvr(ctx *cli.Context) {
 	}
 	go cmdsvr.DefCliRpcSvr.Start(rpcAddress, rpcPort)
 
+	config.DefConfig.Rpc.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.RPCPortFlag))
+
 	abiPath := ctx.GlobalString(utils.GetFlagName(utils.CliABIPathFlag))
 	abi.DefAbiMgr.Init(abiPath)
 
@@ -21,6 +21,13 @@ package context
 import (
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+)
+
+const (
+	NEOVM_CONTRACT  = "neovm"  //Contract executed by neovm
+	NATIVE_CONTRACT = "native" //Native contract
 )
 
 // ContextRef is a interface of smart context
@@ -40,6 +47,7 @@ type ContextRef interface {
 	NewExecuteEngine(code []byte) (Engine, error)
 	CheckUseGas(gas uint64) bool
 	CheckExecStep() bool
+	GasLeft() uint64
 }
 
 type Engine interface {
@@ -51,3 +59,18 @@ type Context struct {
 	ContractAddress common.Address
 	Code            []byte
 }
+
+// Tracer receives the execution steps of smart contracts, including the opcodes of neovm,
+// storage access, system calls and the contract calls, used to trace transaction
+type Tracer interface {
+	vm.Tracer
+	storage.Tracer
+	// CaptureEnter is called when a contract of vmType is invoked, gas is the gas left before invoking
+	CaptureEnter(vmType string, address common.Address, method string, input []byte, gas uint64)
+	// CaptureExit is called when the contract invoked by the last CaptureEnter returns
+	CaptureExit(output interface{}, gas uint64, err error)
+	// CaptureSyscall is called when a neovm system call returns
+	CaptureSyscall(name string, err error)
+	// CaptureGas is called when neovm charges gas for the opcode name, or for the system call name if syscall is true
+	CaptureGas(name string, syscall bool, gas uint64)
+}
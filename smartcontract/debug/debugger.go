@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+//Package debug provides an interactive debugger of neovm contracts, which pauses the execution at breakpoints
+//or steps, and inspects the stacks and storage while paused
+package debug
+
+import (
+	"sync"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+)
+
+type stepMode int
+
+const (
+	modeContinue stepMode = iota //run until a breakpoint
+	modeStepInto                 //pause at the next opcode
+	modeStepOver                 //pause at the next opcode not in the calls of current opcode
+	modeStepOut                  //pause at the next opcode after current call returns
+	modeAbort                    //stop the execution
+)
+
+//depth is the position of an opcode in the call stack
+type depth struct {
+	calls    int //count of contract calls
+	contexts int //count of neovm contexts in the engine of innermost contract
+}
+
+func (this depth) deeper(other depth) bool {
+	return this.calls > other.calls || this.calls == other.calls && this.contexts > other.contexts
+}
+
+type breakpoint struct {
+	contract common.Address
+	offset   int
+}
+
+type frame struct {
+	vmType   string
+	contract common.Address
+	method   string
+	engine   *vm.ExecutionEngine
+	offset   int
+	op       vm.OpCode
+}
+
+//Debugger is the tracer pausing the execution on the goroutine running contract. It blocks in CaptureOp until
+//resumed by Session, and the execution states are only read by Session while it blocks
+type Debugger struct {
+	lock        sync.Mutex
+	breakpoints map[breakpoint]bool
+
+	frames  []*frame
+	mode    stepMode
+	from    depth              //depth of the opcode starting step
+	storage map[string]*[]byte //storage accessed in execution, nil value means deleted
+
+	stops  chan *Stop
+	resume chan stepMode
+}
+
+func newDebugger() *Debugger {
+	return &Debugger{
+		breakpoints: make(map[breakpoint]bool),
+		storage:     make(map[string]*[]byte),
+		stops:       make(chan *Stop),
+		resume:      make(chan stepMode),
+	}
+}
+
+func (this *Debugger) current() *frame {
+	for i := len(this.frames) - 1; i >= 0; i-- {
+		if this.frames[i].vmType == context.NEOVM_CONTRACT {
+			return this.frames[i]
+		}
+	}
+	return nil
+}
+
+func (this *Debugger) depth() depth {
+	d := depth{calls: len(this.frames)}
+	if f := this.current(); f != nil && f.engine != nil {
+		d.contexts = len(f.engine.Contexts)
+	}
+	return d
+}
+
+func (this *Debugger) hitBreakpoint(contract common.Address, offset int) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.breakpoints[breakpoint{contract, offset}]
+}
+
+//CaptureOp pause the execution if the opcode is at breakpoint or ends the step
+func (this *Debugger) CaptureOp(engine *vm.ExecutionEngine, pc int, op vm.OpCode) {
+	f := this.current()
+	if f == nil {
+		return
+	}
+	f.engine, f.offset, f.op = engine, pc, op
+	d := this.depth()
+	hit := this.hitBreakpoint(f.contract, pc)
+	pause := hit
+	switch this.mode {
+	case modeStepInto:
+		pause = true
+	case modeStepOver:
+		pause = pause || !d.deeper(this.from)
+	case modeStepOut:
+		pause = pause || this.from.deeper(d)
+	case modeAbort:
+		panic(errAborted)
+	}
+	if !pause {
+		return
+	}
+	this.stops <- &Stop{Contract: f.contract, Offset: pc, Op: vm.OpCodeName(op), Depth: d.calls, Breakpoint: hit}
+	this.mode = <-this.resume
+	this.from = d
+	if this.mode == modeAbort {
+		panic(errAborted)
+	}
+}
+
+func (this *Debugger) CaptureStorage(op string, key, value []byte) {
+	switch op {
+	case storage.STORAGE_PUT, storage.STORAGE_GET:
+		if _, ok := this.storage[string(key)]; op == storage.STORAGE_GET && ok {
+			return
+		}
+		v := append([]byte{}, value...)
+		this.storage[string(key)] = &v
+	case storage.STORAGE_DELETE:
+		this.storage[string(key)] = nil
+	}
+}
+
+func (this *Debugger) CaptureEnter(vmType string, address common.Address, method string, input []byte, gas uint64) {
+	this.frames = append(this.frames, &frame{vmType: vmType, contract: address, method: method})
+}
+
+func (this *Debugger) CaptureExit(output interface{}, gas uint64, err error) {
+	if len(this.frames) != 0 {
+		this.frames = this.frames[:len(this.frames)-1]
+	}
+}
+
+func (this *Debugger) CaptureSyscall(name string, err error) {
+}
+
+func (this *Debugger) CaptureGas(name string, syscall bool, gas uint64) {
+}
@@ -0,0 +1,251 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package debug
+
+import (
+	"errors"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/core/store"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	scommon "github.com/dnaproject2/DNA/smartcontract/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+)
+
+var (
+	errAborted    = errors.New("execution aborted by debugger")
+	ErrNotPaused  = errors.New("execution is not paused")
+	ErrNotStarted = errors.New("execution is not started")
+)
+
+//Stop describe where the execution pauses, or the result when the execution finishes
+type Stop struct {
+	Contract   common.Address
+	Offset     int
+	Op         string
+	Depth      int  //count of contract calls
+	Breakpoint bool //whether paused at breakpoint
+	Finished   bool
+	Result     *sstate.PreExecResult
+	Err        error
+}
+
+//Frame is a contract invocation in the call stack of paused execution
+type Frame struct {
+	Type     string //neovm or native
+	Contract common.Address
+	Method   string
+	Offset   int //offset of the current opcode of neovm contract
+	Op       string
+}
+
+//RunFunc execute the contract with tracer, e.g. pre-execute a transaction by ledger
+type RunFunc func(tracer context.Tracer) (*sstate.PreExecResult, error)
+
+//Session is a debug session of one contract execution, which is driven by Continue and Step methods. The
+//inspection methods are only valid while the execution is paused
+type Session struct {
+	debugger *Debugger
+	store    store.LedgerStore
+	run      RunFunc
+	started  bool
+	last     *Stop
+}
+
+//NewSession create a debug session, the storage not accessed in execution is read from store if not nil
+func NewSession(store store.LedgerStore, run RunFunc) *Session {
+	return &Session{debugger: newDebugger(), store: store, run: run}
+}
+
+//SetBreakpoint pause the execution before executing the opcode at offset of contract
+func (this *Session) SetBreakpoint(contract common.Address, offset int) {
+	this.debugger.lock.Lock()
+	defer this.debugger.lock.Unlock()
+	this.debugger.breakpoints[breakpoint{contract, offset}] = true
+}
+
+//ClearBreakpoint remove the breakpoint
+func (this *Session) ClearBreakpoint(contract common.Address, offset int) {
+	this.debugger.lock.Lock()
+	defer this.debugger.lock.Unlock()
+	delete(this.debugger.breakpoints, breakpoint{contract, offset})
+}
+
+//Continue run until a breakpoint or the end of execution
+func (this *Session) Continue() *Stop {
+	return this.step(modeContinue)
+}
+
+//StepInto run to the next opcode, entering the called contract
+func (this *Session) StepInto() *Stop {
+	return this.step(modeStepInto)
+}
+
+//StepOver run to the next opcode of current contract, without pausing in the contract called by APPCALL or CALL
+func (this *Session) StepOver() *Stop {
+	return this.step(modeStepOver)
+}
+
+//StepOut run until the current contract returns
+func (this *Session) StepOut() *Stop {
+	return this.step(modeStepOut)
+}
+
+//Abort stop the execution, which finishes with error
+func (this *Session) Abort() *Stop {
+	return this.step(modeAbort)
+}
+
+//Finished return whether the execution finishes
+func (this *Session) Finished() bool {
+	return this.last != nil && this.last.Finished
+}
+
+func (this *Session) step(mode stepMode) *Stop {
+	if this.Finished() {
+		return this.last
+	}
+	if !this.started {
+		this.started = true
+		if mode == modeAbort {
+			this.last = &Stop{Finished: true, Err: errAborted}
+			return this.last
+		}
+		this.debugger.mode = mode
+		go this.execute()
+	} else {
+		this.debugger.resume <- mode
+	}
+	this.last = <-this.debugger.stops
+	return this.last
+}
+
+func (this *Session) execute() {
+	stop := &Stop{Finished: true}
+	defer func() {
+		if r := recover(); r != nil {
+			if r != errAborted {
+				panic(r)
+			}
+			stop.Err = errAborted
+		}
+		this.debugger.frames = nil
+		this.debugger.stops <- stop
+	}()
+	stop.Result, stop.Err = this.run(this.debugger)
+}
+
+func (this *Session) paused() (*frame, error) {
+	if !this.started {
+		return nil, ErrNotStarted
+	}
+	if this.Finished() {
+		return nil, ErrNotPaused
+	}
+	f := this.debugger.current()
+	if f == nil || f.engine == nil {
+		return nil, ErrNotPaused
+	}
+	return f, nil
+}
+
+//Frames return the call stack, the innermost contract is the last
+func (this *Session) Frames() ([]*Frame, error) {
+	if _, err := this.paused(); err != nil {
+		return nil, err
+	}
+	frames := make([]*Frame, 0, len(this.debugger.frames))
+	for _, f := range this.debugger.frames {
+		frame := &Frame{Type: f.vmType, Contract: f.contract, Method: f.method, Offset: f.offset}
+		if f.engine != nil {
+			frame.Op = vm.OpCodeName(f.op)
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+//Code return the code executing in current neovm context, which may be the code of called contract
+func (this *Session) Code() ([]byte, error) {
+	f, err := this.paused()
+	if err != nil {
+		return nil, err
+	}
+	return f.engine.Context.Code, nil
+}
+
+//EvaluationStack return the items of evaluation stack in hex, the top is the first
+func (this *Session) EvaluationStack() ([]interface{}, error) {
+	f, err := this.paused()
+	if err != nil {
+		return nil, err
+	}
+	return stackItems(f.engine.EvaluationStack)
+}
+
+//AltStack return the items of alt stack in hex, the top is the first
+func (this *Session) AltStack() ([]interface{}, error) {
+	f, err := this.paused()
+	if err != nil {
+		return nil, err
+	}
+	return stackItems(f.engine.AltStack)
+}
+
+//Storage return the storage value of contract, including the writes of paused execution
+func (this *Session) Storage(contract common.Address, key []byte) ([]byte, error) {
+	if this.started && !this.Finished() {
+		if _, err := this.paused(); err != nil {
+			return nil, err
+		}
+	}
+	raw := append(contract[:], key...)
+	if value, ok := this.debugger.storage[string(raw)]; ok {
+		if value == nil || len(*value) == 0 {
+			return nil, nil
+		}
+		return states.GetValueFromRawStorageItem(*value)
+	}
+	if this.store == nil {
+		return nil, nil
+	}
+	item, err := this.store.GetStorageItem(&states.StorageKey{ContractAddress: contract, Key: key})
+	if err == scom.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+func stackItems(stack *vm.RandomAccessStack) ([]interface{}, error) {
+	items := make([]interface{}, 0, stack.Count())
+	for i := 0; i < stack.Count(); i++ {
+		item, err := scommon.ConvertNeoVmTypeHexString(stack.Peek(i))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
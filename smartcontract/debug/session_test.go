@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package debug
+
+import (
+	"testing"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/store/memorystore"
+	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/types"
+	"github.com/dnaproject2/DNA/smartcontract"
+	scommon "github.com/dnaproject2/DNA/smartcontract/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	sstate "github.com/dnaproject2/DNA/smartcontract/states"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/stretchr/testify/assert"
+)
+
+//callee: put "k" = 1 + 2, and return it
+func calleeCode() []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBytes([]byte{byte(vm.PUSH1), byte(vm.PUSH2), byte(vm.ADD), byte(vm.DUP)})
+	sink.WriteBytes([]byte{1, 'k'})
+	sink.WriteByte(byte(vm.SYSCALL))
+	sink.WriteString("System.Storage.GetContext")
+	sink.WriteByte(byte(vm.SYSCALL))
+	sink.WriteString("System.Storage.Put")
+	sink.WriteByte(byte(vm.RET))
+	return sink.Bytes()
+}
+
+//caller: push "x" to alt stack, call callee, and add 3 to the result
+func callerCode(callee common.Address) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteBytes([]byte{1, 'x', byte(vm.TOALTSTACK), byte(vm.APPCALL)})
+	sink.WriteAddress(callee)
+	sink.WriteBytes([]byte{byte(vm.PUSH3), byte(vm.ADD), byte(vm.RET)})
+	return sink.Bytes()
+}
+
+func newTestSession(t *testing.T) (*Session, common.Address, common.Address) {
+	cache := storage.NewCacheDB(overlaydb.NewOverlayDB(memorystore.NewMemoryStore()))
+	deploy := &payload.DeployCode{Code: calleeCode(), NeedStorage: true}
+	assert.Nil(t, cache.PutContract(deploy))
+	code := callerCode(deploy.Address())
+	run := func(tracer context.Tracer) (*sstate.PreExecResult, error) {
+		cache.SetTracer(tracer)
+		sc := smartcontract.SmartContract{
+			Config:  &smartcontract.Config{Time: 10, Height: 10, Tx: &types.Transaction{}},
+			CacheDB: cache,
+			Gas:     100000,
+			Tracer:  tracer,
+		}
+		engine, _ := sc.NewExecuteEngine(code)
+		result, err := engine.Invoke()
+		if err != nil {
+			return nil, err
+		}
+		cv, err := scommon.ConvertNeoVmTypeHexString(result)
+		return &sstate.PreExecResult{State: event.CONTRACT_STATE_SUCCESS, Result: cv}, err
+	}
+	return NewSession(nil, run), common.AddressFromVmCode(code), deploy.Address()
+}
+
+func TestBreakpoint(t *testing.T) {
+	session, caller, callee := newTestSession(t)
+	_, err := session.EvaluationStack()
+	assert.Equal(t, ErrNotStarted, err)
+
+	session.SetBreakpoint(callee, 2)
+	stop := session.Continue()
+	assert.False(t, stop.Finished)
+	assert.True(t, stop.Breakpoint)
+	assert.Equal(t, callee, stop.Contract)
+	assert.Equal(t, 2, stop.Offset)
+	assert.Equal(t, "ADD", stop.Op)
+	assert.Equal(t, 2, stop.Depth)
+	stack, err := session.EvaluationStack()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"02", "01"}, stack)
+	frames, err := session.Frames()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(frames))
+	assert.Equal(t, caller, frames[0].Contract)
+	assert.Equal(t, "APPCALL", frames[0].Op)
+	assert.Equal(t, callee, frames[1].Contract)
+	code, err := session.Code()
+	assert.Nil(t, err)
+	assert.Equal(t, calleeCode(), code)
+	value, err := session.Storage(callee, []byte("k"))
+	assert.Nil(t, err)
+	assert.Nil(t, value)
+
+	//return to caller
+	stop = session.StepOut()
+	assert.Equal(t, caller, stop.Contract)
+	assert.Equal(t, "PUSH3", stop.Op)
+	stack, err = session.EvaluationStack()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"03"}, stack)
+	stack, err = session.AltStack()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"78"}, stack)
+	value, err = session.Storage(callee, []byte("k"))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{3}, value)
+
+	stop = session.Continue()
+	assert.True(t, stop.Finished)
+	assert.Nil(t, stop.Err)
+	assert.Equal(t, "06", stop.Result.Result)
+	assert.Equal(t, stop, session.Continue())
+	_, err = session.EvaluationStack()
+	assert.Equal(t, ErrNotPaused, err)
+}
+
+func TestStep(t *testing.T) {
+	session, caller, callee := newTestSession(t)
+	var ops []string
+	for stop := session.StepInto(); !stop.Finished; stop = session.StepOver() {
+		assert.Equal(t, caller, stop.Contract)
+		ops = append(ops, stop.Op)
+	}
+	assert.Equal(t, []string{"PUSHBYTES1", "TOALTSTACK", "APPCALL", "PUSH3", "ADD", "RET"}, ops)
+
+	session, _, _ = newTestSession(t)
+	ops = nil
+	for stop := session.StepInto(); !stop.Finished; stop = session.StepInto() {
+		if stop.Contract == callee {
+			ops = append(ops, stop.Op)
+		}
+	}
+	assert.Equal(t, []string{"PUSH1", "PUSH2", "ADD", "DUP", "PUSHBYTES1", "SYSCALL", "SYSCALL", "RET"}, ops)
+
+	session, _, _ = newTestSession(t)
+	session.StepInto()
+	stop := session.Abort()
+	assert.True(t, stop.Finished)
+	assert.Equal(t, errAborted, stop.Err)
+}
@@ -0,0 +1,248 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+//Package profile attributes the gas consumed by smart contracts to opcodes, system calls, storage writes and
+//contracts, used to find the expensive parts of contracts
+package profile
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+)
+
+const (
+	TX_FRAME         = "Transaction"        //Frame of the gas charged by transaction, e.g. the invoke code length
+	STORAGE_PUT_NAME = "System.Storage.Put" //Syscall of storage write, same as neovm.STORAGE_PUT_NAME
+)
+
+//GasItem is the gas charged for an opcode or system call
+type GasItem struct {
+	Name  string
+	Count uint64
+	Gas   uint64
+}
+
+//StorageGas is the gas charged for writing a storage key
+type StorageGas struct {
+	Contract string
+	Key      string
+	Count    uint64
+	Gas      uint64
+}
+
+//ContractGas is the gas consumed by a contract, SelfGas excludes the gas of the contracts it calls
+type ContractGas struct {
+	Contract string
+	Calls    uint64
+	SelfGas  uint64
+	TotalGas uint64
+}
+
+//GasProfile is the gas attribution of a transaction execution
+type GasProfile struct {
+	TxHash      common.Uint256
+	Height      uint32 //Height of the block executing transaction
+	State       byte
+	GasConsumed uint64
+	Error       string
+	Opcodes     []*GasItem
+	Syscalls    []*GasItem
+	Storage     []*StorageGas
+	Contracts   []*ContractGas
+	//Folded is the gas in folded stack format "frame;frame;leaf gas", one line per stack, which is accepted by
+	//flame graph tools like flamegraph.pl. The frames are contract addresses, and the leaf is opcode or system call
+	Folded []string
+}
+
+type frame struct {
+	contract common.Address
+	stack    string
+	gas      uint64 //gas left when entering
+	total    uint64 //gas charged in frame, including the called contracts
+}
+
+//GasProfiler implements the context.Tracer, and attributes the gas charged by neovm
+type GasProfiler struct {
+	opcodes   map[string]*GasItem
+	syscalls  map[string]*GasItem
+	storage   map[string]*StorageGas
+	contracts map[common.Address]*ContractGas
+	folded    map[string]uint64
+	frames    []*frame
+	charged   uint64
+	putGas    uint64 //gas of the pending System.Storage.Put, attributed to the key written
+}
+
+var _ context.Tracer = (*GasProfiler)(nil)
+
+//NewGasProfiler return a GasProfiler instance
+func NewGasProfiler() *GasProfiler {
+	return &GasProfiler{
+		opcodes:   make(map[string]*GasItem),
+		syscalls:  make(map[string]*GasItem),
+		storage:   make(map[string]*StorageGas),
+		contracts: make(map[common.Address]*ContractGas),
+		folded:    make(map[string]uint64),
+	}
+}
+
+func (this *GasProfiler) CaptureEnter(vmType string, address common.Address, method string, input []byte, gas uint64) {
+	stack := address.ToHexString()
+	if len(this.frames) > 0 {
+		stack = this.frames[len(this.frames)-1].stack + ";" + stack
+	}
+	this.frames = append(this.frames, &frame{contract: address, stack: stack, gas: gas})
+	this.contract(address).Calls += 1
+}
+
+func (this *GasProfiler) CaptureExit(output interface{}, gas uint64, err error) {
+	if len(this.frames) == 0 {
+		return
+	}
+	current := this.frames[len(this.frames)-1]
+	this.frames = this.frames[:len(this.frames)-1]
+	//the gas of recursive call is counted once in the outermost frame
+	for _, f := range this.frames {
+		if f.contract == current.contract {
+			return
+		}
+	}
+	this.contract(current.contract).TotalGas += current.total
+}
+
+func (this *GasProfiler) CaptureOp(engine *vm.ExecutionEngine, pc int, op vm.OpCode) {
+}
+
+func (this *GasProfiler) CaptureSyscall(name string, err error) {
+	this.putGas = 0
+}
+
+func (this *GasProfiler) CaptureGas(name string, syscall bool, gas uint64) {
+	items := this.opcodes
+	if syscall {
+		items = this.syscalls
+		if name == STORAGE_PUT_NAME {
+			this.putGas = gas
+		}
+	}
+	item, ok := items[name]
+	if !ok {
+		item = &GasItem{Name: name}
+		items[name] = item
+	}
+	item.Count += 1
+	item.Gas += gas
+	this.charged += gas
+
+	stack := name
+	if len(this.frames) > 0 {
+		current := this.frames[len(this.frames)-1]
+		stack = current.stack + ";" + name
+		this.contract(current.contract).SelfGas += gas
+		for _, f := range this.frames {
+			f.total += gas
+		}
+	}
+	this.folded[stack] += gas
+}
+
+func (this *GasProfiler) CaptureStorage(op string, key, value []byte) {
+	if op != storage.STORAGE_PUT || this.putGas == 0 || len(key) < common.ADDR_LEN {
+		return
+	}
+	address, _ := common.AddressParseFromBytes(key[:common.ADDR_LEN])
+	contract := address.ToHexString()
+	k := common.ToHexString(key[common.ADDR_LEN:])
+	item, ok := this.storage[contract+k]
+	if !ok {
+		item = &StorageGas{Contract: contract, Key: k}
+		this.storage[contract+k] = item
+	}
+	item.Count += 1
+	item.Gas += this.putGas
+	this.putGas = 0
+}
+
+func (this *GasProfiler) contract(address common.Address) *ContractGas {
+	item, ok := this.contracts[address]
+	if !ok {
+		item = &ContractGas{Contract: address.ToHexString()}
+		this.contracts[address] = item
+	}
+	return item
+}
+
+//Profile return the gas profile, the gas consumed but not charged by neovm is attributed to TX_FRAME
+func (this *GasProfiler) Profile(gasConsumed uint64) *GasProfile {
+	result := &GasProfile{
+		GasConsumed: gasConsumed,
+		Opcodes:     sortItems(this.opcodes),
+		Syscalls:    sortItems(this.syscalls),
+		Storage:     make([]*StorageGas, 0, len(this.storage)),
+		Contracts:   make([]*ContractGas, 0, len(this.contracts)),
+		Folded:      make([]string, 0, len(this.folded)+1),
+	}
+	for _, item := range this.storage {
+		result.Storage = append(result.Storage, item)
+	}
+	sort.Slice(result.Storage, func(i, j int) bool {
+		a, b := result.Storage[i], result.Storage[j]
+		if a.Gas != b.Gas {
+			return a.Gas > b.Gas
+		}
+		return a.Contract+a.Key < b.Contract+b.Key
+	})
+	for _, item := range this.contracts {
+		result.Contracts = append(result.Contracts, item)
+	}
+	sort.Slice(result.Contracts, func(i, j int) bool {
+		a, b := result.Contracts[i], result.Contracts[j]
+		if a.TotalGas != b.TotalGas {
+			return a.TotalGas > b.TotalGas
+		}
+		return a.Contract < b.Contract
+	})
+	for stack, gas := range this.folded {
+		result.Folded = append(result.Folded, fmt.Sprintf("%s %d", stack, gas))
+	}
+	if gasConsumed > this.charged {
+		result.Folded = append(result.Folded, fmt.Sprintf("%s %d", TX_FRAME, gasConsumed-this.charged))
+	}
+	sort.Strings(result.Folded)
+	return result
+}
+
+func sortItems(items map[string]*GasItem) []*GasItem {
+	result := make([]*GasItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, item)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Gas != result[j].Gas {
+			return result[i].Gas > result[j].Gas
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
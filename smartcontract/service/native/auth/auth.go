@@ -30,6 +30,7 @@ import (
 	"github.com/dnaproject2/DNA/common/serialization"
 	"github.com/dnaproject2/DNA/errors"
 	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
 )
 
@@ -452,6 +453,8 @@ func delegate(native *native.NativeService, contractAddr common.Address, from []
 			if err != nil {
 				return false, fmt.Errorf("putDelegateStatus failed: %v", err)
 			}
+			pushEvent(native, []interface{}{"delegationCreated", contractAddr.ToHexString(), string(from), string(to),
+				common.ToHexString(role), level, expireTime})
 			return true, nil
 		}
 	}
@@ -530,6 +533,8 @@ func withdraw(native *native.NativeService, contractAddr common.Address, initiat
 			if err != nil {
 				return false, err
 			}
+			pushEvent(native, []interface{}{"delegationWithdrawn", contractAddr.ToHexString(), string(initiator),
+				string(delegate), common.ToHexString(role)})
 			return true, nil
 		}
 	}
@@ -645,6 +650,41 @@ func VerifyToken(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_FALSE, nil
 }
 
+//GetContractAuth return the serialized ContractAuth of contract, with the delegations active at the time of block
+func GetContractAuth(native *native.NativeService) ([]byte, error) {
+	param := new(GetContractAuthParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getContractAuth] deserialize param failed: %v", err)
+	}
+	result, err := ReadContractAuth(nativeReader(native), nativeIterator(native), param.ContractAddr,
+		native.Time)
+	if err != nil {
+		return nil, fmt.Errorf("[getContractAuth] %v", err)
+	}
+	bf := new(bytes.Buffer)
+	if err := result.Serialize(bf); err != nil {
+		return nil, fmt.Errorf("[getContractAuth] serialize result failed: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
+//GetDelegations return the serialized Delegations to DNA ID in contract which are active at the time of block
+func GetDelegations(native *native.NativeService) ([]byte, error) {
+	param := new(GetDelegationsParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getDelegations] deserialize param failed: %v", err)
+	}
+	delegations, err := ReadDelegations(nativeReader(native), param.ContractAddr, param.OntID, native.Time)
+	if err != nil {
+		return nil, fmt.Errorf("[getDelegations] %v", err)
+	}
+	bf := new(bytes.Buffer)
+	if err := (&Delegations{Delegations: delegations}).Serialize(bf); err != nil {
+		return nil, fmt.Errorf("[getDelegations] serialize result failed: %v", err)
+	}
+	return bf.Bytes(), nil
+}
+
 func verifySig(native *native.NativeService, ontID []byte, keyNo uint64) (bool, error) {
 	bf := new(bytes.Buffer)
 	if err := serialization.WriteVarBytes(bf, ontID); err != nil {
@@ -677,4 +717,10 @@ func RegisterAuthContract(native *native.NativeService) {
 	native.Register("assignDnaIDsToRole", AssignDnaIDsToRole)
 	native.Register("verifyToken", VerifyToken)
 	native.Register("transfer", Transfer)
+	if registry.Enabled(native.Height) {
+		native.Register("upgradeContract", UpgradeContract)
+		native.Register("claimDeployer", ClaimDeployer)
+	}
+	native.Register("getContractAuth", GetContractAuth)
+	native.Register("getDelegations", GetDelegations)
 }
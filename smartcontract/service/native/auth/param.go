@@ -358,3 +358,143 @@ func (this *VerifyTokenParam) Deserialize(rd io.Reader) error {
 	}
 	return nil
 }
+
+/* **********************************************   */
+type UpgradeContractParam struct {
+	ContractAddr  common.Address
+	Code          []byte
+	NeedStorage   bool
+	Name          string
+	Version       string
+	Author        string
+	Email         string
+	Description   string
+	MigrateMethod string //method of new code called after upgrade, with the previous version as argument, empty to skip
+	KeyNo         uint64
+}
+
+func (this *UpgradeContractParam) Serialize(w io.Writer) error {
+	if err := serializeAddress(w, this.ContractAddr); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Code); err != nil {
+		return err
+	}
+	//bool is pushed as integer by neovm
+	needStorage := uint64(0)
+	if this.NeedStorage {
+		needStorage = 1
+	}
+	if err := utils.WriteVarUint(w, needStorage); err != nil {
+		return err
+	}
+	for _, s := range []string{this.Name, this.Version, this.Author, this.Email, this.Description, this.MigrateMethod} {
+		if err := serialization.WriteString(w, s); err != nil {
+			return err
+		}
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *UpgradeContractParam) Deserialize(rd io.Reader) error {
+	var err error
+	if this.ContractAddr, err = utils.ReadAddress(rd); err != nil {
+		return err
+	}
+	if this.Code, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	needStorage, err := utils.ReadVarUint(rd)
+	if err != nil {
+		return err
+	}
+	this.NeedStorage = needStorage != 0
+	for _, s := range []*string{&this.Name, &this.Version, &this.Author, &this.Email, &this.Description, &this.MigrateMethod} {
+		if *s, err = serialization.ReadString(rd); err != nil {
+			return err
+		}
+	}
+	if this.KeyNo, err = utils.ReadVarUint(rd); err != nil {
+		return err
+	}
+	return nil
+}
+
+/* **********************************************   */
+type ClaimDeployerParam struct {
+	ContractAddr common.Address
+	Deployer     common.Address
+	KeyNo        uint64
+}
+
+func (this *ClaimDeployerParam) Serialize(w io.Writer) error {
+	if err := serializeAddress(w, this.ContractAddr); err != nil {
+		return err
+	}
+	if err := serializeAddress(w, this.Deployer); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *ClaimDeployerParam) Deserialize(rd io.Reader) error {
+	var err error
+	if this.ContractAddr, err = utils.ReadAddress(rd); err != nil {
+		return err
+	}
+	if this.Deployer, err = utils.ReadAddress(rd); err != nil {
+		return err
+	}
+	if this.KeyNo, err = utils.ReadVarUint(rd); err != nil {
+		return err
+	}
+	return nil
+}
+
+/* **********************************************   */
+type GetContractAuthParam struct {
+	ContractAddr common.Address
+}
+
+func (this *GetContractAuthParam) Serialize(w io.Writer) error {
+	return serializeAddress(w, this.ContractAddr)
+}
+
+func (this *GetContractAuthParam) Deserialize(rd io.Reader) error {
+	var err error
+	this.ContractAddr, err = utils.ReadAddress(rd)
+	return err
+}
+
+/* **********************************************   */
+type GetDelegationsParam struct {
+	ContractAddr common.Address
+	OntID        []byte
+}
+
+func (this *GetDelegationsParam) Serialize(w io.Writer) error {
+	if err := serializeAddress(w, this.ContractAddr); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.OntID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *GetDelegationsParam) Deserialize(rd io.Reader) error {
+	var err error
+	if this.ContractAddr, err = utils.ReadAddress(rd); err != nil {
+		return err
+	}
+	if this.OntID, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	return nil
+}
@@ -186,3 +186,27 @@ func TestSerialization_VerifyToken(t *testing.T) {
 	}
 	assert.Equal(t, param, param2)
 }
+
+func TestSerialization_UpgradeContract(t *testing.T) {
+	param := &UpgradeContractParam{
+		ContractAddr:  OntContractAddr,
+		Code:          p1,
+		NeedStorage:   true,
+		Name:          "name",
+		Version:       "2.0",
+		Description:   "desc",
+		MigrateMethod: "migrate",
+		KeyNo:         1,
+	}
+	bf := new(bytes.Buffer)
+	if err := param.Serialize(bf); err != nil {
+		t.Fatal(err)
+	}
+	rd := bytes.NewReader(bf.Bytes())
+
+	param2 := new(UpgradeContractParam)
+	if err := param2.Deserialize(rd); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, param, param2)
+}
@@ -0,0 +1,306 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//StorageReader read the value of storage item by the full storage key, it returns nil if the item not exists
+type StorageReader func(key []byte) ([]byte, error)
+
+//StorageIterator call fn with the full storage key and value of each item whose key starts with prefix, in key order
+type StorageIterator func(prefix []byte, fn func(key, value []byte) error) error
+
+func GenAdminKey(contractAddr common.Address) []byte {
+	return utils.ConcatKey(utils.AuthContractAddress, contractAddr[:], PreAdmin)
+}
+
+func GenRoleFuncKey(contractAddr common.Address, role []byte) []byte {
+	return utils.ConcatKey(utils.AuthContractAddress, contractAddr[:], PreRoleFunc, role)
+}
+
+func GenOntIDTokenKey(contractAddr common.Address, ontID []byte) []byte {
+	return utils.ConcatKey(utils.AuthContractAddress, contractAddr[:], PreRoleToken, ontID)
+}
+
+func GenDelegateStatusKey(contractAddr common.Address, ontID []byte) []byte {
+	return utils.ConcatKey(utils.AuthContractAddress, contractAddr[:], PreDelegateStatus, ontID)
+}
+
+//RoleInfo is the functions of role and the DNA IDs holding the role permanently
+type RoleInfo struct {
+	Role    []byte
+	Funcs   []string
+	Holders [][]byte
+}
+
+func (this *RoleInfo) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Role); err != nil {
+		return err
+	}
+	if err := (&roleFuncs{funcNames: this.Funcs}).Serialize(w); err != nil {
+		return err
+	}
+	return (&byteList{items: this.Holders}).Serialize(w)
+}
+
+func (this *RoleInfo) Deserialize(rd io.Reader) error {
+	var err error
+	if this.Role, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	funcs := new(roleFuncs)
+	if err := funcs.Deserialize(rd); err != nil {
+		return err
+	}
+	holders := new(byteList)
+	if err := holders.Deserialize(rd); err != nil {
+		return err
+	}
+	this.Funcs = funcs.funcNames
+	this.Holders = holders.items
+	return nil
+}
+
+//DelegationInfo is the role delegated to DNA ID by Root, which is active until ExpireTime
+type DelegationInfo struct {
+	OntID      []byte
+	Root       []byte
+	Role       []byte
+	Level      uint8
+	ExpireTime uint32
+}
+
+func (this *DelegationInfo) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.OntID); err != nil {
+		return err
+	}
+	status := &DelegateStatus{root: this.Root}
+	status.role = this.Role
+	status.level = this.Level
+	status.expireTime = this.ExpireTime
+	return status.Serialize(w)
+}
+
+func (this *DelegationInfo) Deserialize(rd io.Reader) error {
+	var err error
+	if this.OntID, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	status := new(DelegateStatus)
+	if err := status.Deserialize(rd); err != nil {
+		return err
+	}
+	this.Root = status.root
+	this.Role = status.role
+	this.Level = status.level
+	this.ExpireTime = status.expireTime
+	return nil
+}
+
+//Delegations is the active delegations sorted by expire time, so the upcoming expiries come first
+type Delegations struct {
+	Delegations []*DelegationInfo
+}
+
+func (this *Delegations) Serialize(w io.Writer) error {
+	if err := serialization.WriteUint32(w, uint32(len(this.Delegations))); err != nil {
+		return err
+	}
+	for _, d := range this.Delegations {
+		if err := d.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *Delegations) Deserialize(rd io.Reader) error {
+	n, err := serialization.ReadUint32(rd)
+	if err != nil {
+		return err
+	}
+	this.Delegations = make([]*DelegationInfo, 0)
+	for i := uint32(0); i < n; i++ {
+		d := new(DelegationInfo)
+		if err := d.Deserialize(rd); err != nil {
+			return err
+		}
+		this.Delegations = append(this.Delegations, d)
+	}
+	return nil
+}
+
+//ContractAuth is the admin, roles and active delegations of contract in auth contract
+type ContractAuth struct {
+	Admin []byte
+	Roles []*RoleInfo
+	Delegations
+}
+
+func (this *ContractAuth) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Admin); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, uint32(len(this.Roles))); err != nil {
+		return err
+	}
+	for _, role := range this.Roles {
+		if err := role.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return this.Delegations.Serialize(w)
+}
+
+func (this *ContractAuth) Deserialize(rd io.Reader) error {
+	var err error
+	if this.Admin, err = serialization.ReadVarBytes(rd); err != nil {
+		return err
+	}
+	n, err := serialization.ReadUint32(rd)
+	if err != nil {
+		return err
+	}
+	this.Roles = make([]*RoleInfo, 0)
+	for i := uint32(0); i < n; i++ {
+		role := new(RoleInfo)
+		if err := role.Deserialize(rd); err != nil {
+			return err
+		}
+		this.Roles = append(this.Roles, role)
+	}
+	return this.Delegations.Deserialize(rd)
+}
+
+func readState(read StorageReader, key []byte, state interface{ Deserialize(io.Reader) error }) (bool, error) {
+	value, err := read(key)
+	if err != nil || value == nil {
+		return false, err
+	}
+	if err := state.Deserialize(bytes.NewReader(value)); err != nil {
+		return false, fmt.Errorf("deserialize %T failed. data: %x", state, value)
+	}
+	return true, nil
+}
+
+//ReadDelegations return the delegations to DNA ID in contract which are active at time now
+func ReadDelegations(read StorageReader, contractAddr common.Address, ontID []byte, now uint32) ([]*DelegationInfo, error) {
+	status := new(Status)
+	if _, err := readState(read, GenDelegateStatusKey(contractAddr, ontID), status); err != nil {
+		return nil, err
+	}
+	delegations := make([]*DelegationInfo, 0)
+	for _, s := range status.status {
+		if now < s.expireTime {
+			delegations = append(delegations, &DelegationInfo{
+				OntID:      ontID,
+				Root:       s.root,
+				Role:       s.role,
+				Level:      s.level,
+				ExpireTime: s.expireTime,
+			})
+		}
+	}
+	return delegations, nil
+}
+
+//ReadContractAuth return the admin, roles with functions and holders, and the delegations active at time now of
+//contract. Roles, holders and delegations are enumerated from the storage of auth contract, so they are listed
+//whenever they were assigned
+func ReadContractAuth(read StorageReader, iterate StorageIterator, contractAddr common.Address,
+	now uint32) (*ContractAuth, error) {
+	admin, err := read(GenAdminKey(contractAddr))
+	if err != nil {
+		return nil, err
+	}
+	result := &ContractAuth{Admin: admin, Roles: make([]*RoleInfo, 0)}
+	roles := make(map[string]*RoleInfo)
+	getRole := func(role []byte) (*RoleInfo, error) {
+		if info, ok := roles[string(role)]; ok {
+			return info, nil
+		}
+		info := &RoleInfo{Role: role, Funcs: make([]string, 0), Holders: make([][]byte, 0)}
+		funcs := new(roleFuncs)
+		if _, err := readState(read, GenRoleFuncKey(contractAddr, role), funcs); err != nil {
+			return nil, err
+		}
+		info.Funcs = append(info.Funcs, funcs.funcNames...)
+		sort.Strings(info.Funcs)
+		roles[string(role)] = info
+		result.Roles = append(result.Roles, info)
+		return info, nil
+	}
+	prefix := GenRoleFuncKey(contractAddr, nil)
+	err = iterate(prefix, func(key, value []byte) error {
+		_, err := getRole(key[len(prefix):])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	prefix = GenOntIDTokenKey(contractAddr, nil)
+	err = iterate(prefix, func(key, value []byte) error {
+		tokens := new(roleTokens)
+		if err := tokens.Deserialize(bytes.NewReader(value)); err != nil {
+			return fmt.Errorf("deserialize roleTokens failed. data: %x", value)
+		}
+		for _, token := range tokens.tokens {
+			info, err := getRole(token.role)
+			if err != nil {
+				return err
+			}
+			info.Holders = append(info.Holders, key[len(prefix):])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Delegations.Delegations = make([]*DelegationInfo, 0)
+	prefix = GenDelegateStatusKey(contractAddr, nil)
+	err = iterate(prefix, func(key, value []byte) error {
+		delegations, err := ReadDelegations(read, contractAddr, key[len(prefix):], now)
+		if err != nil {
+			return err
+		}
+		for _, d := range delegations {
+			if _, err := getRole(d.Role); err != nil {
+				return err
+			}
+		}
+		result.Delegations.Delegations = append(result.Delegations.Delegations, delegations...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result.Delegations.Delegations, func(i, j int) bool {
+		return result.Delegations.Delegations[i].ExpireTime < result.Delegations.Delegations[j].ExpireTime
+	})
+	return result, nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+//authEvents return the states of events in auth contract
+func authEvents(notify []*event.NotifyEventInfo) [][]interface{} {
+	states := make([][]interface{}, 0)
+	for _, n := range notify {
+		if n.ContractAddress == utils.AuthContractAddress {
+			states = append(states, n.States.([]interface{}))
+		}
+	}
+	return states
+}
+
+func TestContractAuthQuery(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	invoke := func(method string, param interface{}, signers ...*account.Account) ([]byte, [][]interface{}) {
+		result, notify, err := suite.InvokeNative(utils.AuthContractAddress, method, []interface{}{param}, signers...)
+		assert.Nil(t, err)
+		return result, authEvents(notify)
+	}
+
+	accounts := make([]*account.Account, 3)
+	ids := make([][]byte, 3)
+	for i := range accounts {
+		accounts[i] = account.NewAccount("")
+		ids[i] = regID(t, suite, accounts[i])
+	}
+	admin, holder, delegatee := ids[0], ids[1], ids[2]
+	contract := deployAdminContract(t, suite, admin)
+
+	role := []byte("operator")
+	invoke("assignFuncsToRole", &FuncsToRoleParam{ContractAddr: contract, AdminOntID: admin, Role: role,
+		FuncNames: []string{"pause", "mint"}, KeyNo: 1}, accounts[0])
+	invoke("assignDnaIDsToRole", &OntIDsToRoleParam{ContractAddr: contract, AdminOntID: admin, Role: role,
+		Persons: [][]byte{holder}, KeyNo: 1}, accounts[0])
+
+	period := uint32(3600)
+	suite.Time = 1000
+	expireTime := suite.Time + period
+	_, events := invoke("delegate", &DelegateParam{ContractAddr: contract, From: holder, To: delegatee, Role: role,
+		Period: uint64(period), Level: 1, KeyNo: 1}, accounts[1])
+	assert.Equal(t, []interface{}{"delegationCreated", contract.ToHexString(), string(holder), string(delegatee),
+		common.ToHexString(role), uint8(1), expireTime}, events[0])
+
+	//query contract auth at time now
+	queryAuth := func(now uint32) *ContractAuth {
+		suite.Time = now
+		data, _ := invoke("getContractAuth", &GetContractAuthParam{ContractAddr: contract})
+		result := new(ContractAuth)
+		assert.Nil(t, result.Deserialize(bytes.NewReader(data)))
+		return result
+	}
+	result := queryAuth(1000)
+	assert.Equal(t, admin, result.Admin)
+	assert.Equal(t, []*RoleInfo{{Role: role, Funcs: []string{"mint", "pause"}, Holders: [][]byte{holder}}},
+		result.Roles)
+	assert.Equal(t, []*DelegationInfo{{OntID: delegatee, Root: holder, Role: role, Level: 1,
+		ExpireTime: expireTime}}, result.Delegations.Delegations)
+
+	//the delegation is not listed after it expires
+	expired := queryAuth(expireTime)
+	assert.Equal(t, 0, len(expired.Delegations.Delegations))
+
+	suite.Time = 1000
+	_, events = invoke("withdraw", &WithdrawParam{ContractAddr: contract, Initiator: holder, Delegate: delegatee,
+		Role: role, KeyNo: 1}, accounts[1])
+	assert.Equal(t, []interface{}{"delegationWithdrawn", contract.ToHexString(), string(holder), string(delegatee),
+		common.ToHexString(role)}, events[0])
+
+	data, _ := invoke("getDelegations", &GetDelegationsParam{ContractAddr: contract, OntID: delegatee})
+	delegations := new(Delegations)
+	assert.Nil(t, delegations.Deserialize(bytes.NewReader(data)))
+	assert.Equal(t, 0, len(delegations.Delegations))
+}
@@ -187,3 +187,38 @@ func (this *roleTokens) Deserialize(rd io.Reader) error {
 	}
 	return nil
 }
+
+/*
+ * list of roles or DNA IDs of contract, for enumeration
+ */
+type byteList struct {
+	items [][]byte
+}
+
+func (this *byteList) Serialize(w io.Writer) error {
+	if err := serialization.WriteUint32(w, uint32(len(this.items))); err != nil {
+		return err
+	}
+	for _, item := range this.items {
+		if err := serialization.WriteVarBytes(w, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *byteList) Deserialize(rd io.Reader) error {
+	n, err := serialization.ReadUint32(rd)
+	if err != nil {
+		return err
+	}
+	this.items = make([][]byte, 0)
+	for i := uint32(0); i < n; i++ {
+		item, err := serialization.ReadVarBytes(rd)
+		if err != nil {
+			return err
+		}
+		this.items = append(this.items, item)
+	}
+	return nil
+}
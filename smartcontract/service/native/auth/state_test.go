@@ -20,6 +20,7 @@ package auth
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 )
 
@@ -98,3 +99,28 @@ func TestSerDelegateStatus(t *testing.T) {
 		t.Fatalf("failed")
 	}
 }
+
+func TestSerContractAuth(t *testing.T) {
+	param := &ContractAuth{
+		Admin: []byte("did:dna:admin"),
+		Roles: []*RoleInfo{
+			{Role: []byte("role1"), Funcs: []string{"foo1", "foo2"}, Holders: [][]byte{[]byte("did:dna:1")}},
+			{Role: []byte("role2"), Funcs: []string{}, Holders: [][]byte{}},
+		},
+		Delegations: Delegations{[]*DelegationInfo{
+			{OntID: []byte("did:dna:2"), Root: []byte("did:dna:1"), Role: []byte("role1"), Level: 1, ExpireTime: 1000000},
+		}},
+	}
+	bf := new(bytes.Buffer)
+	if err := param.Serialize(bf); err != nil {
+		t.Fatal(err)
+	}
+	rd := bytes.NewReader(bf.Bytes())
+	param2 := new(ContractAuth)
+	if err := param2.Deserialize(rd); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(param, param2) {
+		t.Fatalf("%+v \t %+v does not match", param, param2)
+	}
+}
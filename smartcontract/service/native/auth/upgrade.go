@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/service/neovm"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+)
+
+const (
+	MAX_UPGRADE_CODE_SIZE  = 1024 * 1024
+	MAX_UPGRADE_FIELD_SIZE = 252
+	MAX_UPGRADE_DESC_SIZE  = 65536
+)
+
+/*
+ * contract upgrade
+ */
+//checkUpgradeAuth check the upgrade is authorized by the admin of contract, contracts without admin can not be
+//upgraded
+func checkUpgradeAuth(native *native.NativeService, contractAddr common.Address, keyNo uint64) error {
+	admin, err := getContractAdmin(native, contractAddr)
+	if err != nil {
+		return fmt.Errorf("getContractAdmin failed: %v", err)
+	}
+	if admin == nil {
+		return fmt.Errorf("admin of contract %s is not set", contractAddr.ToHexString())
+	}
+	ret, err := verifySig(native, admin, keyNo)
+	if err != nil {
+		return fmt.Errorf("verifySig failed: %v", err)
+	}
+	if !ret {
+		return fmt.Errorf("verify admin's signature failed: admin=%s, keyNo=%d", string(admin), keyNo)
+	}
+	return nil
+}
+
+func checkUpgradeParam(param *UpgradeContractParam) error {
+	if len(param.Code) == 0 || len(param.Code) > MAX_UPGRADE_CODE_SIZE {
+		return fmt.Errorf("invalid code size: %d", len(param.Code))
+	}
+	for _, field := range []string{param.Name, param.Version, param.Author, param.Email, param.MigrateMethod} {
+		if len(field) > MAX_UPGRADE_FIELD_SIZE {
+			return fmt.Errorf("field %q too long", field)
+		}
+	}
+	if len(param.Description) > MAX_UPGRADE_DESC_SIZE {
+		return fmt.Errorf("description too long")
+	}
+	return nil
+}
+
+//chargeUpgradeGas charge the gas of storing code as deploying contract
+func chargeUpgradeGas(native *native.NativeService, codeLen int) error {
+	createGas, ok := neovm.GAS_TABLE.Load(neovm.CONTRACT_CREATE_NAME)
+	if !ok {
+		return fmt.Errorf("get %s gas failed", neovm.CONTRACT_CREATE_NAME)
+	}
+	unitGas, ok := neovm.GAS_TABLE.Load(neovm.UINT_DEPLOY_CODE_LEN_NAME)
+	if !ok {
+		return fmt.Errorf("get %s gas failed", neovm.UINT_DEPLOY_CODE_LEN_NAME)
+	}
+	gas := createGas.(uint64) + uint64(codeLen/neovm.PER_UNIT_CODE_LEN)*unitGas.(uint64)
+	if !native.ContextRef.CheckUseGas(gas) {
+		return neovm.ERR_GAS_INSUFFICIENT
+	}
+	return nil
+}
+
+//callMigrate invoke the migrate method of upgraded contract with the previous version as argument
+func callMigrate(native *native.NativeService, contractAddr common.Address, method, prevVersion string) error {
+	builder := vm.NewParamsBuilder(new(bytes.Buffer))
+	builder.EmitPushByteArray([]byte(prevVersion))
+	builder.EmitPushInteger(big.NewInt(1))
+	builder.Emit(vm.PACK)
+	builder.EmitPushByteArray([]byte(method))
+	builder.EmitPushCall(contractAddr[:])
+	engine, err := native.ContextRef.NewExecuteEngine(builder.ToArray())
+	if err != nil {
+		return err
+	}
+	_, err = engine.Invoke()
+	return err
+}
+
+//UpgradeContract replace the code of contract in place, keeping the address and storage of contract, and record
+//the new version in the version history of registry contract
+func UpgradeContract(native *native.NativeService) ([]byte, error) {
+	param := new(UpgradeContractParam)
+	rd := bytes.NewReader(native.Input)
+	if err := param.Deserialize(rd); err != nil {
+		return nil, fmt.Errorf("[upgradeContract] deserialize param failed: %v", err)
+	}
+	if err := checkUpgradeParam(param); err != nil {
+		return nil, fmt.Errorf("[upgradeContract] invalid param: %v", err)
+	}
+	old, err := native.CacheDB.GetContract(param.ContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("[upgradeContract] get contract failed: %v", err)
+	}
+	if old == nil {
+		return nil, fmt.Errorf("[upgradeContract] contract %s not exist", param.ContractAddr.ToHexString())
+	}
+	if err := checkUpgradeAuth(native, param.ContractAddr, param.KeyNo); err != nil {
+		return nil, fmt.Errorf("[upgradeContract] authentication failed: %v", err)
+	}
+	if err := chargeUpgradeGas(native, len(param.Code)); err != nil {
+		return nil, fmt.Errorf("[upgradeContract] charge gas failed: %v", err)
+	}
+
+	contract := &payload.DeployCode{
+		Code:        param.Code,
+		NeedStorage: param.NeedStorage,
+		Name:        param.Name,
+		Version:     param.Version,
+		Author:      param.Author,
+		Email:       param.Email,
+		Description: param.Description,
+	}
+	if err := native.CacheDB.PutContractAt(param.ContractAddr, contract); err != nil {
+		return nil, fmt.Errorf("[upgradeContract] put contract failed: %v", err)
+	}
+	version := registry.NewContractVersion(contract, native.Height, native.Tx.Hash())
+	if err := registry.AddVersion(native.CacheDB, param.ContractAddr, version); err != nil {
+		return nil, fmt.Errorf("[upgradeContract] add version failed: %v", err)
+	}
+	if param.MigrateMethod != "" {
+		if err := callMigrate(native, param.ContractAddr, param.MigrateMethod, old.Version); err != nil {
+			return nil, fmt.Errorf("[upgradeContract] call %s failed: %v", param.MigrateMethod, err)
+		}
+	}
+
+	msg := []interface{}{"upgradeContract", param.ContractAddr.ToHexString(), old.Version, param.Version,
+		version.CodeHash.ToHexString()}
+	pushEvent(native, msg)
+	return utils.BYTE_TRUE, nil
+}
+
+//ClaimDeployer record the deployer of a contract deployed before the registry contract was enabled, so that the
+//deployer can publish its abi. It is authorized by both the admin of contract and the deployer
+func ClaimDeployer(native *native.NativeService) ([]byte, error) {
+	param := new(ClaimDeployerParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[claimDeployer] deserialize param failed: %v", err)
+	}
+	contract, err := native.CacheDB.GetContract(param.ContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("[claimDeployer] get contract failed: %v", err)
+	}
+	if contract == nil {
+		return nil, fmt.Errorf("[claimDeployer] contract %s not exist", param.ContractAddr.ToHexString())
+	}
+	admin, err := getContractAdmin(native, param.ContractAddr)
+	if err != nil {
+		return nil, fmt.Errorf("[claimDeployer] getContractAdmin failed: %v", err)
+	}
+	if admin == nil {
+		return nil, fmt.Errorf("[claimDeployer] admin of contract %s is not set", param.ContractAddr.ToHexString())
+	}
+	ret, err := verifySig(native, admin, param.KeyNo)
+	if err != nil {
+		return nil, fmt.Errorf("[claimDeployer] verifySig failed: %v", err)
+	}
+	if !ret {
+		return nil, fmt.Errorf("[claimDeployer] verify admin's signature failed: admin=%s, keyNo=%d",
+			string(admin), param.KeyNo)
+	}
+	if !native.ContextRef.CheckWitness(param.Deployer) {
+		return nil, fmt.Errorf("[claimDeployer] authentication failed, deployer:%s", param.Deployer.ToBase58())
+	}
+	if err := registry.ClaimDeployer(native.CacheDB, param.ContractAddr, param.Deployer); err != nil {
+		return nil, fmt.Errorf("[claimDeployer] %v", err)
+	}
+
+	msg := []interface{}{"claimDeployer", param.ContractAddr.ToHexString(), param.Deployer.ToBase58()}
+	pushEvent(native, msg)
+	return utils.BYTE_TRUE, nil
+}
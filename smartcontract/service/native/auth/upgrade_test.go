@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package auth
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/core/payload"
+	cutils "github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ontid"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	ontid.Init()
+	Init()
+	registry.InitRegistry()
+}
+
+//doubleCounterCode increase the counter of the key in arguments by two
+func doubleCounterCode() []byte {
+	sink := common.NewZeroCopySink(nil)
+	syscall := func(name string) {
+		sink.WriteByte(byte(neovm.SYSCALL))
+		sink.WriteVarBytes([]byte(name))
+	}
+	sink.WriteByte(byte(neovm.DUP))
+	syscall("System.Storage.GetContext")
+	syscall("System.Storage.Get")
+	sink.WriteByte(byte(neovm.INC))
+	sink.WriteByte(byte(neovm.INC))
+	sink.WriteByte(byte(neovm.SWAP))
+	syscall("System.Storage.GetContext")
+	syscall("System.Storage.Put")
+	sink.WriteByte(byte(neovm.RET))
+	return sink.Bytes()
+}
+
+//regID register a new ONT ID of acc
+func regID(t *testing.T, suite *testsuite.NativeSuite, acc *account.Account) []byte {
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	_, _, err = suite.InvokeNative(utils.OntIDContractAddress, "regIDWithPublicKey", []interface{}{&struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(id), keypair.SerializePublicKey(acc.PublicKey)}}, acc)
+	assert.Nil(t, err)
+	return []byte(id)
+}
+
+//deployAdminContract deploy and invoke a contract, which sets its admin by calling auth contract
+func deployAdminContract(t *testing.T, suite *testsuite.NativeSuite, admin []byte) common.Address {
+	initCode, err := cutils.BuildNativeInvokeCode(utils.AuthContractAddress, 0, "initContractAdmin",
+		[]interface{}{&InitContractAdminParam{AdminOntID: admin}})
+	assert.Nil(t, err)
+	deploy := &payload.DeployCode{Code: initCode, Name: "authed"}
+	assert.Nil(t, suite.Deploy(deploy))
+	contract := deploy.Address()
+	builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+	builder.EmitPushCall(contract[:])
+	_, _, err = suite.Invoke(builder.ToArray())
+	assert.Nil(t, err)
+	return contract
+}
+
+func TestClaimDeployer(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	deployer := account.NewAccount("")
+	other := account.NewAccount("")
+	id := regID(t, suite, deployer)
+	contract := deployAdminContract(t, suite, id)
+
+	claim := func(param *ClaimDeployerParam, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(utils.AuthContractAddress, "claimDeployer", []interface{}{param}, signer)
+		return err
+	}
+	//only the admin of contract records the deployer, and only once
+	assert.NotNil(t, claim(&ClaimDeployerParam{ContractAddr: contract, Deployer: other.Address, KeyNo: 1}, other))
+	assert.Nil(t, claim(&ClaimDeployerParam{ContractAddr: contract, Deployer: deployer.Address, KeyNo: 1}, deployer))
+	assert.NotNil(t, claim(&ClaimDeployerParam{ContractAddr: contract, Deployer: other.Address, KeyNo: 1}, deployer))
+
+	value, err := suite.Get(registry.DeployerKey(contract))
+	assert.Nil(t, err)
+	assert.Equal(t, deployer.Address[:], value)
+}
+
+func assertCounter(t *testing.T, suite *testsuite.NativeSuite, contract common.Address, key string, value int64) {
+	item, err := suite.Get(append(contract[:], key...))
+	assert.Nil(t, err)
+	assert.Equal(t, common.BigIntToNeoBytes(big.NewInt(value)), item)
+}
+
+func TestUpgradeContract(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	owner := account.NewAccount("")
+	other := account.NewAccount("")
+	contract := deployAdminContract(t, suite, regID(t, suite, owner))
+
+	upgrade := func(param *UpgradeContractParam, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(utils.AuthContractAddress, "upgradeContract", []interface{}{param}, signer)
+		return err
+	}
+	increase := func(key string) {
+		builder := neovm.NewParamsBuilder(new(bytes.Buffer))
+		builder.EmitPushByteArray([]byte(key))
+		builder.EmitPushCall(contract[:])
+		_, _, err := suite.Invoke(builder.ToArray())
+		assert.Nil(t, err)
+	}
+
+	//only the contract admin can upgrade
+	newCode := doubleCounterCode()
+	param := &UpgradeContractParam{
+		ContractAddr:  contract,
+		Code:          newCode,
+		NeedStorage:   true,
+		Name:          "counter",
+		Version:       "2.0",
+		MigrateMethod: "migrate",
+		KeyNo:         1,
+	}
+	suite.Height = 2
+	assert.NotNil(t, upgrade(param, other))
+	assert.Nil(t, upgrade(param, owner))
+
+	//the new code runs at the same address with the storage kept, and the migrate method was called
+	var dep *payload.DeployCode
+	assert.Nil(t, suite.Update(func(cache *storage.CacheDB) (err error) {
+		dep, err = cache.GetContract(contract)
+		return
+	}))
+	assert.Equal(t, newCode, dep.Code)
+	assertCounter(t, suite, contract, "migrate", 2)
+	increase("a")
+	assertCounter(t, suite, contract, "a", 2)
+
+	raw, err := suite.Get(registry.VersionsKey(contract))
+	assert.Nil(t, err)
+	versions, err := registry.DecodeVersions(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(versions.Versions))
+	assert.Equal(t, "2.0", versions.Versions[0].Version)
+	assert.Equal(t, common.AddressFromVmCode(newCode), versions.Versions[0].CodeHash)
+	assert.Equal(t, uint32(2), versions.Versions[0].Height)
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/serialization"
+	cstates "github.com/dnaproject2/DNA/core/states"
 	"github.com/dnaproject2/DNA/smartcontract/event"
 	"github.com/dnaproject2/DNA/smartcontract/service/native"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
@@ -181,6 +182,37 @@ func putDelegateStatus(native *native.NativeService, contractAddr common.Address
 	return nil
 }
 
+//nativeReader read the storage in the cache of contract execution
+func nativeReader(native *native.NativeService) StorageReader {
+	return func(key []byte) ([]byte, error) {
+		item, err := utils.GetStorageItem(native, key)
+		if err != nil || item == nil {
+			return nil, err
+		}
+		return item.Value, nil
+	}
+}
+
+//nativeIterator iterate the storage in the cache of contract execution
+func nativeIterator(native *native.NativeService) StorageIterator {
+	return func(prefix []byte, fn func(key, value []byte) error) error {
+		iter := native.CacheDB.NewIterator(prefix)
+		defer iter.Release()
+		for has := iter.First(); has; has = iter.Next() {
+			value, err := cstates.GetValueFromRawStorageItem(iter.Value())
+			if err != nil {
+				return fmt.Errorf("get value of %x failed: %v", iter.Key(), err)
+			}
+			//key of iterator is reused, but it may be kept by fn
+			key := append([]byte{}, iter.Key()...)
+			if err := fn(key, value); err != nil {
+				return err
+			}
+		}
+		return iter.Error()
+	}
+}
+
 //remote duplicates in the slice of string
 func stringSliceUniq(s []string) []string {
 	smap := make(map[string]int)
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claimrecord
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	//function name
+	COMMIT     = "commit"
+	REVOKE     = "revoke"
+	GET_STATUS = "getStatus"
+
+	//event name
+	EVENT_COMMIT = "commit"
+	EVENT_REVOKE = "revoke"
+
+	//limits
+	MAX_CLAIM_ID_SIZE = 256
+)
+
+func InitClaimRecord() {
+	native.Contracts[utils.ClaimRecordContractAddress] = RegisterClaimRecordContract
+}
+
+func RegisterClaimRecordContract(native *native.NativeService) {
+	if !Enabled(native.Height) {
+		return
+	}
+	native.Register(COMMIT, Commit)
+	native.Register(REVOKE, Revoke)
+	native.Register(GET_STATUS, GetStatus)
+}
+
+//Commit anchor the status of a credential issued by the issuer ID, the signature of issuer or its controller is
+//verified by ontid contract. Claim id is unique within the claims of issuer
+func Commit(native *native.NativeService) ([]byte, error) {
+	param := new(CommitParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[commit] deserialize param failed: %v", err)
+	}
+	if len(param.ClaimId) == 0 || len(param.ClaimId) > MAX_CLAIM_ID_SIZE {
+		return nil, fmt.Errorf("[commit] invalid claim id size: %d", len(param.ClaimId))
+	}
+	if !account.VerifyID(string(param.Subject)) {
+		return nil, fmt.Errorf("[commit] invalid subject id: %s", param.Subject)
+	}
+	record, err := getClaimRecord(native.CacheDB, param.Issuer, param.ClaimId)
+	if err != nil {
+		return nil, fmt.Errorf("[commit] %v", err)
+	}
+	if record != nil {
+		return nil, fmt.Errorf("[commit] claim %s already committed", param.ClaimId)
+	}
+	if err := verifyIssuer(native, param.Issuer, param.KeyNo); err != nil {
+		return nil, fmt.Errorf("[commit] %v", err)
+	}
+
+	record = &ClaimRecord{
+		Issuer:       param.Issuer,
+		Subject:      param.Subject,
+		Status:       STATUS_COMMITTED,
+		CommitHeight: native.Height,
+	}
+	putClaimRecord(native.CacheDB, param.Issuer, param.ClaimId, record)
+
+	pushEvent(native, []interface{}{EVENT_COMMIT, string(param.ClaimId), string(param.Issuer), string(param.Subject)})
+	return utils.BYTE_TRUE, nil
+}
+
+//Revoke revoke a committed credential, only the issuer can revoke
+func Revoke(native *native.NativeService) ([]byte, error) {
+	param := new(RevokeParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[revoke] deserialize param failed: %v", err)
+	}
+	record, err := getClaimRecord(native.CacheDB, param.Issuer, param.ClaimId)
+	if err != nil {
+		return nil, fmt.Errorf("[revoke] %v", err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("[revoke] claim %s of %s not exist", param.ClaimId, param.Issuer)
+	}
+	if record.Status != STATUS_COMMITTED {
+		return nil, fmt.Errorf("[revoke] claim %s already revoked", param.ClaimId)
+	}
+	if err := verifyIssuer(native, param.Issuer, param.KeyNo); err != nil {
+		return nil, fmt.Errorf("[revoke] %v", err)
+	}
+
+	record.Status = STATUS_REVOKED
+	record.RevokeHeight = native.Height
+	putClaimRecord(native.CacheDB, param.Issuer, param.ClaimId, record)
+
+	pushEvent(native, []interface{}{EVENT_REVOKE, string(param.ClaimId), string(param.Issuer)})
+	return utils.BYTE_TRUE, nil
+}
+
+//GetStatus return the serialized record of a credential committed by issuer, or empty if not committed
+func GetStatus(native *native.NativeService) ([]byte, error) {
+	param := new(GetStatusParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[getStatus] deserialize param failed: %v", err)
+	}
+	record, err := getClaimRecord(native.CacheDB, param.Issuer, param.ClaimId)
+	if err != nil {
+		return nil, fmt.Errorf("[getStatus] %v", err)
+	}
+	if record == nil {
+		return []byte{}, nil
+	}
+	buf := new(bytes.Buffer)
+	if err := record.Serialize(buf); err != nil {
+		return nil, fmt.Errorf("[getStatus] serialize record failed: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claimrecord
+
+import (
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ontid"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	ontid.Init()
+	InitClaimRecord()
+}
+
+func getRecord(t *testing.T, suite *testsuite.NativeSuite, issuer string, claimId []byte) *ClaimRecord {
+	value, err := suite.Get(ClaimKey([]byte(issuer), claimId))
+	assert.Nil(t, err)
+	record, err := DecodeClaimRecord(value)
+	assert.Nil(t, err)
+	return record
+}
+
+func TestClaimRecord(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	invoke := func(contract common.Address, method string, param interface{}, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(contract, method, []interface{}{param}, signer)
+		return err
+	}
+
+	issuerAcc := account.NewAccount("")
+	other := account.NewAccount("")
+	issuer, err := account.GenerateID()
+	assert.Nil(t, err)
+	subject, err := account.GenerateID()
+	assert.Nil(t, err)
+	otherIssuer, err := account.GenerateID()
+	assert.Nil(t, err)
+	controlled, err := account.GenerateID()
+	assert.Nil(t, err)
+	assert.Nil(t, invoke(utils.OntIDContractAddress, "regIDWithPublicKey", &struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(issuer), keypair.SerializePublicKey(issuerAcc.PublicKey)}, issuerAcc))
+	assert.Nil(t, invoke(utils.OntIDContractAddress, "regIDWithPublicKey", &struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(otherIssuer), keypair.SerializePublicKey(other.PublicKey)}, other))
+	assert.Nil(t, invoke(utils.OntIDContractAddress, "regIDWithController", &struct {
+		ID         []byte
+		Controller []byte
+		Index      uint64
+	}{[]byte(controlled), []byte(issuer), 1}, issuerAcc))
+
+	claimId := []byte("claim-1")
+	//the same claim id committed by another issuer first does not take the claim of issuer
+	assert.Nil(t, invoke(utils.ClaimRecordContractAddress, COMMIT, &CommitParam{ClaimId: claimId,
+		Issuer: []byte(otherIssuer), KeyNo: 1, Subject: []byte(subject)}, other))
+	commit := &CommitParam{ClaimId: claimId, Issuer: []byte(issuer), KeyNo: 1, Subject: []byte(subject)}
+	suite.Height = 2
+	assert.NotNil(t, invoke(utils.ClaimRecordContractAddress, COMMIT, commit, other))
+	assert.Nil(t, invoke(utils.ClaimRecordContractAddress, COMMIT, commit, issuerAcc))
+	assert.NotNil(t, invoke(utils.ClaimRecordContractAddress, COMMIT, commit, issuerAcc))
+	//claim of controlled ID is committed with the key of its controller
+	assert.Nil(t, invoke(utils.ClaimRecordContractAddress, COMMIT, &CommitParam{ClaimId: claimId,
+		Issuer: []byte(controlled), KeyNo: 1, Subject: []byte(subject)}, issuerAcc))
+
+	record := getRecord(t, suite, issuer, claimId)
+	assert.Equal(t, []byte(issuer), record.Issuer)
+	assert.Equal(t, []byte(subject), record.Subject)
+	assert.Equal(t, STATUS_COMMITTED, record.Status)
+	assert.Equal(t, uint32(2), record.CommitHeight)
+
+	revoke := &RevokeParam{ClaimId: claimId, Issuer: []byte(issuer), KeyNo: 1}
+	suite.Height = 3
+	assert.NotNil(t, invoke(utils.ClaimRecordContractAddress, REVOKE, revoke, other))
+	assert.Nil(t, invoke(utils.ClaimRecordContractAddress, REVOKE, revoke, issuerAcc))
+
+	record = getRecord(t, suite, issuer, claimId)
+	assert.Equal(t, STATUS_REVOKED, record.Status)
+	assert.Equal(t, uint32(2), record.CommitHeight)
+	assert.Equal(t, uint32(3), record.RevokeHeight)
+	assert.Equal(t, []byte(otherIssuer), getRecord(t, suite, otherIssuer, claimId).Issuer)
+	assert.Equal(t, []byte(controlled), getRecord(t, suite, controlled, claimId).Issuer)
+}
+
+func TestClaimRecordActivation(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	height := config.CLAIM_RECORD_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.CLAIM_RECORD_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.CLAIM_RECORD_HEIGHT[config.NETWORK_ID_SOLO_NET] = 2
+
+	suite := testsuite.NewNativeSuite()
+	issuerAcc := account.NewAccount("")
+	issuer, err := account.GenerateID()
+	assert.Nil(t, err)
+	_, _, err = suite.InvokeNative(utils.OntIDContractAddress, "regIDWithPublicKey", []interface{}{&struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(issuer), keypair.SerializePublicKey(issuerAcc.PublicKey)}}, issuerAcc)
+	assert.Nil(t, err)
+
+	//no method of claim record contract is registered before the activation height
+	commit := &CommitParam{ClaimId: []byte("claim-1"), Issuer: []byte(issuer), KeyNo: 1, Subject: []byte(issuer)}
+	_, _, err = suite.InvokeNative(utils.ClaimRecordContractAddress, COMMIT, []interface{}{commit}, issuerAcc)
+	assert.NotNil(t, err)
+	suite.Height = 2
+	_, _, err = suite.InvokeNative(utils.ClaimRecordContractAddress, COMMIT, []interface{}{commit}, issuerAcc)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claimrecord
+
+import (
+	"io"
+
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	STATUS_COMMITTED byte = 1
+	STATUS_REVOKED   byte = 2
+)
+
+type CommitParam struct {
+	ClaimId []byte
+	Issuer  []byte
+	KeyNo   uint64
+	Subject []byte
+}
+
+func (this *CommitParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.ClaimId); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Subject); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *CommitParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.ClaimId, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.KeyNo, err = utils.ReadVarUint(r); err != nil {
+		return err
+	}
+	if this.Subject, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type RevokeParam struct {
+	ClaimId []byte
+	Issuer  []byte
+	KeyNo   uint64
+}
+
+func (this *RevokeParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.ClaimId); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(w, this.KeyNo); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *RevokeParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.ClaimId, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.KeyNo, err = utils.ReadVarUint(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+type GetStatusParam struct {
+	Issuer  []byte
+	ClaimId []byte
+}
+
+func (this *GetStatusParam) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.ClaimId); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *GetStatusParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.ClaimId, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+//ClaimRecord is the on-chain status of a credential, RevokeHeight is zero until revoked
+type ClaimRecord struct {
+	Issuer       []byte
+	Subject      []byte
+	Status       byte
+	CommitHeight uint32
+	RevokeHeight uint32
+}
+
+func (this *ClaimRecord) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Issuer); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Subject); err != nil {
+		return err
+	}
+	if err := serialization.WriteByte(w, this.Status); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, this.CommitHeight); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, this.RevokeHeight); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *ClaimRecord) Deserialize(r io.Reader) error {
+	var err error
+	if this.Issuer, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.Subject, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.Status, err = serialization.ReadByte(r); err != nil {
+		return err
+	}
+	if this.CommitHeight, err = serialization.ReadUint32(r); err != nil {
+		return err
+	}
+	if this.RevokeHeight, err = serialization.ReadUint32(r); err != nil {
+		return err
+	}
+	return nil
+}
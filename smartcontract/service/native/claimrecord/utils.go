@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package claimrecord
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/serialization"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ontid"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+)
+
+var PreClaim = []byte{0x01}
+
+//Enabled return whether the claim record contract can be invoked at the height
+func Enabled(height uint32) bool {
+	return height >= config.GetClaimRecordHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//ClaimKey return the key of claim record, claim id is scoped by issuer so that it can not be taken by other issuers
+func ClaimKey(issuer, claimId []byte) []byte {
+	bf := new(bytes.Buffer)
+	serialization.WriteVarBytes(bf, issuer)
+	return utils.ConcatKey(utils.ClaimRecordContractAddress, PreClaim, bf.Bytes(), claimId)
+}
+
+func getClaimRecord(cache *storage.CacheDB, issuer, claimId []byte) (*ClaimRecord, error) {
+	raw, err := cache.Get(ClaimKey(issuer, claimId))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeClaimRecord(value)
+}
+
+func putClaimRecord(cache *storage.CacheDB, issuer, claimId []byte, record *ClaimRecord) {
+	buf := new(bytes.Buffer)
+	record.Serialize(buf)
+	cache.Put(ClaimKey(issuer, claimId), cstates.GenRawStorageItem(buf.Bytes()))
+}
+
+//DecodeClaimRecord decode claim record from the value of storage item or the result of getStatus
+func DecodeClaimRecord(value []byte) (*ClaimRecord, error) {
+	record := new(ClaimRecord)
+	if err := record.Deserialize(bytes.NewReader(value)); err != nil {
+		return nil, fmt.Errorf("deserialize claim record failed: %v", err)
+	}
+	return record, nil
+}
+
+//verifyIssuer check the transaction is signed by the key of issuer ID through ontid contract. If the issuer is
+//controlled by another ID, keyNo is the key index of controller and the signature of controller is verified instead
+func verifyIssuer(native *native.NativeService, issuer []byte, keyNo uint64) error {
+	controlled, err := ontid.IsControlled(native, issuer)
+	if err != nil {
+		return fmt.Errorf("get controller of %s failed: %v", issuer, err)
+	}
+	method := "verifySignature"
+	if controlled {
+		method = "verifyController"
+	}
+	bf := new(bytes.Buffer)
+	if err := serialization.WriteVarBytes(bf, issuer); err != nil {
+		return err
+	}
+	if err := utils.WriteVarUint(bf, keyNo); err != nil {
+		return err
+	}
+	ret, err := native.NativeCall(utils.OntIDContractAddress, method, bf.Bytes())
+	if err != nil {
+		return fmt.Errorf("verify signature of %s failed: %v", issuer, err)
+	}
+	if valid, ok := ret.([]byte); !ok || !bytes.Equal(valid, utils.BYTE_TRUE) {
+		return fmt.Errorf("verify signature of %s failed", issuer)
+	}
+	return nil
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
@@ -156,7 +156,7 @@ func SetGlobalParam(native *native.NativeService) ([]byte, error) {
 	if err != nil || operator == common.ADDRESS_EMPTY {
 		return utils.BYTE_FALSE, fmt.Errorf("set param, operator doesn't exist, caused by %v", err)
 	}
-	if !native.ContextRef.CheckWitness(operator) {
+	if !native.ContextRef.CheckWitness(operator) && !utils.CheckProposalWitness(native) {
 		return utils.BYTE_FALSE, errors.NewErr("set param, authentication failed!")
 	}
 	params := Params{}
@@ -223,7 +223,7 @@ func CreateSnapshot(native *native.NativeService) ([]byte, error) {
 	if err != nil || operator == common.ADDRESS_EMPTY {
 		return utils.BYTE_FALSE, fmt.Errorf("create snapshot, operator doesn't exist, caused by %v", err)
 	}
-	if !native.ContextRef.CheckWitness(operator) {
+	if !native.ContextRef.CheckWitness(operator) && !utils.CheckProposalWitness(native) {
 		return utils.BYTE_FALSE, errors.NewErr("create snapshot, authentication failed!")
 	}
 	// read prepare param
@@ -32,6 +32,7 @@ import (
 	"github.com/dnaproject2/DNA/common/constants"
 	"github.com/dnaproject2/DNA/common/serialization"
 	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
 	"github.com/dnaproject2/DNA/smartcontract/service/native"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
@@ -77,6 +78,7 @@ const (
 	SET_PROMISE_POS                  = "setPromisePos"
 	SET_GAS_ADDRESS                  = "setGasAddress"
 	DESTROY_CONTRACT                 = "destroyContract"
+	SLASH_EQUIVOCATION               = "slashEquivocation"
 
 	//key prefix
 	GLOBAL_PARAM      = "globalParam"
@@ -96,6 +98,9 @@ const (
 	PROMISE_POS       = "promisePos"
 	PRE_CONFIG        = "preConfig"
 	GAS_ADDRESS       = "gasAddress"
+	EVIDENCE          = "evidence"
+	SPLIT_FEE_VIEW    = "splitFeeView"
+	VOTE_LOCK         = "voteLock"
 
 	//global
 	PRECISE            = 1000000
@@ -104,6 +109,13 @@ const (
 	NEW_WITHDRAW_BLOCK = 2800000
 )
 
+const (
+	//equivocation evidence type
+	EVIDENCE_PROPOSAL uint8 = iota + 1
+	EVIDENCE_ENDORSE
+	EVIDENCE_COMMIT
+)
+
 // candidate fee must >= 1 ONG
 var MIN_CANDIDATE_FEE = uint64(math.Pow(10, constants.ONG_DECIMALS))
 var AUTHORIZE_INFO_POOL = []byte{118, 111, 116, 101, 73, 110, 102, 111, 80, 111, 111, 108}
@@ -155,6 +167,9 @@ func RegisterGovernanceContract(native *native.NativeService) {
 	native.Register(SET_GAS_ADDRESS, SetGasAddress)
 
 	native.Register(DESTROY_CONTRACT, DestroyContract)
+	if native.Height >= config.GetSlashEquivocationHeight(config.DefConfig.P2PNode.NetworkId) {
+		native.Register(SLASH_EQUIVOCATION, SlashEquivocation)
+	}
 }
 
 //Init governance contract, include vbft config, global param and ontid admin.
@@ -624,31 +639,17 @@ func BlackNode(native *native.NativeService) ([]byte, error) {
 	}
 	commit := false
 	for _, peerPubkey := range params.PeerPubkeyList {
-		peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
-		if err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
-		}
 		peerPoolItem, ok := peerPoolMap.PeerPoolMap[peerPubkey]
 		if !ok {
 			return utils.BYTE_FALSE, fmt.Errorf("blackNode, peerPubkey is not in peerPoolMap")
 		}
-
-		blackListItem := &BlackListItem{
-			PeerPubkey: peerPoolItem.PeerPubkey,
-			Address:    peerPoolItem.Address,
-			InitPos:    peerPoolItem.InitPos,
-		}
-		bf := new(bytes.Buffer)
-		if err := blackListItem.Serialize(bf); err != nil {
-			return utils.BYTE_FALSE, fmt.Errorf("serialize, serialize blackListItem error: %v", err)
-		}
-		//put peer into black list
-		native.CacheDB.Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(bf.Bytes()))
-		//change peerPool status
 		if peerPoolItem.Status == ConsensusStatus {
 			commit = true
 		}
-		peerPoolItem.Status = BlackStatus
+		err = blackPeer(native, contract, peerPoolItem)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("blackPeer, black peer error: %v", err)
+		}
 		peerPoolMap.PeerPoolMap[peerPubkey] = peerPoolItem
 	}
 	err = putPeerPoolMap(native, contract, view, peerPoolMap)
@@ -926,6 +927,15 @@ func Withdraw(native *native.NativeService) ([]byte, error) {
 	}
 	contract := native.ContextRef.CurrentContext().ContractAddress
 
+	//stake of voter can not be withdrawn before voting ends, or it could be moved to vote again
+	voteLock, err := getVoteLock(native, contract, address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getVoteLock, get vote lock error: %v", err)
+	}
+	if native.Time < voteLock {
+		return utils.BYTE_FALSE, fmt.Errorf("withdraw, stake of address is locked by voting until %d", voteLock)
+	}
+
 	var total uint64
 	for i := 0; i < len(params.PeerPubkeyList); i++ {
 		peerPubkey := params.PeerPubkeyList[i]
@@ -1026,7 +1036,7 @@ func UpdateConfig(native *native.NativeService) ([]byte, error) {
 	}
 
 	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err = validateAdmin(native, adminAddress)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("updateConfig, checkWitness error: %v", err)
 	}
@@ -1111,7 +1121,7 @@ func UpdateGlobalParam(native *native.NativeService) ([]byte, error) {
 	}
 
 	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err = validateAdmin(native, adminAddress)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("updateGlobalParam, checkWitness error: %v", err)
 	}
@@ -1168,7 +1178,7 @@ func UpdateGlobalParam2(native *native.NativeService) ([]byte, error) {
 	}
 
 	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err = validateAdmin(native, adminAddress)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("updateGlobalParam2, checkWitness error: %v", err)
 	}
@@ -1206,7 +1216,7 @@ func UpdateSplitCurve(native *native.NativeService) ([]byte, error) {
 	}
 
 	//check witness
-	err = utils.ValidateOwner(native, adminAddress)
+	err = validateAdmin(native, adminAddress)
 	if err != nil {
 		return utils.BYTE_FALSE, fmt.Errorf("updateSplitCurve, checkWitness error: %v", err)
 	}
@@ -1254,6 +1264,91 @@ func TransferPenalty(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_TRUE, nil
 }
 
+//Slash a node with evidence of vbft equivocation, can be submitted by anyone.
+//Evidence is two conflicting headers signed by the node at the same height, the node is put into black list,
+//and its initPos with several percent of authorize deposit will be moved to penalty stake in commitDpos,
+//which is executed at once if the node is a consensus node, like blackNode.
+func SlashEquivocation(native *native.NativeService) ([]byte, error) {
+	params := new(EquivocationEvidence)
+	if err := params.Deserialize(bytes.NewBuffer(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("deserialize, contract params deserialize error: %v", err)
+	}
+	contract := native.ContextRef.CurrentContext().ContractAddress
+
+	height, proposer, err := VerifyEquivocationEvidence(params)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("verifyEquivocationEvidence, invalid evidence: %v", err)
+	}
+	if height > native.Height {
+		return utils.BYTE_FALSE, fmt.Errorf("slashEquivocation, evidence height is larger than current height")
+	}
+
+	peerPubkeyPrefix, err := hex.DecodeString(params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
+	}
+	evidenceKey, err := getEvidenceKey(contract, peerPubkeyPrefix, height)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getEvidenceKey, get evidence key error: %v", err)
+	}
+	evidenceBytes, err := native.CacheDB.Get(evidenceKey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("native.CacheDB.Get, get evidence error: %v", err)
+	}
+	if evidenceBytes != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("slashEquivocation, evidence has already been used")
+	}
+
+	//get current view
+	view, err := GetView(native, contract)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getView, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
+	}
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("slashEquivocation, peerPubkey is not in peerPoolMap")
+	}
+	if peerPoolItem.Status == BlackStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("slashEquivocation, peer is already in black list")
+	}
+	if params.MsgType == EVIDENCE_PROPOSAL && proposer != peerPoolItem.Index {
+		return utils.BYTE_FALSE, fmt.Errorf("slashEquivocation, proposal evidence is not proposed by peer")
+	}
+
+	commit := peerPoolItem.Status == ConsensusStatus
+	err = blackPeer(native, contract, peerPoolItem)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("blackPeer, black peer error: %v", err)
+	}
+	peerPoolMap.PeerPoolMap[params.PeerPubkey] = peerPoolItem
+	err = putPeerPoolMap(native, contract, view, peerPoolMap)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("putPeerPoolMap, put peerPoolMap error: %v", err)
+	}
+	txHash := native.Tx.Hash()
+	native.CacheDB.Put(evidenceKey, cstates.GenRawStorageItem(txHash[:]))
+
+	//commitDpos
+	if commit {
+		err = executeCommitDpos(native, contract)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("executeCommitDpos, executeCommitDpos error: %v", err)
+		}
+	}
+
+	native.Notifications = append(native.Notifications,
+		&event.NotifyEventInfo{
+			ContractAddress: contract,
+			States:          []interface{}{SLASH_EQUIVOCATION, params.PeerPubkey, height, params.MsgType},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
 //Withdraw unbounded ONG according to deposit ONT in this governance contract
 func WithdrawOng(native *native.NativeService) ([]byte, error) {
 	params := new(WithdrawOngParam)
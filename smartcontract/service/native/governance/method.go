@@ -830,6 +830,10 @@ func executeSplit2(native *native.NativeService, contract common.Address, view u
 		panic("balance less than splitFee to withdraw!")
 	}
 	income := balance - splitFee
+	viewSplitFee := &ViewSplitFee{
+		View:   view,
+		Income: income,
+	}
 
 	//fee split to dapp address
 	dappIncome := new(big.Int).Div(new(big.Int).Mul(new(big.Int).SetUint64(income),
@@ -847,6 +851,8 @@ func executeSplit2(native *native.NativeService, contract common.Address, view u
 		}
 	}
 
+	viewSplitFee.DappFee = dappIncome.Uint64()
+
 	//fee split to node
 	if income < dappIncome.Uint64() {
 		panic("income less than dappIncome!")
@@ -889,7 +895,7 @@ func executeSplit2(native *native.NativeService, contract common.Address, view u
 	}
 	// if sum = 0, means consensus peer in config, do not split
 	if sum < uint64(config.K) {
-		return splitSum, nil
+		return splitSum, putViewSplitFee(native, contract, viewSplitFee)
 	}
 	avg := sum / uint64(config.K)
 	var sumS uint64
@@ -916,13 +922,20 @@ func executeSplit2(native *native.NativeService, contract common.Address, view u
 		nodeWeight := new(big.Int).Mul(consensusAmount, new(big.Int).SetUint64(peersCandidate[i].S))
 		nodeAmount := new(big.Int).Div(nodeWeight, new(big.Int).SetUint64(sumS))
 
-		err = splitNodeFee(native, contract, peersCandidate[i].PeerPubkey, peersCandidate[i].Address,
-			currentPeerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].Status == ConsensusStatus,
-			peerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].TotalPos, nodeAmount.Uint64())
+		ifConsensus := currentPeerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].Status == ConsensusStatus
+		stakeAmount, err := splitNodeFee(native, contract, peersCandidate[i].PeerPubkey, peersCandidate[i].Address,
+			ifConsensus, peerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].TotalPos, nodeAmount.Uint64())
 		if err != nil {
 			return splitSum, fmt.Errorf("executeSplit2, splitNodeFee error: %v", err)
 		}
 		splitSum += nodeAmount.Uint64()
+		viewSplitFee.Peers = append(viewSplitFee.Peers, &PeerSplitFee{
+			PeerPubkey:  peersCandidate[i].PeerPubkey,
+			Address:     peersCandidate[i].Address,
+			Consensus:   ifConsensus,
+			Amount:      nodeAmount.Uint64(),
+			StakeAmount: stakeAmount,
+		})
 	}
 
 	//fee split of candidate peer
@@ -938,7 +951,7 @@ func executeSplit2(native *native.NativeService, contract common.Address, view u
 		sum += peersCandidate[i].Stake
 	}
 	if sum == 0 {
-		return splitSum, nil
+		return splitSum, putViewSplitFee(native, contract, viewSplitFee)
 	}
 	for i := int(config.K); i < length; i++ {
 		//nodeAmount := nodeIncome * uint64(globalParam.B) / 100 * peersCandidate[i].Stake / sum
@@ -951,16 +964,23 @@ func executeSplit2(native *native.NativeService, contract common.Address, view u
 		nodeWeight := new(big.Int).Mul(candidateAmount, new(big.Int).SetUint64(peersCandidate[i].Stake))
 		nodeAmount := new(big.Int).Div(nodeWeight, new(big.Int).SetUint64(sum))
 
-		err = splitNodeFee(native, contract, peersCandidate[i].PeerPubkey, peersCandidate[i].Address,
-			currentPeerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].Status == ConsensusStatus,
-			peerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].TotalPos, nodeAmount.Uint64())
+		ifConsensus := currentPeerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].Status == ConsensusStatus
+		stakeAmount, err := splitNodeFee(native, contract, peersCandidate[i].PeerPubkey, peersCandidate[i].Address,
+			ifConsensus, peerPoolMap.PeerPoolMap[peersCandidate[i].PeerPubkey].TotalPos, nodeAmount.Uint64())
 		if err != nil {
 			return splitSum, fmt.Errorf("executeSplit2, splitNodeFee error: %v", err)
 		}
 		splitSum += nodeAmount.Uint64()
+		viewSplitFee.Peers = append(viewSplitFee.Peers, &PeerSplitFee{
+			PeerPubkey:  peersCandidate[i].PeerPubkey,
+			Address:     peersCandidate[i].Address,
+			Consensus:   ifConsensus,
+			Amount:      nodeAmount.Uint64(),
+			StakeAmount: stakeAmount,
+		})
 	}
 
-	return splitSum, nil
+	return splitSum, putViewSplitFee(native, contract, viewSplitFee)
 }
 
 func executeAddressSplit(native *native.NativeService, contract common.Address, authorizeInfo *AuthorizeInfo, ifConsensus bool, totalPos uint64, totalAmount uint64, peerAddress common.Address) (uint64, error) {
@@ -1307,16 +1327,17 @@ func executeCommitDpos2(native *native.NativeService, contract common.Address) e
 	return nil
 }
 
-func splitNodeFee(native *native.NativeService, contract common.Address, peerPubkey string, peerAddress common.Address, ifConsensus bool, totalPos uint64, nodeAmount uint64) error {
+//splitNodeFee split fee of peer to its authorize users and owner, return the amount split to authorize users
+func splitNodeFee(native *native.NativeService, contract common.Address, peerPubkey string, peerAddress common.Address, ifConsensus bool, totalPos uint64, nodeAmount uint64) (uint64, error) {
 	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
 	if err != nil {
-		return fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
+		return 0, fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
 	}
 	//fee split of address
 	//get peerCost
 	peerCost, err := getPeerCost(native, contract, peerPubkey)
 	if err != nil {
-		return fmt.Errorf("getPeerCost, getPeerCost error: %v", err)
+		return 0, fmt.Errorf("getPeerCost, getPeerCost error: %v", err)
 	}
 	amount := nodeAmount * (100 - peerCost) / 100
 	var sumAmount uint64 = 0
@@ -1325,28 +1346,28 @@ func splitNodeFee(native *native.NativeService, contract common.Address, peerPub
 	for has := iter.First(); has; has = iter.Next() {
 		authorizeInfoStore, err := cstates.GetValueFromRawStorageItem(iter.Value())
 		if err != nil {
-			return fmt.Errorf("authorizeInfoStore is not available!:%v", err)
+			return 0, fmt.Errorf("authorizeInfoStore is not available!:%v", err)
 		}
 		var authorizeInfo AuthorizeInfo
 		if err := authorizeInfo.Deserialize(bytes.NewBuffer(authorizeInfoStore)); err != nil {
-			return fmt.Errorf("deserialize, deserialize authorizeInfo error: %v", err)
+			return 0, fmt.Errorf("deserialize, deserialize authorizeInfo error: %v", err)
 		}
 
 		//fee split
 		splitAmount, err := executeAddressSplit(native, contract, &authorizeInfo, ifConsensus, totalPos, amount, peerAddress)
 		if err != nil {
-			return fmt.Errorf("excuteAddressSplit, excuteAddressSplit error: %v", err)
+			return 0, fmt.Errorf("excuteAddressSplit, excuteAddressSplit error: %v", err)
 		}
 		sumAmount = sumAmount + splitAmount
 	}
 	if err := iter.Error(); err != nil {
-		return err
+		return 0, err
 	}
 	//split fee to peer
 	remainAmount := nodeAmount - sumAmount
 	err = executePeerSplit(native, contract, peerAddress, remainAmount)
 	if err != nil {
-		return fmt.Errorf("excutePeerSplit, excutePeerSplit error: %v", err)
+		return 0, fmt.Errorf("excutePeerSplit, excutePeerSplit error: %v", err)
 	}
-	return nil
+	return sumAmount, nil
 }
@@ -1040,3 +1040,71 @@ func (this *DestroyContractParam) Deserialize(r io.Reader) error {
 	this.ContractAddress = contractAddress
 	return nil
 }
+
+type EquivocationEvidence struct {
+	MsgType    uint8
+	PeerPubkey string
+	Header1    []byte
+	Sig1       []byte
+	Header2    []byte
+	Sig2       []byte
+}
+
+func (this *EquivocationEvidence) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(this.MsgType)); err != nil {
+		return fmt.Errorf("utils.WriteVarUint, serialize msgType error: %v", err)
+	}
+	if err := serialization.WriteString(w, this.PeerPubkey); err != nil {
+		return fmt.Errorf("serialization.WriteString, serialize peerPubkey error: %v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Header1); err != nil {
+		return fmt.Errorf("serialization.WriteVarBytes, serialize header1 error: %v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Sig1); err != nil {
+		return fmt.Errorf("serialization.WriteVarBytes, serialize sig1 error: %v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Header2); err != nil {
+		return fmt.Errorf("serialization.WriteVarBytes, serialize header2 error: %v", err)
+	}
+	if err := serialization.WriteVarBytes(w, this.Sig2); err != nil {
+		return fmt.Errorf("serialization.WriteVarBytes, serialize sig2 error: %v", err)
+	}
+	return nil
+}
+
+func (this *EquivocationEvidence) Deserialize(r io.Reader) error {
+	msgType, err := utils.ReadVarUint(r)
+	if err != nil {
+		return fmt.Errorf("utils.ReadVarUint, deserialize msgType error: %v", err)
+	}
+	if msgType > math.MaxUint8 {
+		return fmt.Errorf("msgType larger than max of uint8")
+	}
+	peerPubkey, err := serialization.ReadString(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadString, deserialize peerPubkey error: %v", err)
+	}
+	header1, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize header1 error: %v", err)
+	}
+	sig1, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize sig1 error: %v", err)
+	}
+	header2, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize header2 error: %v", err)
+	}
+	sig2, err := serialization.ReadVarBytes(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadVarBytes, deserialize sig2 error: %v", err)
+	}
+	this.MsgType = uint8(msgType)
+	this.PeerPubkey = peerPubkey
+	this.Header1 = header1
+	this.Sig1 = sig1
+	this.Header2 = header2
+	this.Sig2 = sig2
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package governance
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//StorageReader read the value of storage item by the full storage key, it returns nil if the item not exists
+type StorageReader func(key []byte) ([]byte, error)
+
+//ReadGovernanceView read the current view of governance contract
+func ReadGovernanceView(read StorageReader) (*GovernanceView, error) {
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(GOVERNANCE_VIEW)))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("governance view not found")
+	}
+	governanceView := new(GovernanceView)
+	if err := governanceView.Deserialize(bytes.NewBuffer(value)); err != nil {
+		return nil, fmt.Errorf("deserialize governanceView error: %v", err)
+	}
+	return governanceView, nil
+}
+
+//ReadConfig read the consensus configuration of governance contract
+func ReadConfig(read StorageReader) (*Configuration, error) {
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(VBFT_CONFIG)))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("config not found")
+	}
+	config := new(Configuration)
+	if err := config.Deserialize(bytes.NewBuffer(value)); err != nil {
+		return nil, fmt.Errorf("deserialize config error: %v", err)
+	}
+	return config, nil
+}
+
+//ReadPeerPoolMap read the peer pool of view, it returns nil if the view not exists
+func ReadPeerPoolMap(read StorageReader, view uint32) (*PeerPoolMap, error) {
+	viewBytes, err := GetUint32Bytes(view)
+	if err != nil {
+		return nil, err
+	}
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(PEER_POOL), viewBytes))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	peerPoolMap := new(PeerPoolMap)
+	if err := peerPoolMap.Deserialize(bytes.NewBuffer(value)); err != nil {
+		return nil, fmt.Errorf("deserialize peerPoolMap error: %v", err)
+	}
+	return peerPoolMap, nil
+}
+
+//ReadPeerAttributes read the attributes of peer, default attributes are returned if peer never set them
+func ReadPeerAttributes(read StorageReader, peerPubkey string) (*PeerAttributes, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("peerPubkey format error: %v", err)
+	}
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(PEER_ATTRIBUTES), peerPubkeyPrefix))
+	if err != nil {
+		return nil, err
+	}
+	peerAttributes := &PeerAttributes{
+		PeerPubkey:   peerPubkey,
+		MaxAuthorize: 0,
+		T2PeerCost:   100,
+		T1PeerCost:   100,
+		TPeerCost:    100,
+	}
+	if value != nil {
+		if err := peerAttributes.Deserialize(bytes.NewBuffer(value)); err != nil {
+			return nil, fmt.Errorf("deserialize peerAttributes error: %v", err)
+		}
+	}
+	return peerAttributes, nil
+}
+
+//ReadAuthorizeInfo read the authorization of address to peer, an empty authorization is returned if not exists
+func ReadAuthorizeInfo(read StorageReader, peerPubkey string, address common.Address) (*AuthorizeInfo, error) {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("peerPubkey format error: %v", err)
+	}
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, AUTHORIZE_INFO_POOL, peerPubkeyPrefix,
+		address[:]))
+	if err != nil {
+		return nil, err
+	}
+	authorizeInfo := &AuthorizeInfo{
+		PeerPubkey: peerPubkey,
+		Address:    address,
+	}
+	if value != nil {
+		if err := authorizeInfo.Deserialize(bytes.NewBuffer(value)); err != nil {
+			return nil, fmt.Errorf("deserialize authorizeInfo error: %v", err)
+		}
+	}
+	return authorizeInfo, nil
+}
+
+//ReadTotalStake read the total stake of address in governance contract
+func ReadTotalStake(read StorageReader, address common.Address) (*TotalStake, error) {
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(TOTAL_STAKE), address[:]))
+	if err != nil {
+		return nil, err
+	}
+	totalStake := &TotalStake{
+		Address: address,
+	}
+	if value != nil {
+		if err := totalStake.Deserialize(bytes.NewBuffer(value)); err != nil {
+			return nil, fmt.Errorf("deserialize totalStake error: %v", err)
+		}
+	}
+	return totalStake, nil
+}
+
+//ReadSplitFeeAddress read the ong fee of address which is not withdrawn yet
+func ReadSplitFeeAddress(read StorageReader, address common.Address) (*SplitFeeAddress, error) {
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(SPLIT_FEE_ADDRESS), address[:]))
+	if err != nil {
+		return nil, err
+	}
+	splitFeeAddress := &SplitFeeAddress{
+		Address: address,
+	}
+	if value != nil {
+		if err := splitFeeAddress.Deserialize(bytes.NewBuffer(value)); err != nil {
+			return nil, fmt.Errorf("deserialize splitFeeAddress error: %v", err)
+		}
+	}
+	return splitFeeAddress, nil
+}
+
+//ReadViewSplitFee read the fee split at the end of view, it returns nil if the fee split of view is not recorded,
+//which is the case for views committed before the height of config.GetViewSplitFeeHeight
+func ReadViewSplitFee(read StorageReader, view uint32) (*ViewSplitFee, error) {
+	viewBytes, err := GetUint32Bytes(view)
+	if err != nil {
+		return nil, err
+	}
+	value, err := read(utils.ConcatKey(utils.GovernanceContractAddress, []byte(SPLIT_FEE_VIEW), viewBytes))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	viewSplitFee := new(ViewSplitFee)
+	if err := viewSplitFee.Deserialize(bytes.NewBuffer(value)); err != nil {
+		return nil, fmt.Errorf("deserialize viewSplitFee error: %v", err)
+	}
+	return viewSplitFee, nil
+}
+
+//RankPeers return the candidate and consensus peers sorted by stake in the same order as commitDpos, the first
+//K peers will be consensus peers in next view if the stakes are not changed
+func RankPeers(peerPoolMap *PeerPoolMap) []*PeerStakeInfo {
+	var peers []*PeerStakeInfo
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus {
+			peers = append(peers, &PeerStakeInfo{
+				Index:      peerPoolItem.Index,
+				PeerPubkey: peerPoolItem.PeerPubkey,
+				Stake:      peerPoolItem.TotalPos + peerPoolItem.InitPos,
+			})
+		}
+	}
+	sort.SliceStable(peers, func(i, j int) bool {
+		if peers[i].Stake > peers[j].Stake {
+			return true
+		} else if peers[i].Stake == peers[j].Stake {
+			return peers[i].PeerPubkey > peers[j].PeerPubkey
+		}
+		return false
+	})
+	return peers
+}
@@ -547,3 +547,119 @@ func (this *SplitFeeAddress) Deserialize(r io.Reader) error {
 	this.Amount = amount
 	return nil
 }
+
+type PeerSplitFee struct { //fee split of a peer at the end of view
+	PeerPubkey  string
+	Address     common.Address
+	Consensus   bool   //if peer is consensus node in next view
+	Amount      uint64 //total ong split to this peer
+	StakeAmount uint64 //ong split to authorize users of this peer
+}
+
+func (this *PeerSplitFee) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.PeerPubkey); err != nil {
+		return fmt.Errorf("serialization.WriteString, serialize peerPubkey error: %v", err)
+	}
+	if err := this.Address.Serialize(w); err != nil {
+		return fmt.Errorf("address.Serialize, serialize address error: %v", err)
+	}
+	if err := serialization.WriteBool(w, this.Consensus); err != nil {
+		return fmt.Errorf("serialization.WriteBool, serialize consensus error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.Amount); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize amount error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.StakeAmount); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize stakeAmount error: %v", err)
+	}
+	return nil
+}
+
+func (this *PeerSplitFee) Deserialize(r io.Reader) error {
+	peerPubkey, err := serialization.ReadString(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadString, deserialize peerPubkey error: %v", err)
+	}
+	address := new(common.Address)
+	if err := address.Deserialize(r); err != nil {
+		return fmt.Errorf("address.Deserialize, deserialize address error: %v", err)
+	}
+	consensus, err := serialization.ReadBool(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadBool, deserialize consensus error: %v", err)
+	}
+	amount, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize amount error: %v", err)
+	}
+	stakeAmount, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize stakeAmount error: %v", err)
+	}
+	this.PeerPubkey = peerPubkey
+	this.Address = *address
+	this.Consensus = consensus
+	this.Amount = amount
+	this.StakeAmount = stakeAmount
+	return nil
+}
+
+type ViewSplitFee struct { //table record fee split of each view
+	View    uint32
+	Income  uint64 //ong income of governance contract in this view
+	DappFee uint64 //ong split to gas address
+	Peers   []*PeerSplitFee
+}
+
+func (this *ViewSplitFee) Serialize(w io.Writer) error {
+	if err := serialization.WriteUint32(w, this.View); err != nil {
+		return fmt.Errorf("serialization.WriteUint32, serialize view error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.Income); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize income error: %v", err)
+	}
+	if err := serialization.WriteUint64(w, this.DappFee); err != nil {
+		return fmt.Errorf("serialization.WriteUint64, serialize dappFee error: %v", err)
+	}
+	if err := serialization.WriteUint32(w, uint32(len(this.Peers))); err != nil {
+		return fmt.Errorf("serialization.WriteUint32, serialize peers length error: %v", err)
+	}
+	for _, peer := range this.Peers {
+		if err := peer.Serialize(w); err != nil {
+			return fmt.Errorf("serialize peerSplitFee error: %v", err)
+		}
+	}
+	return nil
+}
+
+func (this *ViewSplitFee) Deserialize(r io.Reader) error {
+	view, err := serialization.ReadUint32(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint32, deserialize view error: %v", err)
+	}
+	income, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize income error: %v", err)
+	}
+	dappFee, err := serialization.ReadUint64(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint64, deserialize dappFee error: %v", err)
+	}
+	n, err := serialization.ReadUint32(r)
+	if err != nil {
+		return fmt.Errorf("serialization.ReadUint32, deserialize peers length error: %v", err)
+	}
+	peers := make([]*PeerSplitFee, 0)
+	for i := 0; uint32(i) < n; i++ {
+		peer := new(PeerSplitFee)
+		if err := peer.Deserialize(r); err != nil {
+			return fmt.Errorf("deserialize peerSplitFee error: %v", err)
+		}
+		peers = append(peers, peer)
+	}
+	this.View = view
+	this.Income = income
+	this.DappFee = dappFee
+	this.Peers = peers
+	return nil
+}
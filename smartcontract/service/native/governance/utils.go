@@ -21,13 +21,16 @@ package governance
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/serialization"
 	vbftconfig "github.com/dnaproject2/DNA/consensus/vbft/config"
+	"github.com/dnaproject2/DNA/core/signature"
 	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/core/types"
 	"github.com/dnaproject2/DNA/smartcontract/service/native"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/auth"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
@@ -498,6 +501,22 @@ func putSplitFee(native *native.NativeService, contract common.Address, splitFee
 	return nil
 }
 
+func putViewSplitFee(native *native.NativeService, contract common.Address, viewSplitFee *ViewSplitFee) error {
+	if native.Height < config.GetViewSplitFeeHeight(config.DefConfig.P2PNode.NetworkId) {
+		return nil
+	}
+	bf := new(bytes.Buffer)
+	if err := viewSplitFee.Serialize(bf); err != nil {
+		return fmt.Errorf("serialize, serialize viewSplitFee error: %v", err)
+	}
+	viewBytes, err := GetUint32Bytes(viewSplitFee.View)
+	if err != nil {
+		return fmt.Errorf("GetUint32Bytes, get viewBytes error: %v", err)
+	}
+	native.CacheDB.Put(utils.ConcatKey(contract, []byte(SPLIT_FEE_VIEW), viewBytes), cstates.GenRawStorageItem(bf.Bytes()))
+	return nil
+}
+
 func getSplitFeeAddress(native *native.NativeService, contract common.Address, address common.Address) (*SplitFeeAddress, error) {
 	splitFeeAddressBytes, err := native.CacheDB.Get(utils.ConcatKey(contract, []byte(SPLIT_FEE_ADDRESS), address[:]))
 	if err != nil {
@@ -817,3 +836,160 @@ func putGasAddress(native *native.NativeService, contract common.Address, gasAdd
 		cstates.GenRawStorageItem(sink.Bytes()))
 	return nil
 }
+
+//VerifyEquivocationEvidence check that two headers signed by the same peer conflict with each other,
+//it returns the height and proposer index of the conflicting headers.
+//Full block and empty block of one proposal share prevBlockHash, timestamp and consensusPayload, they are not equivocation.
+func VerifyEquivocationEvidence(evidence *EquivocationEvidence) (uint32, uint32, error) {
+	if evidence.MsgType < EVIDENCE_PROPOSAL || evidence.MsgType > EVIDENCE_COMMIT {
+		return 0, 0, fmt.Errorf("invalid evidence msgType %d", evidence.MsgType)
+	}
+	pubkey, err := vbftconfig.Pubkey(evidence.PeerPubkey)
+	if err != nil {
+		return 0, 0, fmt.Errorf("vbftconfig.Pubkey, peerPubkey format error: %v", err)
+	}
+	header1, err := types.HeaderFromRawBytes(evidence.Header1)
+	if err != nil {
+		return 0, 0, fmt.Errorf("types.HeaderFromRawBytes, deserialize header1 error: %v", err)
+	}
+	header2, err := types.HeaderFromRawBytes(evidence.Header2)
+	if err != nil {
+		return 0, 0, fmt.Errorf("types.HeaderFromRawBytes, deserialize header2 error: %v", err)
+	}
+	if header1.Height != header2.Height {
+		return 0, 0, fmt.Errorf("headers are not at the same height")
+	}
+	hash1 := header1.Hash()
+	hash2 := header2.Hash()
+	if hash1 == hash2 {
+		return 0, 0, fmt.Errorf("headers are the same")
+	}
+	if err := signature.Verify(pubkey, hash1[:], evidence.Sig1); err != nil {
+		return 0, 0, fmt.Errorf("verify sig1 error: %v", err)
+	}
+	if err := signature.Verify(pubkey, hash2[:], evidence.Sig2); err != nil {
+		return 0, 0, fmt.Errorf("verify sig2 error: %v", err)
+	}
+
+	blkInfo1 := new(vbftconfig.VbftBlockInfo)
+	if err := json.Unmarshal(header1.ConsensusPayload, blkInfo1); err != nil {
+		return 0, 0, fmt.Errorf("json.Unmarshal, unmarshal consensusPayload of header1 error: %v", err)
+	}
+	blkInfo2 := new(vbftconfig.VbftBlockInfo)
+	if err := json.Unmarshal(header2.ConsensusPayload, blkInfo2); err != nil {
+		return 0, 0, fmt.Errorf("json.Unmarshal, unmarshal consensusPayload of header2 error: %v", err)
+	}
+	//an honest endorser may endorse blocks of several proposers at the same height, but never two blocks of
+	//one proposer. Proposals, endorsements and commits are all signed over the block hash, so msgType is not
+	//bound to the signatures, and an endorsement of one proposer with a commit of another must not count either
+	if blkInfo1.Proposer != blkInfo2.Proposer {
+		return 0, 0, fmt.Errorf("headers are proposed by different proposers")
+	}
+	if header1.PrevBlockHash == header2.PrevBlockHash && header1.Timestamp == header2.Timestamp &&
+		bytes.Equal(header1.ConsensusPayload, header2.ConsensusPayload) {
+		return 0, 0, fmt.Errorf("headers belong to the same proposal")
+	}
+	return header1.Height, blkInfo1.Proposer, nil
+}
+
+func getVoteLock(native *native.NativeService, contract common.Address, address common.Address) (uint32, error) {
+	voteLockBytes, err := native.CacheDB.Get(utils.ConcatKey(contract, []byte(VOTE_LOCK), address[:]))
+	if err != nil {
+		return 0, fmt.Errorf("native.CacheDB.Get, get vote lock error: %v", err)
+	}
+	if voteLockBytes == nil {
+		return 0, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(voteLockBytes)
+	if err != nil {
+		return 0, fmt.Errorf("getValueFromRawStorageItem, deserialize vote lock error: %v", err)
+	}
+	return GetBytesUint32(value)
+}
+
+//LockAuthorizeStake forbid address to withdraw its stake in governance contract before time until,
+//it is used by proposal contract, so that stake of a voter can not be moved to another address to vote again
+func LockAuthorizeStake(native *native.NativeService, address common.Address, until uint32) error {
+	contract := utils.GovernanceContractAddress
+	voteLock, err := getVoteLock(native, contract, address)
+	if err != nil {
+		return fmt.Errorf("getVoteLock, get vote lock error: %v", err)
+	}
+	if voteLock >= until {
+		return nil
+	}
+	untilBytes, err := GetUint32Bytes(until)
+	if err != nil {
+		return fmt.Errorf("getUint32Bytes, get until bytes error: %v", err)
+	}
+	native.CacheDB.Put(utils.ConcatKey(contract, []byte(VOTE_LOCK), address[:]), cstates.GenRawStorageItem(untilBytes))
+	return nil
+}
+
+func getEvidenceKey(contract common.Address, peerPubkeyPrefix []byte, height uint32) ([]byte, error) {
+	heightBytes, err := GetUint32Bytes(height)
+	if err != nil {
+		return nil, fmt.Errorf("getUint32Bytes, getUint32Bytes error: %v", err)
+	}
+	return utils.ConcatKey(contract, []byte(EVIDENCE), peerPubkeyPrefix, heightBytes), nil
+}
+
+//validateAdmin check witness of admin, passed proposals executed by proposal contract are also accepted
+func validateAdmin(native *native.NativeService, adminAddress common.Address) error {
+	if utils.CheckProposalWitness(native) {
+		return nil
+	}
+	return utils.ValidateOwner(native, adminAddress)
+}
+
+//blackPeer put peer into black list and change its status, stake of black node is punished in commitDpos
+func blackPeer(native *native.NativeService, contract common.Address, peerPoolItem *PeerPoolItem) error {
+	peerPubkeyPrefix, err := hex.DecodeString(peerPoolItem.PeerPubkey)
+	if err != nil {
+		return fmt.Errorf("hex.DecodeString, peerPubkey format error: %v", err)
+	}
+	blackListItem := &BlackListItem{
+		PeerPubkey: peerPoolItem.PeerPubkey,
+		Address:    peerPoolItem.Address,
+		InitPos:    peerPoolItem.InitPos,
+	}
+	bf := new(bytes.Buffer)
+	if err := blackListItem.Serialize(bf); err != nil {
+		return fmt.Errorf("serialize, serialize blackListItem error: %v", err)
+	}
+	//put peer into black list
+	native.CacheDB.Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(bf.Bytes()))
+	//change peerPool status
+	peerPoolItem.Status = BlackStatus
+	return nil
+}
+
+//GetAuthorizeStake return the stake of address in candidate and consensus peers of current view, which is the init
+//pos of peers owned by address and the pos authorized by address, and the total stake of these peers
+func GetAuthorizeStake(native *native.NativeService, address common.Address) (uint64, uint64, error) {
+	contract := utils.GovernanceContractAddress
+	view, err := GetView(native, contract)
+	if err != nil {
+		return 0, 0, fmt.Errorf("getView, get view error: %v", err)
+	}
+	peerPoolMap, err := GetPeerPoolMap(native, contract, view)
+	if err != nil {
+		return 0, 0, fmt.Errorf("getPeerPoolMap, get peerPoolMap error: %v", err)
+	}
+	var stake, total uint64
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Status != CandidateStatus && peerPoolItem.Status != ConsensusStatus {
+			continue
+		}
+		total = total + peerPoolItem.InitPos + peerPoolItem.TotalPos
+		if peerPoolItem.Address == address {
+			stake = stake + peerPoolItem.InitPos
+		}
+		authorizeInfo, err := getAuthorizeInfo(native, contract, peerPoolItem.PeerPubkey, address)
+		if err != nil {
+			return 0, 0, fmt.Errorf("getAuthorizeInfo, get authorizeInfo error: %v", err)
+		}
+		stake = stake + authorizeInfo.ConsensusPos + authorizeInfo.CandidatePos + authorizeInfo.NewPos
+	}
+	return stake, total, nil
+}
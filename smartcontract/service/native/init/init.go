@@ -24,12 +24,18 @@ import (
 
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/auth"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/claimrecord"
 	params "github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/nft"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ong"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/ontid"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/proposal"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/token"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/vesting"
 	"github.com/dnaproject2/DNA/smartcontract/service/neovm"
 	vm "github.com/dnaproject2/DNA/vm/neovm"
 )
@@ -45,6 +51,12 @@ func init() {
 	ontid.Init()
 	auth.Init()
 	governance.InitGovernance()
+	registry.InitRegistry()
+	claimrecord.InitClaimRecord()
+	token.InitToken()
+	nft.InitNft()
+	vesting.InitVesting()
+	proposal.InitProposal()
 }
 
 func InitBytes(addr common.Address, method string) []byte {
@@ -53,6 +53,7 @@ type NativeService struct {
 	Time          uint32
 	BlockHash     common.Uint256
 	ContextRef    context.ContextRef
+	Tracer        context.Tracer
 }
 
 func (this *NativeService) Register(methodName string, handler Handler) {
@@ -60,6 +61,17 @@ func (this *NativeService) Register(methodName string, handler Handler) {
 }
 
 func (this *NativeService) Invoke() (interface{}, error) {
+	if this.Tracer == nil {
+		return this.invoke()
+	}
+	contract := this.InvokeParam
+	this.Tracer.CaptureEnter(context.NATIVE_CONTRACT, contract.Address, contract.Method, contract.Args, this.ContextRef.GasLeft())
+	result, err := this.invoke()
+	this.Tracer.CaptureExit(result, this.ContextRef.GasLeft(), err)
+	return result, err
+}
+
+func (this *NativeService) invoke() (interface{}, error) {
 	contract := this.InvokeParam
 	services, ok := Contracts[contract.Address]
 	if !ok {
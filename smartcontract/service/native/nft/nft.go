@@ -0,0 +1,299 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/dnaproject2/DNA/common"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	//function name
+	MINT_NAME         = "mint"
+	TRANSFER_NAME     = "transfer"
+	APPROVE_NAME      = "approve"
+	TRANSFERFROM_NAME = "transferFrom"
+	OWNEROF_NAME      = "ownerOf"
+	GETAPPROVED_NAME  = "getApproved"
+	BALANCEOF_NAME    = "balanceOf"
+	TOTALSUPPLY_NAME  = "totalSupply"
+	TOKENSOF_NAME     = "tokensOf"
+	TOKENURI_NAME     = "tokenURI"
+	GETTOKEN_NAME     = "getToken"
+
+	//limits
+	MAX_TOKEN_ID_LEN     = 64
+	MAX_URI_LEN          = 256
+	METADATA_HASH_LENGTH = common.UINT256_SIZE
+	MAX_PAGE_LIMIT       = 100
+)
+
+func InitNft() {
+	native.Contracts[utils.NftContractAddress] = RegisterNftContract
+}
+
+func RegisterNftContract(native *native.NativeService) {
+	if !Enabled(native.Height) {
+		return
+	}
+	native.Register(MINT_NAME, NftMint)
+	native.Register(TRANSFER_NAME, NftTransfer)
+	native.Register(APPROVE_NAME, NftApprove)
+	native.Register(TRANSFERFROM_NAME, NftTransferFrom)
+	native.Register(OWNEROF_NAME, NftOwnerOf)
+	native.Register(GETAPPROVED_NAME, NftGetApproved)
+	native.Register(BALANCEOF_NAME, NftBalanceOf)
+	native.Register(TOTALSUPPLY_NAME, NftTotalSupply)
+	native.Register(TOKENSOF_NAME, NftTokensOf)
+	native.Register(TOKENURI_NAME, NftTokenURI)
+	native.Register(GETTOKEN_NAME, NftGetToken)
+}
+
+//NftMint create a token with unique id, the metadata hash is optional and must be 32 bytes if provided
+func NftMint(native *native.NativeService) ([]byte, error) {
+	var param MintParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] deserialize param error:%v", err)
+	}
+	if len(param.TokenId) == 0 || len(param.TokenId) > MAX_TOKEN_ID_LEN {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] invalid token id length:%d", len(param.TokenId))
+	}
+	if len(param.URI) > MAX_URI_LEN {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] uri length:%d over max:%d", len(param.URI), MAX_URI_LEN)
+	}
+	if len(param.MetadataHash) != 0 && len(param.MetadataHash) != METADATA_HASH_LENGTH {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] invalid metadata hash length:%d", len(param.MetadataHash))
+	}
+	if param.To == common.ADDRESS_EMPTY {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] mint to empty address")
+	}
+	if !native.ContextRef.CheckWitness(param.Issuer) {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] authentication failed!")
+	}
+	exist, err := GetToken(nativeReader(native), param.TokenId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] get token error:%v", err)
+	}
+	if exist != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] token %x already exist", param.TokenId)
+	}
+	supply, err := utils.GetStorageUInt64(native, GenTotalSupplyKey())
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] get total supply error:%v", err)
+	}
+
+	token := &TokenState{
+		TokenId:      param.TokenId,
+		Issuer:       param.Issuer,
+		URI:          param.URI,
+		MetadataHash: param.MetadataHash,
+		MintHeight:   native.Height,
+	}
+	if err := addOwnerToken(native, param.To, token); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftMint] %v", err)
+	}
+	putToken(native, token)
+	putUint64(native, GenTotalSupplyKey(), supply+1)
+	AddTransferNotification(native, common.ADDRESS_EMPTY, param.To, param.TokenId)
+	return utils.BYTE_TRUE, nil
+}
+
+//NftTransfer transfer the token owned by From, signed by From
+func NftTransfer(native *native.NativeService) ([]byte, error) {
+	var param TransferParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransfer] deserialize param error:%v", err)
+	}
+	if param.To == common.ADDRESS_EMPTY {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransfer] transfer to empty address")
+	}
+	token, err := getToken(native, param.TokenId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransfer] %v", err)
+	}
+	if token.Owner != param.From {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransfer] token %x is not owned by %s", param.TokenId,
+			param.From.ToBase58())
+	}
+	if !native.ContextRef.CheckWitness(param.From) {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransfer] authentication failed!")
+	}
+	if err := transfer(native, token, param.To); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransfer] %v", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+//NftApprove allow the spender to transfer the token once, the approval is cleared after transfer
+func NftApprove(native *native.NativeService) ([]byte, error) {
+	var param ApproveParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftApprove] deserialize param error:%v", err)
+	}
+	token, err := getToken(native, param.TokenId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftApprove] %v", err)
+	}
+	if token.Owner != param.Owner {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftApprove] token %x is not owned by %s", param.TokenId,
+			param.Owner.ToBase58())
+	}
+	if !native.ContextRef.CheckWitness(param.Owner) {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftApprove] authentication failed!")
+	}
+	if param.Spender == common.ADDRESS_EMPTY {
+		native.CacheDB.Delete(GenApproveKey(param.TokenId))
+	} else {
+		native.CacheDB.Put(GenApproveKey(param.TokenId), cstates.GenRawStorageItem(param.Spender[:]))
+	}
+	pushEvent(native, []interface{}{APPROVE_NAME, param.Owner.ToBase58(), param.Spender.ToBase58(),
+		common.ToHexString(param.TokenId)})
+	return utils.BYTE_TRUE, nil
+}
+
+//NftTransferFrom transfer the token by the approved spender
+func NftTransferFrom(native *native.NativeService) ([]byte, error) {
+	var param TransferFromParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] deserialize param error:%v", err)
+	}
+	if param.To == common.ADDRESS_EMPTY {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] transfer to empty address")
+	}
+	token, err := getToken(native, param.TokenId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] %v", err)
+	}
+	if token.Owner != param.From {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] token %x is not owned by %s", param.TokenId,
+			param.From.ToBase58())
+	}
+	spender, err := getApproved(native, param.TokenId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] %v", err)
+	}
+	if spender != param.Sender {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] token %x is not approved to %s", param.TokenId,
+			param.Sender.ToBase58())
+	}
+	if !native.ContextRef.CheckWitness(param.Sender) {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] authentication failed!")
+	}
+	if err := transfer(native, token, param.To); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTransferFrom] %v", err)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func NftOwnerOf(native *native.NativeService) ([]byte, error) {
+	token, err := readToken(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftOwnerOf] %v", err)
+	}
+	return token.Owner[:], nil
+}
+
+func NftGetApproved(native *native.NativeService) ([]byte, error) {
+	tokenId, err := decodeVarBytes(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftGetApproved] read token id error:%v", err)
+	}
+	spender, err := getApproved(native, tokenId)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftGetApproved] %v", err)
+	}
+	return spender[:], nil
+}
+
+func NftBalanceOf(native *native.NativeService) ([]byte, error) {
+	owner, err := utils.DecodeAddress(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftBalanceOf] read address error:%v", err)
+	}
+	count, err := GetBalance(nativeReader(native), owner)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftBalanceOf] %v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(count))), nil
+}
+
+func NftTotalSupply(native *native.NativeService) ([]byte, error) {
+	supply, err := GetTotalSupply(nativeReader(native))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTotalSupply] %v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(supply))), nil
+}
+
+//NftTokensOf return the serialized TokenList of owner in page
+func NftTokensOf(native *native.NativeService) ([]byte, error) {
+	var param TokensOfParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTokensOf] deserialize param error:%v", err)
+	}
+	ids, err := GetTokensOf(nativeReader(native), param.Owner, param.Start, param.Limit)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTokensOf] %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	list := &TokenList{TokenIds: ids}
+	list.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+func NftTokenURI(native *native.NativeService) ([]byte, error) {
+	token, err := readToken(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftTokenURI] %v", err)
+	}
+	return []byte(token.URI), nil
+}
+
+//NftGetToken return the serialized TokenState, including the issuer and metadata hash
+func NftGetToken(native *native.NativeService) ([]byte, error) {
+	token, err := readToken(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[NftGetToken] %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	token.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//readToken read the token id from input and return the state of token
+func readToken(native *native.NativeService) (*TokenState, error) {
+	tokenId, err := decodeVarBytes(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("read token id error:%v", err)
+	}
+	return getToken(native, tokenId)
+}
+
+func getApproved(native *native.NativeService, tokenId []byte) (common.Address, error) {
+	item, err := utils.GetStorageItem(native, GenApproveKey(tokenId))
+	if err != nil || item == nil {
+		return common.ADDRESS_EMPTY, err
+	}
+	return common.AddressParseFromBytes(item.Value)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	InitNft()
+}
+
+func TestNftContract(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	invoke := func(method string, param interface{}, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(utils.NftContractAddress, method, []interface{}{param}, signer)
+		return err
+	}
+
+	issuer := account.NewAccount("")
+	alice := account.NewAccount("")
+	bob := account.NewAccount("")
+	hash := common.UINT256_EMPTY
+	hash[0] = 1
+
+	for _, id := range []string{"t1", "t2", "t3"} {
+		assert.Nil(t, invoke(MINT_NAME, &MintParam{Issuer: issuer.Address, To: alice.Address, TokenId: []byte(id),
+			URI: "https://asset/" + id, MetadataHash: hash[:]}, issuer))
+	}
+	assert.NotNil(t, invoke(MINT_NAME, &MintParam{Issuer: bob.Address, To: bob.Address, TokenId: []byte("t1")}, bob))
+	assert.NotNil(t, invoke(MINT_NAME, &MintParam{Issuer: bob.Address, To: bob.Address, TokenId: []byte("t4"),
+		MetadataHash: []byte{1}}, bob))
+
+	_, notify, err := suite.InvokeNative(utils.NftContractAddress, TRANSFER_NAME, []interface{}{&TransferParam{
+		From: alice.Address, To: bob.Address, TokenId: []byte("t1")}}, alice)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{TRANSFER_NAME, alice.Address.ToBase58(), bob.Address.ToBase58(),
+		common.ToHexString([]byte("t1"))}, notify[0].States)
+	assert.NotNil(t, invoke(TRANSFER_NAME, &TransferParam{From: alice.Address, To: bob.Address,
+		TokenId: []byte("t2")}, bob))
+	assert.Nil(t, invoke(APPROVE_NAME, &ApproveParam{Owner: alice.Address, Spender: bob.Address,
+		TokenId: []byte("t3")}, alice))
+
+	//the approval is used up by the transfer
+	assert.Nil(t, invoke(TRANSFERFROM_NAME, &TransferFromParam{Sender: bob.Address, From: alice.Address,
+		To: bob.Address, TokenId: []byte("t3")}, bob))
+	assert.NotNil(t, invoke(TRANSFERFROM_NAME, &TransferFromParam{Sender: bob.Address, From: bob.Address,
+		To: alice.Address, TokenId: []byte("t3")}, bob))
+
+	supply, err := GetTotalSupply(suite.Get)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), supply)
+
+	ids, err := GetTokensOf(suite.Get, alice.Address, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("t2")}, ids)
+	ids, err = GetTokensOf(suite.Get, bob.Address, 0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("t1"), []byte("t3")}, ids)
+	ids, err = GetTokensOf(suite.Get, bob.Address, 1, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("t3")}, ids)
+
+	token, err := GetToken(suite.Get, []byte("t2"))
+	assert.Nil(t, err)
+	assert.Equal(t, alice.Address, token.Owner)
+	assert.Equal(t, issuer.Address, token.Issuer)
+	assert.Equal(t, "https://asset/t2", token.URI)
+	assert.Equal(t, hash[:], token.MetadataHash)
+	assert.Equal(t, uint64(0), token.Index)
+}
+
+func TestNftActivation(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	height := config.NFT_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.NFT_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.NFT_HEIGHT[config.NETWORK_ID_SOLO_NET] = 2
+
+	suite := testsuite.NewNativeSuite()
+	issuer := account.NewAccount("")
+	mint := []interface{}{&MintParam{Issuer: issuer.Address, To: issuer.Address, TokenId: []byte("t1")}}
+	//no method of non-fungible token contract is registered before the activation height
+	_, _, err := suite.InvokeNative(utils.NftContractAddress, MINT_NAME, mint, issuer)
+	assert.NotNil(t, err)
+	suite.Height = 2
+	_, _, err = suite.InvokeNative(utils.NftContractAddress, MINT_NAME, mint, issuer)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"io"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//MintParam mint a token with unique TokenId to To, the Issuer is recorded in token and must sign the transaction
+type MintParam struct {
+	Issuer       common.Address
+	To           common.Address
+	TokenId      []byte
+	URI          string
+	MetadataHash []byte
+}
+
+func (this *MintParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Issuer)
+	utils.EncodeAddress(sink, this.To)
+	sink.WriteVarBytes(this.TokenId)
+	sink.WriteString(this.URI)
+	sink.WriteVarBytes(this.MetadataHash)
+}
+
+func (this *MintParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Issuer, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.To, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.TokenId, err = decodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.URI, err = decodeString(source); err != nil {
+		return err
+	}
+	this.MetadataHash, err = decodeVarBytes(source)
+	return err
+}
+
+type TransferParam struct {
+	From    common.Address
+	To      common.Address
+	TokenId []byte
+}
+
+func (this *TransferParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.From)
+	utils.EncodeAddress(sink, this.To)
+	sink.WriteVarBytes(this.TokenId)
+}
+
+func (this *TransferParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.From, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.To, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	this.TokenId, err = decodeVarBytes(source)
+	return err
+}
+
+//ApproveParam allow Spender to transfer the token once, an empty Spender clears the approval
+type ApproveParam struct {
+	Owner   common.Address
+	Spender common.Address
+	TokenId []byte
+}
+
+func (this *ApproveParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Owner)
+	utils.EncodeAddress(sink, this.Spender)
+	sink.WriteVarBytes(this.TokenId)
+}
+
+func (this *ApproveParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Owner, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Spender, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	this.TokenId, err = decodeVarBytes(source)
+	return err
+}
+
+type TransferFromParam struct {
+	Sender  common.Address
+	From    common.Address
+	To      common.Address
+	TokenId []byte
+}
+
+func (this *TransferFromParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Sender)
+	utils.EncodeAddress(sink, this.From)
+	utils.EncodeAddress(sink, this.To)
+	sink.WriteVarBytes(this.TokenId)
+}
+
+func (this *TransferFromParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Sender, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.From, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.To, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	this.TokenId, err = decodeVarBytes(source)
+	return err
+}
+
+//TokensOfParam query at most Limit tokens of Owner from the position Start
+type TokensOfParam struct {
+	Owner common.Address
+	Start uint64
+	Limit uint64
+}
+
+func (this *TokensOfParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Owner)
+	utils.EncodeVarUint(sink, this.Start)
+	utils.EncodeVarUint(sink, this.Limit)
+}
+
+func (this *TokensOfParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Owner, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Start, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	this.Limit, err = utils.DecodeVarUint(source)
+	return err
+}
+
+//TokenState is the stored state of token, Index is the position of token in the token list of owner
+type TokenState struct {
+	TokenId      []byte
+	Issuer       common.Address
+	Owner        common.Address
+	URI          string
+	MetadataHash []byte
+	MintHeight   uint32
+	Index        uint64
+}
+
+func (this *TokenState) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.TokenId)
+	sink.WriteAddress(this.Issuer)
+	sink.WriteAddress(this.Owner)
+	sink.WriteString(this.URI)
+	sink.WriteVarBytes(this.MetadataHash)
+	sink.WriteUint32(this.MintHeight)
+	sink.WriteUint64(this.Index)
+}
+
+func (this *TokenState) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.TokenId, err = decodeVarBytes(source); err != nil {
+		return err
+	}
+	var eof bool
+	if this.Issuer, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Owner, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.URI, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.MetadataHash, err = decodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.MintHeight, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Index, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//TokenList is a page of token ids returned by tokensOf
+type TokenList struct {
+	TokenIds [][]byte
+}
+
+func (this *TokenList) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.TokenIds)))
+	for _, id := range this.TokenIds {
+		sink.WriteVarBytes(id)
+	}
+}
+
+func (this *TokenList) Deserialization(source *common.ZeroCopySource) error {
+	n, _, irregular, eof := source.NextVarUint()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return common.ErrIrregularData
+	}
+	this.TokenIds = make([][]byte, 0, n)
+	for i := uint64(0); i < n; i++ {
+		id, err := decodeVarBytes(source)
+		if err != nil {
+			return err
+		}
+		this.TokenIds = append(this.TokenIds, id)
+	}
+	return nil
+}
+
+func decodeVarBytes(source *common.ZeroCopySource) ([]byte, error) {
+	data, _, irregular, eof := source.NextVarBytes()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	return data, nil
+}
+
+func decodeString(source *common.ZeroCopySource) (string, error) {
+	data, _, irregular, eof := source.NextString()
+	if eof {
+		return "", io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return "", common.ErrIrregularData
+	}
+	return data, nil
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/serialization"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+var (
+	PreToken      = []byte{0x01}
+	PreApprove    = []byte{0x02}
+	PreOwnerCount = []byte{0x03}
+	PreOwnerToken = []byte{0x04}
+	PreSupply     = []byte{0x05}
+)
+
+//Enabled return whether the non-fungible token contract can be invoked at the height
+func Enabled(height uint32) bool {
+	return height >= config.GetNftHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//StorageReader read the value of storage item by the full storage key, it returns nil if the item not exists
+type StorageReader func(key []byte) ([]byte, error)
+
+func GenTokenKey(tokenId []byte) []byte {
+	return utils.ConcatKey(utils.NftContractAddress, PreToken, tokenId)
+}
+
+func GenApproveKey(tokenId []byte) []byte {
+	return utils.ConcatKey(utils.NftContractAddress, PreApprove, tokenId)
+}
+
+func GenOwnerCountKey(owner common.Address) []byte {
+	return utils.ConcatKey(utils.NftContractAddress, PreOwnerCount, owner[:])
+}
+
+func GenOwnerTokenKey(owner common.Address, index uint64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, index)
+	return utils.ConcatKey(utils.NftContractAddress, PreOwnerToken, owner[:], buf)
+}
+
+func GenTotalSupplyKey() []byte {
+	return utils.ConcatKey(utils.NftContractAddress, PreSupply)
+}
+
+//GetToken read the state of token, it returns nil if the token not exists
+func GetToken(read StorageReader, tokenId []byte) (*TokenState, error) {
+	value, err := read(GenTokenKey(tokenId))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	token := new(TokenState)
+	if err := token.Deserialization(common.NewZeroCopySource(value)); err != nil {
+		return nil, fmt.Errorf("deserialize token state error:%v", err)
+	}
+	return token, nil
+}
+
+//GetBalance return the count of tokens owned by owner
+func GetBalance(read StorageReader, owner common.Address) (uint64, error) {
+	return readUint64(read, GenOwnerCountKey(owner))
+}
+
+//GetTotalSupply return the count of all minted tokens
+func GetTotalSupply(read StorageReader) (uint64, error) {
+	return readUint64(read, GenTotalSupplyKey())
+}
+
+//GetTokensOf return at most limit token ids of owner from the position start. The position of a token changes
+//when another token of owner is transferred out, so the pages are only consistent at the same block height
+func GetTokensOf(read StorageReader, owner common.Address, start, limit uint64) ([][]byte, error) {
+	count, err := GetBalance(read, owner)
+	if err != nil {
+		return nil, err
+	}
+	if limit == 0 || limit > MAX_PAGE_LIMIT {
+		limit = MAX_PAGE_LIMIT
+	}
+	ids := make([][]byte, 0)
+	for i := start; i < count && uint64(len(ids)) < limit; i++ {
+		id, err := read(GenOwnerTokenKey(owner, i))
+		if err != nil {
+			return nil, err
+		}
+		if id == nil {
+			return nil, fmt.Errorf("token of %s at %d not found", owner.ToBase58(), i)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func readUint64(read StorageReader, key []byte) (uint64, error) {
+	value, err := read(key)
+	if err != nil || value == nil {
+		return 0, err
+	}
+	return serialization.ReadUint64(bytes.NewBuffer(value))
+}
+
+//nativeReader read the storage in the cache of contract execution
+func nativeReader(native *native.NativeService) StorageReader {
+	return func(key []byte) ([]byte, error) {
+		item, err := utils.GetStorageItem(native, key)
+		if err != nil || item == nil {
+			return nil, err
+		}
+		return item.Value, nil
+	}
+}
+
+func getToken(native *native.NativeService, tokenId []byte) (*TokenState, error) {
+	token, err := GetToken(nativeReader(native), tokenId)
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, fmt.Errorf("token %x not exist", tokenId)
+	}
+	return token, nil
+}
+
+func putToken(native *native.NativeService, token *TokenState) {
+	sink := common.NewZeroCopySink(nil)
+	token.Serialization(sink)
+	native.CacheDB.Put(GenTokenKey(token.TokenId), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func putUint64(native *native.NativeService, key []byte, value uint64) {
+	if value == 0 {
+		native.CacheDB.Delete(key)
+	} else {
+		native.CacheDB.Put(key, utils.GenUInt64StorageItem(value).ToArray())
+	}
+}
+
+//addOwnerToken append the token to the end of token list of owner
+func addOwnerToken(native *native.NativeService, owner common.Address, token *TokenState) error {
+	count, err := utils.GetStorageUInt64(native, GenOwnerCountKey(owner))
+	if err != nil {
+		return err
+	}
+	native.CacheDB.Put(GenOwnerTokenKey(owner, count), cstates.GenRawStorageItem(token.TokenId))
+	putUint64(native, GenOwnerCountKey(owner), count+1)
+	token.Owner = owner
+	token.Index = count
+	return nil
+}
+
+//removeOwnerToken remove the token from the token list of owner, the last token is moved to fill the position
+func removeOwnerToken(native *native.NativeService, token *TokenState) error {
+	owner := token.Owner
+	count, err := utils.GetStorageUInt64(native, GenOwnerCountKey(owner))
+	if err != nil {
+		return err
+	}
+	if count == 0 || token.Index >= count {
+		return fmt.Errorf("token %x not in the list of %s", token.TokenId, owner.ToBase58())
+	}
+	last := count - 1
+	if token.Index != last {
+		item, err := utils.GetStorageItem(native, GenOwnerTokenKey(owner, last))
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return fmt.Errorf("token of %s at %d not found", owner.ToBase58(), last)
+		}
+		moved, err := getToken(native, item.Value)
+		if err != nil {
+			return err
+		}
+		moved.Index = token.Index
+		putToken(native, moved)
+		native.CacheDB.Put(GenOwnerTokenKey(owner, token.Index), cstates.GenRawStorageItem(moved.TokenId))
+	}
+	native.CacheDB.Delete(GenOwnerTokenKey(owner, last))
+	putUint64(native, GenOwnerCountKey(owner), last)
+	return nil
+}
+
+//transfer move the token to another owner and clear the approval, the caller must check the authentication
+func transfer(native *native.NativeService, token *TokenState, to common.Address) error {
+	from := token.Owner
+	if err := removeOwnerToken(native, token); err != nil {
+		return err
+	}
+	if err := addOwnerToken(native, to, token); err != nil {
+		return err
+	}
+	putToken(native, token)
+	native.CacheDB.Delete(GenApproveKey(token.TokenId))
+	AddTransferNotification(native, from, to, token.TokenId)
+	return nil
+}
+
+//AddTransferNotification push the transfer event in the same shape of ONT with the hex token id as the value, the
+//from address of mint is empty address
+func AddTransferNotification(native *native.NativeService, from, to common.Address, tokenId []byte) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	pushEvent(native, []interface{}{TRANSFER_NAME, from.ToBase58(), to.ToBase58(), common.ToHexString(tokenId)})
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
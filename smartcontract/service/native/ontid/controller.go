@@ -63,7 +63,7 @@ func regIdWithController(srvc *native.NativeService) ([]byte, error) {
 			return utils.BYTE_FALSE, err
 		}
 	} else {
-		controller, err := deserializeGroup(arg1)
+		controller, err := deserializeInputGroup(srvc, arg1)
 		if err != nil {
 			return utils.BYTE_FALSE, errors.New("deserialize controller error")
 		}
@@ -322,6 +322,19 @@ func getController(srvc *native.NativeService, encId []byte) (interface{}, error
 	}
 }
 
+//IsControlled return whether the ID is controlled by another ID or a group of IDs
+func IsControlled(srvc *native.NativeService, id []byte) (bool, error) {
+	encId, err := encodeID(id)
+	if err != nil {
+		return false, err
+	}
+	item, err := utils.GetStorageItem(srvc, append(encId, FIELD_CONTROLLER))
+	if err != nil {
+		return false, err
+	}
+	return item != nil, nil
+}
+
 func verifySingleController(srvc *native.NativeService, id []byte, args io.Reader) error {
 	// public key index
 	index, err := utils.ReadVarUint(args)
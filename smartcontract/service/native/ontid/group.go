@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/common/serialization"
 	"github.com/dnaproject2/DNA/smartcontract/service/native"
 	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
@@ -53,7 +54,7 @@ func deserializeGroup(data []byte) (*Group, error) {
 		if err != nil {
 			return nil, fmt.Errorf("error parsing group members: %s", err)
 		}
-		if bytes.Equal(m[:8], []byte("did:dna:")) {
+		if len(m) >= 8 && bytes.Equal(m[:8], []byte("did:dna:")) {
 			g.Members = append(g.Members, string(m))
 		} else {
 			// parse recursively
@@ -61,7 +62,7 @@ func deserializeGroup(data []byte) (*Group, error) {
 			if err != nil {
 				return nil, fmt.Errorf("error parsing group members: %s", err)
 			}
-			g.Members[i] = g1
+			g.Members = append(g.Members, g1)
 		}
 	}
 
@@ -76,6 +77,23 @@ func deserializeGroup(data []byte) (*Group, error) {
 	return &g, nil
 }
 
+//deserializeInputGroup parse the group in the input of contract, a group can only have group members since the
+//nested group activation height
+func deserializeInputGroup(srvc *native.NativeService, data []byte) (*Group, error) {
+	g, err := deserializeGroup(data)
+	if err != nil {
+		return nil, err
+	}
+	if srvc.Height < config.GetNestedGroupHeight(config.DefConfig.P2PNode.NetworkId) {
+		for _, m := range g.Members {
+			if _, ok := m.(*Group); ok {
+				return nil, fmt.Errorf("nested group is not supported")
+			}
+		}
+	}
+	return g, nil
+}
+
 func validateMembers(srvc *native.NativeService, g *Group) error {
 	for _, m := range g.Members {
 		switch t := m.(type) {
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontid
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	Init()
+}
+
+func TestNestedGroupRecovery(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	height := config.NESTED_GROUP_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.NESTED_GROUP_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.NESTED_GROUP_HEIGHT[config.NETWORK_ID_SOLO_NET] = 3
+
+	suite := testsuite.NewNativeSuite()
+	owner := account.NewAccount("")
+	id, err := account.GenerateID()
+	assert.Nil(t, err)
+	_, _, err = suite.InvokeNative(utils.OntIDContractAddress, "regIDWithPublicKey", []interface{}{&struct {
+		ID     []byte
+		PubKey []byte
+	}{[]byte(id), keypair.SerializePublicKey(owner.PublicKey)}}, owner)
+	assert.Nil(t, err)
+
+	//recovery group whose only member is an empty group
+	inner := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteVarUint(inner, 0))
+	assert.Nil(t, utils.WriteVarUint(inner, 0))
+	group := new(bytes.Buffer)
+	assert.Nil(t, utils.WriteVarUint(group, 1))
+	assert.Nil(t, serialization.WriteVarBytes(group, inner.Bytes()))
+	assert.Nil(t, utils.WriteVarUint(group, 1))
+	addRecovery := func() error {
+		_, _, err := suite.InvokeNative(utils.OntIDContractAddress, "addRecovery", []interface{}{&struct {
+			ID    []byte
+			Group []byte
+			Index uint64
+		}{[]byte(id), group.Bytes(), 1}}, owner)
+		return err
+	}
+	//nested group is rejected before the activation height
+	suite.Height = 2
+	assert.NotNil(t, addRecovery())
+	suite.Height = 3
+	assert.Nil(t, addRecovery())
+}
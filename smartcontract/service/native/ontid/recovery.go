@@ -211,7 +211,7 @@ func removeKeyByRecovery(srvc *native.NativeService) ([]byte, error) {
 }
 
 func setRecovery(srvc *native.NativeService, encID, data []byte) (*Group, error) {
-	rec, err := deserializeGroup(data)
+	rec, err := deserializeInputGroup(srvc, data)
 	if err != nil {
 		return nil, err
 	}
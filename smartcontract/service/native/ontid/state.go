@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ontid
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//StorageReader read the value of storage item by the full storage key, it returns nil if the item not exists
+type StorageReader func(key []byte) ([]byte, error)
+
+//PublicKeyState is a public key of ID, the index starts from 1
+type PublicKeyState struct {
+	Index   uint32
+	Key     []byte
+	Revoked bool
+}
+
+type AttributeState struct {
+	Key   []byte
+	Value []byte
+	Type  []byte
+}
+
+//IDState is the on-chain state of ID, only one of Controller and ControllerGroup is set if the ID is controlled
+type IDState struct {
+	Revoked         bool
+	PublicKeys      []*PublicKeyState
+	Attributes      []*AttributeState
+	Controller      string
+	ControllerGroup *Group
+	Recovery        *Group
+}
+
+//GetIDState read the state of ID outside of contract execution, it returns nil if the ID is not registered
+func GetIDState(read StorageReader, id []byte) (*IDState, error) {
+	encID, err := encodeID(id)
+	if err != nil {
+		return nil, err
+	}
+	flag, err := read(encID)
+	if err != nil {
+		return nil, err
+	}
+	if len(flag) == 0 {
+		return nil, nil
+	}
+	state := new(IDState)
+	switch flag[0] {
+	case flag_exist:
+	case flag_revoke:
+		state.Revoked = true
+		return state, nil
+	default:
+		return nil, fmt.Errorf("unknown ID flag %d", flag[0])
+	}
+
+	if state.PublicKeys, err = readPublicKeys(read, encID); err != nil {
+		return nil, fmt.Errorf("read public keys error, %s", err)
+	}
+	if state.Attributes, err = readAttributes(read, encID); err != nil {
+		return nil, fmt.Errorf("read attributes error, %s", err)
+	}
+	con, err := read(append(encID, FIELD_CONTROLLER))
+	if err != nil {
+		return nil, fmt.Errorf("read controller error, %s", err)
+	}
+	if len(con) >= 8 && bytes.Equal(con[:8], []byte("did:dna:")) {
+		state.Controller = string(con)
+	} else if len(con) > 0 {
+		if state.ControllerGroup, err = deserializeGroup(con); err != nil {
+			return nil, fmt.Errorf("read controller error, %s", err)
+		}
+	}
+	rec, err := read(append(encID, FIELD_RECOVERY))
+	if err != nil {
+		return nil, fmt.Errorf("read recovery error, %s", err)
+	}
+	if len(rec) > 0 {
+		if state.Recovery, err = deserializeGroup(rec); err != nil {
+			return nil, fmt.Errorf("read recovery error, %s", err)
+		}
+	}
+	return state, nil
+}
+
+func readPublicKeys(read StorageReader, encID []byte) ([]*PublicKeyState, error) {
+	val, err := read(append(encID, FIELD_PK))
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.NewBuffer(val)
+	keys := make([]*PublicKeyState, 0)
+	for buf.Len() > 0 {
+		var t owner
+		if err := t.Deserialize(buf); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &PublicKeyState{Index: uint32(len(keys) + 1), Key: t.key, Revoked: t.revoked})
+	}
+	return keys, nil
+}
+
+func readAttributes(read StorageReader, encID []byte) ([]*AttributeState, error) {
+	key := append(encID, FIELD_ATTR)
+	item, err := read(key)
+	if err != nil {
+		return nil, err
+	}
+	attrs := make([]*AttributeState, 0)
+	for len(item) > 0 {
+		raw, err := read(append(key[:len(key):len(key)], item...))
+		if err != nil {
+			return nil, err
+		} else if len(raw) == 0 {
+			return nil, fmt.Errorf("storage item not exists, %v", item)
+		}
+		node := new(utils.LinkedlistNode)
+		if err := node.Deserialize(raw); err != nil {
+			return nil, err
+		}
+		var attr attribute
+		if err := attr.SetValue(node.GetPayload()); err != nil {
+			return nil, fmt.Errorf("parse attribute failed, %s", err)
+		}
+		attrs = append(attrs, &AttributeState{Key: item, Value: attr.value, Type: attr.valueType})
+		item = node.GetNext()
+	}
+	return attrs, nil
+}
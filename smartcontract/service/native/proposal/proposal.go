@@ -0,0 +1,321 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/governance"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	//function name
+	PROPOSE_NAME     = "propose"
+	VOTE_NAME        = "vote"
+	EXECUTE_NAME     = "execute"
+	GETPROPOSAL_NAME = "getProposal"
+	GETVOTE_NAME     = "getVote"
+	GETCOUNT_NAME    = "getProposalCount"
+	GETSTATUS_NAME   = "getStatus"
+
+	//global param name of periods in seconds
+	VOTING_PERIOD_PARAM = "proposalVotingPeriod"
+	TIMELOCK_PARAM      = "proposalTimelock"
+
+	DEFAULT_VOTING_PERIOD uint32 = 7 * 24 * 3600
+	DEFAULT_TIMELOCK      uint32 = 2 * 24 * 3600
+
+	//percent of total stake needed to pass a proposal
+	PASS_RATE uint64 = 50
+
+	MAX_DESCRIPTION_LEN = 1024
+)
+
+//proposal status
+const (
+	STATUS_VOTING uint8 = iota + 1
+	STATUS_REJECTED
+	STATUS_QUEUED
+	STATUS_EXECUTABLE
+	STATUS_EXECUTED
+)
+
+//allowedMethods is the methods which can be invoked by a passed proposal
+var allowedMethods = map[common.Address][]string{
+	utils.GovernanceContractAddress: {
+		governance.UPDATE_CONFIG,
+		governance.UPDATE_GLOBAL_PARAM,
+		governance.UPDATE_GLOBAL_PARAM2,
+		governance.UPDATE_SPLIT_CURVE,
+	},
+	utils.ParamContractAddress: {
+		global_params.SET_GLOBAL_PARAM_NAME,
+		global_params.CREATE_SNAPSHOT_NAME,
+	},
+}
+
+func InitProposal() {
+	native.Contracts[utils.ProposalContractAddress] = RegisterProposalContract
+}
+
+func RegisterProposalContract(native *native.NativeService) {
+	if !Enabled(native.Height) {
+		return
+	}
+	native.Register(PROPOSE_NAME, Propose)
+	native.Register(VOTE_NAME, Vote)
+	native.Register(EXECUTE_NAME, Execute)
+	native.Register(GETPROPOSAL_NAME, GetProposal)
+	native.Register(GETVOTE_NAME, GetVote)
+	native.Register(GETCOUNT_NAME, GetProposalCount)
+	native.Register(GETSTATUS_NAME, GetStatus)
+}
+
+//Propose open a proposal by a candidate or staker and return the id of proposal
+func Propose(native *native.NativeService) ([]byte, error) {
+	var param ProposeParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] deserialize param error:%v", err)
+	}
+	if !isAllowed(param.Contract, param.Method) {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] method %s of contract %s is not allowed", param.Method,
+			param.Contract.ToHexString())
+	}
+	if len(param.Description) > MAX_DESCRIPTION_LEN {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] description is too long")
+	}
+	if !native.ContextRef.CheckWitness(param.Proposer) {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] authentication failed!")
+	}
+	stake, total, err := governance.GetAuthorizeStake(native, param.Proposer)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] get stake error:%v", err)
+	}
+	if stake == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] proposer %s is neither candidate nor staker",
+			param.Proposer.ToBase58())
+	}
+	votingPeriod, timelock, err := getPeriods(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] get periods error:%v", err)
+	}
+
+	id, err := utils.GetStorageUInt64(native, GenNextIdKey())
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] get next id error:%v", err)
+	}
+	id++
+	proposal := &Proposal{
+		Id:          id,
+		Proposer:    param.Proposer,
+		Contract:    param.Contract,
+		Method:      param.Method,
+		Params:      param.Params,
+		Description: param.Description,
+		StartTime:   native.Time,
+		EndTime:     native.Time + votingPeriod,
+		ExecuteTime: native.Time + votingPeriod + timelock,
+		TotalStake:  total,
+	}
+	pending, err := getPending(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Propose] %v", err)
+	}
+	putProposal(native, proposal)
+	putPending(native, append(pending, id))
+	native.CacheDB.Put(GenNextIdKey(), utils.GenUInt64StorageItem(id).ToArray())
+	pushEvent(native, []interface{}{PROPOSE_NAME, id, param.Proposer.ToBase58(), param.Contract.ToHexString(),
+		param.Method, proposal.EndTime, proposal.ExecuteTime})
+	return common.BigIntToNeoBytes(new(big.Int).SetUint64(id)), nil
+}
+
+//Vote vote for or against a proposal in voting period, the weight of vote is the stake of voter when voting,
+//and the stake can not be withdrawn from governance contract until the voting ends
+func Vote(native *native.NativeService) ([]byte, error) {
+	var param VoteParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] deserialize param error:%v", err)
+	}
+	proposal, err := getProposal(native, param.Id)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] %v", err)
+	}
+	if native.Time >= proposal.EndTime {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] voting of proposal %d is ended", param.Id)
+	}
+	if !native.ContextRef.CheckWitness(param.Voter) {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] authentication failed!")
+	}
+	vote, err := getVote(native, param.Id, param.Voter)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] %v", err)
+	}
+	if vote != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] %s already voted for proposal %d", param.Voter.ToBase58(),
+			param.Id)
+	}
+	stake, _, err := governance.GetAuthorizeStake(native, param.Voter)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] get stake error:%v", err)
+	}
+	if stake == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] voter %s has no stake", param.Voter.ToBase58())
+	}
+	if err := governance.LockAuthorizeStake(native, param.Voter, proposal.EndTime); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Vote] lock stake error:%v", err)
+	}
+	if param.Approve {
+		proposal.YesStake += stake
+	} else {
+		proposal.NoStake += stake
+	}
+	putProposal(native, proposal)
+	putVote(native, param.Id, param.Voter, &VoteInfo{Approve: param.Approve, Stake: stake})
+	pushEvent(native, []interface{}{VOTE_NAME, param.Id, param.Voter.ToBase58(), param.Approve, stake})
+	return utils.BYTE_TRUE, nil
+}
+
+//Execute invoke the method of a passed proposal after timelock, anyone can execute it. Due proposals are also
+//executed by ledger at the end of block, see DueProposals
+func Execute(native *native.NativeService) ([]byte, error) {
+	id, err := utils.DecodeVarUint(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] read proposal id error:%v", err)
+	}
+	proposal, err := getProposal(native, id)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] %v", err)
+	}
+	switch proposal.Status(native.Time) {
+	case STATUS_EXECUTED:
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] proposal %d is already executed", id)
+	case STATUS_VOTING:
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] proposal %d is still in voting", id)
+	case STATUS_REJECTED:
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] proposal %d is rejected", id)
+	case STATUS_QUEUED:
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] proposal %d is timelocked until %d", id,
+			proposal.ExecuteTime)
+	}
+	proposal.Executed = true
+	putProposal(native, proposal)
+	if err := removePending(native, id); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] %v", err)
+	}
+	if _, err := native.NativeCall(proposal.Contract, proposal.Method, proposal.Params); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[Execute] invoke %s of proposal %d error:%v", proposal.Method, id, err)
+	}
+	pushEvent(native, []interface{}{EXECUTE_NAME, id, proposal.Contract.ToHexString(), proposal.Method})
+	return utils.BYTE_TRUE, nil
+}
+
+//DueProposals return the ids of pending proposals which are executable at the time of native, the rejected and
+//executed ones are removed from pending list. It is called by ledger at the end of each block, which then executes
+//the returned proposals one by one
+func DueProposals(native *native.NativeService) ([]uint64, error) {
+	pending, err := getPending(native)
+	if err != nil {
+		return nil, err
+	}
+	var due, remain []uint64
+	for _, id := range pending {
+		proposal, err := getProposal(native, id)
+		if err != nil {
+			return nil, err
+		}
+		switch proposal.Status(native.Time) {
+		case STATUS_EXECUTABLE:
+			due = append(due, id)
+			remain = append(remain, id)
+		case STATUS_VOTING, STATUS_QUEUED:
+			remain = append(remain, id)
+		}
+	}
+	if len(remain) != len(pending) {
+		putPending(native, remain)
+	}
+	return due, nil
+}
+
+//DropProposal remove a proposal whose automatic execution failed from pending list, so that it is not retried
+//at every block. It can still be executed by anyone with execute method
+func DropProposal(native *native.NativeService, id uint64) error {
+	return removePending(native, id)
+}
+
+//GetProposal return the serialized proposal
+func GetProposal(native *native.NativeService) ([]byte, error) {
+	proposal, err := readProposal(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetProposal] %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	proposal.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//GetStatus return the status of proposal at current block
+func GetStatus(native *native.NativeService) ([]byte, error) {
+	proposal, err := readProposal(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetStatus] %v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(proposal.Status(native.Time)))), nil
+}
+
+//GetVote return the serialized vote of voter, or empty if voter has not voted
+func GetVote(native *native.NativeService) ([]byte, error) {
+	var param GetVoteParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetVote] deserialize param error:%v", err)
+	}
+	vote, err := getVote(native, param.Id, param.Voter)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetVote] %v", err)
+	}
+	if vote == nil {
+		return []byte{}, nil
+	}
+	sink := common.NewZeroCopySink(nil)
+	vote.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//GetProposalCount return the number of proposals, which is also the id of last proposal
+func GetProposalCount(native *native.NativeService) ([]byte, error) {
+	id, err := utils.GetStorageUInt64(native, GenNextIdKey())
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetProposalCount] get next id error:%v", err)
+	}
+	return common.BigIntToNeoBytes(new(big.Int).SetUint64(id)), nil
+}
+
+//readProposal read the proposal id from input and return the proposal
+func readProposal(native *native.NativeService) (*Proposal, error) {
+	id, err := utils.DecodeVarUint(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("read proposal id error:%v", err)
+	}
+	return getProposal(native, id)
+}
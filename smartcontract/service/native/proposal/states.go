@@ -0,0 +1,275 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"io"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//ProposeParam open a proposal which invoke Method of Contract with Params when it is passed
+type ProposeParam struct {
+	Proposer    common.Address
+	Contract    common.Address
+	Method      string
+	Params      []byte
+	Description string
+}
+
+func (this *ProposeParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Proposer)
+	utils.EncodeAddress(sink, this.Contract)
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Params)
+	sink.WriteString(this.Description)
+}
+
+func (this *ProposeParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Proposer, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Contract, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Method, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.Params, err = decodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Description, err = decodeString(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+//VoteParam vote for or against a proposal with the authorized stake of Voter
+type VoteParam struct {
+	Id      uint64
+	Voter   common.Address
+	Approve bool
+}
+
+func (this *VoteParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.Id)
+	utils.EncodeAddress(sink, this.Voter)
+	utils.EncodeVarUint(sink, boolToUint(this.Approve))
+}
+
+func (this *VoteParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Id, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Voter, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	approve, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.Approve = approve != 0
+	return nil
+}
+
+//GetVoteParam query the vote of Voter on proposal
+type GetVoteParam struct {
+	Id    uint64
+	Voter common.Address
+}
+
+func (this *GetVoteParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeVarUint(sink, this.Id)
+	utils.EncodeAddress(sink, this.Voter)
+}
+
+func (this *GetVoteParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Id, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Voter, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+//Proposal is the stored proposal. TotalStake is the total authorized stake when the proposal is opened, votes are
+//accepted before EndTime and a passed proposal can be executed from ExecuteTime
+type Proposal struct {
+	Id          uint64
+	Proposer    common.Address
+	Contract    common.Address
+	Method      string
+	Params      []byte
+	Description string
+	StartTime   uint32
+	EndTime     uint32
+	ExecuteTime uint32
+	TotalStake  uint64
+	YesStake    uint64
+	NoStake     uint64
+	Executed    bool
+}
+
+//Passed check if more than PASS_RATE percent of total stake voted for the proposal, and more stake voted for than
+//against it
+func (this *Proposal) Passed() bool {
+	return this.YesStake > this.NoStake && this.YesStake*100 > this.TotalStake*PASS_RATE
+}
+
+//Status return the status of proposal at the timestamp
+func (this *Proposal) Status(timestamp uint32) uint8 {
+	switch {
+	case this.Executed:
+		return STATUS_EXECUTED
+	case timestamp < this.EndTime:
+		return STATUS_VOTING
+	case !this.Passed():
+		return STATUS_REJECTED
+	case timestamp < this.ExecuteTime:
+		return STATUS_QUEUED
+	default:
+		return STATUS_EXECUTABLE
+	}
+}
+
+func (this *Proposal) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Id)
+	sink.WriteAddress(this.Proposer)
+	sink.WriteAddress(this.Contract)
+	sink.WriteString(this.Method)
+	sink.WriteVarBytes(this.Params)
+	sink.WriteString(this.Description)
+	sink.WriteUint32(this.StartTime)
+	sink.WriteUint32(this.EndTime)
+	sink.WriteUint32(this.ExecuteTime)
+	sink.WriteUint64(this.TotalStake)
+	sink.WriteUint64(this.YesStake)
+	sink.WriteUint64(this.NoStake)
+	sink.WriteBool(this.Executed)
+}
+
+func (this *Proposal) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	var err error
+	if this.Id, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Proposer, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Contract, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Method, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.Params, err = decodeVarBytes(source); err != nil {
+		return err
+	}
+	if this.Description, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.StartTime, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.EndTime, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.ExecuteTime, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.TotalStake, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.YesStake, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.NoStake, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Executed, err = decodeBool(source); err != nil {
+		return err
+	}
+	return nil
+}
+
+//VoteInfo is the stored vote of a voter on a proposal
+type VoteInfo struct {
+	Approve bool
+	Stake   uint64
+}
+
+func (this *VoteInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteBool(this.Approve)
+	sink.WriteUint64(this.Stake)
+}
+
+func (this *VoteInfo) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Approve, err = decodeBool(source); err != nil {
+		return err
+	}
+	var eof bool
+	if this.Stake, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+func decodeVarBytes(source *common.ZeroCopySource) ([]byte, error) {
+	data, _, irregular, eof := source.NextVarBytes()
+	if eof {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return nil, common.ErrIrregularData
+	}
+	return data, nil
+}
+
+func decodeString(source *common.ZeroCopySource) (string, error) {
+	data, err := decodeVarBytes(source)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func decodeBool(source *common.ZeroCopySource) (bool, error) {
+	b, irregular, eof := source.NextBool()
+	if eof {
+		return false, io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return false, common.ErrIrregularData
+	}
+	return b, nil
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,223 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proposal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/global_params"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+var (
+	PreNextId   = []byte{0x01}
+	PreProposal = []byte{0x02}
+	PreVote     = []byte{0x03}
+	PrePending  = []byte{0x04}
+)
+
+//Enabled return whether the proposal contract can be invoked at the height
+func Enabled(height uint32) bool {
+	return height >= config.GetProposalHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+func GenNextIdKey() []byte {
+	return utils.ConcatKey(utils.ProposalContractAddress, PreNextId)
+}
+
+func GenProposalKey(id uint64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, id)
+	return utils.ConcatKey(utils.ProposalContractAddress, PreProposal, buf)
+}
+
+func GenVoteKey(id uint64, voter common.Address) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, id)
+	return utils.ConcatKey(utils.ProposalContractAddress, PreVote, buf, voter[:])
+}
+
+func GenPendingKey() []byte {
+	return utils.ConcatKey(utils.ProposalContractAddress, PrePending)
+}
+
+//DecodeProposal decode the storage value of proposal
+func DecodeProposal(data []byte) (*Proposal, error) {
+	proposal := new(Proposal)
+	if err := proposal.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, fmt.Errorf("deserialize proposal error:%v", err)
+	}
+	return proposal, nil
+}
+
+func getProposal(native *native.NativeService, id uint64) (*Proposal, error) {
+	item, err := utils.GetStorageItem(native, GenProposalKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("get proposal error:%v", err)
+	}
+	if item == nil {
+		return nil, fmt.Errorf("proposal %d not exist", id)
+	}
+	return DecodeProposal(item.Value)
+}
+
+func putProposal(native *native.NativeService, proposal *Proposal) {
+	sink := common.NewZeroCopySink(nil)
+	proposal.Serialization(sink)
+	native.CacheDB.Put(GenProposalKey(proposal.Id), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getVote(native *native.NativeService, id uint64, voter common.Address) (*VoteInfo, error) {
+	item, err := utils.GetStorageItem(native, GenVoteKey(id, voter))
+	if err != nil {
+		return nil, fmt.Errorf("get vote error:%v", err)
+	}
+	if item == nil {
+		return nil, nil
+	}
+	vote := new(VoteInfo)
+	if err := vote.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, fmt.Errorf("deserialize vote error:%v", err)
+	}
+	return vote, nil
+}
+
+func putVote(native *native.NativeService, id uint64, voter common.Address, vote *VoteInfo) {
+	sink := common.NewZeroCopySink(nil)
+	vote.Serialization(sink)
+	native.CacheDB.Put(GenVoteKey(id, voter), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+//getPending return the ids of proposals which are neither executed nor rejected yet
+func getPending(native *native.NativeService) ([]uint64, error) {
+	item, err := utils.GetStorageItem(native, GenPendingKey())
+	if err != nil {
+		return nil, fmt.Errorf("get pending proposals error:%v", err)
+	}
+	if item == nil {
+		return nil, nil
+	}
+	source := common.NewZeroCopySource(item.Value)
+	n, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return nil, fmt.Errorf("deserialize pending proposals error:%v", err)
+	}
+	ids := make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		id, eof := source.NextUint64()
+		if eof {
+			return nil, fmt.Errorf("deserialize pending proposals error:%v", io.ErrUnexpectedEOF)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func putPending(native *native.NativeService, ids []uint64) {
+	if len(ids) == 0 {
+		native.CacheDB.Delete(GenPendingKey())
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	utils.EncodeVarUint(sink, uint64(len(ids)))
+	for _, id := range ids {
+		sink.WriteUint64(id)
+	}
+	native.CacheDB.Put(GenPendingKey(), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+//removePending remove the proposal from pending list
+func removePending(native *native.NativeService, id uint64) error {
+	ids, err := getPending(native)
+	if err != nil {
+		return err
+	}
+	for i, v := range ids {
+		if v == id {
+			putPending(native, append(ids[:i], ids[i+1:]...))
+			return nil
+		}
+	}
+	return nil
+}
+
+//isAllowed check if method of contract can be invoked by a passed proposal
+func isAllowed(contract common.Address, method string) bool {
+	for _, m := range allowedMethods[contract] {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+//getPeriods return the voting period and timelock of proposal from global params, the default values are used
+//if they are not set
+func getPeriods(native *native.NativeService) (uint32, uint32, error) {
+	nameList := global_params.ParamNameList{VOTING_PERIOD_PARAM, TIMELOCK_PARAM}
+	bf := new(bytes.Buffer)
+	if err := nameList.Serialize(bf); err != nil {
+		return 0, 0, fmt.Errorf("serialize param name list error:%v", err)
+	}
+	result, err := native.NativeCall(utils.ParamContractAddress, global_params.GET_GLOBAL_PARAM_NAME, bf.Bytes())
+	if err != nil {
+		return 0, 0, fmt.Errorf("get global param error:%v", err)
+	}
+	params := new(global_params.Params)
+	if err := params.Deserialize(bytes.NewBuffer(result.([]byte))); err != nil {
+		return 0, 0, fmt.Errorf("deserialize global params error:%v", err)
+	}
+	votingPeriod, err := parsePeriod(params, VOTING_PERIOD_PARAM, DEFAULT_VOTING_PERIOD)
+	if err != nil {
+		return 0, 0, err
+	}
+	timelock, err := parsePeriod(params, TIMELOCK_PARAM, DEFAULT_TIMELOCK)
+	if err != nil {
+		return 0, 0, err
+	}
+	return votingPeriod, timelock, nil
+}
+
+func parsePeriod(params *global_params.Params, name string, defaultValue uint32) (uint32, error) {
+	_, param := params.GetParam(name)
+	if param.Value == "" {
+		return defaultValue, nil
+	}
+	period, err := strconv.ParseUint(param.Value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid global param %s:%s", name, param.Value)
+	}
+	return uint32(period), nil
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	//function name
+	PUBLISH      = "publish"
+	GET_METADATA = "getMetadata"
+	GET_DEPLOYER = "getDeployer"
+	GET_VERSIONS = "getVersions"
+
+	//event name
+	EVENT_PUBLISH = "publish"
+
+	//limits
+	MAX_ABI_SIZE         = 64 * 1024
+	MAX_SOURCE_HASH_SIZE = 64
+)
+
+func InitRegistry() {
+	native.Contracts[utils.RegistryContractAddress] = RegisterRegistryContract
+}
+
+func RegisterRegistryContract(native *native.NativeService) {
+	if !Enabled(native.Height) {
+		return
+	}
+	native.Register(PUBLISH, Publish)
+	native.Register(GET_METADATA, GetMetadata)
+	native.Register(GET_DEPLOYER, GetDeployer)
+	native.Register(GET_VERSIONS, GetContractVersions)
+}
+
+//Publish store the abi and source hash of a contract, only the deployer of the contract can publish
+func Publish(native *native.NativeService) ([]byte, error) {
+	param := new(PublishParam)
+	if err := param.Deserialize(bytes.NewReader(native.Input)); err != nil {
+		return nil, fmt.Errorf("[publish] deserialize param failed: %v", err)
+	}
+	if len(param.Abi) == 0 || len(param.Abi) > MAX_ABI_SIZE {
+		return nil, fmt.Errorf("[publish] invalid abi size: %d", len(param.Abi))
+	}
+	if !json.Valid(param.Abi) {
+		return nil, fmt.Errorf("[publish] abi is not valid json")
+	}
+	if len(param.SourceHash) > MAX_SOURCE_HASH_SIZE {
+		return nil, fmt.Errorf("[publish] invalid source hash size: %d", len(param.SourceHash))
+	}
+
+	contract, err := native.CacheDB.GetContract(param.Contract)
+	if err != nil {
+		return nil, fmt.Errorf("[publish] get contract failed: %v", err)
+	}
+	if contract == nil {
+		return nil, fmt.Errorf("[publish] contract %s not exist", param.Contract.ToHexString())
+	}
+	deployer, err := getDeployer(native.CacheDB, param.Contract)
+	if err != nil {
+		return nil, fmt.Errorf("[publish] get deployer failed: %v", err)
+	}
+	if deployer == nil {
+		return nil, fmt.Errorf("[publish] deployer of contract %s is unknown", param.Contract.ToHexString())
+	}
+	if !native.ContextRef.CheckWitness(*deployer) {
+		return nil, fmt.Errorf("[publish] authentication failed, deployer:%s", deployer.ToBase58())
+	}
+
+	meta := &Metadata{
+		Abi:        param.Abi,
+		SourceHash: param.SourceHash,
+		Publisher:  *deployer,
+		Height:     native.Height,
+	}
+	putMetadata(native.CacheDB, param.Contract, meta)
+
+	pushEvent(native, []interface{}{EVENT_PUBLISH, param.Contract.ToHexString(), deployer.ToBase58(),
+		common.ToHexString(param.SourceHash)})
+	return utils.BYTE_TRUE, nil
+}
+
+//GetMetadata return the serialized metadata of a contract, or empty if not published
+func GetMetadata(native *native.NativeService) ([]byte, error) {
+	contract, err := utils.ReadAddress(bytes.NewReader(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("[getMetadata] read contract address failed: %v", err)
+	}
+	meta, err := GetContractMetadata(native.CacheDB, contract)
+	if err != nil {
+		return nil, fmt.Errorf("[getMetadata] %v", err)
+	}
+	if meta == nil {
+		return []byte{}, nil
+	}
+	buf := new(bytes.Buffer)
+	if err := meta.Serialize(buf); err != nil {
+		return nil, fmt.Errorf("[getMetadata] serialize metadata failed: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+//GetDeployer return the deployer address of a contract, or empty if unknown
+func GetDeployer(native *native.NativeService) ([]byte, error) {
+	contract, err := utils.ReadAddress(bytes.NewReader(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("[getDeployer] read contract address failed: %v", err)
+	}
+	deployer, err := getDeployer(native.CacheDB, contract)
+	if err != nil {
+		return nil, fmt.Errorf("[getDeployer] %v", err)
+	}
+	if deployer == nil {
+		return []byte{}, nil
+	}
+	return deployer[:], nil
+}
+
+//GetContractVersions return the serialized version history of a contract
+func GetContractVersions(native *native.NativeService) ([]byte, error) {
+	contract, err := utils.ReadAddress(bytes.NewReader(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("[getVersions] read contract address failed: %v", err)
+	}
+	versions, err := GetVersions(native.CacheDB, contract)
+	if err != nil {
+		return nil, fmt.Errorf("[getVersions] %v", err)
+	}
+	if versions == nil {
+		versions = new(ContractVersions)
+	}
+	buf := new(bytes.Buffer)
+	if err := versions.Serialize(buf); err != nil {
+		return nil, fmt.Errorf("[getVersions] serialize versions failed: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry_test
+
+import (
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	registry.InitRegistry()
+}
+
+func publish(suite *testsuite.NativeSuite, param *registry.PublishParam, signer *account.Account) error {
+	_, _, err := suite.InvokeNative(utils.RegistryContractAddress, registry.PUBLISH, []interface{}{param}, signer)
+	return err
+}
+
+func TestPublish(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	deployer := account.NewAccount("")
+	other := account.NewAccount("")
+	deploy := &payload.DeployCode{Code: []byte{0x51}, NeedStorage: true, Name: "counter"}
+	contract := deploy.Address()
+	assert.Nil(t, suite.Deploy(deploy))
+	assert.Nil(t, suite.Update(func(cache *storage.CacheDB) error {
+		registry.PutDeployer(cache, contract, deployer.Address)
+		return nil
+	}))
+
+	abi := []byte(`{"hash":"` + contract.ToHexString() + `","functions":[]}`)
+	sourceHash := []byte{1, 2, 3}
+	param := &registry.PublishParam{Contract: contract, Abi: abi, SourceHash: sourceHash}
+	assert.NotNil(t, publish(suite, param, other))
+	assert.NotNil(t, publish(suite, &registry.PublishParam{Contract: contract, Abi: []byte("{")}, deployer))
+	suite.Height = 2
+	assert.Nil(t, publish(suite, param, deployer))
+
+	value, err := suite.Get(registry.MetadataKey(contract))
+	assert.Nil(t, err)
+	meta, err := registry.DecodeMetadata(value)
+	assert.Nil(t, err)
+	assert.Equal(t, abi, meta.Abi)
+	assert.Equal(t, sourceHash, meta.SourceHash)
+	assert.Equal(t, deployer.Address, meta.Publisher)
+	assert.Equal(t, uint32(2), meta.Height)
+}
+
+func TestPublishActivation(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	config.CONTRACT_REGISTRY_HEIGHT[config.NETWORK_ID_SOLO_NET] = 3
+	defer func() { config.CONTRACT_REGISTRY_HEIGHT[config.NETWORK_ID_SOLO_NET] = 0 }()
+
+	suite := testsuite.NewNativeSuite()
+	deployer := account.NewAccount("")
+	deploy := &payload.DeployCode{Code: []byte{0x51}}
+	contract := deploy.Address()
+	assert.Nil(t, suite.Deploy(deploy))
+	assert.Nil(t, suite.Update(func(cache *storage.CacheDB) error {
+		registry.PutDeployer(cache, contract, deployer.Address)
+		return nil
+	}))
+
+	param := &registry.PublishParam{Contract: contract, Abi: []byte(`{"functions":[]}`)}
+	suite.Height = 2
+	assert.NotNil(t, publish(suite, param, deployer))
+	suite.Height = 3
+	assert.Nil(t, publish(suite, param, deployer))
+}
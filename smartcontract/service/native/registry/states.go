@@ -0,0 +1,175 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"io"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+type PublishParam struct {
+	Contract   common.Address
+	Abi        []byte
+	SourceHash []byte
+}
+
+func (this *PublishParam) Serialize(w io.Writer) error {
+	if err := utils.WriteAddress(w, this.Contract); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.Abi); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.SourceHash); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *PublishParam) Deserialize(r io.Reader) error {
+	var err error
+	if this.Contract, err = utils.ReadAddress(r); err != nil {
+		return err
+	}
+	if this.Abi, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.SourceHash, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+//Metadata is the published information of a contract
+type Metadata struct {
+	Abi        []byte
+	SourceHash []byte
+	Publisher  common.Address
+	Height     uint32
+}
+
+func (this *Metadata) Serialize(w io.Writer) error {
+	if err := serialization.WriteVarBytes(w, this.Abi); err != nil {
+		return err
+	}
+	if err := serialization.WriteVarBytes(w, this.SourceHash); err != nil {
+		return err
+	}
+	if err := utils.WriteAddress(w, this.Publisher); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, this.Height); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *Metadata) Deserialize(r io.Reader) error {
+	var err error
+	if this.Abi, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.SourceHash, err = serialization.ReadVarBytes(r); err != nil {
+		return err
+	}
+	if this.Publisher, err = utils.ReadAddress(r); err != nil {
+		return err
+	}
+	if this.Height, err = serialization.ReadUint32(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+//ContractVersion is a record of the code deployed or upgraded at a contract address
+type ContractVersion struct {
+	Version  string
+	CodeHash common.Address
+	Height   uint32
+	TxHash   common.Uint256
+}
+
+func (this *ContractVersion) Serialize(w io.Writer) error {
+	if err := serialization.WriteString(w, this.Version); err != nil {
+		return err
+	}
+	if err := utils.WriteAddress(w, this.CodeHash); err != nil {
+		return err
+	}
+	if err := serialization.WriteUint32(w, this.Height); err != nil {
+		return err
+	}
+	if err := this.TxHash.Serialize(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (this *ContractVersion) Deserialize(r io.Reader) error {
+	var err error
+	if this.Version, err = serialization.ReadString(r); err != nil {
+		return err
+	}
+	if this.CodeHash, err = utils.ReadAddress(r); err != nil {
+		return err
+	}
+	if this.Height, err = serialization.ReadUint32(r); err != nil {
+		return err
+	}
+	if err = this.TxHash.Deserialize(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+//ContractVersions is the version history of a contract, from the first deployment to the latest upgrade
+type ContractVersions struct {
+	Versions []*ContractVersion
+}
+
+func (this *ContractVersions) Serialize(w io.Writer) error {
+	if err := utils.WriteVarUint(w, uint64(len(this.Versions))); err != nil {
+		return err
+	}
+	for _, version := range this.Versions {
+		if err := version.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *ContractVersions) Deserialize(r io.Reader) error {
+	n, err := utils.ReadVarUint(r)
+	if err != nil {
+		return err
+	}
+	this.Versions = make([]*ContractVersion, 0, n)
+	for i := uint64(0); i < n; i++ {
+		version := new(ContractVersion)
+		if err := version.Deserialize(r); err != nil {
+			return err
+		}
+		this.Versions = append(this.Versions, version)
+	}
+	return nil
+}
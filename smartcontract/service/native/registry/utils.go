@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+)
+
+var (
+	PreDeployer = []byte{0x01}
+	PreMetadata = []byte{0x02}
+	PreVersions = []byte{0x03}
+)
+
+func DeployerKey(contract common.Address) []byte {
+	return utils.ConcatKey(utils.RegistryContractAddress, PreDeployer, contract[:])
+}
+
+func MetadataKey(contract common.Address) []byte {
+	return utils.ConcatKey(utils.RegistryContractAddress, MetadataSubKey(contract))
+}
+
+//MetadataSubKey return the metadata key without the registry contract address prefix
+func MetadataSubKey(contract common.Address) []byte {
+	return append(PreMetadata[:len(PreMetadata):len(PreMetadata)], contract[:]...)
+}
+
+func VersionsKey(contract common.Address) []byte {
+	return utils.ConcatKey(utils.RegistryContractAddress, VersionsSubKey(contract))
+}
+
+//VersionsSubKey return the version history key without the registry contract address prefix
+func VersionsSubKey(contract common.Address) []byte {
+	return append(PreVersions[:len(PreVersions):len(PreVersions)], contract[:]...)
+}
+
+//NewContractVersion return the version record of the code deployed by transaction
+func NewContractVersion(deploy *payload.DeployCode, height uint32, txHash common.Uint256) *ContractVersion {
+	return &ContractVersion{
+		Version:  deploy.Version,
+		CodeHash: common.AddressFromVmCode(deploy.Code),
+		Height:   height,
+		TxHash:   txHash,
+	}
+}
+
+//Enabled return whether deployments are recorded and the registry contract can be invoked at the height
+func Enabled(height uint32) bool {
+	return height >= config.GetContractRegistryHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//ClaimDeployer record the deployer of a contract deployed before the registry was enabled
+func ClaimDeployer(cache *storage.CacheDB, contract, deployer common.Address) error {
+	old, err := getDeployer(cache, contract)
+	if err != nil {
+		return err
+	}
+	if old != nil {
+		return fmt.Errorf("deployer of contract %s is already recorded", contract.ToHexString())
+	}
+	PutDeployer(cache, contract, deployer)
+	return nil
+}
+
+//PutDeployer record the deployer of a contract, it is called when the contract is deployed
+func PutDeployer(cache *storage.CacheDB, contract, deployer common.Address) {
+	cache.Put(DeployerKey(contract), cstates.GenRawStorageItem(deployer[:]))
+}
+
+//RecordDeploy record the deployer and the first version of a newly deployed contract
+func RecordDeploy(cache *storage.CacheDB, contract, deployer common.Address, version *ContractVersion) error {
+	PutDeployer(cache, contract, deployer)
+	return AddVersion(cache, contract, version)
+}
+
+//MigrateContract move the deployer and drop the metadata and version history of a migrated contract, the history
+//of new contract starts from the migration
+func MigrateContract(cache *storage.CacheDB, oldContract, newContract common.Address, version *ContractVersion) error {
+	deployer, err := getDeployer(cache, oldContract)
+	if err != nil {
+		return err
+	}
+	DeleteContract(cache, oldContract)
+	if deployer != nil {
+		PutDeployer(cache, newContract, *deployer)
+	}
+	return AddVersion(cache, newContract, version)
+}
+
+//DeleteContract remove the deployer, metadata and version history of a destroyed contract
+func DeleteContract(cache *storage.CacheDB, contract common.Address) {
+	cache.Delete(DeployerKey(contract))
+	cache.Delete(MetadataKey(contract))
+	cache.Delete(VersionsKey(contract))
+}
+
+//AddVersion append the version to the version history of contract
+func AddVersion(cache *storage.CacheDB, contract common.Address, version *ContractVersion) error {
+	versions, err := GetVersions(cache, contract)
+	if err != nil {
+		return err
+	}
+	if versions == nil {
+		versions = new(ContractVersions)
+	}
+	versions.Versions = append(versions.Versions, version)
+	buf := new(bytes.Buffer)
+	if err := versions.Serialize(buf); err != nil {
+		return err
+	}
+	cache.Put(VersionsKey(contract), cstates.GenRawStorageItem(buf.Bytes()))
+	return nil
+}
+
+//GetVersions return the version history of contract, or nil if not recorded
+func GetVersions(cache *storage.CacheDB, contract common.Address) (*ContractVersions, error) {
+	raw, err := cache.Get(VersionsKey(contract))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeVersions(value)
+}
+
+//DecodeVersions decode version history from the value of storage item
+func DecodeVersions(value []byte) (*ContractVersions, error) {
+	versions := new(ContractVersions)
+	if err := versions.Deserialize(bytes.NewReader(value)); err != nil {
+		return nil, fmt.Errorf("deserialize versions failed: %v", err)
+	}
+	return versions, nil
+}
+
+func getDeployer(cache *storage.CacheDB, contract common.Address) (*common.Address, error) {
+	raw, err := cache.Get(DeployerKey(contract))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	deployer, err := common.AddressParseFromBytes(value)
+	if err != nil {
+		return nil, err
+	}
+	return &deployer, nil
+}
+
+func putMetadata(cache *storage.CacheDB, contract common.Address, meta *Metadata) {
+	buf := new(bytes.Buffer)
+	meta.Serialize(buf)
+	cache.Put(MetadataKey(contract), cstates.GenRawStorageItem(buf.Bytes()))
+}
+
+//GetContractMetadata return the published metadata of a contract, or nil if not published
+func GetContractMetadata(cache *storage.CacheDB, contract common.Address) (*Metadata, error) {
+	raw, err := cache.Get(MetadataKey(contract))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	value, err := cstates.GetValueFromRawStorageItem(raw)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeMetadata(value)
+}
+
+//DecodeMetadata decode metadata from the value of storage item
+func DecodeMetadata(value []byte) (*Metadata, error) {
+	meta := new(Metadata)
+	if err := meta.Deserialize(bytes.NewReader(value)); err != nil {
+		return nil, fmt.Errorf("deserialize metadata failed: %v", err)
+	}
+	return meta, nil
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
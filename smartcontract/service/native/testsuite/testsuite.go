@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+//Package testsuite invokes contracts on a state in memory for the unit tests of native contracts, without building
+//blocks of a ledger
+package testsuite
+
+import (
+	"math"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/core/payload"
+	"github.com/dnaproject2/DNA/core/states"
+	scom "github.com/dnaproject2/DNA/core/store/common"
+	"github.com/dnaproject2/DNA/core/store/memorystore"
+	"github.com/dnaproject2/DNA/core/store/overlaydb"
+	"github.com/dnaproject2/DNA/core/types"
+	cutils "github.com/dnaproject2/DNA/core/utils"
+	"github.com/dnaproject2/DNA/smartcontract"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	vmtypes "github.com/dnaproject2/DNA/vm/neovm/types"
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+//NativeSuite is a state in memory, on which code is invoked the same way as a transaction in block.
+//Native contracts invoked must be registered by the test
+type NativeSuite struct {
+	Height  uint32 //block height of invocation
+	Time    uint32 //block time of invocation
+	overlay *overlaydb.OverlayDB
+}
+
+//NewNativeSuite return a NativeSuite with empty state
+func NewNativeSuite() *NativeSuite {
+	return &NativeSuite{
+		Height:  1,
+		Time:    1,
+		overlay: overlaydb.NewOverlayDB(memorystore.NewMemoryStore()),
+	}
+}
+
+//Invoke execute the code in transaction signed by signers. The state is changed only when the execution succeeds
+func (this *NativeSuite) Invoke(code []byte, signers ...*account.Account) (interface{}, []*event.NotifyEventInfo, error) {
+	tx, err := newTx(code, signers)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache := storage.NewCacheDB(this.overlay)
+	sc := &smartcontract.SmartContract{
+		Config: &smartcontract.Config{
+			Time:   this.Time,
+			Height: this.Height,
+			Tx:     tx,
+		},
+		CacheDB: cache,
+		Gas:     math.MaxUint64,
+	}
+	engine, err := sc.NewExecuteEngine(code)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := engine.Invoke()
+	if err != nil {
+		return nil, nil, err
+	}
+	cache.Commit()
+	return result, sc.Notifications, nil
+}
+
+//InvokeNative invoke the method of native contract with params in transaction signed by signers, and return the
+//byte array result
+func (this *NativeSuite) InvokeNative(contract common.Address, method string, params []interface{},
+	signers ...*account.Account) ([]byte, []*event.NotifyEventInfo, error) {
+	code, err := cutils.BuildNativeInvokeCode(contract, 0, method, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, notify, err := this.Invoke(code, signers...)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := result.(vmtypes.StackItems).GetByteArray()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, notify, nil
+}
+
+//Update change the state by f, the state is changed only when f returns nil
+func (this *NativeSuite) Update(f func(cache *storage.CacheDB) error) error {
+	cache := storage.NewCacheDB(this.overlay)
+	err := f(cache)
+	if err != nil {
+		return err
+	}
+	cache.Commit()
+	return nil
+}
+
+//Deploy put the contract into state
+func (this *NativeSuite) Deploy(contract *payload.DeployCode) error {
+	return this.Update(func(cache *storage.CacheDB) error {
+		return cache.PutContract(contract)
+	})
+}
+
+//Get return the value of storage item of key, nil if not exist
+func (this *NativeSuite) Get(key []byte) ([]byte, error) {
+	raw, err := storage.NewCacheDB(this.overlay).Get(key)
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	return states.GetValueFromRawStorageItem(raw)
+}
+
+//NewIterator return the iterator of storage with key prefix
+func (this *NativeSuite) NewIterator(prefix []byte) scom.StoreIterator {
+	return storage.NewCacheDB(this.overlay).NewIterator(prefix)
+}
+
+//newTx return the invoke transaction of code, whose signature addresses are signers. The signature data is a
+//placeholder, since it is not verified in execution
+func newTx(code []byte, signers []*account.Account) (*types.Transaction, error) {
+	mutable := &types.MutableTransaction{
+		TxType:  types.Invoke,
+		Payload: &payload.InvokeCode{Code: code},
+	}
+	for _, signer := range signers {
+		mutable.Sigs = append(mutable.Sigs, types.Sig{PubKeys: []keypair.PublicKey{signer.PublicKey}, M: 1,
+			SigData: [][]byte{{0}}})
+	}
+	if len(signers) != 0 {
+		mutable.Payer = signers[0].Address
+	}
+	return mutable.IntoImmutable()
+}
+
+//UseSoloNet switch the network to solo, whose activation heights are set by tests. It returns the function to
+//switch back
+func UseSoloNet() func() {
+	networkId := config.DefConfig.P2PNode.NetworkId
+	config.DefConfig.P2PNode.NetworkId = config.NETWORK_ID_SOLO_NET
+	return func() {
+		config.DefConfig.P2PNode.NetworkId = networkId
+	}
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"io"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//CreateParam create a token with the total supply owned by Owner, who is also the mint and burn authority
+type CreateParam struct {
+	Symbol      string
+	Name        string
+	Decimals    uint64
+	TotalSupply uint64
+	Owner       common.Address
+	Freezable   bool
+}
+
+func (this *CreateParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	sink.WriteString(this.Name)
+	utils.EncodeVarUint(sink, this.Decimals)
+	utils.EncodeVarUint(sink, this.TotalSupply)
+	utils.EncodeAddress(sink, this.Owner)
+	utils.EncodeVarUint(sink, boolToUint(this.Freezable))
+}
+
+func (this *CreateParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.Name, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.Decimals, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.TotalSupply, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Owner, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	freezable, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.Freezable = freezable != 0
+	return nil
+}
+
+type MintParam struct {
+	Symbol string
+	To     common.Address
+	Value  uint64
+}
+
+func (this *MintParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	utils.EncodeAddress(sink, this.To)
+	utils.EncodeVarUint(sink, this.Value)
+}
+
+func (this *MintParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.To, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	this.Value, err = utils.DecodeVarUint(source)
+	return err
+}
+
+//BurnParam burn the token from the balance of authority
+type BurnParam struct {
+	Symbol string
+	Value  uint64
+}
+
+func (this *BurnParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	utils.EncodeVarUint(sink, this.Value)
+}
+
+func (this *BurnParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	this.Value, err = utils.DecodeVarUint(source)
+	return err
+}
+
+//TransferParam is the symbol followed by the same param of ont transfer
+type TransferParam struct {
+	Symbol string
+	States []ont.State
+}
+
+func (this *TransferParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	transfers := ont.Transfers{States: this.States}
+	transfers.Serialization(sink)
+}
+
+func (this *TransferParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	var transfers ont.Transfers
+	if err = transfers.Deserialization(source); err != nil {
+		return err
+	}
+	this.States = transfers.States
+	return nil
+}
+
+//ApproveParam is the symbol followed by the same param of ont approve
+type ApproveParam struct {
+	Symbol string
+	State  ont.State
+}
+
+func (this *ApproveParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	this.State.Serialization(sink)
+}
+
+func (this *ApproveParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	return this.State.Deserialization(source)
+}
+
+//TransferFromParam is the symbol followed by the same param of ont transferFrom
+type TransferFromParam struct {
+	Symbol string
+	State  ont.TransferFrom
+}
+
+func (this *TransferFromParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	this.State.Serialization(sink)
+}
+
+func (this *TransferFromParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	return this.State.Deserialization(source)
+}
+
+//AllowanceParam query the amount of token approved by From to To
+type AllowanceParam struct {
+	Symbol string
+	From   common.Address
+	To     common.Address
+}
+
+func (this *AllowanceParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	utils.EncodeAddress(sink, this.From)
+	utils.EncodeAddress(sink, this.To)
+}
+
+func (this *AllowanceParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.From, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	this.To, err = utils.DecodeAddress(source)
+	return err
+}
+
+//AccountParam is the param of the methods about an account of token, such as freeze and balanceOf
+type AccountParam struct {
+	Symbol  string
+	Account common.Address
+}
+
+func (this *AccountParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	utils.EncodeAddress(sink, this.Account)
+}
+
+func (this *AccountParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	this.Account, err = utils.DecodeAddress(source)
+	return err
+}
+
+//TokenInfo is the stored information of a token, the total supply is stored separately
+type TokenInfo struct {
+	Symbol    string
+	Name      string
+	Decimals  uint64
+	Authority common.Address
+	Freezable bool
+}
+
+func (this *TokenInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.Symbol)
+	sink.WriteString(this.Name)
+	sink.WriteUint64(this.Decimals)
+	sink.WriteAddress(this.Authority)
+	sink.WriteBool(this.Freezable)
+}
+
+func (this *TokenInfo) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Symbol, err = decodeString(source); err != nil {
+		return err
+	}
+	if this.Name, err = decodeString(source); err != nil {
+		return err
+	}
+	var irregular, eof bool
+	if this.Decimals, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Authority, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Freezable, irregular, eof = source.NextBool()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return common.ErrIrregularData
+	}
+	return nil
+}
+
+func decodeString(source *common.ZeroCopySource) (string, error) {
+	data, _, irregular, eof := source.NextString()
+	if eof {
+		return "", io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return "", common.ErrIrregularData
+	}
+	return data, nil
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
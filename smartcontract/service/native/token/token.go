@@ -0,0 +1,419 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	//function name
+	CREATE_NAME       = "create"
+	TRANSFER_NAME     = "transfer"
+	APPROVE_NAME      = "approve"
+	TRANSFERFROM_NAME = "transferFrom"
+	MINT_NAME         = "mint"
+	BURN_NAME         = "burn"
+	FREEZE_NAME       = "freeze"
+	UNFREEZE_NAME     = "unfreeze"
+	SETAUTHORITY_NAME = "setAuthority"
+	NAME_NAME         = "name"
+	DECIMALS_NAME     = "decimals"
+	TOTALSUPPLY_NAME  = "totalSupply"
+	BALANCEOF_NAME    = "balanceOf"
+	ALLOWANCE_NAME    = "allowance"
+	ISFROZEN_NAME     = "isFrozen"
+	GETTOKENINFO_NAME = "getTokenInfo"
+
+	//limits
+	MAX_SYMBOL_LEN   = 16
+	MAX_NAME_LEN     = 64
+	MAX_DECIMALS     = 18
+	MAX_TOTAL_SUPPLY = math.MaxInt64
+)
+
+func InitToken() {
+	native.Contracts[utils.TokenContractAddress] = RegisterTokenContract
+}
+
+func RegisterTokenContract(native *native.NativeService) {
+	if !Enabled(native.Height) {
+		return
+	}
+	native.Register(CREATE_NAME, TokenCreate)
+	native.Register(TRANSFER_NAME, TokenTransfer)
+	native.Register(APPROVE_NAME, TokenApprove)
+	native.Register(TRANSFERFROM_NAME, TokenTransferFrom)
+	native.Register(MINT_NAME, TokenMint)
+	native.Register(BURN_NAME, TokenBurn)
+	native.Register(FREEZE_NAME, TokenFreeze)
+	native.Register(UNFREEZE_NAME, TokenUnfreeze)
+	native.Register(SETAUTHORITY_NAME, TokenSetAuthority)
+	native.Register(NAME_NAME, TokenName)
+	native.Register(DECIMALS_NAME, TokenDecimals)
+	native.Register(TOTALSUPPLY_NAME, TokenTotalSupply)
+	native.Register(BALANCEOF_NAME, TokenBalanceOf)
+	native.Register(ALLOWANCE_NAME, TokenAllowance)
+	native.Register(ISFROZEN_NAME, TokenIsFrozen)
+	native.Register(GETTOKENINFO_NAME, GetTokenInfo)
+}
+
+//TokenCreate create a new token, the symbol is unique and consists of upper case letters and digits
+func TokenCreate(native *native.NativeService) ([]byte, error) {
+	var param CreateParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] deserialize param error:%v", err)
+	}
+	if err := checkSymbol(param.Symbol); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] %v", err)
+	}
+	if len(param.Name) == 0 || len(param.Name) > MAX_NAME_LEN {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] invalid name length:%d", len(param.Name))
+	}
+	if param.Decimals > MAX_DECIMALS {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] decimals:%d over max:%d", param.Decimals, MAX_DECIMALS)
+	}
+	if param.TotalSupply > MAX_TOTAL_SUPPLY {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] total supply:%d over max:%d", param.TotalSupply,
+			uint64(MAX_TOTAL_SUPPLY))
+	}
+	if !native.ContextRef.CheckWitness(param.Owner) {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] authentication failed!")
+	}
+	item, err := utils.GetStorageItem(native, GenInfoKey(param.Symbol))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] get token info error:%v", err)
+	}
+	if item != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenCreate] token %s already exist", param.Symbol)
+	}
+
+	putTokenInfo(native, &TokenInfo{
+		Symbol:    param.Symbol,
+		Name:      param.Name,
+		Decimals:  param.Decimals,
+		Authority: param.Owner,
+		Freezable: param.Freezable,
+	})
+	native.CacheDB.Put(GenTotalSupplyKey(param.Symbol), utils.GenUInt64StorageItem(param.TotalSupply).ToArray())
+	if param.TotalSupply > 0 {
+		native.CacheDB.Put(GenBalanceKey(param.Symbol, param.Owner),
+			utils.GenUInt64StorageItem(param.TotalSupply).ToArray())
+		AddTransferNotification(native, param.Symbol, common.ADDRESS_EMPTY, param.Owner, param.TotalSupply)
+	}
+	pushEvent(native, []interface{}{CREATE_NAME, param.Symbol, param.Owner.ToBase58(), param.TotalSupply})
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenTransfer transfer token with the same param of ont transfer after the symbol
+func TokenTransfer(native *native.NativeService) ([]byte, error) {
+	var param TransferParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransfer] Transfers deserialize error:%v", err)
+	}
+	info, err := getTokenInfo(native, param.Symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransfer] %v", err)
+	}
+	for _, v := range param.States {
+		if v.Value == 0 {
+			continue
+		}
+		if v.Value > MAX_TOTAL_SUPPLY {
+			return utils.BYTE_FALSE, fmt.Errorf("[TokenTransfer] transfer amount:%d over max supply", v.Value)
+		}
+		if !native.ContextRef.CheckWitness(v.From) {
+			return utils.BYTE_FALSE, fmt.Errorf("[TokenTransfer] authentication failed!")
+		}
+		if err := transfer(native, info, v.From, v.To, v.Value); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("[TokenTransfer] %v", err)
+		}
+		AddTransferNotification(native, param.Symbol, v.From, v.To, v.Value)
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenTransferFrom transfer the approved token with the same param of ont transferFrom after the symbol
+func TokenTransferFrom(native *native.NativeService) ([]byte, error) {
+	var param TransferFromParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] State deserialize error:%v", err)
+	}
+	symbol, state := param.Symbol, param.State
+	if state.Value == 0 {
+		return utils.BYTE_FALSE, nil
+	}
+	if state.Value > MAX_TOTAL_SUPPLY {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] transferFrom amount:%d over max supply", state.Value)
+	}
+	info, err := getTokenInfo(native, symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] %v", err)
+	}
+	if !native.ContextRef.CheckWitness(state.Sender) {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] authentication failed!")
+	}
+	if err := checkNotFrozen(native, info, state.Sender); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] %v", err)
+	}
+	if err := subBalance(native, GenApproveKey(symbol, state.From, state.Sender), state.Value); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] approve %v", err)
+	}
+	if err := transfer(native, info, state.From, state.To, state.Value); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTransferFrom] %v", err)
+	}
+	AddTransferNotification(native, symbol, state.From, state.To, state.Value)
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenApprove approve token with the same param of ont approve after the symbol
+func TokenApprove(native *native.NativeService) ([]byte, error) {
+	var param ApproveParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenApprove] state deserialize error:%v", err)
+	}
+	symbol, state := param.Symbol, param.State
+	if state.Value > MAX_TOTAL_SUPPLY {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenApprove] approve amount:%d over max supply", state.Value)
+	}
+	info, err := getTokenInfo(native, symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenApprove] %v", err)
+	}
+	if !native.ContextRef.CheckWitness(state.From) {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenApprove] authentication failed!")
+	}
+	if err := checkNotFrozen(native, info, state.From); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenApprove] %v", err)
+	}
+	native.CacheDB.Put(GenApproveKey(symbol, state.From, state.To), utils.GenUInt64StorageItem(state.Value).ToArray())
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenMint issue new token to the account, only the authority can mint
+func TokenMint(native *native.NativeService) ([]byte, error) {
+	var param MintParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] deserialize param error:%v", err)
+	}
+	info, err := getTokenInfo(native, param.Symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] %v", err)
+	}
+	if err := checkAuthority(native, info); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] %v", err)
+	}
+	if err := checkNotFrozen(native, info, param.To); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] %v", err)
+	}
+	supply, err := utils.GetStorageUInt64(native, GenTotalSupplyKey(param.Symbol))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] get total supply error:%v", err)
+	}
+	supply, overflow := common.SafeAdd(supply, param.Value)
+	if overflow || supply > MAX_TOTAL_SUPPLY {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] total supply over max supply")
+	}
+	if err := addBalance(native, GenBalanceKey(param.Symbol, param.To), param.Value); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenMint] %v", err)
+	}
+	native.CacheDB.Put(GenTotalSupplyKey(param.Symbol), utils.GenUInt64StorageItem(supply).ToArray())
+	AddTransferNotification(native, param.Symbol, common.ADDRESS_EMPTY, param.To, param.Value)
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenBurn destroy token from the balance of authority, only the authority can burn
+func TokenBurn(native *native.NativeService) ([]byte, error) {
+	var param BurnParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] deserialize param error:%v", err)
+	}
+	info, err := getTokenInfo(native, param.Symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] %v", err)
+	}
+	if err := checkAuthority(native, info); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] %v", err)
+	}
+	if err := subBalance(native, GenBalanceKey(param.Symbol, info.Authority), param.Value); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] %v", err)
+	}
+	if err := subBalance(native, GenTotalSupplyKey(param.Symbol), param.Value); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBurn] total supply %v", err)
+	}
+	AddTransferNotification(native, param.Symbol, info.Authority, common.ADDRESS_EMPTY, param.Value)
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenFreeze forbid the account to send or receive token, only for freezable token
+func TokenFreeze(native *native.NativeService) ([]byte, error) {
+	return setFrozen(native, FREEZE_NAME, true)
+}
+
+func TokenUnfreeze(native *native.NativeService) ([]byte, error) {
+	return setFrozen(native, UNFREEZE_NAME, false)
+}
+
+func setFrozen(native *native.NativeService, method string, frozen bool) ([]byte, error) {
+	var param AccountParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[%s] deserialize param error:%v", method, err)
+	}
+	info, err := getTokenInfo(native, param.Symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[%s] %v", method, err)
+	}
+	if !info.Freezable {
+		return utils.BYTE_FALSE, fmt.Errorf("[%s] token %s is not freezable", method, param.Symbol)
+	}
+	if err := checkAuthority(native, info); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[%s] %v", method, err)
+	}
+	if frozen {
+		native.CacheDB.Put(GenFreezeKey(param.Symbol, param.Account), utils.GenUInt32StorageItem(native.Height).ToArray())
+	} else {
+		native.CacheDB.Delete(GenFreezeKey(param.Symbol, param.Account))
+	}
+	pushEvent(native, []interface{}{method, param.Symbol, param.Account.ToBase58()})
+	return utils.BYTE_TRUE, nil
+}
+
+//TokenSetAuthority hand over the mint, burn and freeze authority to another account
+func TokenSetAuthority(native *native.NativeService) ([]byte, error) {
+	var param AccountParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenSetAuthority] deserialize param error:%v", err)
+	}
+	info, err := getTokenInfo(native, param.Symbol)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenSetAuthority] %v", err)
+	}
+	if err := checkAuthority(native, info); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenSetAuthority] %v", err)
+	}
+	info.Authority = param.Account
+	putTokenInfo(native, info)
+	pushEvent(native, []interface{}{SETAUTHORITY_NAME, param.Symbol, param.Account.ToBase58()})
+	return utils.BYTE_TRUE, nil
+}
+
+func TokenName(native *native.NativeService) ([]byte, error) {
+	info, err := readTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenName] %v", err)
+	}
+	return []byte(info.Name), nil
+}
+
+func TokenDecimals(native *native.NativeService) ([]byte, error) {
+	info, err := readTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenDecimals] %v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(info.Decimals))), nil
+}
+
+func TokenTotalSupply(native *native.NativeService) ([]byte, error) {
+	info, err := readTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTotalSupply] %v", err)
+	}
+	amount, err := utils.GetStorageUInt64(native, GenTotalSupplyKey(info.Symbol))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenTotalSupply] get totalSupply error:%v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(amount))), nil
+}
+
+func TokenBalanceOf(native *native.NativeService) ([]byte, error) {
+	var param AccountParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBalanceOf] deserialize param error:%v", err)
+	}
+	amount, err := utils.GetStorageUInt64(native, GenBalanceKey(param.Symbol, param.Account))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenBalanceOf] get balance error:%v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(amount))), nil
+}
+
+func TokenAllowance(native *native.NativeService) ([]byte, error) {
+	var param AllowanceParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenAllowance] deserialize param error:%v", err)
+	}
+	amount, err := utils.GetStorageUInt64(native, GenApproveKey(param.Symbol, param.From, param.To))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenAllowance] get allowance error:%v", err)
+	}
+	return common.BigIntToNeoBytes(big.NewInt(int64(amount))), nil
+}
+
+func TokenIsFrozen(native *native.NativeService) ([]byte, error) {
+	var param AccountParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenIsFrozen] deserialize param error:%v", err)
+	}
+	frozen, err := isFrozen(native, param.Symbol, param.Account)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[TokenIsFrozen] %v", err)
+	}
+	if frozen {
+		return utils.BYTE_TRUE, nil
+	}
+	return utils.BYTE_FALSE, nil
+}
+
+//GetTokenInfo return the serialized information of token
+func GetTokenInfo(native *native.NativeService) ([]byte, error) {
+	info, err := readTokenInfo(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetTokenInfo] %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//readTokenInfo read the symbol from input and return the information of token
+func readTokenInfo(native *native.NativeService) (*TokenInfo, error) {
+	symbol, err := decodeString(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("read symbol error:%v", err)
+	}
+	return getTokenInfo(native, symbol)
+}
+
+func checkSymbol(symbol string) error {
+	if len(symbol) == 0 || len(symbol) > MAX_SYMBOL_LEN {
+		return fmt.Errorf("invalid symbol length:%d", len(symbol))
+	}
+	for _, c := range symbol {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return fmt.Errorf("invalid symbol %s, only upper case letters and digits are allowed", symbol)
+		}
+	}
+	return nil
+}
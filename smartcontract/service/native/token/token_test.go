@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	InitToken()
+}
+
+func getUint64(t *testing.T, suite *testsuite.NativeSuite, key []byte) uint64 {
+	value, err := suite.Get(key)
+	assert.Nil(t, err)
+	if value == nil {
+		return 0
+	}
+	n, err := serialization.ReadUint64(bytes.NewBuffer(value))
+	assert.Nil(t, err)
+	return n
+}
+
+func TestTokenContract(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	suite := testsuite.NewNativeSuite()
+	invoke := func(method string, param interface{}, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(utils.TokenContractAddress, method, []interface{}{param}, signer)
+		return err
+	}
+
+	owner := account.NewAccount("")
+	alice := account.NewAccount("")
+	bob := account.NewAccount("")
+	const symbol = "USDX"
+
+	assert.Nil(t, invoke(CREATE_NAME, &CreateParam{Symbol: symbol, Name: "USD X", Decimals: 6, TotalSupply: 1000,
+		Owner: owner.Address, Freezable: true}, owner))
+	assert.NotNil(t, invoke(CREATE_NAME, &CreateParam{Symbol: symbol, Name: "Other", TotalSupply: 1,
+		Owner: alice.Address}, alice))
+	assert.NotNil(t, invoke(CREATE_NAME, &CreateParam{Symbol: "usd", Name: "lower", Owner: alice.Address}, alice))
+
+	_, notify, err := suite.InvokeNative(utils.TokenContractAddress, TRANSFER_NAME, []interface{}{&TransferParam{
+		Symbol: symbol, States: []ont.State{{From: owner.Address, To: alice.Address, Value: 300}}}}, owner)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{TRANSFER_NAME, owner.Address.ToBase58(), alice.Address.ToBase58(), uint64(300),
+		symbol}, notify[0].States)
+	assert.Nil(t, invoke(APPROVE_NAME, &ApproveParam{Symbol: symbol,
+		State: ont.State{From: alice.Address, To: bob.Address, Value: 100}}, alice))
+
+	transferFrom := &TransferFromParam{Symbol: symbol,
+		State: ont.TransferFrom{Sender: bob.Address, From: alice.Address, To: bob.Address, Value: 60}}
+	assert.Nil(t, invoke(TRANSFERFROM_NAME, transferFrom, bob))
+	assert.NotNil(t, invoke(TRANSFERFROM_NAME, transferFrom, bob))
+	assert.NotNil(t, invoke(MINT_NAME, &MintParam{Symbol: symbol, To: alice.Address, Value: 10}, alice))
+	assert.Nil(t, invoke(MINT_NAME, &MintParam{Symbol: symbol, To: alice.Address, Value: 500}, owner))
+	assert.Nil(t, invoke(BURN_NAME, &BurnParam{Symbol: symbol, Value: 200}, owner))
+
+	//frozen account can neither send nor receive
+	assert.Nil(t, invoke(FREEZE_NAME, &AccountParam{Symbol: symbol, Account: bob.Address}, owner))
+	assert.NotNil(t, invoke(TRANSFER_NAME, &TransferParam{Symbol: symbol,
+		States: []ont.State{{From: bob.Address, To: alice.Address, Value: 10}}}, bob))
+	assert.NotNil(t, invoke(TRANSFER_NAME, &TransferParam{Symbol: symbol,
+		States: []ont.State{{From: alice.Address, To: bob.Address, Value: 10}}}, alice))
+
+	assert.Equal(t, uint64(500), getUint64(t, suite, GenBalanceKey(symbol, owner.Address)))
+	assert.Equal(t, uint64(740), getUint64(t, suite, GenBalanceKey(symbol, alice.Address)))
+	assert.Equal(t, uint64(60), getUint64(t, suite, GenBalanceKey(symbol, bob.Address)))
+	assert.Equal(t, uint64(40), getUint64(t, suite, GenApproveKey(symbol, alice.Address, bob.Address)))
+	assert.Equal(t, uint64(1300), getUint64(t, suite, GenTotalSupplyKey(symbol)))
+}
+
+func TestTokenActivation(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	height := config.TOKEN_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.TOKEN_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.TOKEN_HEIGHT[config.NETWORK_ID_SOLO_NET] = 2
+
+	suite := testsuite.NewNativeSuite()
+	owner := account.NewAccount("")
+	create := []interface{}{&CreateParam{Symbol: "USDX", Name: "USD X", TotalSupply: 1000, Owner: owner.Address}}
+	//no method of token contract is registered before the activation height
+	_, _, err := suite.InvokeNative(utils.TokenContractAddress, CREATE_NAME, create, owner)
+	assert.NotNil(t, err)
+	suite.Height = 2
+	_, _, err = suite.InvokeNative(utils.TokenContractAddress, CREATE_NAME, create, owner)
+	assert.Nil(t, err)
+}
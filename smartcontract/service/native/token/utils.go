@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package token
+
+import (
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+var (
+	PreInfo    = []byte{0x01}
+	PreSupply  = []byte{0x02}
+	PreBalance = []byte{0x03}
+	PreApprove = []byte{0x04}
+	PreFreeze  = []byte{0x05}
+)
+
+//Enabled return whether the token contract can be invoked at the height
+func Enabled(height uint32) bool {
+	return height >= config.GetTokenHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+//symbolKey prefix the symbol with its length, so that the keys of different symbols never overlap
+func symbolKey(symbol string) []byte {
+	return append([]byte{byte(len(symbol))}, symbol...)
+}
+
+func GenInfoKey(symbol string) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, PreInfo, symbolKey(symbol))
+}
+
+func GenTotalSupplyKey(symbol string) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, PreSupply, symbolKey(symbol))
+}
+
+func GenBalanceKey(symbol string, addr common.Address) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, PreBalance, symbolKey(symbol), addr[:])
+}
+
+func GenApproveKey(symbol string, from, to common.Address) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, PreApprove, symbolKey(symbol), from[:], to[:])
+}
+
+func GenFreezeKey(symbol string, addr common.Address) []byte {
+	return utils.ConcatKey(utils.TokenContractAddress, PreFreeze, symbolKey(symbol), addr[:])
+}
+
+//getTokenInfo return the information of token, or error if the token not exists
+func getTokenInfo(native *native.NativeService, symbol string) (*TokenInfo, error) {
+	item, err := utils.GetStorageItem(native, GenInfoKey(symbol))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("token %s not exist", symbol)
+	}
+	info := new(TokenInfo)
+	if err := info.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, fmt.Errorf("deserialize token info error:%v", err)
+	}
+	return info, nil
+}
+
+func putTokenInfo(native *native.NativeService, info *TokenInfo) {
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+	native.CacheDB.Put(GenInfoKey(info.Symbol), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func isFrozen(native *native.NativeService, symbol string, addr common.Address) (bool, error) {
+	item, err := utils.GetStorageItem(native, GenFreezeKey(symbol, addr))
+	if err != nil {
+		return false, err
+	}
+	return item != nil, nil
+}
+
+//checkNotFrozen check none of the accounts is frozen in token
+func checkNotFrozen(native *native.NativeService, info *TokenInfo, addrs ...common.Address) error {
+	if !info.Freezable {
+		return nil
+	}
+	for _, addr := range addrs {
+		frozen, err := isFrozen(native, info.Symbol, addr)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return fmt.Errorf("account %s is frozen in token %s", addr.ToBase58(), info.Symbol)
+		}
+	}
+	return nil
+}
+
+func checkAuthority(native *native.NativeService, info *TokenInfo) error {
+	if !native.ContextRef.CheckWitness(info.Authority) {
+		return fmt.Errorf("authentication failed, authority of token %s is %s", info.Symbol, info.Authority.ToBase58())
+	}
+	return nil
+}
+
+//subBalance decrease the balance of key, the storage item is deleted if the balance becomes zero
+func subBalance(native *native.NativeService, key []byte, value uint64) error {
+	balance, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return err
+	}
+	if balance < value {
+		return fmt.Errorf("balance insufficient, have %d, got %d", balance, value)
+	} else if balance == value {
+		native.CacheDB.Delete(key)
+	} else {
+		native.CacheDB.Put(key, utils.GenUInt64StorageItem(balance-value).ToArray())
+	}
+	return nil
+}
+
+func addBalance(native *native.NativeService, key []byte, value uint64) error {
+	balance, err := utils.GetStorageUInt64(native, key)
+	if err != nil {
+		return err
+	}
+	balance, overflow := common.SafeAdd(balance, value)
+	if overflow {
+		return fmt.Errorf("balance overflow")
+	}
+	native.CacheDB.Put(key, utils.GenUInt64StorageItem(balance).ToArray())
+	return nil
+}
+
+//transfer move the value of token between accounts without authentication
+func transfer(native *native.NativeService, info *TokenInfo, from, to common.Address, value uint64) error {
+	if err := checkNotFrozen(native, info, from, to); err != nil {
+		return err
+	}
+	if err := subBalance(native, GenBalanceKey(info.Symbol, from), value); err != nil {
+		return fmt.Errorf("transfer %s from %s error:%v", info.Symbol, from.ToBase58(), err)
+	}
+	return addBalance(native, GenBalanceKey(info.Symbol, to), value)
+}
+
+//AddTransferNotification push the transfer event in the same shape of ONT, followed by the symbol of token. The
+//from address of mint and to address of burn are empty address
+func AddTransferNotification(native *native.NativeService, symbol string, from, to common.Address, value uint64) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	pushEvent(native, []interface{}{TRANSFER_NAME, from.ToBase58(), to.ToBase58(), value, symbol})
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
@@ -20,6 +20,7 @@ package utils
 
 import (
 	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
 	"github.com/dnaproject2/DNA/errors"
 	"github.com/dnaproject2/DNA/smartcontract/event"
 	"github.com/dnaproject2/DNA/smartcontract/service/native"
@@ -47,3 +48,10 @@ func ValidateOwner(native *native.NativeService, address common.Address) error {
 	}
 	return nil
 }
+
+//CheckProposalWitness check if the method is invoked by a passed proposal executed by proposal contract, which is
+//accepted only from the activation height of proposal contract
+func CheckProposalWitness(native *native.NativeService) bool {
+	return native.Height >= config.GetProposalHeight(config.DefConfig.P2PNode.NetworkId) &&
+		native.ContextRef.CheckWitness(ProposalContractAddress)
+}
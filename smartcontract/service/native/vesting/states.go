@@ -0,0 +1,240 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vesting
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+//CreateParam lock Amount of Asset from Grantor for Beneficiary. Nothing is released before Start+Cliff, then the
+//amount is released linearly until Start+Duration. A time-locked transfer is a schedule with Cliff equal to Duration
+type CreateParam struct {
+	Grantor     common.Address
+	Beneficiary common.Address
+	Asset       common.Address
+	Amount      uint64
+	Start       uint32
+	Cliff       uint32
+	Duration    uint32
+	Revocable   bool
+}
+
+func (this *CreateParam) Serialization(sink *common.ZeroCopySink) {
+	utils.EncodeAddress(sink, this.Grantor)
+	utils.EncodeAddress(sink, this.Beneficiary)
+	utils.EncodeAddress(sink, this.Asset)
+	utils.EncodeVarUint(sink, this.Amount)
+	utils.EncodeVarUint(sink, uint64(this.Start))
+	utils.EncodeVarUint(sink, uint64(this.Cliff))
+	utils.EncodeVarUint(sink, uint64(this.Duration))
+	utils.EncodeVarUint(sink, boolToUint(this.Revocable))
+}
+
+func (this *CreateParam) Deserialization(source *common.ZeroCopySource) error {
+	var err error
+	if this.Grantor, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Beneficiary, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Asset, err = utils.DecodeAddress(source); err != nil {
+		return err
+	}
+	if this.Amount, err = utils.DecodeVarUint(source); err != nil {
+		return err
+	}
+	if this.Start, err = decodeUint32(source); err != nil {
+		return err
+	}
+	if this.Cliff, err = decodeUint32(source); err != nil {
+		return err
+	}
+	if this.Duration, err = decodeUint32(source); err != nil {
+		return err
+	}
+	revocable, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return err
+	}
+	this.Revocable = revocable != 0
+	return nil
+}
+
+//Schedule is the stored vesting schedule. Amount is reduced to the vested amount when revoked, and TimeOffset is the
+//time offset from genesis block until which the ONG unbound by locked ONT has been paid to beneficiary
+type Schedule struct {
+	Id          uint64
+	Grantor     common.Address
+	Beneficiary common.Address
+	Asset       common.Address
+	Amount      uint64
+	Claimed     uint64
+	Start       uint32
+	Cliff       uint32
+	Duration    uint32
+	Revocable   bool
+	Revoked     bool
+	TimeOffset  uint32
+}
+
+//Vested return the amount released at the timestamp
+func (this *Schedule) Vested(timestamp uint32) uint64 {
+	if this.Revoked || timestamp >= this.Start+this.Duration {
+		return this.Amount
+	}
+	if timestamp < this.Start+this.Cliff {
+		return 0
+	}
+	vested := new(big.Int).SetUint64(this.Amount)
+	vested.Mul(vested, big.NewInt(int64(timestamp-this.Start)))
+	vested.Div(vested, big.NewInt(int64(this.Duration)))
+	return vested.Uint64()
+}
+
+//Claimable return the released amount not claimed yet at the timestamp
+func (this *Schedule) Claimable(timestamp uint32) uint64 {
+	return this.Vested(timestamp) - this.Claimed
+}
+
+//Locked return the amount still held by vesting contract
+func (this *Schedule) Locked() uint64 {
+	return this.Amount - this.Claimed
+}
+
+func (this *Schedule) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Id)
+	sink.WriteAddress(this.Grantor)
+	sink.WriteAddress(this.Beneficiary)
+	sink.WriteAddress(this.Asset)
+	sink.WriteUint64(this.Amount)
+	sink.WriteUint64(this.Claimed)
+	sink.WriteUint32(this.Start)
+	sink.WriteUint32(this.Cliff)
+	sink.WriteUint32(this.Duration)
+	sink.WriteBool(this.Revocable)
+	sink.WriteBool(this.Revoked)
+	sink.WriteUint32(this.TimeOffset)
+}
+
+func (this *Schedule) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if this.Id, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Grantor, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Beneficiary, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Asset, eof = source.NextAddress(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Amount, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Claimed, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Start, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Cliff, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Duration, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	var err error
+	if this.Revocable, err = decodeBool(source); err != nil {
+		return err
+	}
+	if this.Revoked, err = decodeBool(source); err != nil {
+		return err
+	}
+	if this.TimeOffset, eof = source.NextUint32(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//ScheduleIds is the ids of schedules of a beneficiary
+type ScheduleIds struct {
+	Ids []uint64
+}
+
+func (this *ScheduleIds) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Ids)))
+	for _, id := range this.Ids {
+		sink.WriteUint64(id)
+	}
+}
+
+func (this *ScheduleIds) Deserialization(source *common.ZeroCopySource) error {
+	n, _, irregular, eof := source.NextVarUint()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return common.ErrIrregularData
+	}
+	this.Ids = make([]uint64, 0, n)
+	for i := uint64(0); i < n; i++ {
+		id, eof := source.NextUint64()
+		if eof {
+			return io.ErrUnexpectedEOF
+		}
+		this.Ids = append(this.Ids, id)
+	}
+	return nil
+}
+
+func decodeUint32(source *common.ZeroCopySource) (uint32, error) {
+	v, err := utils.DecodeVarUint(source)
+	if err != nil {
+		return 0, err
+	}
+	if v > uint64(^uint32(0)) {
+		return 0, common.ErrIrregularData
+	}
+	return uint32(v), nil
+}
+
+func decodeBool(source *common.ZeroCopySource) (bool, error) {
+	b, irregular, eof := source.NextBool()
+	if eof {
+		return false, io.ErrUnexpectedEOF
+	}
+	if irregular {
+		return false, common.ErrIrregularData
+	}
+	return b, nil
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
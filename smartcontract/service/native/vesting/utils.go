@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vesting
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/constants"
+	cstates "github.com/dnaproject2/DNA/core/states"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+var (
+	PreNextId      = []byte{0x01}
+	PreSchedule    = []byte{0x02}
+	PreBeneficiary = []byte{0x03}
+)
+
+//Enabled return whether the vesting contract can be invoked at the height
+func Enabled(height uint32) bool {
+	return height >= config.GetVestingHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+func GenNextIdKey() []byte {
+	return utils.ConcatKey(utils.VestingContractAddress, PreNextId)
+}
+
+func GenScheduleKey(id uint64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, id)
+	return utils.ConcatKey(utils.VestingContractAddress, PreSchedule, buf)
+}
+
+func GenBeneficiaryKey(addr common.Address) []byte {
+	return utils.ConcatKey(utils.VestingContractAddress, PreBeneficiary, addr[:])
+}
+
+//DecodeSchedule decode the storage value of schedule
+func DecodeSchedule(data []byte) (*Schedule, error) {
+	schedule := new(Schedule)
+	if err := schedule.Deserialization(common.NewZeroCopySource(data)); err != nil {
+		return nil, fmt.Errorf("deserialize schedule error:%v", err)
+	}
+	return schedule, nil
+}
+
+func getSchedule(native *native.NativeService, id uint64) (*Schedule, error) {
+	item, err := utils.GetStorageItem(native, GenScheduleKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("schedule %d not exist", id)
+	}
+	return DecodeSchedule(item.Value)
+}
+
+func putSchedule(native *native.NativeService, schedule *Schedule) {
+	sink := common.NewZeroCopySink(nil)
+	schedule.Serialization(sink)
+	native.CacheDB.Put(GenScheduleKey(schedule.Id), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getScheduleIds(native *native.NativeService, addr common.Address) (*ScheduleIds, error) {
+	ids := new(ScheduleIds)
+	item, err := utils.GetStorageItem(native, GenBeneficiaryKey(addr))
+	if err != nil || item == nil {
+		return ids, err
+	}
+	if err := ids.Deserialization(common.NewZeroCopySource(item.Value)); err != nil {
+		return nil, fmt.Errorf("deserialize schedule ids error:%v", err)
+	}
+	return ids, nil
+}
+
+func addScheduleId(native *native.NativeService, addr common.Address, id uint64) error {
+	ids, err := getScheduleIds(native, addr)
+	if err != nil {
+		return err
+	}
+	ids.Ids = append(ids.Ids, id)
+	sink := common.NewZeroCopySink(nil)
+	ids.Serialization(sink)
+	native.CacheDB.Put(GenBeneficiaryKey(addr), cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
+
+//timeOffset return the time offset of current block from genesis block, which is used for ONG unbinding
+func timeOffset(native *native.NativeService) uint32 {
+	if native.Time <= constants.GENESIS_BLOCK_TIMESTAMP {
+		return 0
+	}
+	return native.Time - constants.GENESIS_BLOCK_TIMESTAMP
+}
+
+//unboundOng return the ONG unbound by the locked ONT of schedule since last settlement
+func unboundOng(native *native.NativeService, schedule *Schedule) uint64 {
+	if schedule.Asset != utils.OntContractAddress {
+		return 0
+	}
+	return utils.CalcUnbindOng(schedule.Locked(), schedule.TimeOffset, timeOffset(native))
+}
+
+//settleOng pay the ONG unbound by the locked ONT of schedule to beneficiary. The locked ONT of all schedules is held
+//by vesting contract, so a self transfer is made first to get the unbound ONG of contract approved
+func settleOng(native *native.NativeService, schedule *Schedule) (uint64, error) {
+	amount := unboundOng(native, schedule)
+	schedule.TimeOffset = timeOffset(native)
+	if amount == 0 {
+		return 0, nil
+	}
+	if err := appCallTransfer(native, utils.OntContractAddress, utils.VestingContractAddress,
+		utils.VestingContractAddress, 1); err != nil {
+		return 0, err
+	}
+	if err := appCallTransferFrom(native, utils.OngContractAddress, utils.VestingContractAddress,
+		utils.OntContractAddress, schedule.Beneficiary, amount); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+func appCallTransfer(native *native.NativeService, contract, from, to common.Address, amount uint64) error {
+	transfers := ont.Transfers{
+		States: []ont.State{{From: from, To: to, Value: amount}},
+	}
+	sink := common.NewZeroCopySink(nil)
+	transfers.Serialization(sink)
+	if _, err := native.NativeCall(contract, ont.TRANSFER_NAME, sink.Bytes()); err != nil {
+		return fmt.Errorf("appCallTransfer, appCall error: %v", err)
+	}
+	return nil
+}
+
+func appCallTransferFrom(native *native.NativeService, contract, sender, from, to common.Address, amount uint64) error {
+	params := &ont.TransferFrom{
+		Sender: sender,
+		From:   from,
+		To:     to,
+		Value:  amount,
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.Serialization(sink)
+	if _, err := native.NativeCall(contract, ont.TRANSFERFROM_NAME, sink.Bytes()); err != nil {
+		return fmt.Errorf("appCallTransferFrom, appCall error: %v", err)
+	}
+	return nil
+}
+
+func pushEvent(native *native.NativeService, s interface{}) {
+	event := new(event.NotifyEventInfo)
+	event.ContractAddress = native.ContextRef.CurrentContext().ContractAddress
+	event.States = s
+	native.Notifications = append(native.Notifications, event)
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vesting
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/constants"
+	"github.com/dnaproject2/DNA/smartcontract/service/native"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+)
+
+const (
+	//function name
+	CREATE_NAME        = "create"
+	CLAIM_NAME         = "claim"
+	REVOKE_NAME        = "revoke"
+	GETSCHEDULE_NAME   = "getSchedule"
+	GETSCHEDULES_NAME  = "getSchedules"
+	GETCLAIMABLE_NAME  = "getClaimable"
+	GETUNBOUNDONG_NAME = "getUnboundOng"
+)
+
+func InitVesting() {
+	native.Contracts[utils.VestingContractAddress] = RegisterVestingContract
+}
+
+func RegisterVestingContract(native *native.NativeService) {
+	if !Enabled(native.Height) {
+		return
+	}
+	native.Register(CREATE_NAME, VestingCreate)
+	native.Register(CLAIM_NAME, VestingClaim)
+	native.Register(REVOKE_NAME, VestingRevoke)
+	native.Register(GETSCHEDULE_NAME, GetSchedule)
+	native.Register(GETSCHEDULES_NAME, GetSchedules)
+	native.Register(GETCLAIMABLE_NAME, GetClaimable)
+	native.Register(GETUNBOUNDONG_NAME, GetUnboundOng)
+}
+
+//VestingCreate lock the ONT or ONG of grantor in vesting contract and return the id of schedule
+func VestingCreate(native *native.NativeService) ([]byte, error) {
+	var param CreateParam
+	if err := param.Deserialization(common.NewZeroCopySource(native.Input)); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] deserialize param error:%v", err)
+	}
+	var totalSupply uint64
+	switch param.Asset {
+	case utils.OntContractAddress:
+		totalSupply = constants.ONT_TOTAL_SUPPLY
+	case utils.OngContractAddress:
+		totalSupply = constants.ONG_TOTAL_SUPPLY
+	default:
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] asset %s is neither ONT nor ONG", param.Asset.ToHexString())
+	}
+	if param.Amount == 0 || param.Amount > totalSupply {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] invalid amount:%d", param.Amount)
+	}
+	if param.Duration == 0 || param.Cliff > param.Duration {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] invalid cliff:%d or duration:%d", param.Cliff,
+			param.Duration)
+	}
+	if param.Start+param.Duration < param.Start {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] end time of schedule overflow")
+	}
+	if param.Beneficiary == common.ADDRESS_EMPTY || param.Beneficiary == utils.VestingContractAddress {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] invalid beneficiary:%s", param.Beneficiary.ToBase58())
+	}
+	if !native.ContextRef.CheckWitness(param.Grantor) {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] authentication failed!")
+	}
+	if err := appCallTransfer(native, param.Asset, param.Grantor, utils.VestingContractAddress,
+		param.Amount); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] lock asset error:%v", err)
+	}
+
+	id, err := utils.GetStorageUInt64(native, GenNextIdKey())
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] get next id error:%v", err)
+	}
+	id++
+	schedule := &Schedule{
+		Id:          id,
+		Grantor:     param.Grantor,
+		Beneficiary: param.Beneficiary,
+		Asset:       param.Asset,
+		Amount:      param.Amount,
+		Start:       param.Start,
+		Cliff:       param.Cliff,
+		Duration:    param.Duration,
+		Revocable:   param.Revocable,
+		TimeOffset:  timeOffset(native),
+	}
+	putSchedule(native, schedule)
+	native.CacheDB.Put(GenNextIdKey(), utils.GenUInt64StorageItem(id).ToArray())
+	if err := addScheduleId(native, param.Beneficiary, id); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingCreate] add schedule id error:%v", err)
+	}
+	pushEvent(native, []interface{}{CREATE_NAME, id, param.Grantor.ToBase58(), param.Beneficiary.ToBase58(),
+		param.Asset.ToHexString(), param.Amount})
+	return common.BigIntToNeoBytes(new(big.Int).SetUint64(id)), nil
+}
+
+//VestingClaim transfer the released asset and the ONG unbound by locked ONT to beneficiary
+func VestingClaim(native *native.NativeService) ([]byte, error) {
+	id, err := utils.DecodeVarUint(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingClaim] read schedule id error:%v", err)
+	}
+	schedule, err := getSchedule(native, id)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingClaim] %v", err)
+	}
+	if !native.ContextRef.CheckWitness(schedule.Beneficiary) {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingClaim] authentication failed!")
+	}
+	ong, err := settleOng(native, schedule)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingClaim] settle ong error:%v", err)
+	}
+	amount := schedule.Claimable(native.Time)
+	if amount == 0 && ong == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingClaim] nothing to claim in schedule %d", id)
+	}
+	if amount > 0 {
+		if err := appCallTransfer(native, schedule.Asset, utils.VestingContractAddress, schedule.Beneficiary,
+			amount); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("[VestingClaim] release asset error:%v", err)
+		}
+		schedule.Claimed += amount
+	}
+	putSchedule(native, schedule)
+	pushEvent(native, []interface{}{CLAIM_NAME, id, schedule.Beneficiary.ToBase58(), amount, ong})
+	return utils.BYTE_TRUE, nil
+}
+
+//VestingRevoke return the unreleased asset to grantor, the released part is still claimable by beneficiary
+func VestingRevoke(native *native.NativeService) ([]byte, error) {
+	id, err := utils.DecodeVarUint(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] read schedule id error:%v", err)
+	}
+	schedule, err := getSchedule(native, id)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] %v", err)
+	}
+	if !schedule.Revocable {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] schedule %d is not revocable", id)
+	}
+	if schedule.Revoked {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] schedule %d is already revoked", id)
+	}
+	if !native.ContextRef.CheckWitness(schedule.Grantor) {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] authentication failed!")
+	}
+	if _, err := settleOng(native, schedule); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] settle ong error:%v", err)
+	}
+	vested := schedule.Vested(native.Time)
+	unvested := schedule.Amount - vested
+	if unvested > 0 {
+		if err := appCallTransfer(native, schedule.Asset, utils.VestingContractAddress, schedule.Grantor,
+			unvested); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("[VestingRevoke] return asset error:%v", err)
+		}
+	}
+	schedule.Amount = vested
+	schedule.Revoked = true
+	putSchedule(native, schedule)
+	pushEvent(native, []interface{}{REVOKE_NAME, id, schedule.Grantor.ToBase58(), unvested})
+	return utils.BYTE_TRUE, nil
+}
+
+//GetSchedule return the serialized schedule
+func GetSchedule(native *native.NativeService) ([]byte, error) {
+	schedule, err := readSchedule(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetSchedule] %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	schedule.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//GetSchedules return the serialized ids of schedules of beneficiary
+func GetSchedules(native *native.NativeService) ([]byte, error) {
+	addr, err := utils.DecodeAddress(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetSchedules] read address error:%v", err)
+	}
+	ids, err := getScheduleIds(native, addr)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetSchedules] %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	ids.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+//GetClaimable return the released amount of schedule not claimed yet
+func GetClaimable(native *native.NativeService) ([]byte, error) {
+	schedule, err := readSchedule(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetClaimable] %v", err)
+	}
+	return common.BigIntToNeoBytes(new(big.Int).SetUint64(schedule.Claimable(native.Time))), nil
+}
+
+//GetUnboundOng return the ONG unbound by the locked ONT of schedule not paid yet
+func GetUnboundOng(native *native.NativeService) ([]byte, error) {
+	schedule, err := readSchedule(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("[GetUnboundOng] %v", err)
+	}
+	return common.BigIntToNeoBytes(new(big.Int).SetUint64(unboundOng(native, schedule))), nil
+}
+
+//readSchedule read the schedule id from input and return the schedule
+func readSchedule(native *native.NativeService) (*Schedule, error) {
+	id, err := utils.DecodeVarUint(common.NewZeroCopySource(native.Input))
+	if err != nil {
+		return nil, fmt.Errorf("read schedule id error:%v", err)
+	}
+	return getSchedule(native, id)
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vesting
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dnaproject2/DNA/account"
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/common/config"
+	"github.com/dnaproject2/DNA/common/constants"
+	"github.com/dnaproject2/DNA/common/serialization"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ong"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/ont"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/testsuite"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/utils"
+	"github.com/dnaproject2/DNA/smartcontract/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	ont.InitOnt()
+	ong.InitOng()
+	InitVesting()
+}
+
+//newVestingSuite return a suite in which grantor holds ONT of amount, and all ONG is held by ONT contract to be
+//unbound
+func newVestingSuite(t *testing.T, grantor common.Address, amount uint64) *testsuite.NativeSuite {
+	suite := testsuite.NewNativeSuite()
+	setHeight(suite, 1)
+	assert.Nil(t, suite.Update(func(cache *storage.CacheDB) error {
+		cache.Put(ont.GenBalanceKey(utils.OntContractAddress, grantor), utils.GenUInt64StorageItem(amount).ToArray())
+		cache.Put(ont.GenBalanceKey(utils.OngContractAddress, utils.OntContractAddress),
+			utils.GenUInt64StorageItem(constants.ONG_TOTAL_SUPPLY).ToArray())
+		return nil
+	}))
+	return suite
+}
+
+//setHeight move the suite to height, whose block time is the genesis time plus height
+func setHeight(suite *testsuite.NativeSuite, height uint32) {
+	suite.Height = height
+	suite.Time = constants.GENESIS_BLOCK_TIMESTAMP + height
+}
+
+func getBalance(t *testing.T, suite *testsuite.NativeSuite, asset, addr common.Address) uint64 {
+	value, err := suite.Get(ont.GenBalanceKey(asset, addr))
+	assert.Nil(t, err)
+	if value == nil {
+		return 0
+	}
+	balance, err := serialization.ReadUint64(bytes.NewBuffer(value))
+	assert.Nil(t, err)
+	return balance
+}
+
+func TestVesting(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	grantor := account.NewAccount("")
+	alice := account.NewAccount("")
+	bob := account.NewAccount("")
+	suite := newVestingSuite(t, grantor.Address, 1000)
+	invoke := func(method string, param interface{}, signer *account.Account) error {
+		_, _, err := suite.InvokeNative(utils.VestingContractAddress, method, []interface{}{param}, signer)
+		return err
+	}
+	start := constants.GENESIS_BLOCK_TIMESTAMP + 1
+
+	assert.Nil(t, invoke(CREATE_NAME, &CreateParam{Grantor: grantor.Address, Beneficiary: alice.Address,
+		Asset: utils.OntContractAddress, Amount: 100, Start: start, Cliff: 2, Duration: 4, Revocable: true}, grantor))
+	assert.Nil(t, invoke(CREATE_NAME, &CreateParam{Grantor: grantor.Address, Beneficiary: bob.Address,
+		Asset: utils.OntContractAddress, Amount: 40, Start: start, Cliff: 2, Duration: 2}, grantor))
+	assert.NotNil(t, invoke(CREATE_NAME, &CreateParam{Grantor: grantor.Address, Beneficiary: bob.Address,
+		Asset: utils.OntContractAddress, Amount: 1, Duration: 1}, bob))
+	assert.Equal(t, uint64(140), getBalance(t, suite, utils.OntContractAddress, utils.VestingContractAddress))
+
+	//before cliff only the ONG unbound by locked ONT is claimable
+	setHeight(suite, 2)
+	assert.Nil(t, invoke(CLAIM_NAME, uint64(1), alice))
+	assert.NotNil(t, invoke(REVOKE_NAME, uint64(2), grantor))
+	assert.Equal(t, uint64(0), getBalance(t, suite, utils.OntContractAddress, alice.Address))
+	assert.Equal(t, utils.CalcUnbindOng(100, 1, 2), getBalance(t, suite, utils.OngContractAddress, alice.Address))
+
+	setHeight(suite, 3)
+	assert.Nil(t, invoke(REVOKE_NAME, uint64(1), grantor))
+	assert.Nil(t, invoke(CLAIM_NAME, uint64(2), bob))
+	setHeight(suite, 4)
+	assert.Nil(t, invoke(CLAIM_NAME, uint64(1), alice))
+	setHeight(suite, 5)
+	assert.NotNil(t, invoke(CLAIM_NAME, uint64(1), alice))
+
+	value, err := suite.Get(GenScheduleKey(1))
+	assert.Nil(t, err)
+	schedule, err := DecodeSchedule(value)
+	assert.Nil(t, err)
+	assert.True(t, schedule.Revoked)
+	assert.Equal(t, uint64(50), schedule.Amount)
+	assert.Equal(t, uint64(50), schedule.Claimed)
+	assert.Equal(t, uint64(0), schedule.Locked())
+
+	assert.Equal(t, uint64(50), getBalance(t, suite, utils.OntContractAddress, alice.Address))
+	assert.Equal(t, uint64(40), getBalance(t, suite, utils.OntContractAddress, bob.Address))
+	assert.Equal(t, uint64(910), getBalance(t, suite, utils.OntContractAddress, grantor.Address))
+	assert.Equal(t, uint64(0), getBalance(t, suite, utils.OntContractAddress, utils.VestingContractAddress))
+	assert.Equal(t, utils.CalcUnbindOng(100, 1, 3)+utils.CalcUnbindOng(50, 3, 4),
+		getBalance(t, suite, utils.OngContractAddress, alice.Address))
+	assert.Equal(t, utils.CalcUnbindOng(40, 1, 3), getBalance(t, suite, utils.OngContractAddress, bob.Address))
+}
+
+func TestVestingActivation(t *testing.T) {
+	defer testsuite.UseSoloNet()()
+	height := config.VESTING_HEIGHT[config.NETWORK_ID_SOLO_NET]
+	defer func() { config.VESTING_HEIGHT[config.NETWORK_ID_SOLO_NET] = height }()
+	config.VESTING_HEIGHT[config.NETWORK_ID_SOLO_NET] = 2
+
+	grantor := account.NewAccount("")
+	suite := newVestingSuite(t, grantor.Address, 1000)
+	create := []interface{}{&CreateParam{Grantor: grantor.Address, Beneficiary: grantor.Address,
+		Asset: utils.OntContractAddress, Amount: 100, Duration: 1}}
+	//no method of vesting contract is registered before the activation height
+	_, _, err := suite.InvokeNative(utils.VestingContractAddress, CREATE_NAME, create, grantor)
+	assert.NotNil(t, err)
+	setHeight(suite, 2)
+	_, _, err = suite.InvokeNative(utils.VestingContractAddress, CREATE_NAME, create, grantor)
+	assert.Nil(t, err)
+}
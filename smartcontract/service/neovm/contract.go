@@ -24,6 +24,7 @@ import (
 	"github.com/dnaproject2/DNA/common"
 	"github.com/dnaproject2/DNA/core/payload"
 	"github.com/dnaproject2/DNA/errors"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
 	vm "github.com/dnaproject2/DNA/vm/neovm"
 )
 
@@ -40,6 +41,13 @@ func ContractCreate(service *NeoVmService, engine *vm.ExecutionEngine) error {
 	}
 	if dep == nil {
 		service.CacheDB.PutContract(contract)
+		if registry.Enabled(service.Height) {
+			version := registry.NewContractVersion(contract, service.Height, service.Tx.Hash())
+			deployer := service.ContextRef.CurrentContext().ContractAddress
+			if err := registry.RecordDeploy(service.CacheDB, contractAddress, deployer, version); err != nil {
+				return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractCreate] record deploy error!")
+			}
+		}
 		dep = contract
 	}
 	vm.PushData(engine, dep)
@@ -62,6 +70,12 @@ func ContractMigrate(service *NeoVmService, engine *vm.ExecutionEngine) error {
 
 	service.CacheDB.PutContract(contract)
 	service.CacheDB.DeleteContract(oldAddr)
+	if registry.Enabled(service.Height) {
+		version := registry.NewContractVersion(contract, service.Height, service.Tx.Hash())
+		if err := registry.MigrateContract(service.CacheDB, oldAddr, newAddr, version); err != nil {
+			return errors.NewDetailErr(err, errors.ErrNoCode, "[ContractMigrate] migrate registry error!")
+		}
+	}
 
 	iter := service.CacheDB.NewIterator(oldAddr[:])
 	for has := iter.First(); has; has = iter.Next() {
@@ -94,6 +108,9 @@ func ContractDestory(service *NeoVmService, engine *vm.ExecutionEngine) error {
 	}
 
 	service.CacheDB.DeleteContract(addr)
+	if registry.Enabled(service.Height) {
+		registry.DeleteContract(service.CacheDB, addr)
+	}
 
 	iter := service.CacheDB.NewIterator(addr[:])
 	for has := iter.First(); has; has = iter.Next() {
@@ -124,12 +141,17 @@ func ContractGetStorageContext(service *NeoVmService, engine *vm.ExecutionEngine
 	if !ok {
 		return errors.NewErr("[GetStorageContext] Pop data not contract!")
 	}
+	current := service.ContextRef.CurrentContext().ContractAddress
 	address := contractState.Address()
+	if registry.Enabled(service.Height) {
+		//the address of an upgraded contract differs from its code hash, so use the executing address
+		address = current
+	}
 	item, err := service.CacheDB.GetContract(address)
 	if err != nil || item == nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "[GetStorageContext] Get StorageContext nil")
 	}
-	if address != service.ContextRef.CurrentContext().ContractAddress {
+	if address != current || item.Address() != contractState.Address() {
 		return errors.NewErr("[GetStorageContext] CodeHash not equal!")
 	}
 	vm.PushData(engine, NewStorageContext(address))
@@ -79,6 +79,7 @@ func NativeInvoke(service *NeoVmService, engine *vm.ExecutionEngine) error {
 		Time:        service.Time,
 		ContextRef:  service.ContextRef,
 		ServiceMap:  make(map[string]native.Handler),
+		Tracer:      service.Tracer,
 	}
 
 	result, err := native.Invoke()
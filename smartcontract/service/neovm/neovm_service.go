@@ -30,6 +30,7 @@ import (
 	"github.com/dnaproject2/DNA/errors"
 	"github.com/dnaproject2/DNA/smartcontract/context"
 	"github.com/dnaproject2/DNA/smartcontract/event"
+	"github.com/dnaproject2/DNA/smartcontract/service/native/registry"
 	"github.com/dnaproject2/DNA/smartcontract/storage"
 	vm "github.com/dnaproject2/DNA/vm/neovm"
 	ntypes "github.com/dnaproject2/DNA/vm/neovm/types"
@@ -123,20 +124,32 @@ type NeoVmService struct {
 	ContextRef    context.ContextRef
 	Notifications []*event.NotifyEventInfo
 	Code          []byte
+	Address       scommon.Address //address of the invoked contract, it differs from the code hash after upgrade
 	Tx            *types.Transaction
 	Time          uint32
 	Height        uint32
 	BlockHash     scommon.Uint256
 	Engine        *vm.ExecutionEngine
 	PreExec       bool
+	Tracer        context.Tracer
 }
 
 // Invoke a smart contract
 func (this *NeoVmService) Invoke() (interface{}, error) {
+	if this.Tracer == nil {
+		return this.invoke()
+	}
+	this.Tracer.CaptureEnter(context.NEOVM_CONTRACT, this.contractAddress(), "", nil, this.ContextRef.GasLeft())
+	result, err := this.invoke()
+	this.Tracer.CaptureExit(result, this.ContextRef.GasLeft(), err)
+	return result, err
+}
+
+func (this *NeoVmService) invoke() (interface{}, error) {
 	if len(this.Code) == 0 {
 		return nil, ERR_EXECUTE_CODE
 	}
-	this.ContextRef.PushContext(&context.Context{ContractAddress: scommon.AddressFromVmCode(this.Code), Code: this.Code})
+	this.ContextRef.PushContext(&context.Context{ContractAddress: this.contractAddress(), Code: this.Code})
 	this.Engine.PushContext(vm.NewExecutionContext(this.Engine, this.Code))
 	for {
 		//check the execution step count
@@ -161,6 +174,9 @@ func (this *NeoVmService) Invoke() (interface{}, error) {
 			if !this.ContextRef.CheckUseGas(OPCODE_GAS) {
 				return nil, ERR_GAS_INSUFFICIENT
 			}
+			if this.Tracer != nil {
+				this.Tracer.CaptureGas(vm.OpCodeName(this.Engine.OpCode), false, OPCODE_GAS)
+			}
 		} else {
 			if err := this.Engine.ValidateOp(); err != nil {
 				return nil, err
@@ -172,6 +188,9 @@ func (this *NeoVmService) Invoke() (interface{}, error) {
 			if !this.ContextRef.CheckUseGas(price) {
 				return nil, ERR_GAS_INSUFFICIENT
 			}
+			if this.Tracer != nil {
+				this.Tracer.CaptureGas(this.Engine.OpExec.Name, false, price)
+			}
 		}
 		switch this.Engine.OpCode {
 		case vm.VERIFY:
@@ -232,6 +251,7 @@ func (this *NeoVmService) Invoke() (interface{}, error) {
 			if err != nil {
 				return nil, err
 			}
+			service.(*NeoVmService).Address = addr
 			this.Engine.EvaluationStack.CopyTo(service.(*NeoVmService).Engine.EvaluationStack)
 			result, err := service.Invoke()
 			if err != nil {
@@ -257,12 +277,29 @@ func (this *NeoVmService) Invoke() (interface{}, error) {
 	return nil, nil
 }
 
+//contractAddress return the address of invoked contract, which is the code hash if not specified or before
+//contracts can be upgraded
+func (this *NeoVmService) contractAddress() scommon.Address {
+	if this.Address != scommon.ADDRESS_EMPTY && registry.Enabled(this.Height) {
+		return this.Address
+	}
+	return scommon.AddressFromVmCode(this.Code)
+}
+
 // SystemCall provide register service for smart contract to interaction with blockchain
 func (this *NeoVmService) SystemCall(engine *vm.ExecutionEngine) error {
 	serviceName, err := engine.Context.OpReader.ReadVarString(vm.MAX_BYTEARRAY_SIZE)
 	if err != nil {
 		return err
 	}
+	err = this.systemCall(engine, serviceName)
+	if this.Tracer != nil {
+		this.Tracer.CaptureSyscall(serviceName, err)
+	}
+	return err
+}
+
+func (this *NeoVmService) systemCall(engine *vm.ExecutionEngine, serviceName string) error {
 	service, ok := ServiceMap[serviceName]
 	if !ok {
 		return errors.NewErr(fmt.Sprintf("[SystemCall] the given service is not supported: %s", serviceName))
@@ -279,6 +316,9 @@ func (this *NeoVmService) SystemCall(engine *vm.ExecutionEngine) error {
 	if !this.ContextRef.CheckUseGas(price) {
 		return ERR_GAS_INSUFFICIENT
 	}
+	if this.Tracer != nil {
+		this.Tracer.CaptureGas(serviceName, true, price)
+	}
 	if err := service.Execute(this, engine); err != nil {
 		return errors.NewDetailErr(err, errors.ErrNoCode, "[SystemCall] service execution error!")
 	}
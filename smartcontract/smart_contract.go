@@ -46,6 +46,7 @@ type SmartContract struct {
 	Gas           uint64
 	ExecStep      int
 	PreExec       bool
+	Tracer        context.Tracer // trace the execution if not nil
 }
 
 // Config describe smart contract need parameters configuration
@@ -113,6 +114,11 @@ func (this *SmartContract) CheckUseGas(gas uint64) bool {
 	return true
 }
 
+// GasLeft return the gas left for execution
+func (this *SmartContract) GasLeft() uint64 {
+	return this.Gas
+}
+
 func (this *SmartContract) checkContexts() bool {
 	if len(this.Contexts) > MAX_EXECUTE_ENGINE {
 		return false
@@ -138,6 +144,10 @@ func (this *SmartContract) NewExecuteEngine(code []byte) (context.Engine, error)
 		BlockHash:  this.Config.BlockHash,
 		Engine:     vm.NewExecutionEngine(this.Config.Height),
 		PreExec:    this.PreExec,
+		Tracer:     this.Tracer,
+	}
+	if this.Tracer != nil {
+		service.Engine.Tracer = this.Tracer
 	}
 	return service, nil
 }
@@ -154,6 +164,7 @@ func (this *SmartContract) NewNativeService() (*native.NativeService, error) {
 		Height:     this.Config.Height,
 		BlockHash:  this.Config.BlockHash,
 		ServiceMap: make(map[string]native.Handler),
+		Tracer:     this.Tracer,
 	}
 	return service, nil
 }
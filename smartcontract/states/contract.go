@@ -116,3 +116,43 @@ type PreExecResult struct {
 	Result interface{}
 	Notify []*event.NotifyEventInfo
 }
+
+//GasEstimate is the gas needed by a transaction, with the suggested gas limit and the minimum gas price.
+//Signature verification of the transaction is not charged by the node, so it is not included
+type GasEstimate struct {
+	State    byte
+	Gas      uint64 //gas of execution and code length, as charged by the tx handler
+	GasLimit uint64 //suggested gas limit with safety margin
+	GasPrice uint64 //minimum gas price accepted by the network
+	Result   interface{}
+	Notify   []*event.NotifyEventInfo
+}
+
+//StateOverride is the states replaced before pre-executing a transaction, which only take effect in that execution
+type StateOverride struct {
+	Height   uint32 //block height of execution, 0 means the next block height
+	Time     uint32 //block time of execution, 0 means the current time
+	Balances []*BalanceOverride
+	Storage  []*StorageOverride
+	Code     []*CodeOverride
+}
+
+//BalanceOverride replace the balance of address in native asset contract
+type BalanceOverride struct {
+	Asset   common.Address
+	Address common.Address
+	Balance uint64
+}
+
+//StorageOverride replace the storage value of contract, nil value deletes the key
+type StorageOverride struct {
+	Contract common.Address
+	Key      []byte
+	Value    []byte
+}
+
+//CodeOverride replace the code of contract, keeping its address
+type CodeOverride struct {
+	Contract common.Address
+	Code     []byte
+}
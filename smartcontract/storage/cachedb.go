@@ -32,8 +32,21 @@ type CacheDB struct {
 	memdb      *overlaydb.MemDB
 	backend    *overlaydb.OverlayDB
 	keyScratch []byte
+	tracer     Tracer
 }
 
+//Tracer is notified with the contract storage access through CacheDB
+type Tracer interface {
+	//CaptureStorage is called with the operation STORAGE_GET/STORAGE_PUT/STORAGE_DELETE, the storage key and value
+	CaptureStorage(op string, key, value []byte)
+}
+
+const (
+	STORAGE_GET    = "GET"
+	STORAGE_PUT    = "PUT"
+	STORAGE_DELETE = "DELETE"
+)
+
 const initCap = 1024
 const initKvNum = 16
 
@@ -45,6 +58,11 @@ func NewCacheDB(store *overlaydb.OverlayDB) *CacheDB {
 	}
 }
 
+//SetTracer set the tracer notified with storage access, nil means disable tracing
+func (self *CacheDB) SetTracer(tracer Tracer) {
+	self.tracer = tracer
+}
+
 func (self *CacheDB) Reset() {
 	self.memdb.Reset()
 }
@@ -75,6 +93,9 @@ func (self *CacheDB) Commit() {
 }
 
 func (self *CacheDB) Put(key []byte, value []byte) {
+	if self.tracer != nil {
+		self.tracer.CaptureStorage(STORAGE_PUT, key, value)
+	}
 	self.put(common.ST_STORAGE, key, value)
 }
 
@@ -101,8 +122,11 @@ func (self *CacheDB) GetContract(addr comm.Address) (*payload.DeployCode, error)
 }
 
 func (self *CacheDB) PutContract(contract *payload.DeployCode) error {
-	address := contract.Address()
+	return self.PutContractAt(contract.Address(), contract)
+}
 
+//PutContractAt put the contract at address, which may differ from the address derived from its code
+func (self *CacheDB) PutContractAt(address comm.Address, contract *payload.DeployCode) error {
 	sink := comm.NewZeroCopySink(nil)
 	err := contract.Serialization(sink)
 	if err != nil {
@@ -119,7 +143,11 @@ func (self *CacheDB) DeleteContract(address comm.Address) {
 }
 
 func (self *CacheDB) Get(key []byte) ([]byte, error) {
-	return self.get(common.ST_STORAGE, key)
+	value, err := self.get(common.ST_STORAGE, key)
+	if err == nil && self.tracer != nil {
+		self.tracer.CaptureStorage(STORAGE_GET, key, value)
+	}
+	return value, err
 }
 
 func (self *CacheDB) get(prefix common.DataEntryPrefix, key []byte) ([]byte, error) {
@@ -137,6 +165,9 @@ func (self *CacheDB) get(prefix common.DataEntryPrefix, key []byte) ([]byte, err
 }
 
 func (self *CacheDB) Delete(key []byte) {
+	if self.tracer != nil {
+		self.tracer.CaptureStorage(STORAGE_DELETE, key, nil)
+	}
 	self.delete(common.ST_STORAGE, key)
 }
 
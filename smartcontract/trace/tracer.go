@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+
+//Package trace records the execution steps of smart contracts, used to debug transactions
+package trace
+
+import (
+	"fmt"
+
+	"github.com/dnaproject2/DNA/common"
+	scommon "github.com/dnaproject2/DNA/smartcontract/common"
+	"github.com/dnaproject2/DNA/smartcontract/context"
+	"github.com/dnaproject2/DNA/smartcontract/event"
+	vm "github.com/dnaproject2/DNA/vm/neovm"
+	vmtypes "github.com/dnaproject2/DNA/vm/neovm/types"
+)
+
+const MAX_TRACE_LOGS = 100000 //Max number of opcode and storage logs kept in a trace
+
+//CallFrame is a contract invocation in the call tree
+type CallFrame struct {
+	Type     string //neovm or native
+	Contract string
+	Method   string
+	Input    string
+	Output   interface{}
+	GasUsed  uint64
+	Error    string
+	Calls    []*CallFrame
+
+	gas uint64
+}
+
+//OpLog is an opcode executed by neovm
+type OpLog struct {
+	Pc      int
+	Op      string
+	Depth   int //Depth of the call frame executing opcode
+	Stack   int //Evaluation stack size before executing
+	Syscall string
+	Error   string
+}
+
+//StorageLog is a storage access of contract
+type StorageLog struct {
+	Depth    int
+	Op       string
+	Contract string
+	Key      string
+	Value    string
+}
+
+//TxTrace is the execution trace of a transaction
+type TxTrace struct {
+	TxHash      common.Uint256
+	Height      uint32 //Height of the block executing transaction
+	State       byte
+	GasConsumed uint64
+	Error       string
+	Notify      []*event.NotifyEventInfo
+	Calls       []*CallFrame
+	Ops         []*OpLog
+	Storage     []*StorageLog
+	Truncated   bool //Whether the opcode and storage logs exceed MAX_TRACE_LOGS
+}
+
+//StructTracer implements the context.Tracer, and records the call tree, opcode and storage logs of execution
+type StructTracer struct {
+	trace   *TxTrace
+	frames  []*CallFrame
+	syscall int //Index of the last SYSCALL opcode log
+}
+
+var _ context.Tracer = (*StructTracer)(nil)
+
+//NewStructTracer return a StructTracer instance
+func NewStructTracer() *StructTracer {
+	return &StructTracer{
+		trace:   &TxTrace{Ops: make([]*OpLog, 0), Storage: make([]*StorageLog, 0)},
+		syscall: -1,
+	}
+}
+
+//Trace return the trace recorded
+func (this *StructTracer) Trace() *TxTrace {
+	return this.trace
+}
+
+func (this *StructTracer) CaptureEnter(vmType string, address common.Address, method string, input []byte, gas uint64) {
+	frame := &CallFrame{
+		Type:     vmType,
+		Contract: address.ToHexString(),
+		Method:   method,
+		Input:    common.ToHexString(input),
+		gas:      gas,
+	}
+	if len(this.frames) == 0 {
+		this.trace.Calls = append(this.trace.Calls, frame)
+	} else {
+		parent := this.frames[len(this.frames)-1]
+		parent.Calls = append(parent.Calls, frame)
+	}
+	this.frames = append(this.frames, frame)
+}
+
+func (this *StructTracer) CaptureExit(output interface{}, gas uint64, err error) {
+	if len(this.frames) == 0 {
+		return
+	}
+	frame := this.frames[len(this.frames)-1]
+	this.frames = this.frames[:len(this.frames)-1]
+	if frame.gas > gas {
+		frame.GasUsed = frame.gas - gas
+	}
+	if err != nil {
+		frame.Error = err.Error()
+		return
+	}
+	frame.Output = convertOutput(output)
+}
+
+func (this *StructTracer) CaptureOp(engine *vm.ExecutionEngine, pc int, op vm.OpCode) {
+	if this.full() {
+		return
+	}
+	if op == vm.SYSCALL {
+		this.syscall = len(this.trace.Ops)
+	}
+	this.trace.Ops = append(this.trace.Ops, &OpLog{
+		Pc:    pc,
+		Op:    vm.OpCodeName(op),
+		Depth: len(this.frames),
+		Stack: engine.EvaluationStack.Count(),
+	})
+}
+
+func (this *StructTracer) CaptureSyscall(name string, err error) {
+	if this.syscall < 0 {
+		return
+	}
+	opLog := this.trace.Ops[this.syscall]
+	this.syscall = -1
+	opLog.Syscall = name
+	if err != nil {
+		opLog.Error = err.Error()
+	}
+}
+
+func (this *StructTracer) CaptureGas(name string, syscall bool, gas uint64) {
+}
+
+func (this *StructTracer) CaptureStorage(op string, key, value []byte) {
+	if this.full() {
+		return
+	}
+	storageLog := &StorageLog{
+		Depth: len(this.frames),
+		Op:    op,
+		Value: common.ToHexString(value),
+	}
+	if len(key) >= common.ADDR_LEN {
+		address, _ := common.AddressParseFromBytes(key[:common.ADDR_LEN])
+		storageLog.Contract = address.ToHexString()
+		key = key[common.ADDR_LEN:]
+	}
+	storageLog.Key = common.ToHexString(key)
+	this.trace.Storage = append(this.trace.Storage, storageLog)
+}
+
+func (this *StructTracer) full() bool {
+	if len(this.trace.Ops)+len(this.trace.Storage) < MAX_TRACE_LOGS {
+		return false
+	}
+	this.trace.Truncated = true
+	return true
+}
+
+func convertOutput(output interface{}) interface{} {
+	switch v := output.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return common.ToHexString(v)
+	case bool:
+		return v
+	case vmtypes.StackItems:
+		result, err := scommon.ConvertNeoVmTypeHexString(v)
+		if err != nil {
+			return nil
+		}
+		return result
+	default:
+		return fmt.Sprint(v)
+	}
+}
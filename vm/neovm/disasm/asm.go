@@ -0,0 +1,169 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package disasm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/vm/neovm"
+)
+
+var opcodes = make(map[string]neovm.OpCode)
+
+func init() {
+	for i := 0; i < 256; i++ {
+		op := neovm.OpCode(i)
+		if neovm.OpExecList[op].Name != "" || op >= neovm.PUSHBYTES1 && op <= neovm.PUSHBYTES75 {
+			opcodes[neovm.OpCodeName(op)] = op
+		}
+	}
+	opcodes["TAILCALL"] = neovm.TAILCALL
+}
+
+type fixup struct {
+	line   int
+	offset int //offset of jump instruction
+	label  string
+}
+
+//stripComment remove the comment started with ';' outside of quoted string
+func stripComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+//Assemble encode the assembly in the format printed by Format into NeoVM code. Each line contains an optional
+//offset prefixed with 0x, an opcode and its operand, or a label definition ending with ':'. Comments start with ';'
+func Assemble(text string) ([]byte, error) {
+	sink := common.NewZeroCopySink(nil)
+	labels := make(map[string]int)
+	var fixups []fixup
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 1 && strings.HasSuffix(line, ":") {
+			name := strings.TrimSuffix(line, ":")
+			if _, ok := labels[name]; ok {
+				return nil, fmt.Errorf("line %d: duplicated label %s", i+1, name)
+			}
+			labels[name] = int(sink.Size())
+			continue
+		}
+		if strings.HasPrefix(fields[0], "0x") {
+			line = strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+			fields = fields[1:]
+			if len(fields) == 0 {
+				continue
+			}
+		}
+		op, ok := opcodes[strings.ToUpper(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown opcode %s", i+1, fields[0])
+		}
+		operand := strings.TrimSpace(line[len(fields[0]):])
+		offset := int(sink.Size())
+		sink.WriteByte(byte(op))
+		var err error
+		switch {
+		case op >= neovm.PUSHBYTES1 && op <= neovm.PUSHBYTES75:
+			var data []byte
+			if data, err = common.HexToBytes(operand); err == nil && len(data) != int(op) {
+				err = fmt.Errorf("%s expects %d bytes, got %d", fields[0], op, len(data))
+			}
+			sink.WriteBytes(data)
+		case op == neovm.PUSHDATA1 || op == neovm.PUSHDATA2 || op == neovm.PUSHDATA4:
+			var data []byte
+			if data, err = common.HexToBytes(operand); err != nil {
+				break
+			}
+			switch {
+			case op == neovm.PUSHDATA1 && len(data) <= math.MaxUint8:
+				sink.WriteByte(byte(len(data)))
+			case op == neovm.PUSHDATA2 && len(data) <= math.MaxUint16:
+				sink.WriteUint16(uint16(len(data)))
+			case op == neovm.PUSHDATA4 && uint64(len(data)) <= math.MaxUint32:
+				sink.WriteUint32(uint32(len(data)))
+			default:
+				err = fmt.Errorf("data of %d bytes too long for %s", len(data), fields[0])
+			}
+			sink.WriteBytes(data)
+		case op == neovm.SYSCALL:
+			var name string
+			if name, err = strconv.Unquote(operand); err == nil {
+				sink.WriteString(name)
+			}
+		case isCall(op):
+			var addr common.Address
+			if addr, err = common.AddressFromHexString(operand); err == nil {
+				sink.WriteAddress(addr)
+			}
+		case isJump(op):
+			if n, e := strconv.ParseInt(operand, 10, 16); e == nil {
+				sink.WriteInt16(int16(n))
+			} else if operand != "" && !strings.ContainsAny(operand, " \t") {
+				fixups = append(fixups, fixup{line: i + 1, offset: offset, label: operand})
+				sink.WriteInt16(0)
+			} else {
+				err = fmt.Errorf("invalid jump target %q", operand)
+			}
+		default:
+			if operand != "" {
+				err = fmt.Errorf("%s takes no operand", fields[0])
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+	}
+	code := sink.Bytes()
+	for _, f := range fixups {
+		target, ok := labels[f.label]
+		if !ok {
+			return nil, fmt.Errorf("line %d: undefined label %s", f.line, f.label)
+		}
+		jump := target - f.offset
+		if jump < math.MinInt16 || jump > math.MaxInt16 {
+			return nil, fmt.Errorf("line %d: label %s out of jump range", f.line, f.label)
+		}
+		binary.LittleEndian.PutUint16(code[f.offset+1:], uint16(int16(jump)))
+	}
+	return code, nil
+}
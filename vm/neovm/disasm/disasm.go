@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package disasm provides functions for disassembling and assembling NeoVM bytecode.
+package disasm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/dnaproject2/DNA/vm/neovm/utils"
+)
+
+//Instr is an instruction of NeoVM code, consisting of the opcode and its operand
+type Instr struct {
+	Offset  int
+	Op      neovm.OpCode
+	Data    []byte         //data pushed by PUSHBYTES and PUSHDATA
+	Syscall string         //service name of SYSCALL
+	Address common.Address //contract called by APPCALL and TAILCALL, empty for dynamic call
+	Jump    int16          //offset of JMP, JMPIF, JMPIFNOT and CALL relative to the instruction
+}
+
+//Target return the absolute offset jumped to by JMP, JMPIF, JMPIFNOT and CALL
+func (self *Instr) Target() int {
+	return self.Offset + int(self.Jump)
+}
+
+//Name return the mnemonic of instruction
+func (self *Instr) Name() string {
+	if self.Op == neovm.TAILCALL {
+		return "TAILCALL"
+	}
+	return neovm.OpCodeName(self.Op)
+}
+
+func isJump(op neovm.OpCode) bool {
+	return op == neovm.JMP || op == neovm.JMPIF || op == neovm.JMPIFNOT || op == neovm.CALL
+}
+
+func isCall(op neovm.OpCode) bool {
+	return op == neovm.APPCALL || op == neovm.TAILCALL
+}
+
+//Disassemble decode NeoVM code into instructions
+func Disassemble(code []byte) ([]Instr, error) {
+	var instrs []Instr
+	reader := utils.NewVmReader(code)
+	for reader.Length() > 0 {
+		instr := Instr{Offset: reader.Position()}
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		instr.Op = neovm.OpCode(b)
+		switch op := instr.Op; {
+		case op >= neovm.PUSHBYTES1 && op <= neovm.PUSHBYTES75:
+			instr.Data, err = readBytes(reader, uint64(op))
+		case op == neovm.PUSHDATA1:
+			var n byte
+			if n, err = reader.ReadByte(); err == nil {
+				instr.Data, err = readBytes(reader, uint64(n))
+			}
+		case op == neovm.PUSHDATA2:
+			var n uint16
+			if n, err = reader.ReadUint16(); err == nil {
+				instr.Data, err = readBytes(reader, uint64(n))
+			}
+		case op == neovm.PUSHDATA4:
+			var n uint32
+			if n, err = reader.ReadUint32(); err == nil {
+				instr.Data, err = readBytes(reader, uint64(n))
+			}
+		case op == neovm.SYSCALL:
+			var n uint64
+			if reader.Length() == 0 {
+				err = io.ErrUnexpectedEOF
+			} else if n, err = reader.ReadVarInt(uint64(neovm.MAX_BYTEARRAY_SIZE)); err == nil {
+				var name []byte
+				name, err = readBytes(reader, n)
+				instr.Syscall = string(name)
+			}
+		case isCall(op):
+			var addr []byte
+			if addr, err = readBytes(reader, common.ADDR_LEN); err == nil {
+				copy(instr.Address[:], addr)
+			}
+		case isJump(op):
+			if reader.Length() < 2 {
+				err = io.ErrUnexpectedEOF
+			} else {
+				instr.Jump, err = reader.ReadInt16()
+			}
+		case neovm.OpExecList[op].Name == "":
+			err = fmt.Errorf("unknown opcode 0x%02x", byte(op))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode instruction at offset %d error: %s", instr.Offset, err)
+		}
+		instrs = append(instrs, instr)
+	}
+	return instrs, nil
+}
+
+//readBytes read n bytes of operand, failing if the code is shorter
+func readBytes(reader *utils.VmReader, n uint64) ([]byte, error) {
+	if n > uint64(reader.Length()) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if n == 0 {
+		return []byte{}, nil
+	}
+	return reader.ReadBytes(int(n))
+}
+
+//label return the name of label at offset
+func label(offset int) string {
+	return fmt.Sprintf("L%04x", offset)
+}
+
+//Format print the instructions as assembly, one instruction per line prefixed with its offset in hex. Jump
+//targets are labeled, and the output can be assembled back to the same code by Assemble
+func Format(instrs []Instr) string {
+	offsets := make(map[int]bool, len(instrs))
+	for _, instr := range instrs {
+		offsets[instr.Offset] = true
+	}
+	labels := make(map[int]bool)
+	for _, instr := range instrs {
+		if isJump(instr.Op) && offsets[instr.Target()] {
+			labels[instr.Target()] = true
+		}
+	}
+	buf := new(bytes.Buffer)
+	for _, instr := range instrs {
+		if labels[instr.Offset] {
+			fmt.Fprintf(buf, "%s:\n", label(instr.Offset))
+		}
+		fmt.Fprintf(buf, "0x%04x  %s", instr.Offset, instr.Name())
+		switch {
+		case len(instr.Data) != 0:
+			fmt.Fprintf(buf, " %x", instr.Data)
+			if isPrintable(instr.Data) {
+				fmt.Fprintf(buf, " ; %q", instr.Data)
+			}
+		case instr.Op == neovm.SYSCALL:
+			fmt.Fprintf(buf, " %s", strconv.Quote(instr.Syscall))
+		case isCall(instr.Op):
+			fmt.Fprintf(buf, " %s", instr.Address.ToHexString())
+			if instr.Address == common.ADDRESS_EMPTY {
+				buf.WriteString(" ; dynamic call")
+			}
+		case isJump(instr.Op):
+			if labels[instr.Target()] {
+				fmt.Fprintf(buf, " %s", label(instr.Target()))
+			} else {
+				fmt.Fprintf(buf, " %+d ; invalid target %d", instr.Jump, instr.Target())
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+func isPrintable(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	for _, b := range data {
+		if b < 0x20 || b > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package disasm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/dnaproject2/DNA/common"
+	"github.com/dnaproject2/DNA/vm/neovm"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCode() []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteByte(3) //PUSHBYTES3
+	sink.WriteBytes([]byte("abc"))
+	sink.WriteByte(byte(neovm.PUSHDATA1))
+	sink.WriteVarBytes(bytes.Repeat([]byte{1}, 80))
+	sink.WriteByte(byte(neovm.DUP))
+	sink.WriteByte(byte(neovm.JMPIFNOT)) //jump to RET
+	sink.WriteInt16(33)
+	sink.WriteByte(byte(neovm.SYSCALL))
+	sink.WriteString("System.Storage.GetContext")
+	sink.WriteByte(byte(neovm.JMP)) //jump back to DUP
+	sink.WriteInt16(-31)
+	sink.WriteByte(byte(neovm.RET))
+	sink.WriteByte(byte(neovm.APPCALL))
+	sink.WriteAddress(common.Address{1, 2, 3})
+	return sink.Bytes()
+}
+
+func TestDisassemble(t *testing.T) {
+	code := testCode()
+	instrs, err := Disassemble(code)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, len(instrs))
+	assert.Equal(t, []byte("abc"), instrs[0].Data)
+	assert.Equal(t, 80, len(instrs[1].Data))
+	assert.Equal(t, instrs[6].Offset, instrs[3].Target())
+	assert.Equal(t, "System.Storage.GetContext", instrs[4].Syscall)
+	assert.Equal(t, instrs[2].Offset, instrs[5].Target())
+	assert.Equal(t, common.Address{1, 2, 3}, instrs[7].Address)
+
+	text := Format(instrs)
+	assert.True(t, strings.Contains(text, `PUSHBYTES3 616263 ; "abc"`))
+	assert.True(t, strings.Contains(text, `SYSCALL "System.Storage.GetContext"`))
+	assert.True(t, strings.Contains(text, "JMPIFNOT L0078"))
+	assert.True(t, strings.Contains(text, "L0078:\n0x0078  RET"))
+
+	assembled, err := Assemble(text)
+	assert.Nil(t, err)
+	assert.Equal(t, code, assembled)
+
+	for i := 1; i < len(code); i++ {
+		if _, err := Disassemble(code[:i]); err == nil {
+			assert.Contains(t, []int{4, 86, 87, 90, 117, 120, 121}, i)
+		}
+	}
+	_, err = Disassemble([]byte{0xff})
+	assert.NotNil(t, err)
+}
+
+func TestAssemble(t *testing.T) {
+	code, err := Assemble(`
+		; loop until zero
+		PUSH3
+	loop:
+		DEC
+		DUP
+		JMPIF loop ; back
+		SYSCALL "a;b"
+		pushdata1
+		RET
+	`)
+	assert.Nil(t, err)
+	expect := []byte{byte(neovm.PUSH3), byte(neovm.DEC), byte(neovm.DUP), byte(neovm.JMPIF), 0xfe, 0xff,
+		byte(neovm.SYSCALL), 3, 'a', ';', 'b', byte(neovm.PUSHDATA1), 0, byte(neovm.RET)}
+	assert.Equal(t, expect, code)
+
+	for _, text := range []string{"FOO", "JMP nowhere", "PUSHBYTES2 01", "ADD 1", "SYSCALL abc", "l:\nl:", "APPCALL 01"} {
+		_, err := Assemble(text)
+		assert.NotNil(t, err, text)
+	}
+}
@@ -44,6 +44,7 @@ type ExecutionEngine struct {
 	BlockHtLevel0   uint32
 	OpCode          OpCode
 	OpExec          OpExec
+	Tracer          Tracer
 }
 
 func (this *ExecutionEngine) CurrentContext() *ExecutionContext {
@@ -79,12 +80,16 @@ func (this *ExecutionEngine) Execute() error {
 }
 
 func (this *ExecutionEngine) ExecuteCode() error {
+	pc := this.Context.GetInstructionPointer()
 	code, err := this.Context.OpReader.ReadByte()
 	if err != nil {
 		this.State = FAULT
 		return err
 	}
 	this.OpCode = OpCode(code)
+	if this.Tracer != nil {
+		this.Tracer.CaptureOp(this, pc, this.OpCode)
+	}
 	return nil
 }
 
@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2018 The DNA Authors
+ * This file is part of The DNA library.
+ *
+ * The DNA is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The DNA is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The DNA.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package neovm
+
+import "fmt"
+
+//Tracer is notified with every opcode fetched by ExecutionEngine, used to trace the contract execution
+type Tracer interface {
+	//CaptureOp is called before executing the opcode at pc of current context
+	CaptureOp(engine *ExecutionEngine, pc int, op OpCode)
+}
+
+//OpCodeName return the readable name of opcode
+func OpCodeName(op OpCode) string {
+	if op >= PUSHBYTES1 && op <= PUSHBYTES75 {
+		return fmt.Sprintf("PUSHBYTES%d", op)
+	}
+	if name := OpExecList[op].Name; name != "" {
+		return name
+	}
+	return fmt.Sprintf("0x%02x", byte(op))
+}